@@ -13,6 +13,14 @@ type Config struct {
 	Logger      LoggerConfig
 	Redis       RedisConfig
 	RateLimiter RateLimiterConfig
+	Auth        AuthConfig
+	Reminders   ReminderConfig
+	SMTP        SMTPConfig
+	Webhooks    WebhookConfig
+	Stream      StreamConfig
+	Invitations InvitationConfig
+	Attachments AttachmentConfig
+	Trash       TrashConfig
 	JWTSecret   string `mapstructure:"jwt_secret"`
 }
 type ServersConfig struct {
@@ -56,6 +64,88 @@ type RateLimiterConfig struct {
 	ErrorMessage string `mapstructure:"errorMessage"`
 }
 
+type AuthConfig struct {
+	AccessTokenTTLMin    int `mapstructure:"accessTokenTTLMinutes"`
+	RefreshTokenTTLHours int `mapstructure:"refreshTokenTTLHours"`
+	AccessTokenTTL       time.Duration
+	RefreshTokenTTL      time.Duration
+}
+
+type ReminderConfig struct {
+	Enabled           bool `mapstructure:"enabled"`
+	PollSeconds       int  `mapstructure:"pollSeconds"`
+	LeaseSeconds      int  `mapstructure:"leaseSeconds"`
+	BatchSize         int  `mapstructure:"batchSize"`
+	MaxAttempts       int  `mapstructure:"maxAttempts"`
+	WebhookTimeoutSec int  `mapstructure:"webhookTimeoutSeconds"`
+	PollInterval      time.Duration
+	Lease             time.Duration
+	WebhookTimeout    time.Duration
+}
+
+type WebhookConfig struct {
+	Enabled      bool `mapstructure:"enabled"`
+	PollSeconds  int  `mapstructure:"pollSeconds"`
+	LeaseSeconds int  `mapstructure:"leaseSeconds"`
+	BatchSize    int  `mapstructure:"batchSize"`
+	MaxAttempts  int  `mapstructure:"maxAttempts"`
+	TimeoutSec   int  `mapstructure:"timeoutSeconds"`
+	PollInterval time.Duration
+	Lease        time.Duration
+	Timeout      time.Duration
+}
+
+type StreamConfig struct {
+	History        int      `mapstructure:"history"`
+	HeartbeatSec   int      `mapstructure:"heartbeatSeconds"`
+	AllowedOrigins []string `mapstructure:"allowedOrigins"`
+	Heartbeat      time.Duration
+}
+
+type InvitationConfig struct {
+	TTLHours  int    `mapstructure:"ttlHours"`
+	AcceptURL string `mapstructure:"acceptUrl"`
+	TTL       time.Duration
+}
+
+type AttachmentConfig struct {
+	Storage         string   `mapstructure:"storage"`
+	Dir             string   `mapstructure:"dir"`
+	MaxSizeMB       int      `mapstructure:"maxSizeMB"`
+	QuotaMB         int      `mapstructure:"quotaMB"`
+	AllowedTypes    []string `mapstructure:"allowedTypes"`
+	CleanupMinutes  int      `mapstructure:"cleanupMinutes"`
+	S3              S3Config `mapstructure:"s3"`
+	MaxSize         int64
+	Quota           int64
+	CleanupInterval time.Duration
+}
+
+type S3Config struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"accessKey"`
+	SecretKey string `mapstructure:"secretKey"`
+	PathStyle bool   `mapstructure:"pathStyle"`
+}
+
+type TrashConfig struct {
+	RetentionDays int `mapstructure:"retentionDays"`
+	PollMinutes   int `mapstructure:"pollMinutes"`
+	BatchSize     int `mapstructure:"batchSize"`
+	Retention     time.Duration
+	PollInterval  time.Duration
+}
+
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+}
+
 func NewConfig() *Config {
 	if err := godotenv.Load(); err != nil {
 		log.Println("[INFO] No .env file found. Using system environment variables.")
@@ -82,6 +172,30 @@ func NewConfig() *Config {
 	_ = viper.BindEnv("rate_limiter.windowSeconds", "TODO_RATELIMIT_WINDOW_SECONDS")
 	_ = viper.BindEnv("rate_limiter.errorMessage", "TODO_RATELIMIT_ERROR_MESSAGE")
 	_ = viper.BindEnv("jwt_secret", "TODO_JWT_SECRET")
+	_ = viper.BindEnv("auth.accessTokenTTLMinutes", "TODO_AUTH_ACCESS_TTL_MINUTES")
+	_ = viper.BindEnv("auth.refreshTokenTTLHours", "TODO_AUTH_REFRESH_TTL_HOURS")
+	_ = viper.BindEnv("reminders.enabled", "TODO_REMINDERS_ENABLED")
+	_ = viper.BindEnv("reminders.pollSeconds", "TODO_REMINDERS_POLL_SECONDS")
+	_ = viper.BindEnv("webhooks.enabled", "TODO_WEBHOOKS_ENABLED")
+	_ = viper.BindEnv("webhooks.maxAttempts", "TODO_WEBHOOKS_MAX_ATTEMPTS")
+	_ = viper.BindEnv("invitations.ttlHours", "TODO_INVITATIONS_TTL_HOURS")
+	_ = viper.BindEnv("invitations.acceptUrl", "TODO_INVITATIONS_ACCEPT_URL")
+	_ = viper.BindEnv("attachments.storage", "TODO_ATTACHMENTS_STORAGE")
+	_ = viper.BindEnv("attachments.dir", "TODO_ATTACHMENTS_DIR")
+	_ = viper.BindEnv("attachments.maxSizeMB", "TODO_ATTACHMENTS_MAX_SIZE_MB")
+	_ = viper.BindEnv("attachments.quotaMB", "TODO_ATTACHMENTS_QUOTA_MB")
+	_ = viper.BindEnv("attachments.s3.endpoint", "TODO_S3_ENDPOINT")
+	_ = viper.BindEnv("attachments.s3.region", "TODO_S3_REGION")
+	_ = viper.BindEnv("attachments.s3.bucket", "TODO_S3_BUCKET")
+	_ = viper.BindEnv("attachments.s3.accessKey", "TODO_S3_ACCESS_KEY")
+	_ = viper.BindEnv("attachments.s3.secretKey", "TODO_S3_SECRET_KEY")
+	_ = viper.BindEnv("trash.retentionDays", "TODO_TRASH_RETENTION_DAYS")
+	_ = viper.BindEnv("trash.pollMinutes", "TODO_TRASH_POLL_MINUTES")
+	_ = viper.BindEnv("smtp.host", "TODO_SMTP_HOST")
+	_ = viper.BindEnv("smtp.port", "TODO_SMTP_PORT")
+	_ = viper.BindEnv("smtp.username", "TODO_SMTP_USERNAME")
+	_ = viper.BindEnv("smtp.password", "TODO_SMTP_PASSWORD")
+	_ = viper.BindEnv("smtp.from", "TODO_SMTP_FROM")
 
 	// Cfg file
 	viper.SetConfigName("config")
@@ -107,5 +221,113 @@ func NewConfig() *Config {
 	// time.Duration для Rate Limiter
 	cfg.RateLimiter.Window = time.Duration(cfg.RateLimiter.WindowSec) * time.Second
 
+	// Время жизни токенов
+	if cfg.Auth.AccessTokenTTLMin <= 0 {
+		cfg.Auth.AccessTokenTTLMin = 15
+	}
+	if cfg.Auth.RefreshTokenTTLHours <= 0 {
+		cfg.Auth.RefreshTokenTTLHours = 720
+	}
+	cfg.Auth.AccessTokenTTL = time.Duration(cfg.Auth.AccessTokenTTLMin) * time.Minute
+	cfg.Auth.RefreshTokenTTL = time.Duration(cfg.Auth.RefreshTokenTTLHours) * time.Hour
+
+	// Планировщик напоминаний
+	if cfg.Reminders.PollSeconds <= 0 {
+		cfg.Reminders.PollSeconds = 15
+	}
+	if cfg.Reminders.LeaseSeconds <= 0 {
+		cfg.Reminders.LeaseSeconds = 120
+	}
+	if cfg.Reminders.BatchSize <= 0 {
+		cfg.Reminders.BatchSize = 50
+	}
+	if cfg.Reminders.MaxAttempts <= 0 {
+		cfg.Reminders.MaxAttempts = 5
+	}
+	if cfg.Reminders.WebhookTimeoutSec <= 0 {
+		cfg.Reminders.WebhookTimeoutSec = 10
+	}
+	cfg.Reminders.PollInterval = time.Duration(cfg.Reminders.PollSeconds) * time.Second
+	cfg.Reminders.Lease = time.Duration(cfg.Reminders.LeaseSeconds) * time.Second
+	cfg.Reminders.WebhookTimeout = time.Duration(cfg.Reminders.WebhookTimeoutSec) * time.Second
+
+	// Исходящие вебхуки
+	if cfg.Webhooks.PollSeconds <= 0 {
+		cfg.Webhooks.PollSeconds = 5
+	}
+	if cfg.Webhooks.LeaseSeconds <= 0 {
+		cfg.Webhooks.LeaseSeconds = 60
+	}
+	if cfg.Webhooks.BatchSize <= 0 {
+		cfg.Webhooks.BatchSize = 50
+	}
+	if cfg.Webhooks.MaxAttempts <= 0 {
+		cfg.Webhooks.MaxAttempts = 8
+	}
+	if cfg.Webhooks.TimeoutSec <= 0 {
+		cfg.Webhooks.TimeoutSec = 10
+	}
+	cfg.Webhooks.PollInterval = time.Duration(cfg.Webhooks.PollSeconds) * time.Second
+	cfg.Webhooks.Lease = time.Duration(cfg.Webhooks.LeaseSeconds) * time.Second
+	cfg.Webhooks.Timeout = time.Duration(cfg.Webhooks.TimeoutSec) * time.Second
+
+	// Поток событий для клиентов
+	if cfg.Stream.History <= 0 {
+		cfg.Stream.History = 1000
+	}
+	if cfg.Stream.HeartbeatSec <= 0 {
+		cfg.Stream.HeartbeatSec = 25
+	}
+	cfg.Stream.Heartbeat = time.Duration(cfg.Stream.HeartbeatSec) * time.Second
+
+	// Приглашения в рабочие пространства
+	if cfg.Invitations.TTLHours <= 0 {
+		cfg.Invitations.TTLHours = 168
+	}
+	cfg.Invitations.TTL = time.Duration(cfg.Invitations.TTLHours) * time.Hour
+
+	// Вложения задач
+	if cfg.Attachments.Storage == "" {
+		cfg.Attachments.Storage = "local"
+	}
+	if cfg.Attachments.Dir == "" {
+		cfg.Attachments.Dir = "data/attachments"
+	}
+	if cfg.Attachments.MaxSizeMB <= 0 {
+		cfg.Attachments.MaxSizeMB = 25
+	}
+	if cfg.Attachments.QuotaMB <= 0 {
+		cfg.Attachments.QuotaMB = 1024
+	}
+	if len(cfg.Attachments.AllowedTypes) == 0 {
+		cfg.Attachments.AllowedTypes = []string{"image/*", "text/plain", "text/csv", "application/pdf", "application/zip"}
+	}
+	if cfg.Attachments.CleanupMinutes <= 0 {
+		cfg.Attachments.CleanupMinutes = 60
+	}
+	if cfg.Attachments.S3.Region == "" {
+		cfg.Attachments.S3.Region = "us-east-1"
+	}
+	cfg.Attachments.MaxSize = int64(cfg.Attachments.MaxSizeMB) << 20
+	cfg.Attachments.Quota = int64(cfg.Attachments.QuotaMB) << 20
+	cfg.Attachments.CleanupInterval = time.Duration(cfg.Attachments.CleanupMinutes) * time.Minute
+
+	// Корзина задач
+	if cfg.Trash.RetentionDays <= 0 {
+		cfg.Trash.RetentionDays = 30
+	}
+	if cfg.Trash.PollMinutes <= 0 {
+		cfg.Trash.PollMinutes = 60
+	}
+	if cfg.Trash.BatchSize <= 0 {
+		cfg.Trash.BatchSize = 200
+	}
+	cfg.Trash.Retention = time.Duration(cfg.Trash.RetentionDays) * 24 * time.Hour
+	cfg.Trash.PollInterval = time.Duration(cfg.Trash.PollMinutes) * time.Minute
+
+	if cfg.SMTP.Port == 0 {
+		cfg.SMTP.Port = 587
+	}
+
 	return cfg
 }
@@ -1,8 +1,10 @@
 package dto
 
 import (
+	"strings"
 	"testing"
 	"time"
+	"todo-list/internal/domain/apperr"
 	"todo-list/internal/domain/model"
 
 	"github.com/google/uuid"
@@ -42,4 +44,88 @@ func TestTaskDTOs(t *testing.T) {
 		assert.ElementsMatch(t, []string{"Go", "Backend"}, response.Tags)
 		assert.Equal(t, now, response.CreatedAt)
 	})
+
+	t.Run("ToTaskResponseDTO_SearchMatch", func(t *testing.T) {
+		assert.Nil(t, ToTaskResponseDTO(model.Task{Title: "Без поиска"}).Match)
+
+		task := model.Task{
+			Title:          "Купить <молоко>",
+			SearchRank:     0.6,
+			TitleHighlight: "Купить <" + model.HighlightStart + "молоко" + model.HighlightStop + ">",
+		}
+		match := ToTaskResponseDTO(task).Match
+		if assert.NotNil(t, match) {
+			assert.Equal(t, 0.6, match.Rank)
+			// Текст экранируется, а совпадения выделяются тегом mark
+			assert.Equal(t, "Купить &lt;<mark>молоко</mark>&gt;", match.Title)
+			assert.Empty(t, match.Snippet)
+		}
+	})
+
+	t.Run("OccurrencesQueryDTO_Window", func(t *testing.T) {
+		now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+		from, to, limit, err := OccurrencesQueryDTO{}.Window(now)
+		assert.NoError(t, err)
+		assert.Equal(t, now, from)
+		assert.Equal(t, now.AddDate(1, 0, 0), to)
+		assert.Equal(t, DefaultOccurrenceLimit, limit)
+
+		_, to, limit, err = OccurrencesQueryDTO{From: "2026-11-01", To: "2026-12-01", Limit: 1000}.Window(now)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), to)
+		assert.Equal(t, MaxOccurrenceLimit, limit)
+
+		_, _, _, err = OccurrencesQueryDTO{From: "2026-12-01", To: "2026-11-01"}.Window(now)
+		assert.Equal(t, []apperr.Violation{{Field: "to", Reason: "must be after from"}}, apperr.ViolationsOf(err))
+
+		// Даты отсчитываются от полуночи в часовом поясе пользователя
+		moscow, err := time.LoadLocation("Europe/Moscow")
+		assert.NoError(t, err)
+		from, _, _, err = OccurrencesQueryDTO{From: "2026-11-01"}.Window(now.In(moscow))
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 10, 31, 21, 0, 0, 0, time.UTC), from.UTC())
+	})
+
+	t.Run("TaskRequestDTO_Validate", func(t *testing.T) {
+		project := uuid.NewString()
+		req := TaskRequestDTO{Title: "Отчёт", Status: "in_review", Priority: "high", DueDate: "2026-11-01T09:00:00+03:00", ProjectID: &project}
+		assert.NoError(t, req.Validate())
+		assert.Equal(t, time.Date(2026, 11, 1, 6, 0, 0, 0, time.UTC), req.Due(time.UTC).UTC())
+		assert.Nil(t, TaskRequestDTO{Title: "t"}.Due(time.UTC))
+
+		// Дата без времени наступает в конце дня по часовому поясу пользователя
+		moscow, err := time.LoadLocation("Europe/Moscow")
+		assert.NoError(t, err)
+		allDay := TaskRequestDTO{Title: "t", DueDate: "2026-11-01"}
+		assert.NoError(t, allDay.Validate())
+		assert.Equal(t, time.Date(2026, 11, 1, 20, 59, 59, 0, time.UTC), allDay.Due(moscow).UTC())
+
+		bad := "42"
+		req = TaskRequestDTO{Title: " ", Status: "In Review", Priority: "asap", DueDate: "01.11.2026", WorkspaceID: &bad}
+		err = req.Validate()
+		assert.Equal(t, apperr.Invalid, apperr.KindOf(err))
+		assert.Equal(t, []apperr.Violation{
+			{Field: "title", Reason: "is required"},
+			{Field: "status", Reason: "must be a lower_snake_case status name"},
+			{Field: "priority", Reason: "must be one of low, medium, high, urgent"},
+			{Field: "due_date", Reason: "must be a date or RFC 3339 timestamp"},
+			{Field: "workspace_id", Reason: "must be a UUID"},
+		}, apperr.ViolationsOf(err))
+
+		// Длина заголовка считается в символах, а не в байтах
+		assert.NoError(t, TaskRequestDTO{Title: strings.Repeat("я", MaxTitleLength)}.Validate())
+		assert.Error(t, TaskRequestDTO{Title: strings.Repeat("я", MaxTitleLength+1)}.Validate())
+	})
+
+	t.Run("BulkStatusRequestDTO_Validate", func(t *testing.T) {
+		err := BulkStatusRequestDTO{IDs: []string{uuid.NewString(), "7"}}.Validate()
+		assert.Equal(t, []apperr.Violation{
+			{Field: "ids[1]", Reason: "must be a UUID"},
+			{Field: "status", Reason: "must be a lower_snake_case status name"},
+		}, apperr.ViolationsOf(err))
+
+		assert.Equal(t, []apperr.Violation{{Field: "ids", Reason: "must list at least one id"}},
+			apperr.ViolationsOf(BulkRequestDTO{}.Validate()))
+	})
 }
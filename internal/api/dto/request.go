@@ -1,9 +1,514 @@
 package dto
 
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/domain/usertime"
+	"unicode/utf8"
+)
+
+const (
+	MaxTitleLength           = 255
+	MaxTagNameLength         = 100
+	MaxSavedSearchNameLength = 100
+)
+
 type TaskRequestDTO struct {
-	Title    string `json:"title"`
-	Content  string `json:"content"`
-	Status   string `json:"status,omitempty"`
-	Priority string `json:"priority,omitempty"`
-	DueDate  string `json:"due_date,omitempty"`
+	Title       string  `json:"title"`
+	Content     string  `json:"content"`
+	Status      string  `json:"status,omitempty"`
+	Priority    string  `json:"priority,omitempty"`
+	DueDate     string  `json:"due_date,omitempty"`
+	ProjectID   *string `json:"project_id,omitempty"`
+	WorkspaceID *string `json:"workspace_id,omitempty"`
+}
+
+// Validate checks the format of every field. Status and priority may be left
+// empty: on create they default to todo and medium, on update they keep the
+// task's current values. Whether the task's workflow allows the status is up
+// to the service.
+func (r TaskRequestDTO) Validate() error {
+	var v apperr.ValidationError
+	checkTitle(&v, r.Title)
+	if r.Status != "" {
+		checkStatus(&v, "status", r.Status)
+	}
+	if r.Priority != "" {
+		checkPriority(&v, r.Priority)
+	}
+	if r.DueDate != "" {
+		if _, err := usertime.ParseDue(r.DueDate, time.UTC); err != nil {
+			v.Add("due_date", "must be a date or RFC 3339 timestamp")
+		}
+	}
+	checkOptionalUUID(&v, "project_id", r.ProjectID)
+	checkOptionalUUID(&v, "workspace_id", r.WorkspaceID)
+	return v.Err()
+}
+
+// Due returns the parsed due date, or nil when none was given. A date without
+// a time falls due at the end of that day in loc. Call it after Validate.
+func (r TaskRequestDTO) Due(loc *time.Location) *time.Time {
+	if r.DueDate == "" {
+		return nil
+	}
+	due, err := usertime.ParseDue(r.DueDate, loc)
+	if err != nil {
+		return nil
+	}
+	return &due
+}
+
+type StatusRequestDTO struct {
+	Status string `json:"status"`
+}
+
+func (r StatusRequestDTO) Validate() error {
+	var v apperr.ValidationError
+	checkStatus(&v, "status", r.Status)
+	return v.Err()
+}
+
+type PriorityRequestDTO struct {
+	Priority string `json:"priority"`
+}
+
+func (r PriorityRequestDTO) Validate() error {
+	var v apperr.ValidationError
+	checkPriority(&v, r.Priority)
+	return v.Err()
+}
+
+type TagNameRequestDTO struct {
+	Tag string `json:"tag"`
+}
+
+func (r TagNameRequestDTO) Validate() error {
+	var v apperr.ValidationError
+	if n := utf8.RuneCountInString(strings.TrimSpace(r.Tag)); n == 0 || n > MaxTagNameLength {
+		v.Add("tag", "must be between 1 and 100 characters")
+	}
+	return v.Err()
+}
+
+type BulkRequestDTO struct {
+	IDs []string `json:"ids"`
+}
+
+func (r BulkRequestDTO) Validate() error {
+	var v apperr.ValidationError
+	checkIDs(&v, r.IDs)
+	return v.Err()
+}
+
+type BulkStatusRequestDTO struct {
+	IDs    []string `json:"ids"`
+	Status string   `json:"status"`
+}
+
+func (r BulkStatusRequestDTO) Validate() error {
+	var v apperr.ValidationError
+	checkIDs(&v, r.IDs)
+	checkStatus(&v, "status", r.Status)
+	return v.Err()
+}
+
+type AssigneeRequestDTO struct {
+	UserID string `json:"user_id"`
+}
+
+func (r AssigneeRequestDTO) Validate() error {
+	var v apperr.ValidationError
+	checkUUID(&v, "user_id", r.UserID)
+	return v.Err()
+}
+
+type MoveTaskRequestDTO struct {
+	ParentID *string `json:"parent_id"`
+}
+
+func (r MoveTaskRequestDTO) Validate() error {
+	var v apperr.ValidationError
+	checkOptionalUUID(&v, "parent_id", r.ParentID)
+	return v.Err()
+}
+
+type MoveToProjectRequestDTO struct {
+	ProjectID *string `json:"project_id"`
+}
+
+func (r MoveToProjectRequestDTO) Validate() error {
+	var v apperr.ValidationError
+	checkOptionalUUID(&v, "project_id", r.ProjectID)
+	return v.Err()
+}
+
+type BulkMoveRequestDTO struct {
+	IDs       []string `json:"ids"`
+	ProjectID *string  `json:"project_id"`
+}
+
+func (r BulkMoveRequestDTO) Validate() error {
+	var v apperr.ValidationError
+	checkIDs(&v, r.IDs)
+	checkOptionalUUID(&v, "project_id", r.ProjectID)
+	return v.Err()
+}
+
+type DependencyRequestDTO struct {
+	BlockedBy string `json:"blocked_by"`
+}
+
+func (r DependencyRequestDTO) Validate() error {
+	var v apperr.ValidationError
+	checkUUID(&v, "blocked_by", r.BlockedBy)
+	return v.Err()
+}
+
+type RecurrenceRequestDTO struct {
+	RRule string `json:"rrule"`
+}
+
+type RevertRequestDTO struct {
+	Revision *int `json:"revision"`
+}
+
+func (r RevertRequestDTO) Validate() error {
+	var v apperr.ValidationError
+	switch {
+	case r.Revision == nil:
+		v.Add("revision", "is required")
+	case *r.Revision < 0:
+		v.Add("revision", "must not be negative")
+	}
+	return v.Err()
+}
+
+const (
+	DefaultOccurrenceLimit = 50
+	MaxOccurrenceLimit     = 500
+)
+
+// OccurrencesQueryDTO selects a window of upcoming occurrences; from and to
+// accept RFC 3339 timestamps or YYYY-MM-DD dates.
+type OccurrencesQueryDTO struct {
+	From  string `query:"from"`
+	To    string `query:"to"`
+	Limit int    `query:"limit"`
+}
+
+// Window resolves the query to [from, to), defaulting to one year from now.
+// Dates without a time start at midnight in now's location.
+func (q OccurrencesQueryDTO) Window(now time.Time) (time.Time, time.Time, int, error) {
+	from, to := now, time.Time{}
+	var err error
+	if q.From != "" {
+		if from, err = parseDateOrTime(q.From, now.Location()); err != nil {
+			return time.Time{}, time.Time{}, 0, apperr.Field("from", "must be a date or RFC 3339 timestamp")
+		}
+	}
+	to = from.AddDate(1, 0, 0)
+	if q.To != "" {
+		if to, err = parseDateOrTime(q.To, now.Location()); err != nil {
+			return time.Time{}, time.Time{}, 0, apperr.Field("to", "must be a date or RFC 3339 timestamp")
+		}
+	}
+	if !to.After(from) {
+		return time.Time{}, time.Time{}, 0, apperr.Field("to", "must be after from")
+	}
+	limit := q.Limit
+	switch {
+	case limit < 0:
+		return time.Time{}, time.Time{}, 0, apperr.Field("limit", "must not be negative")
+	case limit == 0:
+		limit = DefaultOccurrenceLimit
+	case limit > MaxOccurrenceLimit:
+		limit = MaxOccurrenceLimit
+	}
+	return from, to, limit, nil
+}
+
+func parseDateOrTime(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation(usertime.DateLayout, s, loc)
+}
+
+type ReminderRequestDTO struct {
+	RemindAt      *time.Time `json:"remind_at"`
+	OffsetMinutes *int       `json:"offset_minutes"`
+	Channel       string     `json:"channel"`
+	Target        string     `json:"target"`
+}
+
+type CommentRequestDTO struct {
+	Body string `json:"body"`
+}
+
+type TagRequestDTO struct {
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+}
+
+type ProjectRequestDTO struct {
+	Name        *string `json:"name"`
+	Color       *string `json:"color"`
+	Description *string `json:"description"`
+}
+
+type ReorderProjectsRequestDTO struct {
+	IDs []string `json:"ids"`
+}
+
+func (r ReorderProjectsRequestDTO) Validate() error {
+	var v apperr.ValidationError
+	checkIDs(&v, r.IDs)
+	return v.Err()
+}
+
+type WorkspaceRequestDTO struct {
+	Name string `json:"name"`
+}
+
+type WorkflowRequestDTO struct {
+	Statuses    []string            `json:"statuses"`
+	Transitions map[string][]string `json:"transitions"`
+}
+
+type WorkspaceMemberRequestDTO struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// Validate leaves role optional; the service picks the default and rejects
+// roles it does not know.
+func (r WorkspaceMemberRequestDTO) Validate() error {
+	var v apperr.ValidationError
+	if strings.TrimSpace(r.Email) == "" {
+		v.Add("email", "is required")
+	}
+	return v.Err()
+}
+
+type RoleRequestDTO struct {
+	Role string `json:"role"`
+}
+
+func (r RoleRequestDTO) Validate() error {
+	var v apperr.ValidationError
+	if r.Role == "" {
+		v.Add("role", "is required")
+	}
+	return v.Err()
+}
+
+type InvitationTokenRequestDTO struct {
+	Token string `json:"token"`
+}
+
+func (r InvitationTokenRequestDTO) Validate() error {
+	var v apperr.ValidationError
+	if r.Token == "" {
+		v.Add("token", "is required")
+	}
+	return v.Err()
+}
+
+type MergeTagRequestDTO struct {
+	IntoID uint `json:"into_id"`
+}
+
+func (r MergeTagRequestDTO) Validate() error {
+	var v apperr.ValidationError
+	if r.IntoID == 0 {
+		v.Add("into_id", "is required")
+	}
+	return v.Err()
+}
+
+type PageQueryDTO struct {
+	Limit  int    `query:"limit"`
+	Cursor string `query:"cursor"`
+	Sort   string `query:"sort"`
+	Order  string `query:"order"`
+}
+
+func (q PageQueryDTO) Validate() error {
+	var v apperr.ValidationError
+	if q.Limit < 0 {
+		v.Add("limit", "must not be negative")
+	}
+	if q.Order != "" && q.Order != "asc" && q.Order != "desc" {
+		v.Add("order", "must be asc or desc")
+	}
+	if !repository.ValidSort(q.Sort) {
+		v.Add("sort", repository.ErrInvalidSort.Error())
+	}
+	return v.Err()
+}
+
+func (q PageQueryDTO) ToPageRequest() repository.PageRequest {
+	return repository.PageRequest{
+		Limit:  q.Limit,
+		Cursor: q.Cursor,
+		Sort:   q.Sort,
+		Desc:   q.desc(),
+	}
+}
+
+// desc defaults to newest / most important first, and to ascending order for
+// due dates and titles.
+func (q PageQueryDTO) desc() bool {
+	switch q.Order {
+	case "desc":
+		return true
+	case "asc":
+		return false
+	}
+	return q.Sort != repository.SortDueDate && q.Sort != repository.SortTitle
+}
+
+// TaskFilterQueryDTO accepts a filter expression together with shorthand
+// query parameters; both are merged into a single expression.
+type TaskFilterQueryDTO struct {
+	Filter    string `query:"filter"`
+	Status    string `query:"status"`
+	Priority  string `query:"priority"`
+	Tag       string `query:"tag"`
+	DueBefore string `query:"due_before"`
+	DueAfter  string `query:"due_after"`
+	Archived  string `query:"archived"`
+	Q         string `query:"q"`
+}
+
+func (q TaskFilterQueryDTO) Expression() (string, error) {
+	terms := []string{}
+	if strings.TrimSpace(q.Filter) != "" {
+		terms = append(terms, q.Filter)
+	}
+	add := func(prefix, value string) {
+		if value != "" {
+			terms = append(terms, prefix+quoteFilterValue(value))
+		}
+	}
+	add("status:", q.Status)
+	add("priority:", q.Priority)
+	add("tag:", q.Tag)
+	add("due<", q.DueBefore)
+	add("due>", q.DueAfter)
+	// q is plain text search, so it is always quoted and never read as a
+	// field, flag or negation.
+	if text := strings.ReplaceAll(q.Q, `"`, ""); strings.TrimSpace(text) != "" {
+		terms = append(terms, `"`+text+`"`)
+	}
+	switch q.Archived {
+	case "":
+	case "true":
+		terms = append(terms, "archived")
+	case "false":
+		terms = append(terms, "-archived")
+	default:
+		return "", apperr.Field("archived", "must be true or false")
+	}
+	return strings.Join(terms, " "), nil
+}
+
+func quoteFilterValue(v string) string {
+	v = strings.ReplaceAll(v, `"`, "")
+	if strings.ContainsAny(v, " \t") {
+		return `"` + v + `"`
+	}
+	return v
+}
+
+// WebhookRequestDTO is used for both create and update; on update only the
+// fields present in the body change.
+type WebhookRequestDTO struct {
+	URL    *string  `json:"url"`
+	Secret *string  `json:"secret"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+// SavedSearchRequestDTO creates or replaces a saved search. Filter uses the
+// syntax of the filter query parameter; Sort and Order default the same way
+// they do for task lists.
+type SavedSearchRequestDTO struct {
+	Name   string `json:"name"`
+	Filter string `json:"filter"`
+	Sort   string `json:"sort"`
+	Order  string `json:"order"`
+}
+
+func (r SavedSearchRequestDTO) Validate() error {
+	var v apperr.ValidationError
+	if n := utf8.RuneCountInString(strings.TrimSpace(r.Name)); n == 0 || n > MaxSavedSearchNameLength {
+		v.Add("name", "must be between 1 and 100 characters")
+	}
+	if !repository.ValidSort(r.Sort) {
+		v.Add("sort", repository.ErrInvalidSort.Error())
+	}
+	if r.Order != "" && r.Order != "asc" && r.Order != "desc" {
+		v.Add("order", "must be asc or desc")
+	}
+	return v.Err()
+}
+
+func (r SavedSearchRequestDTO) Desc() bool {
+	return PageQueryDTO{Sort: r.Sort, Order: r.Order}.desc()
+}
+
+func checkTitle(v *apperr.ValidationError, title string) {
+	switch {
+	case strings.TrimSpace(title) == "":
+		v.Add("title", "is required")
+	case utf8.RuneCountInString(title) > MaxTitleLength:
+		v.Add("title", "must be at most 255 characters")
+	}
+}
+
+func checkStatus(v *apperr.ValidationError, field, status string) {
+	if !model.ValidStatusName(status) {
+		v.Add(field, "must be a lower_snake_case status name")
+	}
+}
+
+func checkPriority(v *apperr.ValidationError, priority string) {
+	if model.PriorityRank(priority) == 0 {
+		v.Add("priority", "must be one of low, medium, high, urgent")
+	}
+}
+
+func checkUUID(v *apperr.ValidationError, field, id string) {
+	switch {
+	case id == "":
+		v.Add(field, "is required")
+	case uuid.Validate(id) != nil:
+		v.Add(field, "must be a UUID")
+	}
+}
+
+func checkOptionalUUID(v *apperr.ValidationError, field string, id *string) {
+	if id != nil && uuid.Validate(*id) != nil {
+		v.Add(field, "must be a UUID")
+	}
+}
+
+// checkIDs requires a non-empty list of task UUIDs and names bad entries by
+// index, e.g. ids[2].
+func checkIDs(v *apperr.ValidationError, ids []string) {
+	if len(ids) == 0 {
+		v.Add("ids", "must list at least one id")
+	}
+	for i, id := range ids {
+		if uuid.Validate(id) != nil {
+			v.Add(fmt.Sprintf("ids[%d]", i), "must be a UUID")
+		}
+	}
 }
@@ -1,38 +1,511 @@
 package dto
 
 import (
+	"encoding/json"
+	"html"
+	"strings"
 	"time"
+	"todo-list/internal/domain/event"
 	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
 )
 
 type TaskResponseDTO struct {
-	ID        string     `json:"id"`
-	Title     string     `json:"title"`
-	Content   string     `json:"content"`
-	Status    string     `json:"status"`
-	Priority  string     `json:"priority"`
-	Tags      []string   `json:"tags,omitempty"`
-	DueDate   *time.Time `json:"due_date,omitempty"`
-	Archived  bool       `json:"archived"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	ID          string     `json:"id"`
+	ParentID    *string    `json:"parent_id,omitempty"`
+	ProjectID   *string    `json:"project_id,omitempty"`
+	WorkspaceID *string    `json:"workspace_id,omitempty"`
+	Title       string     `json:"title"`
+	Content     string     `json:"content"`
+	Status      string     `json:"status"`
+	Priority    string     `json:"priority"`
+	Tags        []string   `json:"tags,omitempty"`
+	Assignees   []string   `json:"assignees,omitempty"`
+	Watchers    []string   `json:"watchers,omitempty"`
+	Comments    int64      `json:"comment_count"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	RRule       string     `json:"rrule,omitempty"`
+	Archived    bool       `json:"archived"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Version     int64      `json:"version"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	// Match is only present in search results.
+	Match *SearchMatchDTO `json:"match,omitempty"`
+}
+
+// SearchMatchDTO explains why a task matched a search. Title and Snippet are
+// HTML-escaped text in which the matched words are wrapped in <mark>.
+type SearchMatchDTO struct {
+	Rank    float64 `json:"rank"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet,omitempty"`
+}
+
+var highlightMarks = strings.NewReplacer(model.HighlightStart, "<mark>", model.HighlightStop, "</mark>")
+
+func highlight(s string) string {
+	return highlightMarks.Replace(html.EscapeString(s))
 }
 
 func ToTaskResponseDTO(task model.Task) TaskResponseDTO {
-	var tags []string
+	var tags, assignees, watchers []string
 	for _, t := range task.Tags {
 		tags = append(tags, t.Name)
 	}
+	for _, a := range task.Assignees {
+		assignees = append(assignees, a.UserID.String())
+	}
+	for _, w := range task.Watchers {
+		watchers = append(watchers, w.UserID.String())
+	}
+	var parentID, projectID, workspaceID *string
+	if task.ParentID != nil {
+		id := task.ParentID.String()
+		parentID = &id
+	}
+	if task.ProjectID != nil {
+		id := task.ProjectID.String()
+		projectID = &id
+	}
+	if task.WorkspaceID != nil {
+		id := task.WorkspaceID.String()
+		workspaceID = &id
+	}
+	var deletedAt *time.Time
+	if task.DeletedAt.Valid {
+		deletedAt = &task.DeletedAt.Time
+	}
+	var match *SearchMatchDTO
+	if task.TitleHighlight != "" {
+		match = &SearchMatchDTO{
+			Rank:    task.SearchRank,
+			Title:   highlight(task.TitleHighlight),
+			Snippet: highlight(task.ContentSnippet),
+		}
+	}
 	return TaskResponseDTO{
-		ID:        task.ID.String(),
-		Title:     task.Title,
-		Content:   task.Content,
-		Status:    task.Status,
-		Priority:  task.Priority,
-		Tags:      tags,
-		DueDate:   task.DueDate,
-		Archived:  task.Archived,
-		CreatedAt: task.CreatedAt,
-		UpdatedAt: task.UpdatedAt,
+		ID:          task.ID.String(),
+		ParentID:    parentID,
+		ProjectID:   projectID,
+		WorkspaceID: workspaceID,
+		Title:       task.Title,
+		Content:     task.Content,
+		Status:      task.Status,
+		Priority:    task.Priority,
+		Tags:        tags,
+		Assignees:   assignees,
+		Watchers:    watchers,
+		Comments:    task.CommentCount,
+		DueDate:     task.DueDate,
+		RRule:       task.RRule,
+		Archived:    task.Archived,
+		StartedAt:   task.StartedAt,
+		CompletedAt: task.CompletedAt,
+		Version:     task.Version,
+		CreatedAt:   task.CreatedAt,
+		UpdatedAt:   task.UpdatedAt,
+		DeletedAt:   deletedAt,
+		Match:       match,
+	}
+}
+
+type ProjectResponseDTO struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Color       string    `json:"color,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Position    int       `json:"position"`
+	Archived    bool      `json:"archived"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func ToProjectResponseDTO(p model.Project) ProjectResponseDTO {
+	return ProjectResponseDTO{
+		ID:          p.ID.String(),
+		Name:        p.Name,
+		Color:       p.Color,
+		Description: p.Description,
+		Position:    p.Position,
+		Archived:    p.Archived,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+type WorkspaceResponseDTO struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Role        string    `json:"role"`
+	MemberCount int64     `json:"member_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func ToWorkspaceResponseDTO(ws repository.WorkspaceSummary) WorkspaceResponseDTO {
+	return WorkspaceResponseDTO{
+		ID:          ws.ID.String(),
+		Name:        ws.Name,
+		Role:        ws.Role,
+		MemberCount: ws.MemberCount,
+		CreatedAt:   ws.CreatedAt,
+	}
+}
+
+type WorkflowResponseDTO struct {
+	Statuses    []string            `json:"statuses"`
+	Transitions map[string][]string `json:"transitions,omitempty"`
+	// Default is set when nothing was configured and the default applies.
+	Default bool `json:"default"`
+}
+
+func ToWorkflowResponseDTO(wf *model.Workflow) WorkflowResponseDTO {
+	if wf == nil {
+		return WorkflowResponseDTO{Statuses: model.DefaultWorkflow.Statuses, Default: true}
+	}
+	return WorkflowResponseDTO{Statuses: wf.Statuses, Transitions: wf.Transitions}
+}
+
+type WorkspaceMemberResponseDTO struct {
+	UserID   string    `json:"user_id"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+func ToWorkspaceMemberResponseDTO(m repository.Member) WorkspaceMemberResponseDTO {
+	return WorkspaceMemberResponseDTO{
+		UserID:   m.UserID.String(),
+		Email:    m.Email,
+		Role:     m.Role,
+		JoinedAt: m.CreatedAt,
+	}
+}
+
+type WorkspaceInvitationResponseDTO struct {
+	ID          string    `json:"id"`
+	WorkspaceID string    `json:"workspace_id"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	Status      string    `json:"status"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+	// Token is only returned when the invitation is created.
+	Token string `json:"token,omitempty"`
+}
+
+func ToWorkspaceInvitationResponseDTO(inv model.WorkspaceInvitation) WorkspaceInvitationResponseDTO {
+	return WorkspaceInvitationResponseDTO{
+		ID:          inv.ID.String(),
+		WorkspaceID: inv.WorkspaceID.String(),
+		Email:       inv.Email,
+		Role:        inv.Role,
+		Status:      inv.Status,
+		ExpiresAt:   inv.ExpiresAt,
+		CreatedAt:   inv.CreatedAt,
+	}
+}
+
+type TagResponseDTO struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Color     string `json:"color,omitempty"`
+	TaskCount *int64 `json:"task_count,omitempty"`
+}
+
+func ToTagResponseDTO(tag model.Tag) TagResponseDTO {
+	return TagResponseDTO{ID: tag.ID, Name: tag.Name, Color: tag.Color}
+}
+
+func ToTagUsageResponseDTO(usage repository.TagUsage) TagResponseDTO {
+	out := ToTagResponseDTO(usage.Tag)
+	count := usage.TaskCount
+	out.TaskCount = &count
+	return out
+}
+
+type PagedTasksResponseDTO struct {
+	Items      []TaskResponseDTO `json:"items"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	HasMore    bool              `json:"has_more"`
+}
+
+func ToPagedTasksResponseDTO(page repository.TaskPage) PagedTasksResponseDTO {
+	items := make([]TaskResponseDTO, 0, len(page.Tasks))
+	for _, t := range page.Tasks {
+		items = append(items, ToTaskResponseDTO(t))
+	}
+	return PagedTasksResponseDTO{
+		Items:      items,
+		NextCursor: page.NextCursor,
+		HasMore:    page.NextCursor != "",
+	}
+}
+
+type ProgressDTO struct {
+	Done    int `json:"done"`
+	Total   int `json:"total"`
+	Percent int `json:"percent"`
+}
+
+type TaskTreeResponseDTO struct {
+	TaskResponseDTO
+	Progress ProgressDTO           `json:"progress"`
+	Subtasks []TaskTreeResponseDTO `json:"subtasks"`
+}
+
+func ToTaskTreeResponseDTO(node *model.TaskNode) TaskTreeResponseDTO {
+	out := TaskTreeResponseDTO{
+		TaskResponseDTO: ToTaskResponseDTO(node.Task),
+		Progress:        ProgressDTO{Done: node.Done, Total: node.Total},
+		Subtasks:        make([]TaskTreeResponseDTO, 0, len(node.Children)),
+	}
+	switch {
+	case node.Total > 0:
+		out.Progress.Percent = node.Done * 100 / node.Total
+	case node.Task.Status == "done":
+		out.Progress.Percent = 100
+	}
+	for _, child := range node.Children {
+		out.Subtasks = append(out.Subtasks, ToTaskTreeResponseDTO(child))
 	}
+	return out
+}
+
+type DependencyEdgeDTO struct {
+	BlockerID string `json:"blocker_id"`
+	BlockedID string `json:"blocked_id"`
+}
+
+type DependencyGraphResponseDTO struct {
+	RootID string              `json:"root_id"`
+	Nodes  []TaskResponseDTO   `json:"nodes"`
+	Edges  []DependencyEdgeDTO `json:"edges"`
+}
+
+func ToDependencyGraphResponseDTO(graph model.DependencyGraph) DependencyGraphResponseDTO {
+	out := DependencyGraphResponseDTO{
+		RootID: graph.RootID.String(),
+		Nodes:  make([]TaskResponseDTO, 0, len(graph.Tasks)),
+		Edges:  make([]DependencyEdgeDTO, 0, len(graph.Edges)),
+	}
+	for _, t := range graph.Tasks {
+		out.Nodes = append(out.Nodes, ToTaskResponseDTO(t))
+	}
+	for _, e := range graph.Edges {
+		out.Edges = append(out.Edges, DependencyEdgeDTO{BlockerID: e.BlockerID.String(), BlockedID: e.BlockedID.String()})
+	}
+	return out
+}
+
+type OccurrencesResponseDTO struct {
+	TaskID      string      `json:"task_id"`
+	Occurrences []time.Time `json:"occurrences"`
+}
+
+type ReminderResponseDTO struct {
+	ID            string     `json:"id"`
+	TaskID        string     `json:"task_id"`
+	RemindAt      *time.Time `json:"remind_at,omitempty"`
+	OffsetMinutes *int       `json:"offset_minutes,omitempty"`
+	Channel       string     `json:"channel"`
+	Target        string     `json:"target,omitempty"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func ToReminderResponseDTO(r model.Reminder) ReminderResponseDTO {
+	return ReminderResponseDTO{
+		ID:            r.ID.String(),
+		TaskID:        r.TaskID.String(),
+		RemindAt:      r.RemindAt,
+		OffsetMinutes: r.OffsetMinutes,
+		Channel:       r.Channel,
+		Target:        r.Target,
+		Status:        r.Status(),
+		Attempts:      r.Attempts,
+		LastError:     r.LastError,
+		SentAt:        r.SentAt,
+		CreatedAt:     r.CreatedAt,
+	}
+}
+
+type CommentResponseDTO struct {
+	ID        string     `json:"id"`
+	TaskID    string     `json:"task_id"`
+	AuthorID  string     `json:"author_id"`
+	Body      string     `json:"body"`
+	Mentions  []string   `json:"mentions"`
+	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+}
+
+func ToCommentResponseDTO(c model.Comment) CommentResponseDTO {
+	mentions := make([]string, 0, len(c.Mentions))
+	for _, m := range c.Mentions {
+		mentions = append(mentions, m.UserID.String())
+	}
+	return CommentResponseDTO{
+		ID:        c.ID.String(),
+		TaskID:    c.TaskID.String(),
+		AuthorID:  c.AuthorID.String(),
+		Body:      c.Body,
+		Mentions:  mentions,
+		CreatedAt: c.CreatedAt,
+		EditedAt:  c.EditedAt,
+	}
+}
+
+type TaskRevisionResponseDTO struct {
+	Revision  int              `json:"revision"`
+	ChangedBy string           `json:"changed_by"`
+	ChangedAt time.Time        `json:"changed_at"`
+	Changes   []FieldChangeDTO `json:"changes"`
+}
+
+type FieldChangeDTO struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+func ToTaskRevisionResponseDTO(r model.TaskRevision) TaskRevisionResponseDTO {
+	changes := make([]FieldChangeDTO, 0, len(r.Changes))
+	for _, c := range r.Changes {
+		changes = append(changes, FieldChangeDTO{Field: c.Field, Old: c.Old, New: c.New})
+	}
+	return TaskRevisionResponseDTO{
+		Revision:  r.Revision,
+		ChangedBy: r.ChangedBy.String(),
+		ChangedAt: r.CreatedAt,
+		Changes:   changes,
+	}
+}
+
+type AttachmentResponseDTO struct {
+	ID          string    `json:"id"`
+	TaskID      string    `json:"task_id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	UploadedBy  string    `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func ToAttachmentResponseDTO(a model.Attachment) AttachmentResponseDTO {
+	return AttachmentResponseDTO{
+		ID:          a.ID.String(),
+		TaskID:      a.TaskID.String(),
+		Name:        a.Name,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		UploadedBy:  a.UploadedBy.String(),
+		CreatedAt:   a.CreatedAt,
+	}
+}
+
+type WebhookResponseDTO struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ToWebhookResponseDTO leaves the secret out; it is only shown once, in the
+// response to create.
+func ToWebhookResponseDTO(s model.WebhookSubscription) WebhookResponseDTO {
+	return WebhookResponseDTO{
+		ID:        s.ID.String(),
+		URL:       s.URL,
+		Events:    s.EventList(),
+		Active:    s.Active,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+type WebhookDeliveryResponseDTO struct {
+	ID             string          `json:"id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+func ToWebhookDeliveryResponseDTO(d model.WebhookDelivery) WebhookDeliveryResponseDTO {
+	out := WebhookDeliveryResponseDTO{
+		ID:             d.ID.String(),
+		EventID:        d.EventID.String(),
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+		Payload:        json.RawMessage(d.Payload),
+	}
+	if d.Status == model.WebhookDeliveryPending {
+		next := d.NextAttemptAt
+		out.NextAttemptAt = &next
+	}
+	return out
+}
+
+// SavedSearchResponseDTO describes a saved search or, with System set, a
+// built-in smart list whose ID is its key.
+type SavedSearchResponseDTO struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Filter string `json:"filter"`
+	Sort   string `json:"sort,omitempty"`
+	Order  string `json:"order,omitempty"`
+	System bool   `json:"system"`
+}
+
+func ToSavedSearchResponseDTO(s model.SavedSearch) SavedSearchResponseDTO {
+	out := SavedSearchResponseDTO{
+		ID:     s.ID.String(),
+		Name:   s.Name,
+		Filter: s.Filter,
+		Sort:   s.Sort,
+		System: s.System(),
+	}
+	if s.System() {
+		out.ID = s.Key
+	}
+	if s.Sort != "" {
+		out.Order = "asc"
+		if s.Desc {
+			out.Order = "desc"
+		}
+	}
+	return out
+}
+
+// StreamMessageDTO is one WebSocket frame. Over SSE the ID goes in the id
+// field and only the event is sent as data.
+type StreamMessageDTO struct {
+	ID    string       `json:"id,omitempty"`
+	Type  string       `json:"type"`
+	Event *event.Event `json:"event,omitempty"`
+	Error string       `json:"error,omitempty"`
+}
+
+func ToStreamMessageDTO(m event.Message) StreamMessageDTO {
+	return StreamMessageDTO{ID: m.ID, Type: "event", Event: &m.Event}
 }
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"mime"
+	"net/http"
+	"strconv"
+	"todo-list/internal/api/dto"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/service"
+)
+
+type AttachmentHandler interface {
+	List(c echo.Context) error
+	Upload(c echo.Context) error
+	Download(c echo.Context) error
+	Delete(c echo.Context) error
+}
+
+type attachmentHandlerImpl struct {
+	service service.AttachmentService
+	maxSize int64
+}
+
+// multipartOverhead is what the request body may carry beyond the file
+// itself: boundaries, part headers and other form fields.
+const multipartOverhead = 1 << 20
+
+// NewAttachmentHandler rejects request bodies much larger than maxSize before
+// they are spooled to disk; the exact limit is enforced by the service.
+func NewAttachmentHandler(s service.AttachmentService, maxSize int64) AttachmentHandler {
+	return &attachmentHandlerImpl{service: s, maxSize: maxSize}
+}
+
+func (h *attachmentHandlerImpl) getUserID(c echo.Context) string {
+	return c.Get("user_id").(string)
+}
+
+func (h *attachmentHandlerImpl) List(c echo.Context) error {
+	attachments, err := h.service.ListAttachments(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	out := make([]dto.AttachmentResponseDTO, 0, len(attachments))
+	for _, a := range attachments {
+		out = append(out, dto.ToAttachmentResponseDTO(a))
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+// Upload expects multipart/form-data with the file in the "file" field.
+func (h *attachmentHandlerImpl) Upload(c echo.Context) error {
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, h.maxSize+multipartOverhead)
+	fh, err := c.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return respondError(c, service.ErrAttachmentTooLarge)
+	}
+	if err != nil {
+		return respondError(c, apperr.Field("file", "is required"))
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return respondError(c, errMalformedBody)
+	}
+	defer f.Close()
+
+	upload := model.AttachmentUpload{
+		Name:        fh.Filename,
+		ContentType: fh.Header.Get(echo.HeaderContentType),
+		Size:        fh.Size,
+		Body:        f,
+	}
+	a, err := h.service.UploadAttachment(c.Request().Context(), c.Param("id"), h.getUserID(c), upload)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusCreated, dto.ToAttachmentResponseDTO(a))
+}
+
+// Download always serves the file as an attachment and forbids sniffing, so
+// an uploaded HTML or SVG file is never rendered on our origin.
+func (h *attachmentHandlerImpl) Download(c echo.Context) error {
+	a, body, err := h.service.OpenAttachment(c.Request().Context(), c.Param("attachmentId"), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	defer body.Close()
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": a.Name}))
+	header.Set(echo.HeaderContentLength, strconv.FormatInt(a.Size, 10))
+	header.Set(echo.HeaderXContentTypeOptions, "nosniff")
+	return c.Stream(http.StatusOK, a.ContentType, body)
+}
+
+func (h *attachmentHandlerImpl) Delete(c echo.Context) error {
+	err := h.service.DeleteAttachment(c.Request().Context(), c.Param("attachmentId"), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"bytes"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/domain/service"
+	"todo-list/internal/testutils"
+)
+
+func TestAttachmentHandler(t *testing.T) {
+	e := echo.New()
+	mockSvc := new(testutils.AttachmentMocks)
+	h := NewAttachmentHandler(mockSvc, 1<<10)
+	uID := uuid.New()
+	taskID, aID := uuid.New().String(), uuid.New()
+
+	newContext := func(req *http.Request, attachmentID string) (echo.Context, *httptest.ResponseRecorder) {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id", "attachmentId")
+		c.SetParamValues(taskID, attachmentID)
+		c.Set("user_id", uID.String())
+		return c, rec
+	}
+	upload := func(name, contentType, content string) *http.Request {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="file"; filename="`+name+`"`)
+		header.Set("Content-Type", contentType)
+		part, _ := w.CreatePart(header)
+		_, _ = io.WriteString(part, content)
+		w.Close()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks/"+taskID+"/attachments", &body)
+		req.Header.Set(echo.HeaderContentType, w.FormDataContentType())
+		return req
+	}
+
+	t.Run("Upload_Success", func(t *testing.T) {
+		c, rec := newContext(upload("report.pdf", "application/pdf", "%PDF-1.7"), "")
+		mockSvc.On("UploadAttachment", mock.Anything, taskID, uID.String(), mock.MatchedBy(func(u model.AttachmentUpload) bool {
+			return u.Name == "report.pdf" && u.ContentType == "application/pdf" && u.Size == 8
+		})).Return(model.Attachment{ID: aID, Name: "report.pdf", Size: 8}, nil).Once()
+
+		if assert.NoError(t, h.Upload(c)) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Contains(t, rec.Body.String(), aID.String())
+		}
+	})
+
+	t.Run("Upload_Unsupported_Type", func(t *testing.T) {
+		c, rec := newContext(upload("run.exe", "application/x-msdownload", "MZ"), "")
+		mockSvc.On("UploadAttachment", mock.Anything, taskID, uID.String(), mock.Anything).
+			Return(model.Attachment{}, service.ErrAttachmentType).Once()
+
+		assert.NoError(t, h.Upload(c))
+		assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	})
+
+	t.Run("Upload_Body_Too_Large", func(t *testing.T) {
+		c, rec := newContext(upload("big.png", "image/png", strings.Repeat("x", 2<<20)), "")
+
+		assert.NoError(t, h.Upload(c))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+
+	t.Run("Upload_Without_File", func(t *testing.T) {
+		c, rec := newContext(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`)), "")
+
+		assert.NoError(t, h.Upload(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Download_Headers", func(t *testing.T) {
+		c, rec := newContext(httptest.NewRequest(http.MethodGet, "/", nil), aID.String())
+		mockSvc.On("OpenAttachment", mock.Anything, aID.String(), taskID, uID.String()).
+			Return(model.Attachment{ID: aID, Name: "отчёт.html", ContentType: "text/html", Size: 6}, io.NopCloser(strings.NewReader("<html>")), nil).Once()
+
+		if assert.NoError(t, h.Download(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "<html>", rec.Body.String())
+			assert.Equal(t, "nosniff", rec.Header().Get(echo.HeaderXContentTypeOptions))
+			assert.True(t, strings.HasPrefix(rec.Header().Get(echo.HeaderContentDisposition), "attachment; filename*=utf-8''"))
+		}
+	})
+
+	t.Run("Delete_NotFound", func(t *testing.T) {
+		c, rec := newContext(httptest.NewRequest(http.MethodDelete, "/", nil), "x")
+		mockSvc.On("DeleteAttachment", mock.Anything, "x", taskID, uID.String()).Return(repository.ErrAttachmentNotFound).Once()
+
+		assert.NoError(t, h.Delete(c))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -1,32 +1,89 @@
 package handlers
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
 	"net/http"
+	"strings"
 	"time"
+	"todo-list/config"
+	"todo-list/internal/api/problem"
+	"todo-list/internal/domain/apperr"
 	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
 )
 
+type SessionRevoker interface {
+	Revoke(ctx context.Context, sessionID string, ttl time.Duration) error
+}
+
+// InvitationAcceptor lets a new account join the workspace it was invited to.
+type InvitationAcceptor interface {
+	InvitationByToken(ctx context.Context, token string) (model.WorkspaceInvitation, error)
+	AcceptInvitationToken(ctx context.Context, token, userID string) (repository.Member, error)
+}
+
 type AuthHandler struct {
-	DB     *gorm.DB
-	Secret string
+	DB          *gorm.DB
+	Secret      string
+	AccessTTL   time.Duration
+	RefreshTTL  time.Duration
+	Revocations SessionRevoker
+	Invitations InvitationAcceptor
+
+	zones timezoneCache
 }
 
-func NewAuthHandler(db *gorm.DB, secret string) *AuthHandler {
-	return &AuthHandler{DB: db, Secret: secret}
+var errInvalidRefreshToken = errors.New("invalid refresh token")
+
+func NewAuthHandler(db *gorm.DB, secret string, cfg *config.AuthConfig) *AuthHandler {
+	return &AuthHandler{DB: db, Secret: secret, AccessTTL: cfg.AccessTokenTTL, RefreshTTL: cfg.RefreshTokenTTL}
 }
 
 func (h *AuthHandler) Register(c echo.Context) error {
 	var req struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+		Email       string `json:"email"`
+		Password    string `json:"password"`
+		InviteToken string `json:"invite_token"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, errMalformedBody)
+	}
+	var v apperr.ValidationError
+	if strings.TrimSpace(req.Email) == "" {
+		v.Add("email", "is required")
+	}
+	if req.Password == "" {
+		v.Add("password", "is required")
 	}
-	if err := c.Bind(&req); err != nil || req.Email == "" || req.Password == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid input"})
+	if err := v.Err(); err != nil {
+		return respondError(c, err)
+	}
+
+	// Check the invitation before the account exists so a bad token does not
+	// leave a half-finished registration behind.
+	invite := req.InviteToken != "" && h.Invitations != nil
+	if invite {
+		inv, err := h.Invitations.InvitationByToken(c.Request().Context(), req.InviteToken)
+		switch {
+		case errors.Is(err, repository.ErrInvitationClosed):
+			return respondError(c, err)
+		case err != nil:
+			return respondError(c, apperr.Field("invite_token", "is not a valid invitation"))
+		case !strings.EqualFold(inv.Email, strings.TrimSpace(req.Email)):
+			return respondError(c, repository.ErrInvitationEmail)
+		}
 	}
 
 	hash, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
@@ -38,9 +95,21 @@ func (h *AuthHandler) Register(c echo.Context) error {
 	}
 
 	if err := h.DB.Create(&user).Error; err != nil {
-		return c.JSON(http.StatusConflict, map[string]string{"error": "user already exists"})
+		return problem.Respond(c, http.StatusConflict, "user already exists")
+	}
+
+	res := map[string]string{"message": "registration successful"}
+	if invite {
+		m, err := h.Invitations.AcceptInvitationToken(c.Request().Context(), req.InviteToken, user.ID.String())
+		if err != nil {
+			// The account is created either way; the invitation can be retried
+			// after login. Unexpected errors are logged, not shown.
+			res["invitation_error"] = problemFor(c, err).Detail
+		} else {
+			res["workspace_id"] = m.WorkspaceID.String()
+		}
 	}
-	return c.JSON(http.StatusCreated, map[string]string{"message": "registration successful"})
+	return c.JSON(http.StatusCreated, res)
 }
 
 func (h *AuthHandler) Login(c echo.Context) error {
@@ -48,28 +117,289 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		Email    string `json:"email"`
 		Password string `json:"password"`
 	}
-	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid input"})
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
 	}
 
 	var user model.User
 	if err := h.DB.Where("email = ?", req.Email).First(&user).Error; err != nil {
-		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid credentials"})
+		return problem.Respond(c, http.StatusUnauthorized, "invalid credentials")
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid credentials"})
+		return problem.Respond(c, http.StatusUnauthorized, "invalid credentials")
+	}
+
+	now := time.Now()
+	session := model.Session{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		UserAgent:  truncate(c.Request().UserAgent(), 255),
+		IP:         c.RealIP(),
+		ExpiresAt:  now.Add(h.refreshTTL()),
+		LastUsedAt: now,
+		CreatedAt:  now,
+	}
+	refresh, refreshRow := h.newRefreshToken(session.ID, now)
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		return tx.Create(&refreshRow).Error
+	})
+	if err != nil {
+		return problem.Respond(c, http.StatusInternalServerError, "could not create session")
+	}
+
+	return h.respondWithTokens(c, user.ID.String(), session.ID.String(), refresh)
+}
+
+func (h *AuthHandler) Refresh(c echo.Context) error {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, errMalformedBody)
+	}
+	if req.RefreshToken == "" {
+		return respondError(c, apperr.Field("refresh_token", "is required"))
+	}
+
+	now := time.Now()
+	var session model.Session
+	var refresh string
+	reused := false
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var current model.RefreshToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", hashToken(req.RefreshToken)).First(&current).Error
+		if err != nil {
+			return errInvalidRefreshToken
+		}
+		if err := tx.Where("id = ?", current.SessionID).First(&session).Error; err != nil {
+			return errInvalidRefreshToken
+		}
+		if current.UsedAt != nil {
+			// Уже использованный токен предъявлен повторно: считаем сессию скомпрометированной
+			if session.RevokedAt == nil {
+				if err := h.pushRevocation(c.Request().Context(), session.ID.String()); err != nil {
+					return err
+				}
+				if err := tx.Model(&session).Update("revoked_at", now).Error; err != nil {
+					return err
+				}
+				session.RevokedAt = &now
+			}
+			reused = true
+			return nil
+		}
+		if !now.Before(current.ExpiresAt) || !session.Active(now) {
+			return errInvalidRefreshToken
+		}
+
+		var next model.RefreshToken
+		refresh, next = h.newRefreshToken(session.ID, now)
+		if next.ExpiresAt.After(session.ExpiresAt) {
+			next.ExpiresAt = session.ExpiresAt
+		}
+		if err := tx.Model(&current).Update("used_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&next).Error; err != nil {
+			return err
+		}
+		return tx.Model(&session).Update("last_used_at", now).Error
+	})
+
+	if reused {
+		err = errInvalidRefreshToken
+	}
+	if errors.Is(err, errInvalidRefreshToken) {
+		return problem.Respond(c, http.StatusUnauthorized, "invalid refresh token")
+	}
+	if err != nil {
+		return problem.Respond(c, http.StatusInternalServerError, "could not refresh session")
+	}
+
+	return h.respondWithTokens(c, session.UserID.String(), session.ID.String(), refresh)
+}
+
+func (h *AuthHandler) Logout(c echo.Context) error {
+	sessionID, _ := c.Get("session_id").(string)
+	if sessionID == "" {
+		return problem.Respond(c, http.StatusBadRequest, "no active session")
+	}
+	if err := h.revokeSessions(c.Request().Context(), h.getUserID(c), sessionID); err != nil {
+		return problem.Respond(c, http.StatusInternalServerError, "could not revoke session")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *AuthHandler) ListSessions(c echo.Context) error {
+	var sessions []model.Session
+	err := h.DB.WithContext(c.Request().Context()).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", h.getUserID(c), time.Now()).
+		Order("last_used_at DESC").Find(&sessions).Error
+	if err != nil {
+		return respondError(c, err)
+	}
+	currentID, _ := c.Get("session_id").(string)
+	out := make([]map[string]interface{}, 0, len(sessions))
+	for _, s := range sessions {
+		out = append(out, map[string]interface{}{
+			"id":           s.ID,
+			"user_agent":   s.UserAgent,
+			"ip":           s.IP,
+			"created_at":   s.CreatedAt,
+			"last_used_at": s.LastUsedAt,
+			"expires_at":   s.ExpiresAt,
+			"current":      s.ID.String() == currentID,
+		})
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+func (h *AuthHandler) RevokeSession(c echo.Context) error {
+	if _, err := uuid.Parse(c.Param("id")); err != nil {
+		return problem.Respond(c, http.StatusNotFound, "session not found")
+	}
+	var session model.Session
+	err := h.DB.WithContext(c.Request().Context()).
+		Where("id = ? AND user_id = ?", c.Param("id"), h.getUserID(c)).First(&session).Error
+	if err != nil {
+		return problem.Respond(c, http.StatusNotFound, "session not found")
+	}
+	if err := h.revokeSessions(c.Request().Context(), h.getUserID(c), session.ID.String()); err != nil {
+		return problem.Respond(c, http.StatusInternalServerError, "could not revoke session")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *AuthHandler) RevokeAllSessions(c echo.Context) error {
+	if err := h.revokeSessions(c.Request().Context(), h.getUserID(c), ""); err != nil {
+		return problem.Respond(c, http.StatusInternalServerError, "could not revoke sessions")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// IsRevoked checks the session table directly. It is used by AuthMiddleware
+// when no Redis revocation list is available.
+func (h *AuthHandler) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return true, nil
+	}
+	var session model.Session
+	err := h.DB.WithContext(ctx).Select("id", "revoked_at", "expires_at").Where("id = ?", sessionID).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !session.Active(time.Now()), nil
+}
+
+// revokeSessions revokes one session of the user, or all of them when sessionID is empty.
+func (h *AuthHandler) revokeSessions(ctx context.Context, userID, sessionID string) error {
+	q := h.DB.WithContext(ctx).Model(&model.Session{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	if sessionID != "" {
+		q = q.Where("id = ?", sessionID)
+	}
+	var ids []string
+	if err := q.Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
 	}
+	// The revocation list goes first: while it is in use AuthMiddleware checks
+	// nothing else, so a session that is only marked in the table would keep
+	// its access tokens. If the list fails the sessions stay active and the
+	// request can be retried.
+	for _, id := range ids {
+		if err := h.pushRevocation(ctx, id); err != nil {
+			return err
+		}
+	}
+	return h.DB.WithContext(ctx).Model(&model.Session{}).
+		Where("id IN ?", ids).Update("revoked_at", time.Now()).Error
+}
+
+// pushRevocation adds the session to the revocation list, if there is one.
+// It outlives the request so that a client hanging up cannot cancel it.
+func (h *AuthHandler) pushRevocation(ctx context.Context, sessionID string) error {
+	if h.Revocations == nil {
+		return nil
+	}
+	if err := h.Revocations.Revoke(context.WithoutCancel(ctx), sessionID, h.accessTTL()); err != nil {
+		log.Printf("[ERROR] Auth: could not add session %s to revocation list: %v", sessionID, err)
+		return err
+	}
+	return nil
+}
 
+func (h *AuthHandler) respondWithTokens(c echo.Context, userID, sessionID, refresh string) error {
+	now := time.Now()
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": user.ID.String(),
-		"exp": time.Now().Add(time.Hour * 72).Unix(),
+		"sub": userID,
+		"sid": sessionID,
+		"iat": now.Unix(),
+		"exp": now.Add(h.accessTTL()).Unix(),
 	})
 
 	t, err := token.SignedString([]byte(h.Secret))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "could not generate token"})
+		return problem.Respond(c, http.StatusInternalServerError, "could not generate token")
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{"token": t})
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"access_token":  t,
+		"refresh_token": refresh,
+		"token_type":    "Bearer",
+		"expires_in":    int(h.accessTTL().Seconds()),
+	})
+}
+
+func (h *AuthHandler) newRefreshToken(sessionID uuid.UUID, now time.Time) (string, model.RefreshToken) {
+	buf := make([]byte, 32)
+	_, _ = rand.Read(buf)
+	raw := base64.RawURLEncoding.EncodeToString(buf)
+	return raw, model.RefreshToken{
+		ID:        uuid.New(),
+		SessionID: sessionID,
+		TokenHash: hashToken(raw),
+		ExpiresAt: now.Add(h.refreshTTL()),
+		CreatedAt: now,
+	}
+}
+
+func (h *AuthHandler) accessTTL() time.Duration {
+	if h.AccessTTL <= 0 {
+		return 15 * time.Minute
+	}
+	return h.AccessTTL
+}
+
+func (h *AuthHandler) refreshTTL() time.Duration {
+	if h.RefreshTTL <= 0 {
+		return 30 * 24 * time.Hour
+	}
+	return h.RefreshTTL
+}
+
+func (h *AuthHandler) getUserID(c echo.Context) string {
+	return c.Get("user_id").(string)
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
 }
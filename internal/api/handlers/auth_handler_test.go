@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -8,10 +9,16 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/testutils"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -71,6 +78,62 @@ func TestAuthHandler_Unit(t *testing.T) {
 		assert.Equal(t, http.StatusConflict, rec.Code)
 	})
 
+	t.Run("Register_With_Invitation", func(t *testing.T) {
+		invites := new(testutils.WorkspaceMocks)
+		ih := &AuthHandler{DB: db, Secret: "test", Invitations: invites}
+		wsID := uuid.New()
+		ctx := context.Background()
+		invites.On("InvitationByToken", ctx, "invite").
+			Return(model.WorkspaceInvitation{WorkspaceID: wsID, Email: "invited@test.com"}, nil).Twice()
+		invites.On("AcceptInvitationToken", ctx, "invite", testifymock.Anything).
+			Return(repository.Member{WorkspaceMember: model.WorkspaceMember{WorkspaceID: wsID}}, nil).Once()
+
+		// Приглашение на другой адрес отклоняется до создания аккаунта
+		req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"email":"other@test.com","password":"p","invite_token":"invite"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		assert.NoError(t, ih.Register(e.NewContext(req, rec)))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO "users"`).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		req = httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"email":"Invited@test.com","password":"p","invite_token":"invite"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec = httptest.NewRecorder()
+		if assert.NoError(t, ih.Register(e.NewContext(req, rec))) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Contains(t, rec.Body.String(), wsID.String())
+		}
+		invites.AssertExpectations(t)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Register_Invitation_Error_Hidden", func(t *testing.T) {
+		invites := new(testutils.WorkspaceMocks)
+		ih := &AuthHandler{DB: db, Secret: "test", Invitations: invites}
+		ctx := context.Background()
+		invites.On("InvitationByToken", ctx, "invite").
+			Return(model.WorkspaceInvitation{Email: "late@test.com"}, nil).Once()
+		invites.On("AcceptInvitationToken", ctx, "invite", testifymock.Anything).
+			Return(repository.Member{}, errors.New(`pq: relation "workspace_members" does not exist`)).Once()
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO "users"`).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		// Аккаунт создан, но внутренняя ошибка клиенту не показывается
+		req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"email":"late@test.com","password":"p","invite_token":"invite"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		if assert.NoError(t, ih.Register(e.NewContext(req, rec))) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"invitation_error":"internal server error"`)
+			assert.NotContains(t, rec.Body.String(), "workspace_members")
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("Login_Success", func(t *testing.T) {
 		password := "secret123"
 		hash, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -79,6 +142,10 @@ func TestAuthHandler_Unit(t *testing.T) {
 		rows := sqlmock.NewRows([]string{"id", "email", "password_hash"}).
 			AddRow(uID, "test@test.com", string(hash))
 		mock.ExpectQuery(`SELECT \* FROM "users"`).WillReturnRows(rows)
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO "sessions"`).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`INSERT INTO "refresh_tokens"`).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 
 		body := `{"email":"test@test.com","password":"secret123"}`
 		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
@@ -88,10 +155,77 @@ func TestAuthHandler_Unit(t *testing.T) {
 
 		if assert.NoError(t, h.Login(c)) {
 			assert.Equal(t, http.StatusOK, rec.Code)
-			assert.Contains(t, rec.Body.String(), "token")
+			assert.Contains(t, rec.Body.String(), "access_token")
+			assert.Contains(t, rec.Body.String(), "refresh_token")
 		}
 	})
 
+	t.Run("Refresh_UnknownToken", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM "refresh_tokens"`).WillReturnError(gorm.ErrRecordNotFound)
+		mock.ExpectRollback()
+
+		req := httptest.NewRequest(http.MethodPost, "/auth/refresh", strings.NewReader(`{"refresh_token":"garbage"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		assert.NoError(t, h.Refresh(c))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Refresh_ReusedToken_RevokesSession", func(t *testing.T) {
+		sessionID := uuid.New()
+		usedAt := time.Now().Add(-time.Minute)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM "refresh_tokens"`).WillReturnRows(
+			sqlmock.NewRows([]string{"id", "session_id", "token_hash", "expires_at", "used_at"}).
+				AddRow(uuid.New(), sessionID, "hash", time.Now().Add(time.Hour), usedAt))
+		mock.ExpectQuery(`SELECT \* FROM "sessions"`).WillReturnRows(
+			sqlmock.NewRows([]string{"id", "user_id", "expires_at"}).
+				AddRow(sessionID, uuid.New(), time.Now().Add(time.Hour)))
+		mock.ExpectExec(`UPDATE "sessions" SET "revoked_at"`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		req := httptest.NewRequest(http.MethodPost, "/auth/refresh", strings.NewReader(`{"refresh_token":"stolen"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		assert.NoError(t, h.Refresh(c))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Logout_NoSession", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", uuid.New().String())
+
+		assert.NoError(t, h.Logout(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Logout_RevocationListDown", func(t *testing.T) {
+		sessionID := uuid.New().String()
+		down := &AuthHandler{DB: db, Secret: "test", Revocations: failingRevoker{}}
+		mock.ExpectQuery(`SELECT "id" FROM "sessions"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(sessionID))
+
+		req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", uuid.New().String())
+		c.Set("session_id", sessionID)
+
+		// Без записи в список отзыва токен остался бы рабочим, поэтому выход не удаётся,
+		// а сессия в таблице не помечается и повторный запрос отзовёт её заново
+		assert.NoError(t, down.Logout(c))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("Register_InvalidInput", func(t *testing.T) {
 		body := `{"email":"","password":""}`
 		req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(body))
@@ -102,4 +236,91 @@ func TestAuthHandler_Unit(t *testing.T) {
 		assert.NoError(t, h.Register(c))
 		assert.Equal(t, http.StatusBadRequest, rec.Code)
 	})
+
+	t.Run("UpdateProfile_Timezone", func(t *testing.T) {
+		uID := uuid.New()
+		mock.ExpectQuery(`SELECT \* FROM "users"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "timezone"}).AddRow(uID, "me@test.com", ""))
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "users" SET "timezone"`).
+			WithArgs("Europe/Moscow", uID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/profile", strings.NewReader(`{"timezone":"Europe/Moscow"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", uID.String())
+
+		assert.NoError(t, h.UpdateProfile(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"timezone":"Europe/Moscow"`)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("UpdateProfile_InvalidTimezone", func(t *testing.T) {
+		uID := uuid.New()
+		mock.ExpectQuery(`SELECT \* FROM "users"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "timezone"}).AddRow(uID, "me@test.com", ""))
+
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/profile", strings.NewReader(`{"timezone":"MSK"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", uID.String())
+
+		// Значение не сохраняется
+		assert.NoError(t, h.UpdateProfile(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"field":"timezone"`)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Timezone_Lookup", func(t *testing.T) {
+		uID := uuid.New().String()
+		mock.ExpectQuery(`SELECT "id","timezone" FROM "users"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "timezone"}).AddRow(uID, "Asia/Yekaterinburg"))
+
+		tz, err := h.Timezone(context.Background(), uID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Asia/Yekaterinburg", tz)
+
+		// Повторный запрос обслуживается из кэша, без обращения к базе
+		tz, err = h.Timezone(context.Background(), uID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Asia/Yekaterinburg", tz)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Timezone_RefreshedOnUpdate", func(t *testing.T) {
+		uID := uuid.New()
+		mock.ExpectQuery(`SELECT "id","timezone" FROM "users"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "timezone"}).AddRow(uID, ""))
+		tz, err := h.Timezone(context.Background(), uID.String())
+		require.NoError(t, err)
+		assert.Equal(t, "", tz)
+
+		mock.ExpectQuery(`SELECT \* FROM "users"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "timezone"}).AddRow(uID, "me@test.com", ""))
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "users" SET "timezone"`).
+			WithArgs("Asia/Tokyo", uID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/profile", strings.NewReader(`{"timezone":"Asia/Tokyo"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.Set("user_id", uID.String())
+		require.NoError(t, h.UpdateProfile(c))
+
+		tz, err = h.Timezone(context.Background(), uID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, "Asia/Tokyo", tz)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+type failingRevoker struct{}
+
+func (failingRevoker) Revoke(ctx context.Context, sessionID string, ttl time.Duration) error {
+	return errors.New("redis down")
 }
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"todo-list/internal/api/dto"
+	"todo-list/internal/domain/service"
+)
+
+type CommentHandler interface {
+	List(c echo.Context) error
+	Create(c echo.Context) error
+	Update(c echo.Context) error
+	Delete(c echo.Context) error
+}
+
+type commentHandlerImpl struct {
+	service service.CommentService
+}
+
+func NewCommentHandler(s service.CommentService) CommentHandler {
+	return &commentHandlerImpl{service: s}
+}
+
+func (h *commentHandlerImpl) getUserID(c echo.Context) string {
+	return c.Get("user_id").(string)
+}
+
+// respondError treats anything but validation and role errors as a missing
+// task or comment, like task lookups do.
+func (h *commentHandlerImpl) List(c echo.Context) error {
+	comments, err := h.service.ListComments(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	out := make([]dto.CommentResponseDTO, 0, len(comments))
+	for _, cm := range comments {
+		out = append(out, dto.ToCommentResponseDTO(cm))
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+func (h *commentHandlerImpl) Create(c echo.Context) error {
+	var req dto.CommentRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	comment, err := h.service.AddComment(c.Request().Context(), c.Param("id"), h.getUserID(c), req.Body)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusCreated, dto.ToCommentResponseDTO(comment))
+}
+
+func (h *commentHandlerImpl) Update(c echo.Context) error {
+	var req dto.CommentRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	comment, err := h.service.EditComment(c.Request().Context(), c.Param("commentId"), c.Param("id"), h.getUserID(c), req.Body)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToCommentResponseDTO(comment))
+}
+
+func (h *commentHandlerImpl) Delete(c echo.Context) error {
+	err := h.service.DeleteComment(c.Request().Context(), c.Param("commentId"), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/domain/service"
+	"todo-list/internal/testutils"
+)
+
+func TestCommentHandler(t *testing.T) {
+	e := echo.New()
+	mockSvc := new(testutils.CommentMocks)
+	h := NewCommentHandler(mockSvc)
+	uID := uuid.New()
+	taskID, cID := uuid.New().String(), uuid.New()
+
+	newContext := func(method, body, commentID string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(method, "/api/v1/tasks/"+taskID+"/comments", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id", "commentId")
+		c.SetParamValues(taskID, commentID)
+		c.Set("user_id", uID.String())
+		return c, rec
+	}
+
+	t.Run("Create_Success", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, `{"body":"hi @kim"}`, "")
+		mockSvc.On("AddComment", mock.Anything, taskID, uID.String(), "hi @kim").
+			Return(model.Comment{ID: cID, AuthorID: uID, Body: "hi @kim"}, nil).Once()
+
+		if assert.NoError(t, h.Create(c)) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Contains(t, rec.Body.String(), cID.String())
+		}
+	})
+
+	t.Run("Create_Empty", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, `{}`, "")
+		mockSvc.On("AddComment", mock.Anything, taskID, uID.String(), "").
+			Return(model.Comment{}, service.ErrInvalidComment).Once()
+
+		assert.NoError(t, h.Create(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Update_Not_Author", func(t *testing.T) {
+		c, rec := newContext(http.MethodPatch, `{"body":"x"}`, cID.String())
+		mockSvc.On("EditComment", mock.Anything, cID.String(), taskID, uID.String(), "x").
+			Return(model.Comment{}, service.ErrForbidden).Once()
+
+		assert.NoError(t, h.Update(c))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Delete_NotFound", func(t *testing.T) {
+		c, rec := newContext(http.MethodDelete, "", "x")
+		mockSvc.On("DeleteComment", mock.Anything, "x", taskID, uID.String()).Return(repository.ErrCommentNotFound).Once()
+
+		assert.NoError(t, h.Delete(c))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
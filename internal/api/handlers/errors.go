@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"todo-list/internal/api/problem"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/service"
+)
+
+var (
+	errMalformedBody = apperr.New(apperr.Invalid, "request body is not valid JSON or has fields of the wrong type")
+	errInvalidQuery  = apperr.New(apperr.Invalid, "query parameters have the wrong type")
+)
+
+var kindStatus = map[apperr.Kind]int{
+	apperr.Invalid:       http.StatusBadRequest,
+	apperr.Unprocessable: http.StatusUnprocessableEntity,
+	apperr.NotFound:      http.StatusNotFound,
+	apperr.Conflict:      http.StatusConflict,
+	apperr.Forbidden:     http.StatusForbidden,
+	apperr.Unauthorized:  http.StatusUnauthorized,
+	apperr.Precondition:  http.StatusPreconditionFailed,
+	apperr.Gone:          http.StatusGone,
+	apperr.TooLarge:      http.StatusRequestEntityTooLarge,
+	apperr.Unsupported:   http.StatusUnsupportedMediaType,
+}
+
+// respondError writes err as a problem response whose status follows the
+// error's kind. Errors without a kind are logged and reported as a bare 500 so
+// that database and driver messages never reach the client.
+func respondError(c echo.Context, err error) error {
+	return problem.Write(c, problemFor(c, err))
+}
+
+func problemFor(c echo.Context, err error) *problem.Details {
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		detail := ""
+		if httpErr.Code < http.StatusInternalServerError {
+			detail = fmt.Sprint(httpErr.Message)
+		} else {
+			log.Printf("[ERROR] %s %s: %v", c.Request().Method, c.Request().URL.Path, err)
+		}
+		return problem.New(httpErr.Code, detail)
+	}
+	// Tasks and other rows the user cannot see are reported the same way as
+	// missing ones.
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return problem.New(http.StatusNotFound, "resource not found")
+	}
+	status, ok := kindStatus[apperr.KindOf(err)]
+	if !ok {
+		log.Printf("[ERROR] %s %s: %v", c.Request().Method, c.Request().URL.Path, err)
+		return problem.New(http.StatusInternalServerError, "internal server error")
+	}
+	p := problem.New(status, err.Error())
+	p.Errors = apperr.ViolationsOf(err)
+	var transition *service.TransitionError
+	if errors.As(err, &transition) {
+		p.Extensions = map[string]interface{}{"from": transition.From, "allowed": transition.Allowed}
+	}
+	return p
+}
+
+// HTTPErrorHandler replaces echo's default so that errors returned by
+// middleware, unknown routes and the binder are problem responses too.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+	if c.Request().Method == http.MethodHead {
+		p := problemFor(c, err)
+		err = c.NoContent(p.Status)
+	} else {
+		err = respondError(c, err)
+	}
+	if err != nil {
+		c.Logger().Error(err)
+	}
+}
+
+// bind decodes the request into req and runs its Validate method when it has
+// one.
+func bind(c echo.Context, req interface{}) error {
+	if err := c.Bind(req); err != nil {
+		return errMalformedBody
+	}
+	if v, ok := req.(interface{ Validate() error }); ok {
+		return v.Validate()
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"strconv"
+	"strings"
+	"todo-list/internal/api/dto"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+)
+
+// taskETag is the strong entity tag of the task's current version. Every write
+// that changes the task's representation, including its comment count, tag
+// names and project, moves the task to a new version.
+func taskETag(task model.Task) string {
+	return `"` + strconv.FormatInt(task.Version, 10) + `"`
+}
+
+// respondTask writes the task together with its ETag.
+func respondTask(c echo.Context, status int, task model.Task) error {
+	c.Response().Header().Set("ETag", taskETag(task))
+	return c.JSON(status, dto.ToTaskResponseDTO(task))
+}
+
+// ifMatchVersion reads the If-Match precondition of a write. Without one, or
+// with "*", it returns 0, which every version satisfies. Anything but a single
+// strong tag can never match and fails the precondition.
+func ifMatchVersion(c echo.Context) (int64, error) {
+	tag := strings.TrimSpace(c.Request().Header.Get("If-Match"))
+	if tag == "" || tag == "*" {
+		return 0, nil
+	}
+	version, err := strconv.ParseInt(strings.Trim(tag, `"`), 10, 64)
+	if err != nil || version <= 0 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+		return 0, repository.ErrVersionConflict
+	}
+	return version, nil
+}
+
+// notModified reports whether If-None-Match already names the task's current
+// version. Tags are compared weakly, as RFC 9110 asks for.
+func notModified(c echo.Context, task model.Task) bool {
+	header := c.Request().Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	current := taskETag(task)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"sync"
+	"time"
+	"todo-list/internal/api/problem"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/usertime"
+)
+
+func (h *AuthHandler) Profile(c echo.Context) error {
+	var user model.User
+	err := h.DB.WithContext(c.Request().Context()).Where("id = ?", h.getUserID(c)).First(&user).Error
+	if err != nil {
+		return problem.Respond(c, http.StatusNotFound, "user not found")
+	}
+	return c.JSON(http.StatusOK, profileResponse(user))
+}
+
+// UpdateProfile changes the user's settings. An empty timezone resets it to
+// UTC.
+func (h *AuthHandler) UpdateProfile(c echo.Context) error {
+	var req struct {
+		Timezone *string `json:"timezone"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, errMalformedBody)
+	}
+	ctx := c.Request().Context()
+	var user model.User
+	if err := h.DB.WithContext(ctx).Where("id = ?", h.getUserID(c)).First(&user).Error; err != nil {
+		return problem.Respond(c, http.StatusNotFound, "user not found")
+	}
+	if req.Timezone != nil {
+		if _, err := usertime.Load(*req.Timezone); err != nil {
+			return respondError(c, apperr.Field("timezone", "must be an IANA time zone name such as Europe/Moscow"))
+		}
+		user.Timezone = *req.Timezone
+		if err := h.DB.WithContext(ctx).Model(&user).Update("timezone", user.Timezone).Error; err != nil {
+			return respondError(c, err)
+		}
+		h.zones.put(user.ID.String(), user.Timezone)
+	}
+	return c.JSON(http.StatusOK, profileResponse(user))
+}
+
+// Timezone returns the time zone stored in the user's profile. The Timezone
+// middleware asks for it on every request, so answers are cached for
+// timezoneTTL; UpdateProfile refreshes the entry of this instance at once.
+func (h *AuthHandler) Timezone(ctx context.Context, userID string) (string, error) {
+	if name, ok := h.zones.get(userID); ok {
+		return name, nil
+	}
+	var user model.User
+	err := h.DB.WithContext(ctx).Select("id", "timezone").Where("id = ?", userID).First(&user).Error
+	if err != nil {
+		return "", err
+	}
+	h.zones.put(userID, user.Timezone)
+	return user.Timezone, nil
+}
+
+// timezoneTTL bounds how long other instances keep serving a zone the user
+// has since changed.
+const timezoneTTL = 5 * time.Minute
+
+// maxCachedZones is how many users' zones are kept before expired entries are
+// swept out.
+const maxCachedZones = 10000
+
+type cachedZone struct {
+	name    string
+	expires time.Time
+}
+
+// timezoneCache maps user ids to their stored time zone. The zero value is
+// ready to use.
+type timezoneCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedZone
+}
+
+func (c *timezoneCache) get(userID string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	z, ok := c.entries[userID]
+	if !ok || time.Now().After(z.expires) {
+		return "", false
+	}
+	return z.name, true
+}
+
+func (c *timezoneCache) put(userID, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if c.entries == nil {
+		c.entries = make(map[string]cachedZone)
+	}
+	if len(c.entries) >= maxCachedZones {
+		for id, z := range c.entries {
+			if now.After(z.expires) {
+				delete(c.entries, id)
+			}
+		}
+		if len(c.entries) >= maxCachedZones {
+			clear(c.entries)
+		}
+	}
+	c.entries[userID] = cachedZone{name: name, expires: now.Add(timezoneTTL)}
+}
+
+func profileResponse(user model.User) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         user.ID,
+		"email":      user.Email,
+		"timezone":   user.Timezone,
+		"created_at": user.CreatedAt,
+	}
+}
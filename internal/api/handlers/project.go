@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"net/http"
+	"todo-list/internal/api/dto"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/service"
+)
+
+type ProjectHandler interface {
+	List(c echo.Context) error
+	Create(c echo.Context) error
+	Get(c echo.Context) error
+	Update(c echo.Context) error
+	Archive(c echo.Context) error
+	Unarchive(c echo.Context) error
+	Delete(c echo.Context) error
+	Reorder(c echo.Context) error
+	Tasks(c echo.Context) error
+	Search(c echo.Context) error
+	Stats(c echo.Context) error
+	Workflow(c echo.Context) error
+	SetWorkflow(c echo.Context) error
+	ResetWorkflow(c echo.Context) error
+	MoveTask(c echo.Context) error
+	BulkMove(c echo.Context) error
+}
+
+type projectHandlerImpl struct {
+	service service.ProjectService
+}
+
+func NewProjectHandler(s service.ProjectService) ProjectHandler {
+	return &projectHandlerImpl{service: s}
+}
+
+func (h *projectHandlerImpl) getUserID(c echo.Context) string {
+	return c.Get("user_id").(string)
+}
+
+func (h *projectHandlerImpl) List(c echo.Context) error {
+	projects, err := h.service.ListProjects(c.Request().Context(), h.getUserID(c), c.QueryParam("archived") == "true")
+	if err != nil {
+		return respondError(c, err)
+	}
+	out := make([]dto.ProjectResponseDTO, 0, len(projects))
+	for _, p := range projects {
+		out = append(out, dto.ToProjectResponseDTO(p))
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+func (h *projectHandlerImpl) Create(c echo.Context) error {
+	var req dto.ProjectRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	var name, color, description string
+	if req.Name != nil {
+		name = *req.Name
+	}
+	if req.Color != nil {
+		color = *req.Color
+	}
+	if req.Description != nil {
+		description = *req.Description
+	}
+	project, err := h.service.CreateProject(c.Request().Context(), h.getUserID(c), name, color, description)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusCreated, dto.ToProjectResponseDTO(project))
+}
+
+func (h *projectHandlerImpl) Get(c echo.Context) error {
+	project, err := h.service.GetProject(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToProjectResponseDTO(project))
+}
+
+func (h *projectHandlerImpl) Update(c echo.Context) error {
+	var req dto.ProjectRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	project, err := h.service.UpdateProject(c.Request().Context(), c.Param("id"), h.getUserID(c), req.Name, req.Color, req.Description)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToProjectResponseDTO(project))
+}
+
+func (h *projectHandlerImpl) Archive(c echo.Context) error {
+	project, err := h.service.ArchiveProject(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToProjectResponseDTO(project))
+}
+
+func (h *projectHandlerImpl) Unarchive(c echo.Context) error {
+	project, err := h.service.UnarchiveProject(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToProjectResponseDTO(project))
+}
+
+func (h *projectHandlerImpl) Delete(c echo.Context) error {
+	if err := h.service.DeleteProject(c.Request().Context(), c.Param("id"), h.getUserID(c)); err != nil {
+		return respondError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *projectHandlerImpl) Reorder(c echo.Context) error {
+	var req dto.ReorderProjectsRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	projects, err := h.service.ReorderProjects(c.Request().Context(), h.getUserID(c), req.IDs)
+	if err != nil {
+		return respondError(c, err)
+	}
+	out := make([]dto.ProjectResponseDTO, 0, len(projects))
+	for _, p := range projects {
+		out = append(out, dto.ToProjectResponseDTO(p))
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+func (h *projectHandlerImpl) Tasks(c echo.Context) error {
+	page, err := getPageRequest(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	var q dto.TaskFilterQueryDTO
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &q); err != nil {
+		return respondError(c, errInvalidQuery)
+	}
+	expr, err := q.Expression()
+	if err != nil {
+		return respondError(c, err)
+	}
+	res, err := h.service.ListProjectTasks(c.Request().Context(), c.Param("id"), h.getUserID(c), expr, page)
+	return respondTaskPage(c, res, err)
+}
+
+func (h *projectHandlerImpl) Search(c echo.Context) error {
+	page, err := getPageRequest(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	res, err := h.service.SearchProjectTasks(c.Request().Context(), c.Param("id"), h.getUserID(c), c.QueryParam("q"), page)
+	return respondTaskPage(c, res, err)
+}
+
+func (h *projectHandlerImpl) Stats(c echo.Context) error {
+	s, err := h.service.ProjectStats(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, s)
+}
+
+func (h *projectHandlerImpl) Workflow(c echo.Context) error {
+	p, err := h.service.GetProject(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToWorkflowResponseDTO(p.Workflow))
+}
+
+func (h *projectHandlerImpl) SetWorkflow(c echo.Context) error {
+	var req dto.WorkflowRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	wf := &model.Workflow{Statuses: req.Statuses, Transitions: req.Transitions}
+	p, err := h.service.SetWorkflow(c.Request().Context(), c.Param("id"), h.getUserID(c), wf)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToWorkflowResponseDTO(p.Workflow))
+}
+
+func (h *projectHandlerImpl) ResetWorkflow(c echo.Context) error {
+	p, err := h.service.SetWorkflow(c.Request().Context(), c.Param("id"), h.getUserID(c), nil)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToWorkflowResponseDTO(p.Workflow))
+}
+
+// MoveTask moves one task, with its subtasks, to the project in the body;
+// a null project_id moves it back to the inbox.
+func (h *projectHandlerImpl) MoveTask(c echo.Context) error {
+	var req dto.MoveToProjectRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	moved, err := h.service.MoveTasksToProject(c.Request().Context(), h.getUserID(c), req.ProjectID, []string{c.Param("id")})
+	if err != nil {
+		return respondError(c, err)
+	}
+	if len(moved) == 0 {
+		return respondError(c, gorm.ErrRecordNotFound)
+	}
+	return c.JSON(http.StatusOK, map[string]int{"moved": len(moved)})
+}
+
+func (h *projectHandlerImpl) BulkMove(c echo.Context) error {
+	var req dto.BulkMoveRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	moved, err := h.service.MoveTasksToProject(c.Request().Context(), h.getUserID(c), req.ProjectID, req.IDs)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]int{"moved": len(moved)})
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/domain/service"
+	"todo-list/internal/testutils"
+)
+
+func TestProjectHandler(t *testing.T) {
+	e := echo.New()
+	mockSvc := new(testutils.ProjectMocks)
+	h := NewProjectHandler(mockSvc)
+	uID := "test-user"
+	pID := uuid.New()
+
+	newContext := func(method, target, body string, id string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		if id != "" {
+			c.SetParamNames("id")
+			c.SetParamValues(id)
+		}
+		c.Set("user_id", uID)
+		return c, rec
+	}
+
+	t.Run("Create_Success", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, "/api/v1/projects", `{"name":"Work","color":"#1e90ff"}`, "")
+		mockSvc.On("CreateProject", mock.Anything, uID, "Work", "#1e90ff", "").
+			Return(model.Project{ID: pID, Name: "Work", Color: "#1e90ff"}, nil).Once()
+
+		if assert.NoError(t, h.Create(c)) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Contains(t, rec.Body.String(), pID.String())
+		}
+	})
+
+	t.Run("Create_InvalidName", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, "/api/v1/projects", `{}`, "")
+		mockSvc.On("CreateProject", mock.Anything, uID, "", "", "").
+			Return(model.Project{}, service.ErrInvalidProjectName).Once()
+
+		assert.NoError(t, h.Create(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Get_NotFound", func(t *testing.T) {
+		c, rec := newContext(http.MethodGet, "/api/v1/projects/x", "", "x")
+		mockSvc.On("GetProject", mock.Anything, "x", uID).Return(model.Project{}, repository.ErrProjectNotFound).Once()
+
+		assert.NoError(t, h.Get(c))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Stats_Success", func(t *testing.T) {
+		c, rec := newContext(http.MethodGet, "/api/v1/projects/"+pID.String()+"/stats", "", pID.String())
+		mockSvc.On("ProjectStats", mock.Anything, pID.String(), uID).
+			Return(map[string]int64{"todo": 2, "done": 1, "total": 3}, nil).Once()
+
+		if assert.NoError(t, h.Stats(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"total":3`)
+		}
+	})
+
+	t.Run("Tasks_Unknown_Project", func(t *testing.T) {
+		c, rec := newContext(http.MethodGet, "/api/v1/projects/x/tasks", "", "x")
+		mockSvc.On("ListProjectTasks", mock.Anything, "x", uID, "", mock.Anything).
+			Return(repository.TaskPage{}, repository.ErrProjectNotFound).Once()
+
+		assert.NoError(t, h.Tasks(c))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("MoveTask_To_Inbox", func(t *testing.T) {
+		taskID := uuid.New()
+		c, rec := newContext(http.MethodPatch, "/api/v1/tasks/"+taskID.String()+"/project", `{"project_id":null}`, taskID.String())
+		mockSvc.On("MoveTasksToProject", mock.Anything, uID, (*string)(nil), []string{taskID.String()}).
+			Return([]uuid.UUID{taskID, uuid.New()}, nil).Once()
+
+		if assert.NoError(t, h.MoveTask(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"moved":2`)
+		}
+	})
+
+	t.Run("BulkMove_Unknown_Project", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, "/api/v1/tasks/bulk-move", `{"ids":["0b6f4c3e-7a41-4f0e-9d7a-2f1c9a1e5b11"],"project_id":"5d3c0a8e-1f2b-4c6d-8e9f-0a1b2c3d4e5f"}`, "")
+		mockSvc.On("MoveTasksToProject", mock.Anything, uID, mock.Anything, []string{"0b6f4c3e-7a41-4f0e-9d7a-2f1c9a1e5b11"}).
+			Return([]uuid.UUID(nil), repository.ErrProjectNotFound).Once()
+
+		assert.NoError(t, h.BulkMove(c))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Workflow_Default_And_Invalid", func(t *testing.T) {
+		c, rec := newContext(http.MethodGet, "/api/v1/projects/"+pID.String()+"/workflow", "", pID.String())
+		mockSvc.On("GetProject", mock.Anything, pID.String(), uID).Return(model.Project{ID: pID}, nil).Once()
+
+		if assert.NoError(t, h.Workflow(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"default":true`)
+		}
+
+		c, rec = newContext(http.MethodPut, "/api/v1/projects/"+pID.String()+"/workflow", `{"statuses":["todo"]}`, pID.String())
+		mockSvc.On("SetWorkflow", mock.Anything, pID.String(), uID, &model.Workflow{Statuses: []string{"todo"}}).
+			Return(model.Project{}, service.ErrInvalidWorkflow).Once()
+
+		assert.NoError(t, h.SetWorkflow(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	mockSvc.AssertExpectations(t)
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"todo-list/internal/api/dto"
+	"todo-list/internal/domain/service"
+)
+
+type ReminderHandler interface {
+	List(c echo.Context) error
+	Create(c echo.Context) error
+	Delete(c echo.Context) error
+}
+
+type reminderHandlerImpl struct {
+	service service.ReminderService
+}
+
+func NewReminderHandler(s service.ReminderService) ReminderHandler {
+	return &reminderHandlerImpl{service: s}
+}
+
+func (h *reminderHandlerImpl) getUserID(c echo.Context) string {
+	return c.Get("user_id").(string)
+}
+
+func (h *reminderHandlerImpl) List(c echo.Context) error {
+	reminders, err := h.service.ListReminders(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	out := make([]dto.ReminderResponseDTO, 0, len(reminders))
+	for _, r := range reminders {
+		out = append(out, dto.ToReminderResponseDTO(r))
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+func (h *reminderHandlerImpl) Create(c echo.Context) error {
+	var req dto.ReminderRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	reminder, err := h.service.AddReminder(c.Request().Context(), c.Param("id"), h.getUserID(c),
+		req.RemindAt, req.OffsetMinutes, req.Channel, req.Target)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusCreated, dto.ToReminderResponseDTO(reminder))
+}
+
+func (h *reminderHandlerImpl) Delete(c echo.Context) error {
+	err := h.service.DeleteReminder(c.Request().Context(), c.Param("reminderId"), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
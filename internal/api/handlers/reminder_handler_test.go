@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/domain/service"
+	"todo-list/internal/testutils"
+)
+
+func TestReminderHandler(t *testing.T) {
+	e := echo.New()
+	mockSvc := new(testutils.ReminderMocks)
+	h := NewReminderHandler(mockSvc)
+	uID := "test-user"
+
+	newContext := func(method, body string, params ...string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(method, "/api/v1/tasks/1/reminders", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id", "reminderId")
+		c.SetParamValues(params...)
+		c.Set("user_id", uID)
+		return c, rec
+	}
+
+	t.Run("Create_Offset", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, `{"offset_minutes":15,"channel":"webhook","target":"https://hooks.example.com"}`, "1", "")
+		offset := 15
+		mockSvc.On("AddReminder", mock.Anything, "1", uID, (*time.Time)(nil), &offset, "webhook", "https://hooks.example.com").
+			Return(model.Reminder{ID: uuid.New(), Channel: "webhook", OffsetMinutes: &offset}, nil).Once()
+
+		if assert.NoError(t, h.Create(c)) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"status":"pending"`)
+		}
+	})
+
+	t.Run("Create_Invalid", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, `{}`, "1", "")
+		mockSvc.On("AddReminder", mock.Anything, "1", uID, mock.Anything, mock.Anything, "", "").
+			Return(model.Reminder{}, service.ErrReminderTiming).Once()
+
+		assert.NoError(t, h.Create(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Delete_NotFound", func(t *testing.T) {
+		c, rec := newContext(http.MethodDelete, "", "1", "2")
+		mockSvc.On("DeleteReminder", mock.Anything, "2", "1", uID).Return(repository.ErrReminderNotFound).Once()
+
+		assert.NoError(t, h.Delete(c))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	mockSvc.AssertExpectations(t)
+}
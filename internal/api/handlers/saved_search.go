@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"todo-list/internal/api/dto"
+	"todo-list/internal/domain/service"
+)
+
+type SavedSearchHandler interface {
+	List(c echo.Context) error
+	Create(c echo.Context) error
+	Get(c echo.Context) error
+	Update(c echo.Context) error
+	Delete(c echo.Context) error
+	Tasks(c echo.Context) error
+}
+
+type savedSearchHandlerImpl struct {
+	service service.SavedSearchService
+}
+
+func NewSavedSearchHandler(s service.SavedSearchService) SavedSearchHandler {
+	return &savedSearchHandlerImpl{service: s}
+}
+
+func (h *savedSearchHandlerImpl) getUserID(c echo.Context) string {
+	return c.Get("user_id").(string)
+}
+
+func (h *savedSearchHandlerImpl) List(c echo.Context) error {
+	searches, err := h.service.ListSavedSearches(c.Request().Context(), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	out := make([]dto.SavedSearchResponseDTO, 0, len(searches))
+	for _, s := range searches {
+		out = append(out, dto.ToSavedSearchResponseDTO(s))
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+func (h *savedSearchHandlerImpl) Create(c echo.Context) error {
+	var req dto.SavedSearchRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	s, err := h.service.CreateSavedSearch(c.Request().Context(), h.getUserID(c), req.Name, req.Filter, req.Sort, req.Desc())
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusCreated, dto.ToSavedSearchResponseDTO(s))
+}
+
+func (h *savedSearchHandlerImpl) Get(c echo.Context) error {
+	s, err := h.service.GetSavedSearch(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToSavedSearchResponseDTO(s))
+}
+
+func (h *savedSearchHandlerImpl) Update(c echo.Context) error {
+	var req dto.SavedSearchRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	s, err := h.service.UpdateSavedSearch(c.Request().Context(), c.Param("id"), h.getUserID(c), req.Name, req.Filter, req.Sort, req.Desc())
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToSavedSearchResponseDTO(s))
+}
+
+func (h *savedSearchHandlerImpl) Delete(c echo.Context) error {
+	if err := h.service.DeleteSavedSearch(c.Request().Context(), c.Param("id"), h.getUserID(c)); err != nil {
+		return respondError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Tasks runs the saved search; without a sort parameter the order saved with
+// it applies.
+func (h *savedSearchHandlerImpl) Tasks(c echo.Context) error {
+	page, err := getPageRequest(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	res, err := h.service.RunSavedSearch(c.Request().Context(), c.Param("id"), h.getUserID(c), page)
+	return respondTaskPage(c, res, err)
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/domain/service"
+	"todo-list/internal/testutils"
+)
+
+func TestSavedSearchHandler(t *testing.T) {
+	e := echo.New()
+	mockSvc := new(testutils.SavedSearchMocks)
+	h := NewSavedSearchHandler(mockSvc)
+	uID := "test-user"
+
+	newContext := func(method, target, body string, id string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		c.Set("user_id", uID)
+		return c, rec
+	}
+
+	t.Run("Create", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, "/api/v1/saved-searches", `{"name":"Срочное","filter":"priority:urgent","sort":"due_date"}`, "")
+		saved := model.SavedSearch{ID: uuid.New(), Name: "Срочное", Filter: "priority:urgent", Sort: "due_date"}
+		mockSvc.On("CreateSavedSearch", mock.Anything, uID, "Срочное", "priority:urgent", "due_date", false).Return(saved, nil).Once()
+
+		if assert.NoError(t, h.Create(c)) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.JSONEq(t, `{"id":"`+saved.ID.String()+`","name":"Срочное","filter":"priority:urgent","sort":"due_date","order":"asc","system":false}`, rec.Body.String())
+		}
+	})
+
+	t.Run("Create_Validation", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, "/api/v1/saved-searches", `{"name":" ","sort":"colour"}`, "")
+
+		assert.NoError(t, h.Create(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"field":"name"`)
+		assert.Contains(t, rec.Body.String(), `"field":"sort"`)
+	})
+
+	t.Run("List_Shows_System_Lists_By_Key", func(t *testing.T) {
+		c, rec := newContext(http.MethodGet, "/api/v1/saved-searches", "", "")
+		mockSvc.On("ListSavedSearches", mock.Anything, uID).Return([]model.SavedSearch{{Key: service.ListToday, Name: "Today", Filter: "due:today"}}, nil).Once()
+
+		if assert.NoError(t, h.List(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, `[{"id":"today","name":"Today","filter":"due:today","system":true}]`, rec.Body.String())
+		}
+	})
+
+	t.Run("Update_System_List", func(t *testing.T) {
+		c, rec := newContext(http.MethodPut, "/api/v1/saved-searches/today", `{"name":"Сегодня","filter":"due:today"}`, service.ListToday)
+		mockSvc.On("UpdateSavedSearch", mock.Anything, service.ListToday, uID, "Сегодня", "due:today", "", true).Return(model.SavedSearch{}, service.ErrSystemList).Once()
+
+		assert.NoError(t, h.Update(c))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Tasks", func(t *testing.T) {
+		c, rec := newContext(http.MethodGet, "/api/v1/saved-searches/overdue/tasks?limit=5", "", service.ListOverdue)
+		mockSvc.On("RunSavedSearch", mock.Anything, service.ListOverdue, uID, repository.PageRequest{Limit: 5, Desc: true}).
+			Return(repository.TaskPage{Tasks: []model.Task{{Title: "Просрочено"}}}, nil).Once()
+
+		if assert.NoError(t, h.Tasks(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), "Просрочено")
+		}
+	})
+
+	t.Run("Delete_NotFound", func(t *testing.T) {
+		id := uuid.NewString()
+		c, rec := newContext(http.MethodDelete, "/api/v1/saved-searches/"+id, "", id)
+		mockSvc.On("DeleteSavedSearch", mock.Anything, id, uID).Return(repository.ErrSavedSearchNotFound).Once()
+
+		assert.NoError(t, h.Delete(c))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+	mockSvc.AssertExpectations(t)
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"todo-list/internal/api/dto"
+	"todo-list/internal/api/middleware"
+	"todo-list/internal/api/problem"
+	"todo-list/internal/domain/event"
+)
+
+type StreamHandler interface {
+	Events(c echo.Context) error
+	WebSocket(c echo.Context) error
+}
+
+type streamHandlerImpl struct {
+	stream      event.Stream
+	heartbeat   time.Duration
+	revocations middleware.RevocationChecker
+	origins     []string
+}
+
+// NewStreamHandler wires the handler. Streams are re-checked against
+// revocations on every heartbeat; a nil checker leaves only the token expiry.
+// WebSocket upgrades from a browser are accepted from the API's own host and
+// from origins, where "*" allows any.
+func NewStreamHandler(stream event.Stream, heartbeat time.Duration, revocations middleware.RevocationChecker, origins []string) StreamHandler {
+	return &streamHandlerImpl{stream: stream, heartbeat: heartbeat, revocations: revocations, origins: origins}
+}
+
+func (h *streamHandlerImpl) getUserID(c echo.Context) string {
+	return c.Get("user_id").(string)
+}
+
+// lastEventID prefers the header EventSource sends on reconnect; WebSocket
+// clients pass it as a query parameter.
+func (h *streamHandlerImpl) lastEventID(c echo.Context) string {
+	if id := c.Request().Header.Get("Last-Event-ID"); id != "" {
+		return id
+	}
+	return c.QueryParam("last_event_id")
+}
+
+// checkOrigin guards the WebSocket upgrade against cross-site hijacking:
+// browsers attach cookies and the query token to the handshake but never
+// enforce CORS on it. Clients that send no Origin are not browsers.
+func (h *streamHandlerImpl) checkOrigin(req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	if u, err := url.Parse(origin); err == nil && u.Host == req.Host {
+		return nil
+	}
+	for _, allowed := range h.origins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return nil
+		}
+	}
+	return fmt.Errorf("origin %q is not allowed", origin)
+}
+
+// streamSession is what authorized a stream: the session and the expiry of
+// the access token it was opened with.
+type streamSession struct {
+	id      string
+	expires time.Time
+}
+
+func (h *streamHandlerImpl) session(c echo.Context) streamSession {
+	id, _ := c.Get("session_id").(string)
+	expires, _ := c.Get("token_expires_at").(time.Time)
+	return streamSession{id: id, expires: expires}
+}
+
+// watch returns a channel that is closed once the access token expires or the
+// session is revoked. A stream outlives the request that authorized it, so
+// without this a logged-out session would keep receiving events.
+func (h *streamHandlerImpl) watch(ctx context.Context, s streamSession) <-chan struct{} {
+	ended := make(chan struct{})
+	go func() {
+		defer close(ended)
+		var expired <-chan time.Time
+		if !s.expires.IsZero() {
+			timer := time.NewTimer(time.Until(s.expires))
+			defer timer.Stop()
+			expired = timer.C
+		}
+		ticker := time.NewTicker(h.heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-expired:
+				return
+			case <-ticker.C:
+				if h.revoked(ctx, s.id) {
+					return
+				}
+			}
+		}
+	}()
+	return ended
+}
+
+// revoked fails closed: a session that cannot be checked ends the stream and
+// the client has to reconnect through AuthMiddleware.
+func (h *streamHandlerImpl) revoked(ctx context.Context, sessionID string) bool {
+	if h.revocations == nil || sessionID == "" {
+		return false
+	}
+	revoked, err := h.revocations.IsRevoked(ctx, sessionID)
+	if err != nil {
+		log.Printf("[ERROR] Stream: could not check session %s: %v", sessionID, err)
+		return true
+	}
+	return revoked
+}
+
+// Events streams the user's task events as Server-Sent Events.
+func (h *streamHandlerImpl) Events(c echo.Context) error {
+	uID, err := uuid.Parse(h.getUserID(c))
+	if err != nil {
+		return problem.Respond(c, http.StatusUnauthorized, "unauthorized")
+	}
+	ctx := c.Request().Context()
+	ended := h.watch(ctx, h.session(c))
+	messages, err := h.stream.Subscribe(ctx, uID, h.lastEventID(c))
+	if err != nil {
+		return problem.Respond(c, http.StatusServiceUnavailable, "event stream unavailable")
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	fmt.Fprint(res, "retry: 3000\n\n")
+	res.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ended:
+			return nil
+		case <-ticker.C:
+			fmt.Fprint(res, ": ping\n\n")
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(msg.Event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(res, "id: %s\nevent: %s\ndata: %s\n\n", msg.ID, msg.Event.Type, data)
+		}
+		res.Flush()
+	}
+}
+
+// WebSocket streams the same events as JSON frames. The origin is not
+// checked: the connection is authorized by the bearer token, not by cookies.
+func (h *streamHandlerImpl) WebSocket(c echo.Context) error {
+	uID, err := uuid.Parse(h.getUserID(c))
+	if err != nil {
+		return problem.Respond(c, http.StatusUnauthorized, "unauthorized")
+	}
+	lastID := h.lastEventID(c)
+	session := h.session(c)
+	server := websocket.Server{
+		Handshake: func(_ *websocket.Config, req *http.Request) error { return h.checkOrigin(req) },
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+			// A hijacked connection outlives the request context, so the
+			// subscription ends when the client stops reading instead.
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				var discard string
+				for websocket.Message.Receive(ws, &discard) == nil {
+				}
+				cancel()
+			}()
+			h.pump(ctx, ws, uID, lastID, session)
+		},
+	}
+	server.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
+
+func (h *streamHandlerImpl) pump(ctx context.Context, ws *websocket.Conn, uID uuid.UUID, lastID string, session streamSession) {
+	ended := h.watch(ctx, session)
+	messages, err := h.stream.Subscribe(ctx, uID, lastID)
+	if err != nil {
+		_ = websocket.JSON.Send(ws, dto.StreamMessageDTO{Type: "error", Error: "event stream unavailable"})
+		return
+	}
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		var frame dto.StreamMessageDTO
+		select {
+		case <-ctx.Done():
+			return
+		case <-ended:
+			_ = websocket.JSON.Send(ws, dto.StreamMessageDTO{Type: "error", Error: "session ended"})
+			return
+		case <-ticker.C:
+			frame = dto.StreamMessageDTO{Type: "ping"}
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			frame = dto.ToStreamMessageDTO(msg)
+		}
+		if err := websocket.JSON.Send(ws, frame); err != nil {
+			return
+		}
+	}
+}
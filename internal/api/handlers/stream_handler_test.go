@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"todo-list/internal/api/dto"
+	"todo-list/internal/domain/event"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func TestStreamHandler(t *testing.T) {
+	uID := uuid.New()
+	stream := event.NewMemoryStream(10)
+	h := NewStreamHandler(stream, time.Minute, nil, nil)
+
+	e := echo.New()
+	withUser := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user_id", uID.String())
+			return next(c)
+		}
+	}
+	e.GET("/stream", h.Events, withUser)
+	e.GET("/stream/ws", h.WebSocket, withUser)
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	stream.Publish(context.Background(), event.New(event.TaskCreated, uID, map[string]interface{}{"task_id": "t1"}))
+
+	t.Run("SSE_Resumes_And_Follows", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/stream", nil)
+		req.Header.Set("Last-Event-ID", "0")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		lines := bufio.NewScanner(resp.Body)
+		next := func(prefix string) string {
+			for lines.Scan() {
+				if strings.HasPrefix(lines.Text(), prefix) {
+					return strings.TrimPrefix(lines.Text(), prefix)
+				}
+			}
+			t.Fatalf("stream ended before %q", prefix)
+			return ""
+		}
+		assert.Equal(t, "1", next("id: "))
+		assert.Equal(t, "task.created", next("event: "))
+		assert.Contains(t, next("data: "), `"task_id":"t1"`)
+
+		stream.Publish(context.Background(), event.New(event.TaskDeleted, uID, nil))
+		assert.Equal(t, "2", next("id: "))
+		assert.Equal(t, "task.deleted", next("event: "))
+	})
+
+	t.Run("WebSocket", func(t *testing.T) {
+		ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/stream/ws?last_event_id=1", "", srv.URL)
+		require.NoError(t, err)
+		defer ws.Close()
+
+		var frame dto.StreamMessageDTO
+		require.NoError(t, websocket.JSON.Receive(ws, &frame))
+		assert.Equal(t, "2", frame.ID)
+		assert.Equal(t, "event", frame.Type)
+		assert.Equal(t, event.TaskDeleted, frame.Event.Type)
+	})
+
+	// Браузер с чужого сайта не может открыть WebSocket
+	t.Run("WebSocket_Foreign_Origin", func(t *testing.T) {
+		_, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/stream/ws", "", "https://evil.example")
+		assert.Error(t, err)
+
+		allowed := NewStreamHandler(stream, time.Minute, nil, []string{"https://app.example"})
+		e := echo.New()
+		e.GET("/stream/ws", allowed.WebSocket, withUser)
+		other := httptest.NewServer(e)
+		defer other.Close()
+		ws, err := websocket.Dial("ws"+strings.TrimPrefix(other.URL, "http")+"/stream/ws", "", "https://app.example")
+		require.NoError(t, err)
+		ws.Close()
+	})
+}
+
+type flagRevocations struct{ revoked atomic.Bool }
+
+func (f *flagRevocations) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	return f.revoked.Load(), nil
+}
+
+func TestStreamHandler_SessionEnds(t *testing.T) {
+	uID := uuid.New()
+	revocations := &flagRevocations{}
+	h := NewStreamHandler(event.NewMemoryStream(10), 20*time.Millisecond, revocations, nil)
+
+	e := echo.New()
+	var expires time.Time
+	withSession := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user_id", uID.String())
+			c.Set("session_id", "s1")
+			if !expires.IsZero() {
+				c.Set("token_expires_at", expires)
+			}
+			return next(c)
+		}
+	}
+	e.GET("/stream", h.Events, withSession)
+	e.GET("/stream/ws", h.WebSocket, withSession)
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	// Поток закрывается после отзыва сессии
+	t.Run("SSE_Revoked", func(t *testing.T) {
+		revocations.revoked.Store(false)
+		expires = time.Time{}
+		resp, err := http.Get(srv.URL + "/stream")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		revocations.revoked.Store(true)
+		done := make(chan struct{})
+		go func() {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("stream stayed open after the session was revoked")
+		}
+	})
+
+	// WebSocket получает ошибку, когда истекает токен
+	t.Run("WebSocket_Expired", func(t *testing.T) {
+		revocations.revoked.Store(false)
+		expires = time.Now().Add(100 * time.Millisecond)
+		ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/stream/ws", "", srv.URL)
+		require.NoError(t, err)
+		defer ws.Close()
+		require.NoError(t, ws.SetDeadline(time.Now().Add(2*time.Second)))
+
+		var frame dto.StreamMessageDTO
+		for frame.Type != "error" {
+			require.NoError(t, websocket.JSON.Receive(ws, &frame))
+		}
+		assert.Equal(t, "session ended", frame.Error)
+	})
+}
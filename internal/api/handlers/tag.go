@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"todo-list/internal/api/dto"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/domain/service"
+)
+
+type TagHandler interface {
+	List(c echo.Context) error
+	Create(c echo.Context) error
+	Update(c echo.Context) error
+	Merge(c echo.Context) error
+	Delete(c echo.Context) error
+}
+
+type tagHandlerImpl struct {
+	service service.TagService
+}
+
+func NewTagHandler(s service.TagService) TagHandler {
+	return &tagHandlerImpl{service: s}
+}
+
+func (h *tagHandlerImpl) getUserID(c echo.Context) string {
+	return c.Get("user_id").(string)
+}
+
+func (h *tagHandlerImpl) getTagID(c echo.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, repository.ErrTagNotFound
+	}
+	return uint(id), nil
+}
+
+func (h *tagHandlerImpl) List(c echo.Context) error {
+	tags, err := h.service.ListTags(c.Request().Context(), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	out := make([]dto.TagResponseDTO, 0, len(tags))
+	for _, t := range tags {
+		out = append(out, dto.ToTagUsageResponseDTO(t))
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+func (h *tagHandlerImpl) Create(c echo.Context) error {
+	var req dto.TagRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	var name, color string
+	if req.Name != nil {
+		name = *req.Name
+	}
+	if req.Color != nil {
+		color = *req.Color
+	}
+	tag, err := h.service.CreateTag(c.Request().Context(), h.getUserID(c), name, color)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusCreated, dto.ToTagResponseDTO(tag))
+}
+
+func (h *tagHandlerImpl) Update(c echo.Context) error {
+	id, err := h.getTagID(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	var req dto.TagRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	tag, err := h.service.UpdateTag(c.Request().Context(), id, h.getUserID(c), req.Name, req.Color)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToTagResponseDTO(tag))
+}
+
+func (h *tagHandlerImpl) Merge(c echo.Context) error {
+	id, err := h.getTagID(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	var req dto.MergeTagRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	tag, err := h.service.MergeTags(c.Request().Context(), id, req.IntoID, h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToTagResponseDTO(tag))
+}
+
+func (h *tagHandlerImpl) Delete(c echo.Context) error {
+	id, err := h.getTagID(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	if err := h.service.DeleteTag(c.Request().Context(), id, h.getUserID(c)); err != nil {
+		return respondError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
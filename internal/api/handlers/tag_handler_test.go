@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/domain/service"
+	"todo-list/internal/testutils"
+)
+
+func TestTagHandler(t *testing.T) {
+	e := echo.New()
+	mockSvc := new(testutils.TagMocks)
+	h := NewTagHandler(mockSvc)
+	uID := "test-user"
+
+	newContext := func(method, target, body string, id string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		if id != "" {
+			c.SetParamNames("id")
+			c.SetParamValues(id)
+		}
+		c.Set("user_id", uID)
+		return c, rec
+	}
+
+	t.Run("List_WithCounts", func(t *testing.T) {
+		c, rec := newContext(http.MethodGet, "/api/v1/tags", "", "")
+		mockSvc.On("ListTags", mock.Anything, uID).
+			Return([]repository.TagUsage{{Tag: model.Tag{ID: 1, Name: "work"}, TaskCount: 3}}, nil).Once()
+
+		if assert.NoError(t, h.List(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"task_count":3`)
+		}
+	})
+
+	t.Run("Create_Conflict", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, "/api/v1/tags", `{"name":"work"}`, "")
+		mockSvc.On("CreateTag", mock.Anything, uID, "work", "").Return(model.Tag{}, repository.ErrTagExists).Once()
+
+		assert.NoError(t, h.Create(c))
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("Update_InvalidColor", func(t *testing.T) {
+		c, rec := newContext(http.MethodPatch, "/api/v1/tags/1", `{"color":"red"}`, "1")
+		mockSvc.On("UpdateTag", mock.Anything, uint(1), uID, (*string)(nil), mock.Anything).
+			Return(model.Tag{}, service.ErrInvalidTagColor).Once()
+
+		assert.NoError(t, h.Update(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Merge_Success", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, "/api/v1/tags/1/merge", `{"into_id":2}`, "1")
+		mockSvc.On("MergeTags", mock.Anything, uint(1), uint(2), uID).Return(model.Tag{ID: 2, Name: "home"}, nil).Once()
+
+		if assert.NoError(t, h.Merge(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), "home")
+		}
+	})
+
+	t.Run("Delete_NotFound", func(t *testing.T) {
+		c, rec := newContext(http.MethodDelete, "/api/v1/tags/abc", "", "abc")
+
+		assert.NoError(t, h.Delete(c))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	mockSvc.AssertExpectations(t)
+}
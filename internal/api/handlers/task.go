@@ -1,18 +1,30 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+	"io"
+	"mime"
 	"net/http"
 	"time"
 	"todo-list/internal/api/dto"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/repository"
 	"todo-list/internal/domain/service"
+	"todo-list/internal/domain/usertime"
 )
 
+// maxPatchSize bounds PATCH bodies; a task document is small.
+const maxPatchSize = 1 << 20
+
 type TaskHandler interface {
 	Create(c echo.Context) error
 	List(c echo.Context) error
 	Get(c echo.Context) error
 	Update(c echo.Context) error
+	Patch(c echo.Context) error
 	Delete(c echo.Context) error
 	ChangeStatus(c echo.Context) error
 	ListByStatus(c echo.Context) error
@@ -26,9 +38,29 @@ type TaskHandler interface {
 	AddTag(c echo.Context) error
 	RemoveTag(c echo.Context) error
 	ListByTag(c echo.Context) error
+	Assign(c echo.Context) error
+	Unassign(c echo.Context) error
+	Watch(c echo.Context) error
+	Unwatch(c echo.Context) error
+	ListAssigned(c echo.Context) error
+	ListWatching(c echo.Context) error
 	BulkDelete(c echo.Context) error
 	BulkUpdateStatus(c echo.Context) error
 	Stats(c echo.Context) error
+	CreateSubtask(c echo.Context) error
+	Move(c echo.Context) error
+	Tree(c echo.Context) error
+	AddDependency(c echo.Context) error
+	RemoveDependency(c echo.Context) error
+	Dependencies(c echo.Context) error
+	SetRecurrence(c echo.Context) error
+	Occurrences(c echo.Context) error
+	History(c echo.Context) error
+	Revert(c echo.Context) error
+	Trash(c echo.Context) error
+	Restore(c echo.Context) error
+	BulkRestore(c echo.Context) error
+	Purge(c echo.Context) error
 }
 
 type taskHandlerImpl struct {
@@ -43,220 +75,335 @@ func (h *taskHandlerImpl) getUserID(c echo.Context) string {
 	return c.Get("user_id").(string)
 }
 
+func getPageRequest(c echo.Context) (repository.PageRequest, error) {
+	var q dto.PageQueryDTO
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &q); err != nil {
+		return repository.PageRequest{}, errInvalidQuery
+	}
+	if err := q.Validate(); err != nil {
+		return repository.PageRequest{}, err
+	}
+	return q.ToPageRequest(), nil
+}
+
+func respondTaskPage(c echo.Context, page repository.TaskPage, err error) error {
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToPagedTasksResponseDTO(page))
+}
+
 func (h *taskHandlerImpl) Create(c echo.Context) error {
 	var req dto.TaskRequestDTO
-	c.Bind(&req)
-	var due *time.Time
-	if req.DueDate != "" {
-		if t, err := time.Parse(time.RFC3339, req.DueDate); err == nil {
-			due = &t
-		}
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
 	}
-	task, err := h.service.CreateTask(c.Request().Context(), h.getUserID(c), req.Title, req.Content, req.Status, req.Priority, due)
+	task, err := h.service.CreateTask(c.Request().Context(), h.getUserID(c), req.Title, req.Content, req.Status, req.Priority, req.Due(usertime.Location(c.Request().Context())), req.ProjectID, req.WorkspaceID)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return respondError(c, err)
 	}
-	return c.JSON(http.StatusCreated, dto.ToTaskResponseDTO(task))
+	return respondTask(c, http.StatusCreated, task)
 }
 
 func (h *taskHandlerImpl) List(c echo.Context) error {
-	tasks, err := h.service.GetAllTasks(c.Request().Context(), h.getUserID(c))
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
-	}
-	out := make([]dto.TaskResponseDTO, 0, len(tasks))
-	for _, t := range tasks {
-		out = append(out, dto.ToTaskResponseDTO(t))
-	}
-	return c.JSON(http.StatusOK, out)
+	return h.listFiltered(c, h.service.ListTasks)
 }
 
 func (h *taskHandlerImpl) Get(c echo.Context) error {
 	task, err := h.service.GetTaskByID(c.Request().Context(), c.Param("id"), h.getUserID(c))
 	if err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		return respondError(c, err)
+	}
+	if notModified(c, task) {
+		c.Response().Header().Set("ETag", taskETag(task))
+		return c.NoContent(http.StatusNotModified)
 	}
-	return c.JSON(http.StatusOK, dto.ToTaskResponseDTO(task))
+	return respondTask(c, http.StatusOK, task)
 }
 
 func (h *taskHandlerImpl) Update(c echo.Context) error {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return respondError(c, err)
+	}
 	var req dto.TaskRequestDTO
-	c.Bind(&req)
-	var due *time.Time
-	if req.DueDate != "" {
-		if t, err := time.Parse(time.RFC3339, req.DueDate); err == nil {
-			due = &t
-		}
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
 	}
-	task, err := h.service.UpdateTask(c.Request().Context(), c.Param("id"), h.getUserID(c), req.Title, req.Content, req.Status, req.Priority, due)
+	task, err := h.service.UpdateTask(c.Request().Context(), c.Param("id"), h.getUserID(c), req.Title, req.Content, req.Status, req.Priority, req.Due(usertime.Location(c.Request().Context())), version)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return respondError(c, err)
 	}
-	return c.JSON(http.StatusOK, dto.ToTaskResponseDTO(task))
+	return respondTask(c, http.StatusOK, task)
+}
+
+// Patch accepts application/merge-patch+json, plain application/json read as
+// a merge patch, and application/json-patch+json.
+func (h *taskHandlerImpl) Patch(c echo.Context) error {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	format := service.MergePatch
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	switch mediaType {
+	case service.MergePatch, echo.MIMEApplicationJSON:
+	case service.JSONPatch:
+		format = service.JSONPatch
+	default:
+		c.Response().Header().Set("Accept-Patch", service.MergePatch+", "+service.JSONPatch)
+		return respondError(c, service.ErrPatchFormat)
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxPatchSize))
+	if err != nil {
+		return respondError(c, errMalformedBody)
+	}
+
+	task, err := h.service.PatchTask(c.Request().Context(), c.Param("id"), h.getUserID(c), format, body, version)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return respondTask(c, http.StatusOK, task)
 }
 
 func (h *taskHandlerImpl) Delete(c echo.Context) error {
-	err := h.service.DeleteTask(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	err = h.service.DeleteTask(c.Request().Context(), c.Param("id"), h.getUserID(c), version)
 	if err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		return respondError(c, err)
 	}
 	return c.NoContent(http.StatusNoContent)
 }
 
 func (h *taskHandlerImpl) ChangeStatus(c echo.Context) error {
-	var body struct {
-		Status string `json:"status"`
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	var req dto.StatusRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
 	}
-	c.Bind(&body)
-	task, err := h.service.ChangeStatus(c.Request().Context(), c.Param("id"), h.getUserID(c), body.Status)
+	task, err := h.service.ChangeStatus(c.Request().Context(), c.Param("id"), h.getUserID(c), req.Status, version)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return respondError(c, err)
 	}
-	return c.JSON(http.StatusOK, dto.ToTaskResponseDTO(task))
+	return respondTask(c, http.StatusOK, task)
 }
 
 func (h *taskHandlerImpl) ListByStatus(c echo.Context) error {
-	tasks, err := h.service.GetTasksByStatus(c.Request().Context(), c.Param("status"), h.getUserID(c))
+	page, err := getPageRequest(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return respondError(c, err)
 	}
-	out := make([]dto.TaskResponseDTO, 0, len(tasks))
-	for _, t := range tasks {
-		out = append(out, dto.ToTaskResponseDTO(t))
-	}
-	return c.JSON(http.StatusOK, out)
+	res, err := h.service.GetTasksByStatus(c.Request().Context(), c.Param("status"), h.getUserID(c), page)
+	return respondTaskPage(c, res, err)
 }
 
 func (h *taskHandlerImpl) Search(c echo.Context) error {
-	tasks, err := h.service.SearchTasks(c.Request().Context(), c.QueryParam("q"), h.getUserID(c))
+	page, err := getPageRequest(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
-	}
-	out := make([]dto.TaskResponseDTO, 0, len(tasks))
-	for _, t := range tasks {
-		out = append(out, dto.ToTaskResponseDTO(t))
+		return respondError(c, err)
 	}
-	return c.JSON(http.StatusOK, out)
+	res, err := h.service.SearchTasks(c.Request().Context(), c.QueryParam("q"), h.getUserID(c), page)
+	return respondTaskPage(c, res, err)
 }
 
 func (h *taskHandlerImpl) GetToday(c echo.Context) error {
-	tasks, err := h.service.GetTodayTasks(c.Request().Context(), h.getUserID(c))
+	page, err := getPageRequest(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
-	}
-	out := make([]dto.TaskResponseDTO, 0, len(tasks))
-	for _, t := range tasks {
-		out = append(out, dto.ToTaskResponseDTO(t))
+		return respondError(c, err)
 	}
-	return c.JSON(http.StatusOK, out)
+	res, err := h.service.GetTodayTasks(c.Request().Context(), h.getUserID(c), page)
+	return respondTaskPage(c, res, err)
 }
 
 func (h *taskHandlerImpl) GetOverdue(c echo.Context) error {
-	tasks, err := h.service.GetOverdueTasks(c.Request().Context(), h.getUserID(c))
+	page, err := getPageRequest(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
-	}
-	out := make([]dto.TaskResponseDTO, 0, len(tasks))
-	for _, t := range tasks {
-		out = append(out, dto.ToTaskResponseDTO(t))
+		return respondError(c, err)
 	}
-	return c.JSON(http.StatusOK, out)
+	res, err := h.service.GetOverdueTasks(c.Request().Context(), h.getUserID(c), page)
+	return respondTaskPage(c, res, err)
 }
 
 func (h *taskHandlerImpl) Archive(c echo.Context) error {
-	task, err := h.service.ArchiveTask(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	task, err := h.service.ArchiveTask(c.Request().Context(), c.Param("id"), h.getUserID(c), version)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return respondError(c, err)
 	}
-	return c.JSON(http.StatusOK, dto.ToTaskResponseDTO(task))
+	return respondTask(c, http.StatusOK, task)
 }
 
 func (h *taskHandlerImpl) Unarchive(c echo.Context) error {
-	task, err := h.service.UnarchiveTask(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	version, err := ifMatchVersion(c)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return respondError(c, err)
 	}
-	return c.JSON(http.StatusOK, dto.ToTaskResponseDTO(task))
+	task, err := h.service.UnarchiveTask(c.Request().Context(), c.Param("id"), h.getUserID(c), version)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return respondTask(c, http.StatusOK, task)
 }
 
 func (h *taskHandlerImpl) ChangePriority(c echo.Context) error {
-	var body struct {
-		Priority string `json:"priority"`
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return respondError(c, err)
 	}
-	c.Bind(&body)
-	task, err := h.service.ChangePriority(c.Request().Context(), c.Param("id"), h.getUserID(c), body.Priority)
+	var req dto.PriorityRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	task, err := h.service.ChangePriority(c.Request().Context(), c.Param("id"), h.getUserID(c), req.Priority, version)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return respondError(c, err)
 	}
-	return c.JSON(http.StatusOK, dto.ToTaskResponseDTO(task))
+	return respondTask(c, http.StatusOK, task)
 }
 
 func (h *taskHandlerImpl) ListByPriority(c echo.Context) error {
-	tasks, err := h.service.GetTasksByPriority(c.Request().Context(), c.Param("priority"), h.getUserID(c))
+	page, err := getPageRequest(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
-	}
-	out := make([]dto.TaskResponseDTO, 0, len(tasks))
-	for _, t := range tasks {
-		out = append(out, dto.ToTaskResponseDTO(t))
+		return respondError(c, err)
 	}
-	return c.JSON(http.StatusOK, out)
+	res, err := h.service.GetTasksByPriority(c.Request().Context(), c.Param("priority"), h.getUserID(c), page)
+	return respondTaskPage(c, res, err)
 }
 
 func (h *taskHandlerImpl) AddTag(c echo.Context) error {
-	var body struct {
-		Tag string `json:"tag"`
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	var req dto.TagNameRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
 	}
-	c.Bind(&body)
-	task, err := h.service.AddTag(c.Request().Context(), c.Param("id"), h.getUserID(c), body.Tag)
+	task, err := h.service.AddTag(c.Request().Context(), c.Param("id"), h.getUserID(c), req.Tag, version)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return respondError(c, err)
 	}
-	return c.JSON(http.StatusOK, dto.ToTaskResponseDTO(task))
+	return respondTask(c, http.StatusOK, task)
 }
 
 func (h *taskHandlerImpl) RemoveTag(c echo.Context) error {
-	task, err := h.service.RemoveTag(c.Request().Context(), c.Param("id"), h.getUserID(c), c.Param("tag"))
+	version, err := ifMatchVersion(c)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return respondError(c, err)
 	}
-	return c.JSON(http.StatusOK, dto.ToTaskResponseDTO(task))
+	task, err := h.service.RemoveTag(c.Request().Context(), c.Param("id"), h.getUserID(c), c.Param("tag"), version)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return respondTask(c, http.StatusOK, task)
 }
 
 func (h *taskHandlerImpl) ListByTag(c echo.Context) error {
-	tasks, err := h.service.GetTasksByTag(c.Request().Context(), c.Param("tag"), h.getUserID(c))
+	page, err := getPageRequest(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return respondError(c, err)
 	}
-	out := make([]dto.TaskResponseDTO, 0, len(tasks))
-	for _, t := range tasks {
-		out = append(out, dto.ToTaskResponseDTO(t))
+	res, err := h.service.GetTasksByTag(c.Request().Context(), c.Param("tag"), h.getUserID(c), page)
+	return respondTaskPage(c, res, err)
+}
+
+func (h *taskHandlerImpl) Assign(c echo.Context) error {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return respondError(c, err)
 	}
-	return c.JSON(http.StatusOK, out)
+	var req dto.AssigneeRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	task, err := h.service.AssignTask(c.Request().Context(), c.Param("id"), h.getUserID(c), req.UserID, version)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return respondTask(c, http.StatusOK, task)
 }
 
-func (h *taskHandlerImpl) BulkDelete(c echo.Context) error {
-	var body struct {
-		IDs []string `json:"ids"`
+func (h *taskHandlerImpl) Unassign(c echo.Context) error {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return respondError(c, err)
 	}
-	c.Bind(&body)
-	err := h.service.BulkDelete(c.Request().Context(), body.IDs, h.getUserID(c))
+	task, err := h.service.UnassignTask(c.Request().Context(), c.Param("id"), h.getUserID(c), c.Param("userId"), version)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return respondError(c, err)
 	}
-	return c.NoContent(http.StatusNoContent)
+	return respondTask(c, http.StatusOK, task)
 }
 
-func (h *taskHandlerImpl) BulkUpdateStatus(c echo.Context) error {
-	var body struct {
-		IDs    []string `json:"ids"`
-		Status string   `json:"status"`
+func (h *taskHandlerImpl) Watch(c echo.Context) error {
+	task, err := h.service.WatchTask(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
 	}
+	return respondTask(c, http.StatusOK, task)
+}
 
-	c.Bind(&body)
-	err := h.service.BulkUpdateStatus(c.Request().Context(), body.IDs, body.Status, h.getUserID(c))
+func (h *taskHandlerImpl) Unwatch(c echo.Context) error {
+	task, err := h.service.UnwatchTask(c.Request().Context(), c.Param("id"), h.getUserID(c))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return respondError(c, err)
+	}
+	return respondTask(c, http.StatusOK, task)
+}
+
+func (h *taskHandlerImpl) ListAssigned(c echo.Context) error {
+	return h.listFiltered(c, h.service.ListAssignedTasks)
+}
+
+func (h *taskHandlerImpl) ListWatching(c echo.Context) error {
+	return h.listFiltered(c, h.service.ListWatchedTasks)
+}
+
+// listFiltered serves list views that accept the same query filters as List.
+func (h *taskHandlerImpl) listFiltered(c echo.Context, list func(ctx context.Context, userID, filter string, page repository.PageRequest) (repository.TaskPage, error)) error {
+	page, err := getPageRequest(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	var q dto.TaskFilterQueryDTO
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &q); err != nil {
+		return respondError(c, errInvalidQuery)
+	}
+	expr, err := q.Expression()
+	if err != nil {
+		return respondError(c, err)
+	}
+	res, err := list(c.Request().Context(), h.getUserID(c), expr, page)
+	return respondTaskPage(c, res, err)
+}
+
+func (h *taskHandlerImpl) BulkDelete(c echo.Context) error {
+	var req dto.BulkRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	if err := h.service.BulkDelete(c.Request().Context(), req.IDs, h.getUserID(c)); err != nil {
+		return respondError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *taskHandlerImpl) BulkUpdateStatus(c echo.Context) error {
+	var req dto.BulkStatusRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	if err := h.service.BulkUpdateStatus(c.Request().Context(), req.IDs, req.Status, h.getUserID(c)); err != nil {
+		return respondError(c, err)
 	}
 	return c.NoContent(http.StatusNoContent)
 }
@@ -264,7 +411,199 @@ func (h *taskHandlerImpl) BulkUpdateStatus(c echo.Context) error {
 func (h *taskHandlerImpl) Stats(c echo.Context) error {
 	s, err := h.service.Stats(c.Request().Context(), h.getUserID(c))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return respondError(c, err)
 	}
 	return c.JSON(http.StatusOK, s)
 }
+
+func (h *taskHandlerImpl) CreateSubtask(c echo.Context) error {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	var req dto.TaskRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	task, err := h.service.CreateSubtask(c.Request().Context(), c.Param("id"), h.getUserID(c), req.Title, req.Content, req.Status, req.Priority, req.Due(usertime.Location(c.Request().Context())), version)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return respondTask(c, http.StatusCreated, task)
+}
+
+func (h *taskHandlerImpl) Move(c echo.Context) error {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	var req dto.MoveTaskRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	task, err := h.service.MoveTask(c.Request().Context(), c.Param("id"), h.getUserID(c), req.ParentID, version)
+	// The parent comes from the body, so a missing one is a bad request
+	// rather than a missing resource.
+	if errors.Is(err, repository.ErrParentNotFound) {
+		return respondError(c, apperr.Field("parent_id", err.Error()))
+	}
+	if err != nil {
+		return respondError(c, err)
+	}
+	return respondTask(c, http.StatusOK, task)
+}
+
+func (h *taskHandlerImpl) Tree(c echo.Context) error {
+	tree, err := h.service.GetTaskTree(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	if tree == nil {
+		return respondError(c, gorm.ErrRecordNotFound)
+	}
+	return c.JSON(http.StatusOK, dto.ToTaskTreeResponseDTO(tree))
+}
+
+func (h *taskHandlerImpl) AddDependency(c echo.Context) error {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	var req dto.DependencyRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	task, err := h.service.AddTaskDependency(c.Request().Context(), c.Param("id"), h.getUserID(c), req.BlockedBy, version)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return respondTask(c, http.StatusOK, task)
+}
+
+func (h *taskHandlerImpl) RemoveDependency(c echo.Context) error {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	task, err := h.service.RemoveTaskDependency(c.Request().Context(), c.Param("id"), h.getUserID(c), c.Param("blocker"), version)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return respondTask(c, http.StatusOK, task)
+}
+
+func (h *taskHandlerImpl) Dependencies(c echo.Context) error {
+	graph, err := h.service.GetTaskDependencies(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToDependencyGraphResponseDTO(graph))
+}
+
+func (h *taskHandlerImpl) SetRecurrence(c echo.Context) error {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	var req dto.RecurrenceRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	task, err := h.service.SetRecurrence(c.Request().Context(), c.Param("id"), h.getUserID(c), req.RRule, version)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return respondTask(c, http.StatusOK, task)
+}
+
+func (h *taskHandlerImpl) Occurrences(c echo.Context) error {
+	var q dto.OccurrencesQueryDTO
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &q); err != nil {
+		return respondError(c, errInvalidQuery)
+	}
+	from, to, limit, err := q.Window(usertime.Now(c.Request().Context()))
+	if err != nil {
+		return respondError(c, err)
+	}
+	id := c.Param("id")
+	occurrences, err := h.service.GetOccurrences(c.Request().Context(), id, h.getUserID(c), from, to, limit)
+	if err != nil {
+		return respondError(c, err)
+	}
+	if occurrences == nil {
+		occurrences = []time.Time{}
+	}
+	return c.JSON(http.StatusOK, dto.OccurrencesResponseDTO{TaskID: id, Occurrences: occurrences})
+}
+
+func (h *taskHandlerImpl) History(c echo.Context) error {
+	revisions, err := h.service.GetTaskHistory(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	out := make([]dto.TaskRevisionResponseDTO, 0, len(revisions))
+	for _, r := range revisions {
+		out = append(out, dto.ToTaskRevisionResponseDTO(r))
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+func (h *taskHandlerImpl) Revert(c echo.Context) error {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	var req dto.RevertRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	task, err := h.service.RevertTask(c.Request().Context(), c.Param("id"), h.getUserID(c), *req.Revision, version)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return respondTask(c, http.StatusOK, task)
+}
+
+func (h *taskHandlerImpl) Trash(c echo.Context) error {
+	page, err := getPageRequest(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	res, err := h.service.ListTrash(c.Request().Context(), h.getUserID(c), page)
+	return respondTaskPage(c, res, err)
+}
+
+func (h *taskHandlerImpl) Restore(c echo.Context) error {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	task, err := h.service.RestoreTask(c.Request().Context(), c.Param("id"), h.getUserID(c), version)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return respondTask(c, http.StatusOK, task)
+}
+
+func (h *taskHandlerImpl) BulkRestore(c echo.Context) error {
+	var req dto.BulkRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	if err := h.service.BulkRestore(c.Request().Context(), req.IDs, h.getUserID(c)); err != nil {
+		return respondError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *taskHandlerImpl) Purge(c echo.Context) error {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	err = h.service.PurgeTask(c.Request().Context(), c.Param("id"), h.getUserID(c), version)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
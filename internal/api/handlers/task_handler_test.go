@@ -1,21 +1,30 @@
 package handlers
 
 import (
-	"errors"
+	"fmt"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
+	"todo-list/internal/domain/jsonpatch"
 	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/recurrence"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/domain/service"
 	"todo-list/internal/testutils"
 )
 
 func TestHandler_Complete(t *testing.T) {
 	e := echo.New()
-	mockSvc := new(testutils.AllMocks)
+	mockSvc := new(testutils.TaskServiceMocks)
 	h := NewTaskHandler(mockSvc)
 	uID := "test-user"
 
@@ -27,7 +36,7 @@ func TestHandler_Complete(t *testing.T) {
 		c := e.NewContext(req, rec)
 		c.Set("user_id", uID)
 
-		mockSvc.On("CreateTask", mock.Anything, uID, "API", "Desc", "", "", mock.Anything).
+		mockSvc.On("CreateTask", mock.Anything, uID, "API", "Desc", "", "", mock.Anything, (*string)(nil), (*string)(nil)).
 			Return(model.Task{Title: "API"}, nil).Once()
 
 		if assert.NoError(t, h.Create(c)) {
@@ -35,13 +44,63 @@ func TestHandler_Complete(t *testing.T) {
 		}
 	})
 
+	t.Run("Create_Validation", func(t *testing.T) {
+		body := `{"title":"","priority":"asap","due_date":"tomorrow"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", uID)
+
+		// Сервис не вызывается, пока запрос не прошёл проверку
+		assert.NoError(t, h.Create(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "application/problem+json", rec.Header().Get(echo.HeaderContentType))
+		assert.JSONEq(t, `{
+			"type":"about:blank","title":"Bad Request","status":400,
+			"detail":"request has 3 invalid fields","instance":"/api/v1/tasks",
+			"errors":[
+				{"field":"title","reason":"is required"},
+				{"field":"priority","reason":"must be one of low, medium, high, urgent"},
+				{"field":"due_date","reason":"must be a date or RFC 3339 timestamp"}
+			]}`, rec.Body.String())
+	})
+
+	t.Run("Create_MalformedBody", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(`{"title":42}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", uID)
+
+		assert.NoError(t, h.Create(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Get_HidesInternalErrors", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/3", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("3")
+		c.Set("user_id", uID)
+
+		mockSvc.On("GetTaskByID", mock.Anything, "3", uID).
+			Return(model.Task{}, fmt.Errorf("ERROR: relation \"tasks\" does not exist (SQLSTATE 42P01)")).Once()
+
+		assert.NoError(t, h.Get(c))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"detail":"internal server error"`)
+		assert.NotContains(t, rec.Body.String(), "SQLSTATE")
+	})
+
 	t.Run("List_Tasks", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 		c.Set("user_id", uID)
 
-		mockSvc.On("GetAllTasks", mock.Anything, uID).Return([]model.Task{{Title: "T1"}}, nil).Once()
+		mockSvc.On("ListTasks", mock.Anything, uID, "", mock.Anything).Return(repository.TaskPage{Tasks: []model.Task{{Title: "T1"}}}, nil).Once()
 
 		if assert.NoError(t, h.List(c)) {
 			assert.Equal(t, http.StatusOK, rec.Code)
@@ -58,13 +117,57 @@ func TestHandler_Complete(t *testing.T) {
 		c.SetParamValues("999")
 		c.Set("user_id", uID)
 
-		mockSvc.On("DeleteTask", mock.Anything, "999", uID).Return(errors.New("not found")).Once()
+		mockSvc.On("DeleteTask", mock.Anything, "999", uID, int64(0)).Return(gorm.ErrRecordNotFound).Once()
 
 		err := h.Delete(c)
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusNotFound, rec.Code)
 	})
 
+	t.Run("Delete_Forbidden_For_Viewer", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/tasks/555", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("555")
+		c.Set("user_id", uID)
+
+		mockSvc.On("DeleteTask", mock.Anything, "555", uID, int64(0)).Return(service.ErrForbidden).Once()
+
+		assert.NoError(t, h.Delete(c))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Assign_Without_Access", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks/555/assignees", strings.NewReader(`{"user_id":"5d3c0a8e-1f2b-4c6d-8e9f-0a1b2c3d4e5f"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("555")
+		c.Set("user_id", uID)
+
+		mockSvc.On("AssignTask", mock.Anything, "555", uID, "5d3c0a8e-1f2b-4c6d-8e9f-0a1b2c3d4e5f", int64(0)).Return(model.Task{}, repository.ErrAssigneeAccess).Once()
+
+		assert.NoError(t, h.Assign(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("List_Assigned", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/assigned?status=todo", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", uID)
+
+		mockSvc.On("ListAssignedTasks", mock.Anything, uID, "status:todo", mock.Anything).
+			Return(repository.TaskPage{Tasks: []model.Task{{Title: "Mine"}}}, nil).Once()
+
+		if assert.NoError(t, h.ListAssigned(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), "Mine")
+		}
+	})
+
 	t.Run("Search_Tasks", func(t *testing.T) {
 		// Эмулируем запрос /api/v1/tasks/search?q=milk
 		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/search?q=milk", nil)
@@ -72,8 +175,7 @@ func TestHandler_Complete(t *testing.T) {
 		c := e.NewContext(req, rec)
 		c.Set("user_id", uID)
 
-		mockSvc.On("SearchTasks", mock.Anything, "milk", uID).
-			Return([]model.Task{{Title: "Buy milk"}}, nil).Once()
+		mockSvc.On("SearchTasks", mock.Anything, "milk", uID, mock.Anything).Return(repository.TaskPage{Tasks: []model.Task{{Title: "Buy milk"}}}, nil).Once()
 
 		if assert.NoError(t, h.Search(c)) {
 			assert.Equal(t, http.StatusOK, rec.Code)
@@ -107,7 +209,7 @@ func TestHandler_Complete(t *testing.T) {
 		c.SetParamValues("123")
 		c.Set("user_id", uID)
 
-		mockSvc.On("AddTag", mock.Anything, "123", uID, "urgent").
+		mockSvc.On("AddTag", mock.Anything, "123", uID, "urgent", int64(0)).
 			Return(model.Task{ID: [16]byte{1}}, nil).Once()
 
 		if assert.NoError(t, h.AddTag(c)) {
@@ -116,14 +218,14 @@ func TestHandler_Complete(t *testing.T) {
 	})
 
 	t.Run("BulkUpdateStatus_Success", func(t *testing.T) {
-		body := `{"ids":["1","2"],"status":"done"}`
+		body := `{"ids":["0b6f4c3e-7a41-4f0e-9d7a-2f1c9a1e5b11","5d3c0a8e-1f2b-4c6d-8e9f-0a1b2c3d4e5f"],"status":"done"}`
 		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 		c.Set("user_id", uID)
 
-		mockSvc.On("BulkUpdateStatus", mock.Anything, []string{"1", "2"}, "done", uID).
+		mockSvc.On("BulkUpdateStatus", mock.Anything, []string{"0b6f4c3e-7a41-4f0e-9d7a-2f1c9a1e5b11", "5d3c0a8e-1f2b-4c6d-8e9f-0a1b2c3d4e5f"}, "done", uID).
 			Return(nil).Once()
 
 		if assert.NoError(t, h.BulkUpdateStatus(c)) {
@@ -142,7 +244,7 @@ func TestHandler_Complete(t *testing.T) {
 		c.SetParamValues("123")
 		c.Set("user_id", uID)
 
-		mockSvc.On("ChangePriority", mock.Anything, "123", uID, "high").
+		mockSvc.On("ChangePriority", mock.Anything, "123", uID, "high", int64(0)).
 			Return(model.Task{Priority: "high"}, nil).Once()
 
 		if assert.NoError(t, h.ChangePriority(c)) {
@@ -159,7 +261,7 @@ func TestHandler_Complete(t *testing.T) {
 		c.SetParamValues("123")
 		c.Set("user_id", uID)
 
-		mockSvc.On("ArchiveTask", mock.Anything, "123", uID).
+		mockSvc.On("ArchiveTask", mock.Anything, "123", uID, int64(0)).
 			Return(model.Task{Status: "archived"}, nil).Once()
 
 		if assert.NoError(t, h.Archive(c)) {
@@ -177,7 +279,7 @@ func TestHandler_Complete(t *testing.T) {
 		cStatus.SetParamValues("todo")
 		cStatus.Set("user_id", uID)
 
-		mockSvc.On("GetTasksByStatus", mock.Anything, "todo", uID).Return([]model.Task{{Title: "S"}}, nil).Once()
+		mockSvc.On("GetTasksByStatus", mock.Anything, "todo", uID, mock.Anything).Return(repository.TaskPage{Tasks: []model.Task{{Title: "S"}}}, nil).Once()
 		assert.NoError(t, h.ListByStatus(cStatus))
 
 		// 2. ListByTag
@@ -189,7 +291,7 @@ func TestHandler_Complete(t *testing.T) {
 		cTag.SetParamValues("work")
 		cTag.Set("user_id", uID)
 
-		mockSvc.On("GetTasksByTag", mock.Anything, "work", uID).Return([]model.Task{{Title: "T"}}, nil).Once()
+		mockSvc.On("GetTasksByTag", mock.Anything, "work", uID, mock.Anything).Return(repository.TaskPage{Tasks: []model.Task{{Title: "T"}}}, nil).Once()
 		assert.NoError(t, h.ListByTag(cTag))
 	})
 
@@ -222,7 +324,7 @@ func TestHandler_Complete(t *testing.T) {
 		c.SetParamValues("123")
 		c.Set("user_id", uID)
 
-		mockSvc.On("UpdateTask", mock.Anything, "123", uID, "New Name", "", "", "high", mock.Anything).
+		mockSvc.On("UpdateTask", mock.Anything, "123", uID, "New Name", "", "", "high", mock.Anything, int64(0)).
 			Return(model.Task{Title: "New Name"}, nil).Once()
 
 		if assert.NoError(t, h.Update(c)) {
@@ -241,7 +343,7 @@ func TestHandler_Complete(t *testing.T) {
 		c.SetParamValues("123")
 		c.Set("user_id", uID)
 
-		mockSvc.On("ChangeStatus", mock.Anything, "123", uID, "in_progress").
+		mockSvc.On("ChangeStatus", mock.Anything, "123", uID, "in_progress", int64(0)).
 			Return(model.Task{Status: "in_progress"}, nil).Once()
 
 		if assert.NoError(t, h.ChangeStatus(c)) {
@@ -255,7 +357,7 @@ func TestHandler_Complete(t *testing.T) {
 		recT := httptest.NewRecorder()
 		cT := e.NewContext(reqT, recT)
 		cT.Set("user_id", uID)
-		mockSvc.On("GetTodayTasks", mock.Anything, uID).Return([]model.Task{{Title: "Today"}}, nil).Once()
+		mockSvc.On("GetTodayTasks", mock.Anything, uID, mock.Anything).Return(repository.TaskPage{Tasks: []model.Task{{Title: "Today"}}}, nil).Once()
 
 		assert.NoError(t, h.GetToday(cT))
 		assert.Equal(t, http.StatusOK, recT.Code)
@@ -265,7 +367,7 @@ func TestHandler_Complete(t *testing.T) {
 		recO := httptest.NewRecorder()
 		cO := e.NewContext(reqO, recO)
 		cO.Set("user_id", uID)
-		mockSvc.On("GetOverdueTasks", mock.Anything, uID).Return([]model.Task{{Title: "Late"}}, nil).Once()
+		mockSvc.On("GetOverdueTasks", mock.Anything, uID, mock.Anything).Return(repository.TaskPage{Tasks: []model.Task{{Title: "Late"}}}, nil).Once()
 
 		assert.NoError(t, h.GetOverdue(cO))
 		assert.Equal(t, http.StatusOK, recO.Code)
@@ -280,7 +382,7 @@ func TestHandler_Complete(t *testing.T) {
 		c.SetParamValues("123")
 		c.Set("user_id", uID)
 
-		mockSvc.On("UnarchiveTask", mock.Anything, "123", uID).
+		mockSvc.On("UnarchiveTask", mock.Anything, "123", uID, int64(0)).
 			Return(model.Task{Status: "todo"}, nil).Once()
 
 		if assert.NoError(t, h.Unarchive(c)) {
@@ -297,8 +399,7 @@ func TestHandler_Complete(t *testing.T) {
 		c.SetParamValues("high")
 		c.Set("user_id", uID)
 
-		mockSvc.On("GetTasksByPriority", mock.Anything, "high", uID).
-			Return([]model.Task{{Priority: "high"}}, nil).Once()
+		mockSvc.On("GetTasksByPriority", mock.Anything, "high", uID, mock.Anything).Return(repository.TaskPage{Tasks: []model.Task{{Priority: "high"}}}, nil).Once()
 
 		if assert.NoError(t, h.ListByPriority(c)) {
 			assert.Equal(t, http.StatusOK, rec.Code)
@@ -314,7 +415,7 @@ func TestHandler_Complete(t *testing.T) {
 		c.SetParamValues("123", "work")
 		c.Set("user_id", uID)
 
-		mockSvc.On("RemoveTag", mock.Anything, "123", uID, "work").
+		mockSvc.On("RemoveTag", mock.Anything, "123", uID, "work", int64(0)).
 			Return(model.Task{Title: "T"}, nil).Once()
 
 		if assert.NoError(t, h.RemoveTag(c)) {
@@ -323,17 +424,462 @@ func TestHandler_Complete(t *testing.T) {
 	})
 
 	t.Run("BulkDelete_Handler_Success", func(t *testing.T) {
-		body := `{"ids":["10","20"]}`
+		body := `{"ids":["0b6f4c3e-7a41-4f0e-9d7a-2f1c9a1e5b11","5d3c0a8e-1f2b-4c6d-8e9f-0a1b2c3d4e5f"]}`
 		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 		c.Set("user_id", uID)
 
-		mockSvc.On("BulkDelete", mock.Anything, []string{"10", "20"}, uID).Return(nil).Once()
+		mockSvc.On("BulkDelete", mock.Anything, []string{"0b6f4c3e-7a41-4f0e-9d7a-2f1c9a1e5b11", "5d3c0a8e-1f2b-4c6d-8e9f-0a1b2c3d4e5f"}, uID).Return(nil).Once()
 
 		if assert.NoError(t, h.BulkDelete(c)) {
 			assert.Equal(t, http.StatusNoContent, rec.Code)
 		}
 	})
+
+	t.Run("List_Paginated", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks?limit=1&sort=due_date&cursor=abc", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", uID)
+
+		expected := repository.PageRequest{Limit: 1, Cursor: "abc", Sort: "due_date", Desc: false}
+		mockSvc.On("ListTasks", mock.Anything, uID, "", expected).
+			Return(repository.TaskPage{Tasks: []model.Task{{Title: "P1"}}, NextCursor: "next"}, nil).Once()
+
+		if assert.NoError(t, h.List(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"next_cursor":"next"`)
+			assert.Contains(t, rec.Body.String(), `"has_more":true`)
+		}
+	})
+
+	t.Run("List_InvalidSort", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks?sort=color", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", uID)
+
+		assert.NoError(t, h.List(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("List_InvalidCursor", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks?cursor=broken", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", uID)
+
+		mockSvc.On("ListTasks", mock.Anything, uID, "", mock.Anything).
+			Return(repository.TaskPage{}, repository.ErrInvalidCursor).Once()
+
+		assert.NoError(t, h.List(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("List_Filtered", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks?filter=tag:work+-archived&priority=high&due_before=2026-11-01", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", uID)
+
+		mockSvc.On("ListTasks", mock.Anything, uID, "tag:work -archived priority:high due<2026-11-01", mock.Anything).
+			Return(repository.TaskPage{Tasks: []model.Task{{Title: "F"}}}, nil).Once()
+
+		if assert.NoError(t, h.List(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("List_Q_Is_Free_Text", func(t *testing.T) {
+		// Однословный q не разбирается как синтаксис фильтра
+		for _, q := range []string{"archived", "status:done", "10:30", "-foo"} {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks?q="+url.QueryEscape(q), nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user_id", uID)
+
+			expr := `"` + q + `"`
+			f, err := service.ParseTaskFilter(expr, time.Now())
+			require.NoError(t, err, q)
+			assert.Equal(t, []string{q}, f.Terms, q)
+			assert.Nil(t, f.Archived, q)
+			assert.Empty(t, f.Statuses, q)
+
+			mockSvc.On("ListTasks", mock.Anything, uID, expr, mock.Anything).
+				Return(repository.TaskPage{}, nil).Once()
+			if assert.NoError(t, h.List(c)) {
+				assert.Equal(t, http.StatusOK, rec.Code, q)
+			}
+		}
+	})
+
+	t.Run("List_InvalidFilter", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks?filter=color:red", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", uID)
+
+		mockSvc.On("ListTasks", mock.Anything, uID, "color:red", mock.Anything).
+			Return(repository.TaskPage{}, &service.FilterError{Token: "color:red", Reason: "unknown field"}).Once()
+
+		assert.NoError(t, h.List(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Tree_WithProgress", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/1/tree", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		c.Set("user_id", uID)
+
+		tree := &model.TaskNode{
+			Task:     model.Task{Title: "Parent"},
+			Children: []*model.TaskNode{{Task: model.Task{Title: "Child", Status: "done"}}},
+			Done:     1,
+			Total:    1,
+		}
+		mockSvc.On("GetTaskTree", mock.Anything, "1", uID).Return(tree, nil).Once()
+
+		if assert.NoError(t, h.Tree(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"percent":100`)
+			assert.Contains(t, rec.Body.String(), "Child")
+		}
+	})
+
+	t.Run("Move_Cycle", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/1/parent", strings.NewReader(`{"parent_id":"5d3c0a8e-1f2b-4c6d-8e9f-0a1b2c3d4e5f"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		c.Set("user_id", uID)
+
+		mockSvc.On("MoveTask", mock.Anything, "1", uID, mock.Anything, int64(0)).Return(model.Task{}, repository.ErrHierarchyCycle).Once()
+
+		assert.NoError(t, h.Move(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("AddDependency_Cycle", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks/1/dependencies", strings.NewReader(`{"blocked_by":"5d3c0a8e-1f2b-4c6d-8e9f-0a1b2c3d4e5f"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		c.Set("user_id", uID)
+
+		mockSvc.On("AddTaskDependency", mock.Anything, "1", uID, "5d3c0a8e-1f2b-4c6d-8e9f-0a1b2c3d4e5f", int64(0)).Return(model.Task{}, repository.ErrDependencyCycle).Once()
+
+		assert.NoError(t, h.AddDependency(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Dependencies_Graph", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/1/dependencies", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		c.Set("user_id", uID)
+
+		blocker, blocked := uuid.New(), uuid.New()
+		graph := model.DependencyGraph{
+			RootID: blocked,
+			Tasks:  []model.Task{{ID: blocker, Title: "Design"}, {ID: blocked, Title: "Build", Status: "blocked"}},
+			Edges:  []model.TaskDependency{{BlockerID: blocker, BlockedID: blocked}},
+		}
+		mockSvc.On("GetTaskDependencies", mock.Anything, "1", uID).Return(graph, nil).Once()
+
+		if assert.NoError(t, h.Dependencies(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"blocker_id":"`+blocker.String()+`"`)
+		}
+	})
+
+	t.Run("Occurrences_Range", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/1/occurrences?from=2026-11-01&to=2026-12-01&limit=2", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		c.Set("user_id", uID)
+
+		from := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+		mockSvc.On("GetOccurrences", mock.Anything, "1", uID, from, to, 2).
+			Return([]time.Time{from.AddDate(0, 0, 2), from.AddDate(0, 0, 9)}, nil).Once()
+
+		if assert.NoError(t, h.Occurrences(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), "2026-11-10T00:00:00Z")
+		}
+	})
+
+	t.Run("SetRecurrence_Invalid", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/tasks/1/recurrence", strings.NewReader(`{"rrule":"FREQ=SOMETIMES"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		c.Set("user_id", uID)
+
+		mockSvc.On("SetRecurrence", mock.Anything, "1", uID, "FREQ=SOMETIMES", int64(0)).Return(model.Task{}, recurrence.ErrInvalidRule).Once()
+
+		assert.NoError(t, h.SetRecurrence(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("History_Changes", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/1/history", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		c.Set("user_id", uID)
+
+		changes := model.TaskFields{Title: "Old"}.Diff(model.TaskFields{Title: "New"})
+		mockSvc.On("GetTaskHistory", mock.Anything, "1", uID).
+			Return([]model.TaskRevision{{Revision: 2, Changes: changes}}, nil).Once()
+
+		if assert.NoError(t, h.History(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `{"field":"title","old":"Old","new":"New"}`)
+		}
+	})
+
+	t.Run("Revert_Validation", func(t *testing.T) {
+		for body, code := range map[string]int{`{}`: http.StatusBadRequest, `{"revision":9}`: http.StatusBadRequest, `{"revision":1}`: http.StatusOK} {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks/1/revert", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("1")
+			c.Set("user_id", uID)
+
+			mockSvc.On("RevertTask", mock.Anything, "1", uID, 9, int64(0)).Return(model.Task{}, service.ErrInvalidRevision).Maybe()
+			mockSvc.On("RevertTask", mock.Anything, "1", uID, 1, int64(0)).Return(model.Task{Title: "Old"}, nil).Maybe()
+
+			assert.NoError(t, h.Revert(c))
+			assert.Equal(t, code, rec.Code, body)
+		}
+	})
+
+	t.Run("Get_NotModified", func(t *testing.T) {
+		mockSvc.On("GetTaskByID", mock.Anything, "7", uID).Return(model.Task{Title: "Cached", Version: 4}, nil).Times(3)
+		for header, code := range map[string]int{`"4"`: http.StatusNotModified, `W/"4", "5"`: http.StatusNotModified, `"3"`: http.StatusOK} {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/7", nil)
+			req.Header.Set("If-None-Match", header)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("7")
+			c.Set("user_id", uID)
+
+			assert.NoError(t, h.Get(c))
+			assert.Equal(t, code, rec.Code, header)
+			assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("ChangeStatus_IfMatch", func(t *testing.T) {
+		for header, code := range map[string]int{`"2"`: http.StatusPreconditionFailed, `W/"2"`: http.StatusPreconditionFailed, `"3"`: http.StatusOK} {
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/7/status", strings.NewReader(`{"status":"done"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set("If-Match", header)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("7")
+			c.Set("user_id", uID)
+
+			mockSvc.On("ChangeStatus", mock.Anything, "7", uID, "done", int64(2)).Return(model.Task{}, repository.ErrVersionConflict).Maybe()
+			mockSvc.On("ChangeStatus", mock.Anything, "7", uID, "done", int64(3)).Return(model.Task{Status: "done", Version: 4}, nil).Maybe()
+
+			assert.NoError(t, h.ChangeStatus(c))
+			assert.Equal(t, code, rec.Code, header)
+			if code == http.StatusOK {
+				assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+			}
+		}
+	})
+
+	// Каждый обработчик, меняющий задачу, передаёт версию из If-Match в сервис
+	t.Run("Mutations_IfMatch", func(t *testing.T) {
+		assignee := "5d3c0a8e-1f2b-4c6d-8e9f-0a1b2c3d4e5f"
+		cases := []struct {
+			name    string
+			body    string
+			handler echo.HandlerFunc
+			args    []interface{}
+		}{
+			{"DeleteTask", "", h.Delete, nil},
+			{"ArchiveTask", "", h.Archive, nil},
+			{"UnarchiveTask", "", h.Unarchive, nil},
+			{"AddTag", `{"tag":"urgent"}`, h.AddTag, []interface{}{"urgent"}},
+			{"RemoveTag", "", h.RemoveTag, []interface{}{"work"}},
+			{"AssignTask", `{"user_id":"` + assignee + `"}`, h.Assign, []interface{}{assignee}},
+			{"UnassignTask", "", h.Unassign, []interface{}{assignee}},
+			{"CreateSubtask", `{"title":"Step"}`, h.CreateSubtask, []interface{}{"Step", "", "", "", mock.Anything}},
+			{"MoveTask", `{"parent_id":null}`, h.Move, []interface{}{mock.Anything}},
+			{"AddTaskDependency", `{"blocked_by":"` + assignee + `"}`, h.AddDependency, []interface{}{assignee}},
+			{"RemoveTaskDependency", "", h.RemoveDependency, []interface{}{assignee}},
+			{"SetRecurrence", `{"rrule":"FREQ=DAILY"}`, h.SetRecurrence, []interface{}{"FREQ=DAILY"}},
+			{"RevertTask", `{"revision":1}`, h.Revert, []interface{}{1}},
+			{"RestoreTask", "", h.Restore, nil},
+			{"PurgeTask", "", h.Purge, nil},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				req.Header.Set("If-Match", `"2"`)
+				rec := httptest.NewRecorder()
+				c := e.NewContext(req, rec)
+				c.SetParamNames("id", "tag", "userId", "blocker")
+				c.SetParamValues("7", "work", assignee, assignee)
+				c.Set("user_id", uID)
+
+				args := append([]interface{}{mock.Anything, "7", uID}, tc.args...)
+				call := mockSvc.On(tc.name, append(args, int64(2))...)
+				if tc.name == "DeleteTask" || tc.name == "PurgeTask" {
+					call.Return(repository.ErrVersionConflict).Once()
+				} else {
+					call.Return(model.Task{}, repository.ErrVersionConflict).Once()
+				}
+
+				assert.NoError(t, tc.handler(c))
+				assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+			})
+		}
+	})
+
+	t.Run("ChangeStatus_TransitionNotAllowed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/8/status", strings.NewReader(`{"status":"done"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("8")
+		c.Set("user_id", uID)
+
+		mockSvc.On("ChangeStatus", mock.Anything, "8", uID, "done", int64(0)).
+			Return(model.Task{}, &service.TransitionError{From: "todo", To: "done", Allowed: []string{"in_progress"}}).Once()
+
+		if assert.NoError(t, h.ChangeStatus(c)) {
+			assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"allowed":["in_progress"]`)
+		}
+	})
+
+	t.Run("Patch_MediaTypes", func(t *testing.T) {
+		cases := []struct {
+			contentType, body string
+			code              int
+		}{
+			{"application/merge-patch+json", `{"due_date":null}`, http.StatusOK},
+			{echo.MIMEApplicationJSONCharsetUTF8, `{"due_date":null}`, http.StatusOK},
+			{"application/json-patch+json", `[{"op":"test","path":"/title","value":"x"}]`, http.StatusConflict},
+			{"application/merge-patch+json", `{"status":"nope"}`, http.StatusUnprocessableEntity},
+			{"application/json-patch+json", `[{"op":"move"}]`, http.StatusBadRequest},
+			{echo.MIMETextPlain, `due_date=`, http.StatusUnsupportedMediaType},
+		}
+		mockSvc.On("PatchTask", mock.Anything, "7", uID, service.MergePatch, []byte(`{"due_date":null}`), int64(0)).
+			Return(model.Task{Title: "Report", Version: 3}, nil).Twice()
+		mockSvc.On("PatchTask", mock.Anything, "7", uID, service.JSONPatch, []byte(`[{"op":"test","path":"/title","value":"x"}]`), int64(0)).
+			Return(model.Task{}, fmt.Errorf("operation 0 (test): %w", jsonpatch.ErrTestFailed)).Once()
+		mockSvc.On("PatchTask", mock.Anything, "7", uID, service.MergePatch, []byte(`{"status":"nope"}`), int64(0)).
+			Return(model.Task{}, &service.FieldError{Field: "status", Reason: "bad"}).Once()
+		mockSvc.On("PatchTask", mock.Anything, "7", uID, service.JSONPatch, []byte(`[{"op":"move"}]`), int64(0)).
+			Return(model.Task{}, jsonpatch.ErrInvalidPatch).Once()
+
+		for _, tc := range cases {
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/7", strings.NewReader(tc.body))
+			req.Header.Set(echo.HeaderContentType, tc.contentType)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("7")
+			c.Set("user_id", uID)
+
+			assert.NoError(t, h.Patch(c))
+			assert.Equal(t, tc.code, rec.Code, tc.contentType+" "+tc.body)
+			if tc.code == http.StatusOK {
+				assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+			}
+			if tc.code == http.StatusUnsupportedMediaType {
+				assert.Contains(t, rec.Header().Get("Accept-Patch"), service.MergePatch)
+			}
+		}
+	})
+
+	t.Run("Trash_ShowsDeletedAt", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/trash", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", uID)
+
+		deleted := model.Task{Title: "Old", DeletedAt: gorm.DeletedAt{Time: time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC), Valid: true}}
+		mockSvc.On("ListTrash", mock.Anything, uID, mock.AnythingOfType("repository.PageRequest")).
+			Return(repository.TaskPage{Tasks: []model.Task{deleted}}, nil).Once()
+
+		if assert.NoError(t, h.Trash(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"deleted_at":"2026-10-01T08:00:00Z"`)
+		}
+	})
+
+	t.Run("Restore_NotInTrash", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks/1/restore", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		c.Set("user_id", uID)
+
+		mockSvc.On("RestoreTask", mock.Anything, "1", uID, int64(0)).Return(model.Task{}, gorm.ErrRecordNotFound).Once()
+
+		assert.NoError(t, h.Restore(c))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Purge_Forbidden", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/tasks/trash/1", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		c.Set("user_id", uID)
+
+		mockSvc.On("PurgeTask", mock.Anything, "1", uID, int64(0)).Return(service.ErrForbidden).Once()
+
+		assert.NoError(t, h.Purge(c))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestHTTPErrorHandler(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = HTTPErrorHandler
+	e.GET("/api/v1/tasks/:id", func(c echo.Context) error {
+		return repository.ErrVersionConflict
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/tasks/1", nil))
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"detail":"task was changed by someone else"`)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/nothing", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get(echo.HeaderContentType))
 }
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"todo-list/internal/api/dto"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/service"
+)
+
+type WebhookHandler interface {
+	List(c echo.Context) error
+	Create(c echo.Context) error
+	Get(c echo.Context) error
+	Update(c echo.Context) error
+	Delete(c echo.Context) error
+	Deliveries(c echo.Context) error
+	Redeliver(c echo.Context) error
+}
+
+type webhookHandlerImpl struct {
+	service service.WebhookService
+}
+
+func NewWebhookHandler(s service.WebhookService) WebhookHandler {
+	return &webhookHandlerImpl{service: s}
+}
+
+func (h *webhookHandlerImpl) getUserID(c echo.Context) string {
+	return c.Get("user_id").(string)
+}
+
+func (h *webhookHandlerImpl) List(c echo.Context) error {
+	subs, err := h.service.ListWebhooks(c.Request().Context(), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	out := make([]dto.WebhookResponseDTO, 0, len(subs))
+	for _, s := range subs {
+		out = append(out, dto.ToWebhookResponseDTO(s))
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+func (h *webhookHandlerImpl) Create(c echo.Context) error {
+	var req dto.WebhookRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	if req.URL == nil {
+		return respondError(c, apperr.Field("url", "is required"))
+	}
+	var secret string
+	if req.Secret != nil {
+		secret = *req.Secret
+	}
+	sub, err := h.service.CreateWebhook(c.Request().Context(), h.getUserID(c), *req.URL, secret, req.Events)
+	if err != nil {
+		return respondError(c, err)
+	}
+	out := dto.ToWebhookResponseDTO(sub)
+	out.Secret = sub.Secret
+	return c.JSON(http.StatusCreated, out)
+}
+
+func (h *webhookHandlerImpl) Get(c echo.Context) error {
+	sub, err := h.service.GetWebhook(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToWebhookResponseDTO(sub))
+}
+
+func (h *webhookHandlerImpl) Update(c echo.Context) error {
+	var req dto.WebhookRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	sub, err := h.service.UpdateWebhook(c.Request().Context(), c.Param("id"), h.getUserID(c),
+		req.URL, req.Secret, req.Events, req.Active)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToWebhookResponseDTO(sub))
+}
+
+func (h *webhookHandlerImpl) Delete(c echo.Context) error {
+	if err := h.service.DeleteWebhook(c.Request().Context(), c.Param("id"), h.getUserID(c)); err != nil {
+		return respondError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *webhookHandlerImpl) Deliveries(c echo.Context) error {
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	deliveries, err := h.service.ListWebhookDeliveries(c.Request().Context(), c.Param("id"), h.getUserID(c), limit)
+	if err != nil {
+		return respondError(c, err)
+	}
+	out := make([]dto.WebhookDeliveryResponseDTO, 0, len(deliveries))
+	for _, d := range deliveries {
+		out = append(out, dto.ToWebhookDeliveryResponseDTO(d))
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+func (h *webhookHandlerImpl) Redeliver(c echo.Context) error {
+	d, err := h.service.Redeliver(c.Request().Context(), c.Param("id"), c.Param("deliveryId"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusAccepted, dto.ToWebhookDeliveryResponseDTO(d))
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/domain/service"
+	"todo-list/internal/testutils"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	e := echo.New()
+	mockSvc := new(testutils.WebhookMocks)
+	h := NewWebhookHandler(mockSvc)
+	uID := "test-user"
+
+	newContext := func(method, body string, params ...string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(method, "/api/v1/webhooks", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id", "deliveryId")
+		c.SetParamValues(params...)
+		c.Set("user_id", uID)
+		return c, rec
+	}
+
+	t.Run("Create_Returns_Secret_Once", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, `{"url":"https://hooks.example.com","events":["task.created"]}`, "", "")
+		sub := model.WebhookSubscription{ID: uuid.New(), URL: "https://hooks.example.com", Secret: "whsec_abc", Events: "task.created", Active: true}
+		mockSvc.On("CreateWebhook", mock.Anything, uID, "https://hooks.example.com", "", []string{"task.created"}).Return(sub, nil).Once()
+
+		if assert.NoError(t, h.Create(c)) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"secret":"whsec_abc"`)
+		}
+
+		c, rec = newContext(http.MethodGet, "", sub.ID.String(), "")
+		mockSvc.On("GetWebhook", mock.Anything, sub.ID.String(), uID).Return(sub, nil).Once()
+		if assert.NoError(t, h.Get(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.NotContains(t, rec.Body.String(), "secret")
+		}
+	})
+
+	t.Run("Create_Invalid", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, `{"url":"nope"}`, "", "")
+		mockSvc.On("CreateWebhook", mock.Anything, uID, "nope", "", []string(nil)).Return(model.WebhookSubscription{}, service.ErrWebhookURL).Once()
+
+		assert.NoError(t, h.Create(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Deliveries", func(t *testing.T) {
+		c, rec := newContext(http.MethodGet, "", "1", "")
+		d := model.WebhookDelivery{ID: uuid.New(), EventType: "task.deleted", Status: model.WebhookDeliveryFailed, Payload: `{"type":"task.deleted"}`}
+		mockSvc.On("ListWebhookDeliveries", mock.Anything, "1", uID, 0).Return([]model.WebhookDelivery{d}, nil).Once()
+
+		if assert.NoError(t, h.Deliveries(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"payload":{"type":"task.deleted"}`)
+		}
+	})
+
+	t.Run("Redeliver_NotFound", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, "", "1", "2")
+		mockSvc.On("Redeliver", mock.Anything, "1", "2", uID).Return(model.WebhookDelivery{}, repository.ErrDeliveryNotFound).Once()
+
+		assert.NoError(t, h.Redeliver(c))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
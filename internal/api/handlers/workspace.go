@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"todo-list/internal/api/dto"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/service"
+)
+
+type WorkspaceHandler interface {
+	List(c echo.Context) error
+	Create(c echo.Context) error
+	Get(c echo.Context) error
+	Rename(c echo.Context) error
+	Delete(c echo.Context) error
+	Workflow(c echo.Context) error
+	SetWorkflow(c echo.Context) error
+	ResetWorkflow(c echo.Context) error
+	Tasks(c echo.Context) error
+	Members(c echo.Context) error
+	AddMember(c echo.Context) error
+	ChangeRole(c echo.Context) error
+	RemoveMember(c echo.Context) error
+
+	Invite(c echo.Context) error
+	Invitations(c echo.Context) error
+	RevokeInvitation(c echo.Context) error
+	AcceptInvitation(c echo.Context) error
+	DeclineInvitation(c echo.Context) error
+}
+
+type workspaceHandlerImpl struct {
+	service service.WorkspaceService
+}
+
+func NewWorkspaceHandler(s service.WorkspaceService) WorkspaceHandler {
+	return &workspaceHandlerImpl{service: s}
+}
+
+func (h *workspaceHandlerImpl) getUserID(c echo.Context) string {
+	return c.Get("user_id").(string)
+}
+
+func (h *workspaceHandlerImpl) List(c echo.Context) error {
+	workspaces, err := h.service.ListWorkspaces(c.Request().Context(), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	out := make([]dto.WorkspaceResponseDTO, 0, len(workspaces))
+	for _, ws := range workspaces {
+		out = append(out, dto.ToWorkspaceResponseDTO(ws))
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+func (h *workspaceHandlerImpl) Create(c echo.Context) error {
+	var req dto.WorkspaceRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	ws, err := h.service.CreateWorkspace(c.Request().Context(), h.getUserID(c), req.Name)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusCreated, dto.ToWorkspaceResponseDTO(ws))
+}
+
+func (h *workspaceHandlerImpl) Get(c echo.Context) error {
+	ws, err := h.service.GetWorkspace(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToWorkspaceResponseDTO(ws))
+}
+
+func (h *workspaceHandlerImpl) Rename(c echo.Context) error {
+	var req dto.WorkspaceRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	ws, err := h.service.RenameWorkspace(c.Request().Context(), c.Param("id"), h.getUserID(c), req.Name)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToWorkspaceResponseDTO(ws))
+}
+
+func (h *workspaceHandlerImpl) Delete(c echo.Context) error {
+	if err := h.service.DeleteWorkspace(c.Request().Context(), c.Param("id"), h.getUserID(c)); err != nil {
+		return respondError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *workspaceHandlerImpl) Workflow(c echo.Context) error {
+	ws, err := h.service.GetWorkspace(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToWorkflowResponseDTO(ws.Workflow))
+}
+
+func (h *workspaceHandlerImpl) SetWorkflow(c echo.Context) error {
+	var req dto.WorkflowRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	wf := &model.Workflow{Statuses: req.Statuses, Transitions: req.Transitions}
+	ws, err := h.service.SetWorkflow(c.Request().Context(), c.Param("id"), h.getUserID(c), wf)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToWorkflowResponseDTO(ws.Workflow))
+}
+
+func (h *workspaceHandlerImpl) ResetWorkflow(c echo.Context) error {
+	ws, err := h.service.SetWorkflow(c.Request().Context(), c.Param("id"), h.getUserID(c), nil)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToWorkflowResponseDTO(ws.Workflow))
+}
+
+func (h *workspaceHandlerImpl) Tasks(c echo.Context) error {
+	page, err := getPageRequest(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	var q dto.TaskFilterQueryDTO
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &q); err != nil {
+		return respondError(c, errInvalidQuery)
+	}
+	expr, err := q.Expression()
+	if err != nil {
+		return respondError(c, err)
+	}
+	res, err := h.service.ListWorkspaceTasks(c.Request().Context(), c.Param("id"), h.getUserID(c), expr, page)
+	return respondTaskPage(c, res, err)
+}
+
+func (h *workspaceHandlerImpl) Members(c echo.Context) error {
+	members, err := h.service.ListWorkspaceMembers(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	out := make([]dto.WorkspaceMemberResponseDTO, 0, len(members))
+	for _, m := range members {
+		out = append(out, dto.ToWorkspaceMemberResponseDTO(m))
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+func (h *workspaceHandlerImpl) AddMember(c echo.Context) error {
+	var req dto.WorkspaceMemberRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	m, err := h.service.AddWorkspaceMember(c.Request().Context(), c.Param("id"), h.getUserID(c), req.Email, req.Role)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusCreated, dto.ToWorkspaceMemberResponseDTO(m))
+}
+
+func (h *workspaceHandlerImpl) ChangeRole(c echo.Context) error {
+	var req dto.RoleRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	m, err := h.service.ChangeMemberRole(c.Request().Context(), c.Param("id"), h.getUserID(c), c.Param("userId"), req.Role)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToWorkspaceMemberResponseDTO(m))
+}
+
+func (h *workspaceHandlerImpl) RemoveMember(c echo.Context) error {
+	err := h.service.RemoveWorkspaceMember(c.Request().Context(), c.Param("id"), h.getUserID(c), c.Param("userId"))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *workspaceHandlerImpl) Invite(c echo.Context) error {
+	var req dto.WorkspaceMemberRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	inv, token, err := h.service.InviteMember(c.Request().Context(), c.Param("id"), h.getUserID(c), req.Email, req.Role)
+	if err != nil {
+		return respondError(c, err)
+	}
+	out := dto.ToWorkspaceInvitationResponseDTO(inv)
+	out.Token = token
+	return c.JSON(http.StatusCreated, out)
+}
+
+func (h *workspaceHandlerImpl) Invitations(c echo.Context) error {
+	invitations, err := h.service.ListPendingInvitations(c.Request().Context(), c.Param("id"), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	out := make([]dto.WorkspaceInvitationResponseDTO, 0, len(invitations))
+	for _, inv := range invitations {
+		out = append(out, dto.ToWorkspaceInvitationResponseDTO(inv))
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+func (h *workspaceHandlerImpl) RevokeInvitation(c echo.Context) error {
+	err := h.service.RevokeWorkspaceInvitation(c.Request().Context(), c.Param("id"), h.getUserID(c), c.Param("invitationId"))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *workspaceHandlerImpl) AcceptInvitation(c echo.Context) error {
+	var req dto.InvitationTokenRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	m, err := h.service.AcceptInvitationToken(c.Request().Context(), req.Token, h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	ws, err := h.service.GetWorkspace(c.Request().Context(), m.WorkspaceID.String(), h.getUserID(c))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, dto.ToWorkspaceResponseDTO(ws))
+}
+
+// DeclineInvitation needs no session: holding the token is enough to turn it down.
+func (h *workspaceHandlerImpl) DeclineInvitation(c echo.Context) error {
+	var req dto.InvitationTokenRequestDTO
+	if err := bind(c, &req); err != nil {
+		return respondError(c, err)
+	}
+	if err := h.service.DeclineInvitationToken(c.Request().Context(), req.Token); err != nil {
+		return respondError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
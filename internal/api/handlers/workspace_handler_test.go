@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/domain/service"
+	"todo-list/internal/testutils"
+)
+
+func TestWorkspaceHandler(t *testing.T) {
+	e := echo.New()
+	mockSvc := new(testutils.WorkspaceMocks)
+	h := NewWorkspaceHandler(mockSvc)
+	uID := "test-user"
+	wsID := uuid.New()
+
+	newContext := func(method, target, body string, params ...string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		if len(params) > 0 {
+			names := []string{"id", "userId"}[:len(params)]
+			c.SetParamNames(names...)
+			c.SetParamValues(params...)
+		}
+		c.Set("user_id", uID)
+		return c, rec
+	}
+
+	t.Run("Create_Success", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, "/api/v1/workspaces", `{"name":"Team"}`)
+		mockSvc.On("CreateWorkspace", mock.Anything, uID, "Team").Return(repository.WorkspaceSummary{
+			Workspace: model.Workspace{ID: wsID, Name: "Team"}, Role: model.RoleOwner, MemberCount: 1,
+		}, nil).Once()
+
+		if assert.NoError(t, h.Create(c)) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"role":"owner"`)
+		}
+	})
+
+	t.Run("AddMember_Forbidden", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, "/", `{"email":"bob@example.com","role":"viewer"}`, wsID.String())
+		mockSvc.On("AddWorkspaceMember", mock.Anything, wsID.String(), uID, "bob@example.com", "viewer").
+			Return(repository.Member{}, service.ErrForbidden).Once()
+
+		assert.NoError(t, h.AddMember(c))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("AddMember_Requires_Email", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, "/", `{}`, wsID.String())
+
+		assert.NoError(t, h.AddMember(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("ChangeRole_LastOwner", func(t *testing.T) {
+		c, rec := newContext(http.MethodPatch, "/", `{"role":"editor"}`, wsID.String(), "u2")
+		mockSvc.On("ChangeMemberRole", mock.Anything, wsID.String(), uID, "u2", "editor").
+			Return(repository.Member{}, repository.ErrLastOwner).Once()
+
+		assert.NoError(t, h.ChangeRole(c))
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("Get_NotMember", func(t *testing.T) {
+		c, rec := newContext(http.MethodGet, "/", "", wsID.String())
+		mockSvc.On("GetWorkspace", mock.Anything, wsID.String(), uID).
+			Return(repository.WorkspaceSummary{}, repository.ErrWorkspaceNotFound).Once()
+
+		assert.NoError(t, h.Get(c))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Invite_Returns_Token", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, "/", `{"email":"bob@example.com"}`, wsID.String())
+		inv := model.WorkspaceInvitation{ID: uuid.New(), WorkspaceID: wsID, Email: "bob@example.com", Role: model.RoleEditor}
+		mockSvc.On("InviteMember", mock.Anything, wsID.String(), uID, "bob@example.com", "").
+			Return(inv, "signed.token", nil).Once()
+
+		if assert.NoError(t, h.Invite(c)) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"token":"signed.token"`)
+		}
+	})
+
+	t.Run("Accept_Closed_Invitation", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, "/api/v1/invitations/accept", `{"token":"t"}`)
+		mockSvc.On("AcceptInvitationToken", mock.Anything, "t", uID).
+			Return(repository.Member{}, repository.ErrInvitationClosed).Once()
+
+		assert.NoError(t, h.AcceptInvitation(c))
+		assert.Equal(t, http.StatusGone, rec.Code)
+	})
+
+	t.Run("Decline_Invalid_Token", func(t *testing.T) {
+		c, rec := newContext(http.MethodPost, "/api/v1/invitations/decline", `{"token":"t"}`)
+		mockSvc.On("DeclineInvitationToken", mock.Anything, "t").Return(service.ErrInvalidInvitation).Once()
+
+		assert.NoError(t, h.DeclineInvitation(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	mockSvc.AssertExpectations(t)
+}
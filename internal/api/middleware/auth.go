@@ -1,35 +1,108 @@
 package middleware
 
 import (
+	"context"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
+	"log"
 	"net/http"
 	"strings"
+	"todo-list/internal/api/problem"
 )
 
-func AuthMiddleware(secret string) echo.MiddlewareFunc {
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, sessionID string) (bool, error)
+}
+
+// FallbackRevocations answers from cache when it knows the session is
+// revoked and asks store otherwise. A cache that was flushed, failed over or
+// cannot be reached therefore never lets a revoked session back in.
+func FallbackRevocations(cache, store RevocationChecker) RevocationChecker {
+	return fallbackRevocations{cache: cache, store: store}
+}
+
+type fallbackRevocations struct {
+	cache, store RevocationChecker
+}
+
+func (f fallbackRevocations) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	revoked, err := f.cache.IsRevoked(ctx, sessionID)
+	if err != nil {
+		log.Printf("[ERROR] Auth: revocation cache unavailable, checking session %s in the store: %v", sessionID, err)
+	} else if revoked {
+		return true, nil
+	}
+	return f.store.IsRevoked(ctx, sessionID)
+}
+
+// AuthMiddleware validates the access token. When revocations is not nil the
+// token must belong to a session that has not been revoked.
+func AuthMiddleware(secret string, revocations RevocationChecker) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			authHeader := c.Request().Header.Get("Authorization")
 			if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing or invalid token"})
+				return problem.Respond(c, http.StatusUnauthorized, "missing or invalid token")
 			}
 
 			tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
 			token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
 				return []byte(secret), nil
-			})
+			}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 			if err != nil || !token.Valid {
-				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+				return problem.Respond(c, http.StatusUnauthorized, "unauthorized")
 			}
 
 			claims, ok := token.Claims.(jwt.MapClaims)
 			if !ok {
-				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token claims"})
+				return problem.Respond(c, http.StatusUnauthorized, "invalid token claims")
+			}
+
+			sessionID, _ := claims["sid"].(string)
+			if revocations != nil {
+				if sessionID == "" {
+					return problem.Respond(c, http.StatusUnauthorized, "unauthorized")
+				}
+				revoked, err := revocations.IsRevoked(c.Request().Context(), sessionID)
+				if err != nil {
+					log.Printf("[ERROR] Auth: could not check session %s: %v", sessionID, err)
+					return problem.Respond(c, http.StatusServiceUnavailable, "could not verify session")
+				}
+				if revoked {
+					return problem.Respond(c, http.StatusUnauthorized, "session revoked")
+				}
 			}
 
 			c.Set("user_id", claims["sub"])
+			c.Set("session_id", sessionID)
+			if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+				c.Set("token_expires_at", exp.Time)
+			}
+			return next(c)
+		}
+	}
+}
+
+// QueryTokenMiddleware lets clients that cannot set headers, such as browser
+// EventSource and WebSocket, pass the access token as a query parameter. It
+// must run before AuthMiddleware and is meant only for those endpoints. The
+// parameter is stripped from the request URL so the access log, which reads
+// the URI after the handler returns, never records the token.
+func QueryTokenMiddleware(param string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			query := req.URL.Query()
+			if !query.Has(param) {
+				return next(c)
+			}
+			if token := query.Get(param); token != "" && req.Header.Get("Authorization") == "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			query.Del(param)
+			req.URL.RawQuery = query.Encode()
+			req.RequestURI = req.URL.RequestURI()
 			return next(c)
 		}
 	}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -14,7 +16,7 @@ import (
 func TestAuthMiddleware(t *testing.T) {
 	e := echo.New()
 	secret := "test-secret"
-	mw := AuthMiddleware(secret)
+	mw := AuthMiddleware(secret, nil)
 
 	nextHandler := func(c echo.Context) error {
 		return c.String(http.StatusOK, "passed")
@@ -120,3 +122,118 @@ func TestAuthMiddleware(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, rec.Code)
 	})
 }
+
+type fakeRevocations struct {
+	revoked map[string]bool
+	err     error
+}
+
+func (f *fakeRevocations) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	return f.revoked[sessionID], f.err
+}
+
+func TestAuthMiddleware_Revocation(t *testing.T) {
+	e := echo.New()
+	secret := "test-secret"
+	nextHandler := func(c echo.Context) error {
+		return c.String(http.StatusOK, "passed")
+	}
+
+	sign := func(claims jwt.MapClaims) string {
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		tokenString, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		return tokenString
+	}
+
+	run := func(mw echo.MiddlewareFunc, token string) (*httptest.ResponseRecorder, echo.Context) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		assert.NoError(t, mw(nextHandler)(c))
+		return rec, c
+	}
+
+	t.Run("Success_ActiveSession", func(t *testing.T) {
+		mw := AuthMiddleware(secret, &fakeRevocations{revoked: map[string]bool{}})
+		rec, c := run(mw, sign(jwt.MapClaims{"sub": "user-123", "sid": "session-1"}))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "session-1", c.Get("session_id"))
+	})
+
+	t.Run("Fail_RevokedSession", func(t *testing.T) {
+		mw := AuthMiddleware(secret, &fakeRevocations{revoked: map[string]bool{"session-1": true}})
+		rec, _ := run(mw, sign(jwt.MapClaims{"sub": "user-123", "sid": "session-1"}))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "session revoked")
+	})
+
+	t.Run("Fail_NoSessionClaim", func(t *testing.T) {
+		// Старые токены без sid нельзя отозвать, поэтому они не принимаются
+		mw := AuthMiddleware(secret, &fakeRevocations{})
+		rec, _ := run(mw, sign(jwt.MapClaims{"sub": "user-123"}))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Fail_StoreUnavailable", func(t *testing.T) {
+		mw := AuthMiddleware(secret, &fakeRevocations{err: errors.New("redis down")})
+		rec, _ := run(mw, sign(jwt.MapClaims{"sub": "user-123", "sid": "session-1"}))
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+
+	// Пустой или недоступный кэш не отменяет отзыв, записанный в базе
+	t.Run("Fallback_ToStore", func(t *testing.T) {
+		store := &fakeRevocations{revoked: map[string]bool{"session-1": true}}
+		token := sign(jwt.MapClaims{"sub": "user-123", "sid": "session-1"})
+
+		for name, cache := range map[string]*fakeRevocations{
+			"flushed":     {revoked: map[string]bool{}},
+			"unavailable": {err: errors.New("redis down")},
+		} {
+			rec, _ := run(AuthMiddleware(secret, FallbackRevocations(cache, store)), token)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code, name)
+		}
+
+		cached := &fakeRevocations{revoked: map[string]bool{"session-2": true}}
+		rec, _ := run(AuthMiddleware(secret, FallbackRevocations(cached, &fakeRevocations{err: errors.New("db down")})),
+			sign(jwt.MapClaims{"sub": "user-123", "sid": "session-2"}))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		rec, _ = run(AuthMiddleware(secret, FallbackRevocations(&fakeRevocations{}, &fakeRevocations{})),
+			sign(jwt.MapClaims{"sub": "user-123", "sid": "session-3"}))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestQueryTokenMiddleware(t *testing.T) {
+	e := echo.New()
+	mw := QueryTokenMiddleware("access_token")
+	var got string
+	next := func(c echo.Context) error {
+		got = c.Request().Header.Get("Authorization")
+		return nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/?access_token=abc", nil)
+	assert.NoError(t, mw(next)(e.NewContext(req, httptest.NewRecorder())))
+	assert.Equal(t, "Bearer abc", got)
+
+	// Заголовок имеет приоритет над параметром
+	req = httptest.NewRequest(http.MethodGet, "/?access_token=abc", nil)
+	req.Header.Set("Authorization", "Bearer header")
+	assert.NoError(t, mw(next)(e.NewContext(req, httptest.NewRecorder())))
+	assert.Equal(t, "Bearer header", got)
+
+	// Токен не попадает в URI, который пишет журнал запросов
+	req = httptest.NewRequest(http.MethodGet, "/stream?access_token=abc&last_event_id=5", nil)
+	c := e.NewContext(req, httptest.NewRecorder())
+	assert.NoError(t, mw(next)(c))
+	assert.Equal(t, "Bearer abc", got)
+	assert.Equal(t, "/stream?last_event_id=5", req.RequestURI)
+	assert.NotContains(t, req.URL.String(), "abc")
+	assert.Equal(t, "5", c.QueryParam("last_event_id"))
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"todo-list/internal/api/problem"
+	"todo-list/internal/domain/apperr"
+)
+
+// UUIDParams rejects requests whose path parameters with the given names are
+// not UUIDs, so that malformed ids never reach a query. Routes without one of
+// the parameters are not affected.
+func UUIDParams(names ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var bad []apperr.Violation
+			for _, name := range names {
+				if v := c.Param(name); v != "" && uuid.Validate(v) != nil {
+					bad = append(bad, apperr.Violation{Field: name, Reason: "must be a UUID"})
+				}
+			}
+			if bad != nil {
+				p := problem.New(http.StatusBadRequest, "path parameters must be UUIDs")
+				p.Errors = bad
+				return problem.Write(c, p)
+			}
+			return next(c)
+		}
+	}
+}
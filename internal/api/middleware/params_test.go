@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUUIDParams(t *testing.T) {
+	e := echo.New()
+	e.Use(UUIDParams("id", "blocker"))
+	e.GET("/tasks/:id/dependencies/:blocker", func(c echo.Context) error {
+		return c.String(http.StatusOK, "passed")
+	})
+	e.GET("/tasks/status/:status", func(c echo.Context) error {
+		return c.String(http.StatusOK, "passed")
+	})
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	rec := get("/tasks/0b6f4c3e-7a41-4f0e-9d7a-2f1c9a1e5b11/dependencies/5d3c0a8e-1f2b-4c6d-8e9f-0a1b2c3d4e5f")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = get("/tasks/42/dependencies/5d3c0a8e-1f2b-4c6d-8e9f-0a1b2c3d4e5f")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get(echo.HeaderContentType))
+	assert.Contains(t, rec.Body.String(), `"errors":[{"field":"id","reason":"must be a UUID"}]`)
+
+	// Параметры с другими именами не проверяются
+	assert.Equal(t, http.StatusOK, get("/tasks/status/in_progress").Code)
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"time"
 	"todo-list/config"
+	"todo-list/internal/api/problem"
 )
 
 func RateLimiterMiddleware(redisClient *redis.Client, cfg *config.RateLimiterConfig) echo.MiddlewareFunc {
@@ -45,13 +46,13 @@ func RateLimiterMiddleware(redisClient *redis.Client, cfg *config.RateLimiterCon
 
 			if err != nil {
 				log.Printf("[ERROR] Rate limiter: Redis error for key %s: %v", redisKey, err)
-				return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "Could not process request rate limit"})
+				return problem.Respond(c, http.StatusServiceUnavailable, "Could not process request rate limit")
 			}
 
 			count, err = incrCmd.Result()
 			if err != nil {
 				log.Printf("[ERROR] Rate limiter: Could not get INCR result for key %s: %v", redisKey, err)
-				return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "Could not process request rate limit count"})
+				return problem.Respond(c, http.StatusServiceUnavailable, "Could not process request rate limit count")
 			}
 
 			if count > int64(cfg.Limit) {
@@ -63,7 +64,7 @@ func RateLimiterMiddleware(redisClient *redis.Client, cfg *config.RateLimiterCon
 				resetTime := time.Now().Add(cfg.Window).Unix()
 				c.Response().Header().Set("X-RateLimit-Reset", fmt.Sprintf("%d", resetTime))
 
-				return c.JSON(http.StatusTooManyRequests, map[string]string{"error": cfg.ErrorMessage})
+				return problem.Respond(c, http.StatusTooManyRequests, cfg.ErrorMessage)
 			}
 
 			remaining := int64(cfg.Limit) - count
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"log"
+	"net/http"
+	"time"
+	"todo-list/internal/api/problem"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/usertime"
+)
+
+// TimezoneHeader lets a client override the time zone stored in the user's
+// profile for a single request, e.g. while travelling.
+const TimezoneHeader = "X-Timezone"
+
+type TimezoneLookup interface {
+	Timezone(ctx context.Context, userID string) (string, error)
+}
+
+// Timezone puts the user's time zone into the request context, where the
+// services read "today" from. It must run after AuthMiddleware. A malformed
+// header is rejected; a stored zone that cannot be loaded falls back to UTC.
+func Timezone(users TimezoneLookup) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			var loc *time.Location
+			if name := req.Header.Get(TimezoneHeader); name != "" {
+				var err error
+				if loc, err = usertime.Load(name); err != nil {
+					p := problem.New(http.StatusBadRequest, "invalid time zone")
+					p.Errors = []apperr.Violation{{Field: TimezoneHeader, Reason: "must be an IANA time zone name"}}
+					return problem.Write(c, p)
+				}
+			} else {
+				loc = storedLocation(req.Context(), users, c.Get("user_id"))
+			}
+			c.SetRequest(req.WithContext(usertime.WithLocation(req.Context(), loc)))
+			return next(c)
+		}
+	}
+}
+
+func storedLocation(ctx context.Context, users TimezoneLookup, userID interface{}) *time.Location {
+	id, _ := userID.(string)
+	if users == nil || id == "" {
+		return time.UTC
+	}
+	name, err := users.Timezone(ctx, id)
+	if err != nil {
+		log.Printf("[ERROR] Timezone: could not look up user %s: %v", id, err)
+		return time.UTC
+	}
+	loc, err := usertime.Load(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-list/internal/domain/usertime"
+)
+
+type timezoneLookupFunc func(ctx context.Context, userID string) (string, error)
+
+func (f timezoneLookupFunc) Timezone(ctx context.Context, userID string) (string, error) {
+	return f(ctx, userID)
+}
+
+func TestTimezone(t *testing.T) {
+	stored := map[string]string{"moscow": "Europe/Moscow", "broken": "Mars/Olympus"}
+	users := timezoneLookupFunc(func(ctx context.Context, userID string) (string, error) {
+		if userID == "offline" {
+			return "", errors.New("connection refused")
+		}
+		return stored[userID], nil
+	})
+
+	e := echo.New()
+	e.GET("/today", func(c echo.Context) error {
+		return c.String(http.StatusOK, usertime.Location(c.Request().Context()).String())
+	}, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user_id", c.QueryParam("user"))
+			return next(c)
+		}
+	}, Timezone(users))
+
+	get := func(user, header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/today?user="+user, nil)
+		if header != "" {
+			req.Header.Set(TimezoneHeader, header)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, "Europe/Moscow", get("moscow", "").Body.String())
+	// Заголовок важнее часового пояса из профиля
+	assert.Equal(t, "Asia/Tokyo", get("moscow", "Asia/Tokyo").Body.String())
+	// Без пояса в профиле, с испорченным поясом или при ошибке БД — UTC
+	assert.Equal(t, "UTC", get("nobody", "").Body.String())
+	assert.Equal(t, "UTC", get("broken", "").Body.String())
+	assert.Equal(t, "UTC", get("offline", "").Body.String())
+
+	rec := get("moscow", "Moscow")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get(echo.HeaderContentType))
+	assert.Contains(t, rec.Body.String(), `"errors":[{"field":"X-Timezone","reason":"must be an IANA time zone name"}]`)
+}
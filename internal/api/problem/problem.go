@@ -0,0 +1,63 @@
+// Package problem writes error responses as RFC 7807 problem details.
+package problem
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"todo-list/internal/domain/apperr"
+)
+
+const MediaType = "application/problem+json"
+
+// Details is an RFC 7807 problem. Type is always about:blank, so Title is the
+// status text and Detail carries the specific message. Extensions are extra
+// members written next to the standard ones.
+type Details struct {
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Status     int                    `json:"status"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	Errors     []apperr.Violation     `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+func New(status int, detail string) *Details {
+	return &Details{Type: "about:blank", Title: http.StatusText(status), Status: status, Detail: detail}
+}
+
+func (d *Details) MarshalJSON() ([]byte, error) {
+	type plain Details
+	b, err := json.Marshal((*plain)(d))
+	if err != nil || len(d.Extensions) == 0 {
+		return b, err
+	}
+	out := make(map[string]interface{}, len(d.Extensions)+6)
+	for k, v := range d.Extensions {
+		out[k] = v
+	}
+	// Standard members win over extensions with the same name.
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return json.Marshal(out)
+}
+
+// Write sends d with the problem media type. Instance defaults to the request
+// path.
+func Write(c echo.Context, d *Details) error {
+	if d.Instance == "" {
+		d.Instance = c.Request().URL.Path
+	}
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return c.Blob(d.Status, MediaType, b)
+}
+
+// Respond is a shortcut for Write(c, New(status, detail)).
+func Respond(c echo.Context, status int, detail string) error {
+	return Write(c, New(status, detail))
+}
@@ -0,0 +1,45 @@
+package problem
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-list/internal/domain/apperr"
+)
+
+func TestWrite(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/7", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	d := New(http.StatusUnprocessableEntity, "cannot move task from \"todo\" to \"done\"")
+	d.Errors = []apperr.Violation{{Field: "status", Reason: "not allowed"}}
+	d.Extensions = map[string]interface{}{"allowed": []string{"in_progress"}, "status": 0}
+	require.NoError(t, Write(c, d))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, MediaType, rec.Header().Get(echo.HeaderContentType))
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "about:blank", body["type"])
+	assert.Equal(t, "Unprocessable Entity", body["title"])
+	// Расширение не может подменить стандартное поле
+	assert.Equal(t, 422.0, body["status"])
+	assert.Equal(t, "/api/v1/tasks/7", body["instance"])
+	assert.Equal(t, []interface{}{"in_progress"}, body["allowed"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"field": "status", "reason": "not allowed"}}, body["errors"])
+}
+
+func TestRespond_OmitsEmptyMembers(t *testing.T) {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+	require.NoError(t, Respond(c, http.StatusNotFound, ""))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Not Found","status":404,"instance":"/"}`, rec.Body.String())
+}
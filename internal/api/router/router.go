@@ -6,25 +6,48 @@ import (
 	"todo-list/internal/api/middleware"
 )
 
-func NewRouter(e *echo.Echo, h handlers.TaskHandler, ah *handlers.AuthHandler, secret string) {
+func NewRouter(e *echo.Echo, h handlers.TaskHandler, ah *handlers.AuthHandler, th handlers.TagHandler, rh handlers.ReminderHandler, ch handlers.CommentHandler, fh handlers.AttachmentHandler, ph handlers.ProjectHandler, wsh handlers.WorkspaceHandler, wh handlers.WebhookHandler, ssh handlers.SavedSearchHandler, sh handlers.StreamHandler, secret string, revocations middleware.RevocationChecker) {
+	authMw := middleware.AuthMiddleware(secret, revocations)
+	// Все идентификаторы в путях, кроме id тегов, — UUID
+	uuidParams := middleware.UUIDParams("id", "blocker", "reminderId", "commentId", "attachmentId", "userId", "invitationId", "deliveryId")
+	// «Сегодня» и даты без времени считаются в часовом поясе пользователя
+	tzMw := middleware.Timezone(ah)
+
 	// Открытые маршруты
 	e.POST("/auth/register", ah.Register)
 	e.POST("/auth/login", ah.Login)
+	e.POST("/auth/refresh", ah.Refresh)
+
+	// Управление сессиями
+	auth := e.Group("/auth")
+	auth.Use(authMw, uuidParams)
+	auth.POST("/logout", ah.Logout)
+	auth.GET("/sessions", ah.ListSessions)
+	auth.DELETE("/sessions", ah.RevokeAllSessions)
+	auth.DELETE("/sessions/:id", ah.RevokeSession)
+
+	// Профиль пользователя
+	profile := e.Group("/api/v1/profile")
+	profile.Use(authMw)
+	profile.GET("", ah.Profile)
+	profile.PATCH("", ah.UpdateProfile)
 
 	// Защищенные маршруты (только с JWT)
 	api := e.Group("/api/v1/tasks")
-	api.Use(middleware.AuthMiddleware(secret))
+	api.Use(authMw, uuidParams, tzMw)
 
 	api.POST("", h.Create)
 	api.GET("", h.List)
 	api.GET("/:id", h.Get)
 	api.PUT("/:id", h.Update)
+	api.PATCH("/:id", h.Patch)
 	api.DELETE("/:id", h.Delete)
 
 	api.PATCH("/:id/status", h.ChangeStatus)
 	api.PATCH("/:id/priority", h.ChangePriority)
 	api.PATCH("/:id/archive", h.Archive)
 	api.PATCH("/:id/unarchive", h.Unarchive)
+	api.POST("/:id/restore", h.Restore)
 
 	api.GET("/status/:status", h.ListByStatus)
 	api.GET("/priority/:priority", h.ListByPriority)
@@ -32,11 +55,131 @@ func NewRouter(e *echo.Echo, h handlers.TaskHandler, ah *handlers.AuthHandler, s
 	api.GET("/search", h.Search)
 	api.GET("/today", h.GetToday)
 	api.GET("/overdue", h.GetOverdue)
+	api.GET("/assigned", h.ListAssigned)
+	api.GET("/watching", h.ListWatching)
+	api.GET("/trash", h.Trash)
+	api.DELETE("/trash/:id", h.Purge)
+
+	api.POST("/:id/subtasks", h.CreateSubtask)
+	api.PATCH("/:id/parent", h.Move)
+	api.PATCH("/:id/project", ph.MoveTask)
+	api.GET("/:id/tree", h.Tree)
+
+	api.GET("/:id/dependencies", h.Dependencies)
+	api.POST("/:id/dependencies", h.AddDependency)
+	api.DELETE("/:id/dependencies/:blocker", h.RemoveDependency)
+
+	api.PUT("/:id/recurrence", h.SetRecurrence)
+	api.GET("/:id/occurrences", h.Occurrences)
+	api.GET("/:id/history", h.History)
+	api.POST("/:id/revert", h.Revert)
+
+	api.GET("/:id/reminders", rh.List)
+	api.POST("/:id/reminders", rh.Create)
+	api.DELETE("/:id/reminders/:reminderId", rh.Delete)
+
+	api.GET("/:id/comments", ch.List)
+	api.POST("/:id/comments", ch.Create)
+	api.PATCH("/:id/comments/:commentId", ch.Update)
+	api.DELETE("/:id/comments/:commentId", ch.Delete)
+
+	api.GET("/:id/attachments", fh.List)
+	api.POST("/:id/attachments", fh.Upload)
+	api.GET("/:id/attachments/:attachmentId", fh.Download)
+	api.DELETE("/:id/attachments/:attachmentId", fh.Delete)
+
+	api.POST("/:id/assignees", h.Assign)
+	api.DELETE("/:id/assignees/:userId", h.Unassign)
+	api.POST("/:id/watch", h.Watch)
+	api.DELETE("/:id/watch", h.Unwatch)
 
 	api.POST("/:id/tags", h.AddTag)
 	api.DELETE("/:id/tags/:tag", h.RemoveTag)
 
 	api.POST("/bulk-delete", h.BulkDelete)
+	api.POST("/bulk-restore", h.BulkRestore)
 	api.POST("/bulk-status", h.BulkUpdateStatus)
+	api.POST("/bulk-move", ph.BulkMove)
 	api.GET("/stats", h.Stats)
+
+	tags := e.Group("/api/v1/tags")
+	tags.Use(authMw)
+
+	tags.GET("", th.List)
+	tags.POST("", th.Create)
+	tags.PATCH("/:id", th.Update)
+	tags.POST("/:id/merge", th.Merge)
+	tags.DELETE("/:id", th.Delete)
+
+	projects := e.Group("/api/v1/projects")
+	projects.Use(authMw, uuidParams, tzMw)
+
+	projects.GET("", ph.List)
+	projects.POST("", ph.Create)
+	projects.PUT("/order", ph.Reorder)
+	projects.GET("/:id", ph.Get)
+	projects.PATCH("/:id", ph.Update)
+	projects.DELETE("/:id", ph.Delete)
+	projects.PATCH("/:id/archive", ph.Archive)
+	projects.PATCH("/:id/unarchive", ph.Unarchive)
+	projects.GET("/:id/tasks", ph.Tasks)
+	projects.GET("/:id/tasks/search", ph.Search)
+	projects.GET("/:id/stats", ph.Stats)
+	projects.GET("/:id/workflow", ph.Workflow)
+	projects.PUT("/:id/workflow", ph.SetWorkflow)
+	projects.DELETE("/:id/workflow", ph.ResetWorkflow)
+
+	workspaces := e.Group("/api/v1/workspaces")
+	workspaces.Use(authMw, uuidParams, tzMw)
+
+	workspaces.GET("", wsh.List)
+	workspaces.POST("", wsh.Create)
+	workspaces.GET("/:id", wsh.Get)
+	workspaces.PATCH("/:id", wsh.Rename)
+	workspaces.DELETE("/:id", wsh.Delete)
+	workspaces.GET("/:id/workflow", wsh.Workflow)
+	workspaces.PUT("/:id/workflow", wsh.SetWorkflow)
+	workspaces.DELETE("/:id/workflow", wsh.ResetWorkflow)
+	workspaces.GET("/:id/tasks", wsh.Tasks)
+	workspaces.GET("/:id/members", wsh.Members)
+	workspaces.POST("/:id/members", wsh.AddMember)
+	workspaces.PATCH("/:id/members/:userId", wsh.ChangeRole)
+	workspaces.DELETE("/:id/members/:userId", wsh.RemoveMember)
+	workspaces.GET("/:id/invitations", wsh.Invitations)
+	workspaces.POST("/:id/invitations", wsh.Invite)
+	workspaces.DELETE("/:id/invitations/:invitationId", wsh.RevokeInvitation)
+
+	// Отклонить приглашение можно без входа, принять — только под своим аккаунтом
+	invitations := e.Group("/api/v1/invitations")
+	invitations.POST("/accept", wsh.AcceptInvitation, authMw)
+	invitations.POST("/decline", wsh.DeclineInvitation)
+
+	webhooks := e.Group("/api/v1/webhooks")
+	webhooks.Use(authMw, uuidParams)
+
+	webhooks.GET("", wh.List)
+	webhooks.POST("", wh.Create)
+	webhooks.GET("/:id", wh.Get)
+	webhooks.PATCH("/:id", wh.Update)
+	webhooks.DELETE("/:id", wh.Delete)
+	webhooks.GET("/:id/deliveries", wh.Deliveries)
+	webhooks.POST("/:id/deliveries/:deliveryId/redeliver", wh.Redeliver)
+
+	// Системные списки адресуются ключом (today, overdue...), поэтому без uuidParams
+	savedSearches := e.Group("/api/v1/saved-searches")
+	savedSearches.Use(authMw, tzMw)
+
+	savedSearches.GET("", ssh.List)
+	savedSearches.POST("", ssh.Create)
+	savedSearches.GET("/:id", ssh.Get)
+	savedSearches.PUT("/:id", ssh.Update)
+	savedSearches.DELETE("/:id", ssh.Delete)
+	savedSearches.GET("/:id/tasks", ssh.Tasks)
+
+	// Поток событий; браузерные EventSource и WebSocket передают токен в query
+	stream := e.Group("/api/v1/stream")
+	stream.Use(middleware.QueryTokenMiddleware("access_token"), authMw)
+
+	stream.GET("", sh.Events)
+	stream.GET("/ws", sh.WebSocket)
 }
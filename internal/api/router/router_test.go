@@ -31,6 +31,119 @@ func (m *mockTaskHandler) RemoveTag(c echo.Context) error        { return nil }
 func (m *mockTaskHandler) BulkDelete(c echo.Context) error       { return nil }
 func (m *mockTaskHandler) BulkUpdateStatus(c echo.Context) error { return nil }
 func (m *mockTaskHandler) Stats(c echo.Context) error            { return nil }
+func (m *mockTaskHandler) CreateSubtask(c echo.Context) error    { return nil }
+func (m *mockTaskHandler) Move(c echo.Context) error             { return nil }
+func (m *mockTaskHandler) Tree(c echo.Context) error             { return nil }
+func (m *mockTaskHandler) AddDependency(c echo.Context) error    { return nil }
+func (m *mockTaskHandler) RemoveDependency(c echo.Context) error { return nil }
+func (m *mockTaskHandler) Dependencies(c echo.Context) error     { return nil }
+func (m *mockTaskHandler) SetRecurrence(c echo.Context) error    { return nil }
+func (m *mockTaskHandler) Occurrences(c echo.Context) error      { return nil }
+func (m *mockTaskHandler) Assign(c echo.Context) error           { return nil }
+func (m *mockTaskHandler) Unassign(c echo.Context) error         { return nil }
+func (m *mockTaskHandler) Watch(c echo.Context) error            { return nil }
+func (m *mockTaskHandler) Unwatch(c echo.Context) error          { return nil }
+func (m *mockTaskHandler) ListAssigned(c echo.Context) error     { return nil }
+func (m *mockTaskHandler) ListWatching(c echo.Context) error     { return nil }
+func (m *mockTaskHandler) History(c echo.Context) error          { return nil }
+func (m *mockTaskHandler) Revert(c echo.Context) error           { return nil }
+func (m *mockTaskHandler) Trash(c echo.Context) error            { return nil }
+func (m *mockTaskHandler) Patch(c echo.Context) error            { return nil }
+func (m *mockTaskHandler) Restore(c echo.Context) error          { return nil }
+func (m *mockTaskHandler) BulkRestore(c echo.Context) error      { return nil }
+func (m *mockTaskHandler) Purge(c echo.Context) error            { return nil }
+
+type mockTagHandler struct{}
+
+func (m *mockTagHandler) List(c echo.Context) error   { return nil }
+func (m *mockTagHandler) Create(c echo.Context) error { return nil }
+func (m *mockTagHandler) Update(c echo.Context) error { return nil }
+func (m *mockTagHandler) Merge(c echo.Context) error  { return nil }
+func (m *mockTagHandler) Delete(c echo.Context) error { return nil }
+
+type mockReminderHandler struct{}
+
+func (m *mockReminderHandler) List(c echo.Context) error   { return nil }
+func (m *mockReminderHandler) Create(c echo.Context) error { return nil }
+func (m *mockReminderHandler) Delete(c echo.Context) error { return nil }
+
+type mockProjectHandler struct{}
+
+func (m *mockProjectHandler) List(c echo.Context) error          { return nil }
+func (m *mockProjectHandler) Create(c echo.Context) error        { return nil }
+func (m *mockProjectHandler) Get(c echo.Context) error           { return nil }
+func (m *mockProjectHandler) Update(c echo.Context) error        { return nil }
+func (m *mockProjectHandler) Archive(c echo.Context) error       { return nil }
+func (m *mockProjectHandler) Unarchive(c echo.Context) error     { return nil }
+func (m *mockProjectHandler) Delete(c echo.Context) error        { return nil }
+func (m *mockProjectHandler) Reorder(c echo.Context) error       { return nil }
+func (m *mockProjectHandler) Tasks(c echo.Context) error         { return nil }
+func (m *mockProjectHandler) Search(c echo.Context) error        { return nil }
+func (m *mockProjectHandler) Stats(c echo.Context) error         { return nil }
+func (m *mockProjectHandler) Workflow(c echo.Context) error      { return nil }
+func (m *mockProjectHandler) SetWorkflow(c echo.Context) error   { return nil }
+func (m *mockProjectHandler) ResetWorkflow(c echo.Context) error { return nil }
+func (m *mockProjectHandler) MoveTask(c echo.Context) error      { return nil }
+func (m *mockProjectHandler) BulkMove(c echo.Context) error      { return nil }
+
+type mockCommentHandler struct{}
+
+func (m *mockCommentHandler) List(c echo.Context) error   { return nil }
+func (m *mockCommentHandler) Create(c echo.Context) error { return nil }
+func (m *mockCommentHandler) Update(c echo.Context) error { return nil }
+func (m *mockCommentHandler) Delete(c echo.Context) error { return nil }
+
+type mockAttachmentHandler struct{}
+
+func (m *mockAttachmentHandler) List(c echo.Context) error     { return nil }
+func (m *mockAttachmentHandler) Upload(c echo.Context) error   { return nil }
+func (m *mockAttachmentHandler) Download(c echo.Context) error { return nil }
+func (m *mockAttachmentHandler) Delete(c echo.Context) error   { return nil }
+
+type mockWorkspaceHandler struct{}
+
+func (m *mockWorkspaceHandler) List(c echo.Context) error              { return nil }
+func (m *mockWorkspaceHandler) Create(c echo.Context) error            { return nil }
+func (m *mockWorkspaceHandler) Get(c echo.Context) error               { return nil }
+func (m *mockWorkspaceHandler) Rename(c echo.Context) error            { return nil }
+func (m *mockWorkspaceHandler) Delete(c echo.Context) error            { return nil }
+func (m *mockWorkspaceHandler) Workflow(c echo.Context) error          { return nil }
+func (m *mockWorkspaceHandler) SetWorkflow(c echo.Context) error       { return nil }
+func (m *mockWorkspaceHandler) ResetWorkflow(c echo.Context) error     { return nil }
+func (m *mockWorkspaceHandler) Tasks(c echo.Context) error             { return nil }
+func (m *mockWorkspaceHandler) Members(c echo.Context) error           { return nil }
+func (m *mockWorkspaceHandler) AddMember(c echo.Context) error         { return nil }
+func (m *mockWorkspaceHandler) ChangeRole(c echo.Context) error        { return nil }
+func (m *mockWorkspaceHandler) RemoveMember(c echo.Context) error      { return nil }
+func (m *mockWorkspaceHandler) Invite(c echo.Context) error            { return nil }
+func (m *mockWorkspaceHandler) Invitations(c echo.Context) error       { return nil }
+func (m *mockWorkspaceHandler) RevokeInvitation(c echo.Context) error  { return nil }
+func (m *mockWorkspaceHandler) AcceptInvitation(c echo.Context) error  { return nil }
+func (m *mockWorkspaceHandler) DeclineInvitation(c echo.Context) error { return nil }
+
+type mockWebhookHandler struct{}
+
+func (m *mockWebhookHandler) List(c echo.Context) error       { return nil }
+func (m *mockWebhookHandler) Create(c echo.Context) error     { return nil }
+func (m *mockWebhookHandler) Get(c echo.Context) error        { return nil }
+func (m *mockWebhookHandler) Update(c echo.Context) error     { return nil }
+func (m *mockWebhookHandler) Delete(c echo.Context) error     { return nil }
+func (m *mockWebhookHandler) Deliveries(c echo.Context) error { return nil }
+func (m *mockWebhookHandler) Redeliver(c echo.Context) error  { return nil }
+
+type mockSavedSearchHandler struct{}
+
+func (m *mockSavedSearchHandler) List(c echo.Context) error   { return nil }
+func (m *mockSavedSearchHandler) Create(c echo.Context) error { return nil }
+func (m *mockSavedSearchHandler) Get(c echo.Context) error    { return nil }
+func (m *mockSavedSearchHandler) Update(c echo.Context) error { return nil }
+func (m *mockSavedSearchHandler) Delete(c echo.Context) error { return nil }
+func (m *mockSavedSearchHandler) Tasks(c echo.Context) error  { return nil }
+
+type mockStreamHandler struct{}
+
+func (m *mockStreamHandler) Events(c echo.Context) error    { return nil }
+func (m *mockStreamHandler) WebSocket(c echo.Context) error { return nil }
 
 func TestNewRouter(t *testing.T) {
 	e := echo.New()
@@ -39,7 +152,7 @@ func TestNewRouter(t *testing.T) {
 	authH := &handlers.AuthHandler{}
 	secret := "test-secret"
 
-	NewRouter(e, taskH, authH, secret)
+	NewRouter(e, taskH, authH, &mockTagHandler{}, &mockReminderHandler{}, &mockCommentHandler{}, &mockAttachmentHandler{}, &mockProjectHandler{}, &mockWorkspaceHandler{}, &mockWebhookHandler{}, &mockSavedSearchHandler{}, &mockStreamHandler{}, secret, nil)
 
 	assert.Greater(t, len(e.Routes()), 0)
 
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -9,9 +10,13 @@ import (
 	"todo-list/internal/api/handlers"
 	md "todo-list/internal/api/middleware"
 	"todo-list/internal/api/router"
+	"todo-list/internal/domain/event"
+	"todo-list/internal/domain/model"
 	"todo-list/internal/domain/service"
+	"todo-list/internal/infrastructure/blob"
 	"todo-list/internal/infrastructure/cache/redis"
 	"todo-list/internal/infrastructure/database/postgres"
+	"todo-list/internal/infrastructure/notify"
 	"todo-list/internal/infrastructure/repository"
 )
 
@@ -33,21 +38,95 @@ func Start() {
 
 	db := dbConn.GetDB()
 
+	// События задач уходят в очередь исходящих вебхуков и в поток для клиентов
+	webhookRepo := repository.NewWebhookRepository(db)
+	var stream event.Stream = event.NewMemoryStream(cfg.Stream.History)
+	if redisClient != nil {
+		stream = redis.NewEventStream(redisClient, cfg.Stream.History)
+	}
+	events := event.NewBus(service.NewWebhookDispatcher(webhookRepo), stream)
+
 	taskRepo := repository.NewTaskRepository(db)
-	taskService := service.NewTaskService(taskRepo)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+	taskService := service.NewTaskService(taskRepo, workspaceRepo, events)
 	taskHandler := handlers.NewTaskHandler(taskService)
-	authHandler := handlers.NewAuthHandler(db, cfg.JWTSecret)
+	tagRepo := repository.NewTagRepository(db)
+	tagService := service.NewTagService(tagRepo, taskRepo, events)
+	tagHandler := handlers.NewTagHandler(tagService)
+	reminderRepo := repository.NewReminderRepository(db)
+	reminderService := service.NewReminderService(reminderRepo, taskRepo)
+	reminderHandler := handlers.NewReminderHandler(reminderService)
+	commentHandler := handlers.NewCommentHandler(service.NewCommentService(repository.NewCommentRepository(db), taskRepo, workspaceRepo, events))
+	// Файлы вложений лежат на диске или в S3-совместимом хранилище
+	var blobs service.BlobStore
+	if cfg.Attachments.Storage == "s3" {
+		blobs, err = blob.NewS3Store(&cfg.Attachments.S3)
+	} else {
+		blobs, err = blob.NewLocalStore(cfg.Attachments.Dir)
+	}
+	if err != nil {
+		log.Fatalf("Failed to set up attachment storage: %v", err)
+	}
+	attachmentService := service.NewAttachmentService(repository.NewAttachmentRepository(db), taskRepo, workspaceRepo, blobs, &cfg.Attachments, events)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService, cfg.Attachments.MaxSize)
+	projectRepo := repository.NewProjectRepository(db)
+	projectHandler := handlers.NewProjectHandler(service.NewProjectService(projectRepo, taskRepo, events))
+	// Приглашения уходят письмом, если настроен SMTP; иначе ссылку передаёт владелец
+	var mailer service.InvitationMailer
+	if cfg.SMTP.Host != "" {
+		mailer = notify.NewSMTPNotifier(&cfg.SMTP)
+	}
+	workspaceService := service.NewWorkspaceService(workspaceRepo, taskRepo, cfg.JWTSecret, &cfg.Invitations, mailer)
+	workspaceHandler := handlers.NewWorkspaceHandler(workspaceService)
+	webhookHandler := handlers.NewWebhookHandler(service.NewWebhookService(webhookRepo))
+	savedSearchHandler := handlers.NewSavedSearchHandler(service.NewSavedSearchService(repository.NewSavedSearchRepository(db), taskRepo))
+	authHandler := handlers.NewAuthHandler(db, cfg.JWTSecret, &cfg.Auth)
+	authHandler.Invitations = workspaceService
+
+	// Отзыв сессий проверяется по таблице sessions; Redis лишь быстрее отвечает
+	// для уже отозванных, и его потеря не возвращает им доступ
+	var revocations md.RevocationChecker = authHandler
+	if redisClient != nil {
+		revocationList := redis.NewRevocationList(redisClient)
+		authHandler.Revocations = revocationList
+		revocations = md.FallbackRevocations(revocationList, authHandler)
+	}
+	// Открытые потоки периодически перепроверяют сессию
+	streamHandler := handlers.NewStreamHandler(stream, cfg.Stream.Heartbeat, revocations, cfg.Stream.AllowedOrigins)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Планировщик напоминаний; несколько инстансов делят работу через аренду в БД
+	if cfg.Reminders.Enabled {
+		notifiers := map[string]service.Notifier{
+			model.ReminderChannelWebhook: notify.NewWebhookNotifier(cfg.Reminders.WebhookTimeout),
+		}
+		if cfg.SMTP.Host != "" {
+			notifiers[model.ReminderChannelEmail] = notify.NewSMTPNotifier(&cfg.SMTP)
+		}
+		go service.NewReminderScheduler(reminderRepo, notifiers, &cfg.Reminders).Run(ctx)
+	}
+	go service.NewAttachmentJanitor(attachmentService, cfg.Attachments.CleanupInterval).Run(ctx)
+	go service.NewTrashPurger(taskRepo, &cfg.Trash).Run(ctx)
+	if cfg.Webhooks.Enabled {
+		sender := notify.NewWebhookSender(cfg.Webhooks.Timeout)
+		go service.NewWebhookWorker(webhookRepo, sender, &cfg.Webhooks).Run(ctx)
+	}
 
 	e := echo.New()
+	// Ошибки маршрутизации и middleware тоже отдаются как application/problem+json
+	e.HTTPErrorHandler = handlers.HTTPErrorHandler
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
-	e.Use(middleware.CORS())
+	// ETag нужен клиентам для If-Match и If-None-Match
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{ExposeHeaders: []string{"ETag"}}))
 
 	if redisClient != nil {
 		e.Use(md.RateLimiterMiddleware(redisClient, &cfg.RateLimiter))
 	}
 
-	router.NewRouter(e, taskHandler, authHandler, cfg.JWTSecret)
+	router.NewRouter(e, taskHandler, authHandler, tagHandler, reminderHandler, commentHandler, attachmentHandler, projectHandler, workspaceHandler, webhookHandler, savedSearchHandler, streamHandler, cfg.JWTSecret, revocations)
 
 	serverAddr := fmt.Sprintf("%s:%d", cfg.Server.HTTP.Host, cfg.Server.HTTP.Port)
 	log.Printf("Server starting on %s", serverAddr)
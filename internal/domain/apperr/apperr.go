@@ -0,0 +1,106 @@
+// Package apperr classifies domain errors so that transports can map them to
+// status codes without knowing every sentinel error by name.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind says what went wrong from the caller's point of view.
+type Kind int
+
+const (
+	// Internal is a failure the caller cannot fix; its message is not shown.
+	Internal Kind = iota
+	// Invalid rejects a malformed or inconsistent request.
+	Invalid
+	// Unprocessable rejects a well-formed request the current state does not allow.
+	Unprocessable
+	NotFound
+	Conflict
+	Forbidden
+	Unauthorized
+	// Precondition means the resource changed since the caller last read it.
+	Precondition
+	// Gone is for things that existed but are no longer available.
+	Gone
+	TooLarge
+	Unsupported
+)
+
+// Error is a sentinel error with a kind. Compare values with errors.Is as
+// usual; they are pointers, so each New call creates a distinct error.
+type Error struct {
+	kind Kind
+	msg  string
+}
+
+func New(kind Kind, msg string) error {
+	return &Error{kind: kind, msg: msg}
+}
+
+func (e *Error) Error() string { return e.msg }
+
+func (e *Error) Kind() Kind { return e.kind }
+
+// KindOf returns the kind of the first error in err's chain that has one, and
+// Internal when none does.
+func KindOf(err error) Kind {
+	var k interface{ Kind() Kind }
+	if errors.As(err, &k) {
+		return k.Kind()
+	}
+	return Internal
+}
+
+// Violation is a problem with one field of a request.
+type Violation struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// ViolationsOf returns the field-level details carried by err, if any.
+func ViolationsOf(err error) []Violation {
+	var v interface{ Violations() []Violation }
+	if errors.As(err, &v) {
+		return v.Violations()
+	}
+	return nil
+}
+
+// ValidationError collects every invalid field of a request instead of
+// stopping at the first one.
+type ValidationError struct {
+	violations []Violation
+}
+
+// Add records that field is invalid for reason.
+func (e *ValidationError) Add(field, reason string) {
+	e.violations = append(e.violations, Violation{Field: field, Reason: reason})
+}
+
+// Err returns e when anything was added and nil otherwise, so a Validate
+// method can end with return v.Err().
+func (e *ValidationError) Err() error {
+	if len(e.violations) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.violations) == 1 {
+		return fmt.Sprintf("invalid %s: %s", e.violations[0].Field, e.violations[0].Reason)
+	}
+	return fmt.Sprintf("request has %d invalid fields", len(e.violations))
+}
+
+// Field is a ValidationError with a single violation.
+func Field(field, reason string) error {
+	return &ValidationError{violations: []Violation{{Field: field, Reason: reason}}}
+}
+
+func (e *ValidationError) Kind() Kind { return Invalid }
+
+func (e *ValidationError) Violations() []Violation { return e.violations }
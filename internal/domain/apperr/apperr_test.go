@@ -0,0 +1,38 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestKindOf(t *testing.T) {
+	notFound := New(NotFound, "task not found")
+	wrapped := fmt.Errorf("loading: %w", notFound)
+
+	assert.Equal(t, NotFound, KindOf(wrapped))
+	assert.ErrorIs(t, wrapped, notFound)
+	// Одинаковый текст не делает ошибки равными
+	assert.NotErrorIs(t, wrapped, New(NotFound, "task not found"))
+	assert.Equal(t, Internal, KindOf(errors.New("connection reset")))
+	assert.Equal(t, Internal, KindOf(nil))
+}
+
+func TestValidationError(t *testing.T) {
+	var v ValidationError
+	assert.NoError(t, v.Err())
+
+	v.Add("title", "is required")
+	err := v.Err()
+	assert.EqualError(t, err, "invalid title: is required")
+	v.Add("due_date", "must be an RFC 3339 timestamp")
+	assert.EqualError(t, err, "request has 2 invalid fields")
+
+	assert.Equal(t, Invalid, KindOf(err))
+	assert.Equal(t, []Violation{
+		{Field: "title", Reason: "is required"},
+		{Field: "due_date", Reason: "must be an RFC 3339 timestamp"},
+	}, ViolationsOf(fmt.Errorf("create: %w", err)))
+	assert.Nil(t, ViolationsOf(New(Invalid, "bad")))
+}
@@ -0,0 +1,90 @@
+// Package event carries task lifecycle notifications from the services to
+// whoever listens: outgoing webhooks, live streams and so on.
+package event
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+type Type string
+
+const (
+	TaskCreated       Type = "task.created"
+	TaskUpdated       Type = "task.updated"
+	TaskStatusChanged Type = "task.status_changed"
+	TaskArchived      Type = "task.archived"
+	TaskUnarchived    Type = "task.unarchived"
+	TaskDeleted       Type = "task.deleted"
+	TaskRestored      Type = "task.restored"
+	TaskPurged        Type = "task.purged"
+	TaskTagAdded      Type = "task.tag_added"
+	TaskTagRemoved    Type = "task.tag_removed"
+	TaskAssigned      Type = "task.assigned"
+	TaskUnassigned    Type = "task.unassigned"
+	TaskCommented     Type = "task.commented"
+	TaskMentioned     Type = "task.mentioned"
+	TaskFileAttached  Type = "task.file_attached"
+	TaskFileRemoved   Type = "task.file_removed"
+)
+
+// Types lists every event a subscriber can ask for.
+var Types = []Type{
+	TaskCreated, TaskUpdated, TaskStatusChanged, TaskArchived, TaskUnarchived,
+	TaskDeleted, TaskRestored, TaskPurged, TaskTagAdded, TaskTagRemoved, TaskAssigned, TaskUnassigned,
+	TaskCommented, TaskMentioned, TaskFileAttached, TaskFileRemoved,
+}
+
+func Known(t Type) bool {
+	for _, k := range Types {
+		if k == t {
+			return true
+		}
+	}
+	return false
+}
+
+type Event struct {
+	ID         uuid.UUID              `json:"id"`
+	Type       Type                   `json:"type"`
+	UserID     uuid.UUID              `json:"-"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+func New(t Type, userID uuid.UUID, data map[string]interface{}) Event {
+	return Event{ID: uuid.New(), Type: t, UserID: userID, OccurredAt: time.Now().UTC(), Data: data}
+}
+
+// Publisher must not block the caller for long and handles its own errors;
+// a failed listener never fails the change that produced the event.
+type Publisher interface {
+	Publish(ctx context.Context, e Event)
+}
+
+// Bus fans an event out to every subscribed publisher in order.
+type Bus struct {
+	subscribers []Publisher
+}
+
+func NewBus(subscribers ...Publisher) *Bus {
+	return &Bus{subscribers: subscribers}
+}
+
+func (b *Bus) Subscribe(p Publisher) {
+	b.subscribers = append(b.subscribers, p)
+}
+
+func (b *Bus) Publish(ctx context.Context, e Event) {
+	for _, s := range b.subscribers {
+		s.Publish(ctx, e)
+	}
+}
+
+type nop struct{}
+
+func (nop) Publish(context.Context, Event) {}
+
+// Nop discards every event.
+var Nop Publisher = nop{}
@@ -0,0 +1,105 @@
+package event
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"strconv"
+	"sync"
+)
+
+// Message is an event as delivered on a live stream. ID orders messages
+// within one user's stream and is what clients send back as Last-Event-ID.
+type Message struct {
+	ID    string
+	Event Event
+}
+
+// Stream is a Publisher whose events can be followed live, per user.
+type Stream interface {
+	Publisher
+	// Subscribe replays the retained messages after lastID, if one is given,
+	// and then follows new ones until ctx is done. The channel is closed when
+	// the subscription ends, which also happens when the reader falls too far
+	// behind; the client is expected to reconnect with its last ID.
+	Subscribe(ctx context.Context, userID uuid.UUID, lastID string) (<-chan Message, error)
+}
+
+// subscriberBuffer is how many live messages a subscriber may lag behind.
+const subscriberBuffer = 64
+
+// MemoryStream keeps the last size messages of every user in process. It is
+// the fallback when Redis is not available and only works for a single
+// instance.
+type MemoryStream struct {
+	mu      sync.Mutex
+	size    int
+	seq     uint64
+	history map[uuid.UUID][]Message
+	subs    map[uuid.UUID]map[chan Message]struct{}
+}
+
+func NewMemoryStream(size int) *MemoryStream {
+	return &MemoryStream{
+		size:    size,
+		history: make(map[uuid.UUID][]Message),
+		subs:    make(map[uuid.UUID]map[chan Message]struct{}),
+	}
+}
+
+func (s *MemoryStream) Publish(ctx context.Context, e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	msg := Message{ID: strconv.FormatUint(s.seq, 10), Event: e}
+	h := append(s.history[e.UserID], msg)
+	if len(h) > s.size {
+		h = h[len(h)-s.size:]
+	}
+	s.history[e.UserID] = h
+	for ch := range s.subs[e.UserID] {
+		select {
+		case ch <- msg:
+		default:
+			s.drop(e.UserID, ch)
+		}
+	}
+}
+
+func (s *MemoryStream) Subscribe(ctx context.Context, userID uuid.UUID, lastID string) (<-chan Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var replay []Message
+	if last, err := strconv.ParseUint(lastID, 10, 64); err == nil {
+		for _, m := range s.history[userID] {
+			if seq, _ := strconv.ParseUint(m.ID, 10, 64); seq > last {
+				replay = append(replay, m)
+			}
+		}
+	}
+	ch := make(chan Message, len(replay)+subscriberBuffer)
+	for _, m := range replay {
+		ch <- m
+	}
+	if s.subs[userID] == nil {
+		s.subs[userID] = make(map[chan Message]struct{})
+	}
+	s.subs[userID][ch] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.subs[userID][ch]; ok {
+			s.drop(userID, ch)
+		}
+	}()
+	return ch, nil
+}
+
+func (s *MemoryStream) drop(userID uuid.UUID, ch chan Message) {
+	delete(s.subs[userID], ch)
+	if len(s.subs[userID]) == 0 {
+		delete(s.subs, userID)
+	}
+	close(ch)
+}
@@ -0,0 +1,82 @@
+package event
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func receive(t *testing.T, ch <-chan Message) Message {
+	t.Helper()
+	select {
+	case m, ok := <-ch:
+		require.True(t, ok, "stream closed")
+		return m
+	case <-time.After(time.Second):
+		t.Fatal("no message")
+	}
+	return Message{}
+}
+
+func TestMemoryStream(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+
+	t.Run("Live_And_Per_User", func(t *testing.T) {
+		s := NewMemoryStream(10)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch, err := s.Subscribe(ctx, alice, "")
+		require.NoError(t, err)
+
+		s.Publish(ctx, New(TaskCreated, bob, nil))
+		s.Publish(ctx, New(TaskDeleted, alice, nil))
+
+		m := receive(t, ch)
+		assert.Equal(t, TaskDeleted, m.Event.Type)
+		assert.Equal(t, "2", m.ID)
+	})
+
+	t.Run("Resumes_After_Last_ID", func(t *testing.T) {
+		s := NewMemoryStream(2)
+		ctx := context.Background()
+		for _, typ := range []Type{TaskCreated, TaskUpdated, TaskArchived} {
+			s.Publish(ctx, New(typ, alice, nil))
+		}
+
+		// Первое событие уже вытеснено из истории
+		ch, err := s.Subscribe(ctx, alice, "0")
+		require.NoError(t, err)
+		assert.Equal(t, TaskUpdated, receive(t, ch).Event.Type)
+		assert.Equal(t, TaskArchived, receive(t, ch).Event.Type)
+
+		ch, err = s.Subscribe(ctx, alice, "3")
+		require.NoError(t, err)
+		assert.Empty(t, ch)
+	})
+
+	t.Run("Closes_On_Cancel_And_Overflow", func(t *testing.T) {
+		s := NewMemoryStream(1)
+		ctx, cancel := context.WithCancel(context.Background())
+		ch, err := s.Subscribe(ctx, alice, "")
+		require.NoError(t, err)
+		cancel()
+		assert.Eventually(t, func() bool {
+			_, ok := <-ch
+			return !ok
+		}, time.Second, 10*time.Millisecond)
+
+		slow, err := s.Subscribe(context.Background(), alice, "")
+		require.NoError(t, err)
+		for i := 0; i <= subscriberBuffer; i++ {
+			s.Publish(context.Background(), New(TaskUpdated, alice, nil))
+		}
+		n := 0
+		for range slow {
+			n++
+		}
+		assert.Equal(t, subscriberBuffer, n)
+	})
+}
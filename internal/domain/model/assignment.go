@@ -0,0 +1,22 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// TaskAssignee marks a user as responsible for a task. Anyone who can see the
+// task can be assigned to it.
+type TaskAssignee struct {
+	TaskID     uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+	UserID     uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"user_id"`
+	AssignedBy uuid.UUID `gorm:"type:uuid;not null" json:"assigned_by"`
+	CreatedAt  time.Time `json:"assigned_at"`
+}
+
+// TaskWatcher follows a task without being responsible for it.
+type TaskWatcher struct {
+	TaskID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"user_id"`
+	CreatedAt time.Time `json:"watching_since"`
+}
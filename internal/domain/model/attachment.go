@@ -0,0 +1,34 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"io"
+	"time"
+)
+
+// Attachment is a file uploaded to a task. The bytes live in blob storage
+// under StorageKey; the row keeps what is needed to list and serve them.
+type Attachment struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	TaskID      uuid.UUID `gorm:"type:uuid;not null;index" json:"task_id"`
+	UploadedBy  uuid.UUID `gorm:"type:uuid;not null;index" json:"uploaded_by"`
+	Name        string    `gorm:"size:255;not null" json:"name"`
+	ContentType string    `gorm:"size:255;not null" json:"content_type"`
+	Size        int64     `gorm:"not null" json:"size"`
+	StorageKey  string    `gorm:"size:255;not null;uniqueIndex" json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AttachmentKey is where the blob of an attachment is stored.
+func AttachmentKey(taskID, id uuid.UUID) string {
+	return "tasks/" + taskID.String() + "/" + id.String()
+}
+
+// AttachmentUpload is a file as received from the client. Size must be the
+// exact length of Body.
+type AttachmentUpload struct {
+	Name        string
+	ContentType string
+	Size        int64
+	Body        io.Reader
+}
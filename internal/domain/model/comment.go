@@ -0,0 +1,52 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Comment is a markdown message in a task's discussion. The body is stored as
+// written; rendering is left to clients.
+type Comment struct {
+	ID        uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	TaskID    uuid.UUID        `gorm:"type:uuid;not null;index" json:"task_id"`
+	AuthorID  uuid.UUID        `gorm:"type:uuid;not null" json:"author_id"`
+	Body      string           `gorm:"type:text;not null" json:"body"`
+	Mentions  []CommentMention `gorm:"foreignKey:CommentID" json:"mentions,omitempty"`
+	EditedAt  *time.Time       `json:"edited_at,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	DeletedAt gorm.DeletedAt   `gorm:"index" json:"-"`
+}
+
+// CommentMention records a user resolved from an @handle in the body.
+type CommentMention struct {
+	CommentID uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"user_id"`
+}
+
+var (
+	codeBlockRe = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`")
+	mentionRe   = regexp.MustCompile(`(^|[^\w.%+\-@])@([\w.%+\-]+(?:@[\w\-]+(?:\.[\w\-]+)+)?)`)
+)
+
+// ParseMentions returns the lower-cased handles mentioned in a markdown body,
+// in order of first appearance. A handle is either a full email address or
+// the part before the @. Mentions inside code spans and blocks are ignored.
+func ParseMentions(body string) []string {
+	body = codeBlockRe.ReplaceAllString(body, " ")
+	seen := make(map[string]bool)
+	var out []string
+	for _, m := range mentionRe.FindAllStringSubmatch(body, -1) {
+		handle := strings.ToLower(strings.TrimRight(m[2], "."))
+		if handle == "" || seen[handle] {
+			continue
+		}
+		seen[handle] = true
+		out = append(out, handle)
+	}
+	return out
+}
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	body := "Hey @Bob, can you ask @carol@example.com? cc @bob.\n" +
+		"Mail me at dave@example.com.\n" +
+		"```\n@ignored in code\n```\nand `@inline` too (@erin)"
+
+	assert.Equal(t, []string{"bob", "carol@example.com", "erin"}, ParseMentions(body))
+	assert.Empty(t, ParseMentions("no mentions here"))
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskDependency records that BlockerID must be done before BlockedID can proceed.
+type TaskDependency struct {
+	BlockerID uuid.UUID `gorm:"type:uuid;primaryKey" json:"blocker_id"`
+	BlockedID uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"blocked_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DependencyGraph holds every task connected to a root task through
+// dependency links, in either direction.
+type DependencyGraph struct {
+	RootID uuid.UUID
+	Tasks  []Task
+	Edges  []TaskDependency
+}
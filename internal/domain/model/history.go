@@ -0,0 +1,92 @@
+package model
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"sort"
+	"time"
+)
+
+// TaskRevision is one recorded change to a task: who made it, when, and the
+// old and new value of every field it touched. Revisions are numbered per
+// task from 1 and never modified.
+type TaskRevision struct {
+	ID        uuid.UUID     `gorm:"type:uuid;primaryKey" json:"id"`
+	TaskID    uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex:idx_task_revisions_task_revision,priority:1" json:"task_id"`
+	Revision  int           `gorm:"not null;uniqueIndex:idx_task_revisions_task_revision,priority:2" json:"revision"`
+	ChangedBy uuid.UUID     `gorm:"type:uuid;not null" json:"changed_by"`
+	Changes   []FieldChange `gorm:"type:jsonb;serializer:json;not null" json:"changes"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// FieldChange holds JSON-encoded values so that every field type round-trips
+// through storage unchanged.
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+// TaskFields is the part of a task whose history is kept.
+type TaskFields struct {
+	Title     string     `json:"title"`
+	Content   string     `json:"content"`
+	Status    string     `json:"status"`
+	Priority  string     `json:"priority"`
+	DueDate   *time.Time `json:"due_date"`
+	RRule     string     `json:"rrule"`
+	Archived  bool       `json:"archived"`
+	ParentID  *uuid.UUID `json:"parent_id"`
+	ProjectID *uuid.UUID `json:"project_id"`
+	Tags      []string   `json:"tags"`
+}
+
+// RevertableFields can be restored from history. Archiving, moves and tags
+// act on whole subtrees or other records and are only shown.
+var RevertableFields = []string{"title", "content", "status", "priority", "due_date", "rrule"}
+
+func FieldsOf(t Task) TaskFields {
+	tags := make([]string, 0, len(t.Tags))
+	for _, tag := range t.Tags {
+		tags = append(tags, tag.Name)
+	}
+	sort.Strings(tags)
+	return TaskFields{
+		Title: t.Title, Content: t.Content, Status: t.Status, Priority: t.Priority,
+		DueDate: t.DueDate, RRule: t.RRule, Archived: t.Archived,
+		ParentID: t.ParentID, ProjectID: t.ProjectID, Tags: tags,
+	}
+}
+
+// Diff lists the fields that differ between f and to, in a fixed order.
+func (f TaskFields) Diff(to TaskFields) []FieldChange {
+	from, _ := fieldValues(f)
+	next, _ := fieldValues(to)
+	var out []FieldChange
+	for _, name := range trackedFields {
+		if string(from[name]) != string(next[name]) {
+			out = append(out, FieldChange{Field: name, Old: from[name], New: next[name]})
+		}
+	}
+	return out
+}
+
+// Set decodes a value taken from a FieldChange into the named field.
+func (f *TaskFields) Set(field string, value json.RawMessage) error {
+	return json.Unmarshal([]byte(`{"`+field+`":`+string(value)+`}`), f)
+}
+
+var trackedFields = []string{"title", "content", "status", "priority", "due_date", "rrule", "archived", "parent_id", "project_id", "tags"}
+
+func fieldValues(f TaskFields) (map[string]json.RawMessage, error) {
+	if f.DueDate != nil {
+		due := f.DueDate.UTC()
+		f.DueDate = &due
+	}
+	data, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]json.RawMessage{}
+	return out, json.Unmarshal(data, &out)
+}
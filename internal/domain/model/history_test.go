@@ -0,0 +1,33 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestTaskFields_Diff_And_Set(t *testing.T) {
+	due := time.Date(2026, 3, 1, 9, 0, 0, 0, time.FixedZone("MSK", 3*3600))
+	project := uuid.New()
+	before := FieldsOf(Task{Title: "Draft", Status: "todo", Tags: []Tag{{Name: "b"}, {Name: "a"}}})
+	after := FieldsOf(Task{Title: "Final", Status: "todo", DueDate: &due, ProjectID: &project, Tags: []Tag{{Name: "a"}, {Name: "b"}}})
+
+	changes := before.Diff(after)
+	fields := make([]string, 0, len(changes))
+	for _, c := range changes {
+		fields = append(fields, c.Field)
+	}
+	// Порядок тегов не считается изменением
+	assert.Equal(t, []string{"title", "due_date", "project_id"}, fields)
+	assert.JSONEq(t, `"2026-03-01T06:00:00Z"`, string(changes[1].New))
+	assert.JSONEq(t, `null`, string(changes[1].Old))
+
+	// Старые значения из истории возвращают поля к прежнему виду
+	for _, c := range changes {
+		require.NoError(t, after.Set(c.Field, c.Old))
+	}
+	assert.Empty(t, before.Diff(after))
+	assert.Nil(t, after.DueDate)
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Project groups a user's tasks into a list. Tasks without a project form the
+// user's inbox.
+type Project struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_projects_user_name,priority:1"`
+	Name        string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_projects_user_name,priority:2"`
+	Color       string    `gorm:"type:varchar(7)"`
+	Description string    `gorm:"type:text"`
+	Position    int       `gorm:"not null;default:0"`
+	Archived    bool      `gorm:"not null;default:false"`
+	// Workflow overrides DefaultWorkflow for the project's tasks.
+	Workflow  *Workflow `gorm:"type:jsonb;serializer:json"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
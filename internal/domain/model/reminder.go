@@ -0,0 +1,62 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	ReminderChannelEmail   = "email"
+	ReminderChannelWebhook = "webhook"
+)
+
+// Reminder fires either at RemindAt or OffsetMinutes before the task's due
+// date, so offset reminders follow the due date when it moves. LeaseUntil is
+// set while a scheduler instance owns the reminder and doubles as the retry
+// time after a failed attempt.
+type Reminder struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TaskID        uuid.UUID `gorm:"type:uuid;not null;index"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null;index"`
+	RemindAt      *time.Time
+	OffsetMinutes *int
+	Channel       string `gorm:"type:varchar(20);not null"`
+	Target        string `gorm:"type:varchar(512)"`
+	Attempts      int    `gorm:"not null;default:0"`
+	LastError     string `gorm:"type:text"`
+	LeaseUntil    *time.Time
+	SentAt        *time.Time `gorm:"index"`
+	FailedAt      *time.Time
+	CreatedAt     time.Time
+}
+
+// FireAt returns when the reminder is due, or false for an offset reminder on
+// a task without a due date.
+func (r *Reminder) FireAt(due *time.Time) (time.Time, bool) {
+	if r.RemindAt != nil {
+		return *r.RemindAt, true
+	}
+	if r.OffsetMinutes == nil || due == nil {
+		return time.Time{}, false
+	}
+	return due.Add(-time.Duration(*r.OffsetMinutes) * time.Minute), true
+}
+
+func (r *Reminder) Status() string {
+	switch {
+	case r.SentAt != nil:
+		return "sent"
+	case r.FailedAt != nil:
+		return "failed"
+	}
+	return "pending"
+}
+
+// Notification is what a notifier delivers when a reminder fires.
+type Notification struct {
+	ReminderID uuid.UUID
+	Channel    string
+	Target     string
+	Task       Task
+	FireAt     time.Time
+}
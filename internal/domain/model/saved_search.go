@@ -0,0 +1,27 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// SavedSearch is a named task filter expression that its owner runs as a
+// list. The expression is stored as written, so relative dates such as
+// due<+7d are resolved each time the list is run. System smart lists share the
+// type but are defined in code and identified by Key instead of ID.
+type SavedSearch struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_saved_searches_user_name,priority:1"`
+	Name      string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_saved_searches_user_name,priority:2"`
+	Filter    string    `gorm:"type:text;not null"`
+	Sort      string    `gorm:"type:varchar(20)"`
+	Desc      bool      `gorm:"column:sort_desc;not null"`
+	Key       string    `gorm:"-"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// System reports whether the list is one of the built-in smart lists.
+func (s SavedSearch) System() bool {
+	return s.Key != ""
+}
@@ -8,23 +8,63 @@ import (
 )
 
 type Task struct {
-	ID        uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
-	UserID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
-	Title     string         `gorm:"type:varchar(255);not null" json:"title"`
-	Content   string         `gorm:"type:text" json:"content"`
-	Status    string         `gorm:"type:varchar(50);default:'todo'" json:"status"`
-	Priority  string         `gorm:"type:varchar(50);default:'medium'" json:"priority"`
-	Tags      []Tag          `gorm:"many2many:task_tags;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"tags"`
-	DueDate   *time.Time     `json:"due_date"`
-	Archived  bool           `gorm:"default:false" json:"archived"`
+	ID          uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID      uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
+	ParentID    *uuid.UUID     `gorm:"type:uuid;index" json:"parent_id"`
+	ProjectID   *uuid.UUID     `gorm:"type:uuid;index" json:"project_id"`
+	WorkspaceID *uuid.UUID     `gorm:"type:uuid;index" json:"workspace_id"`
+	Title       string         `gorm:"type:varchar(255);not null" json:"title"`
+	Content     string         `gorm:"type:text" json:"content"`
+	Status      string         `gorm:"type:varchar(50);default:'todo'" json:"status"`
+	Priority    string         `gorm:"type:varchar(50);default:'medium'" json:"priority"`
+	Tags        []Tag          `gorm:"many2many:task_tags;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"tags"`
+	Assignees   []TaskAssignee `gorm:"foreignKey:TaskID" json:"assignees,omitempty"`
+	Watchers    []TaskWatcher  `gorm:"foreignKey:TaskID" json:"watchers,omitempty"`
+	DueDate     *time.Time     `json:"due_date"`
+	RRule       string         `gorm:"type:varchar(255)" json:"rrule,omitempty"`
+	Archived    bool           `gorm:"default:false" json:"archived"`
+	StartedAt   *time.Time     `json:"started_at"`
+	CompletedAt *time.Time     `json:"completed_at"`
+	// Version grows with every change to the task and backs its ETag.
+	Version   int64          `gorm:"not null;default:1" json:"version"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// CommentCount is computed when the task is read and never stored.
+	CommentCount int64 `gorm:"->;-:migration" json:"comment_count"`
+	// SearchRank, TitleHighlight and ContentSnippet describe how the task
+	// matched a full-text search and are empty everywhere else. Matched words
+	// are enclosed in HighlightStart and HighlightStop.
+	SearchRank     float64 `gorm:"->;-:migration" json:"-"`
+	TitleHighlight string  `gorm:"->;-:migration" json:"-"`
+	ContentSnippet string  `gorm:"->;-:migration" json:"-"`
 }
 
+// Markers around matched words in search highlights. They come from the
+// Unicode private use area, which ordinary text does not use.
+const (
+	HighlightStart = "\ue000"
+	HighlightStop  = "\ue001"
+)
+
 type Tag struct {
-	ID   uint   `gorm:"primaryKey" json:"id"`
-	Name string `gorm:"uniqueIndex;type:varchar(100)" json:"name"`
+	ID     uint      `gorm:"primaryKey" json:"id"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_tags_user_name,priority:1" json:"-"`
+	Name   string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_tags_user_name,priority:2" json:"name"`
+	Color  string    `gorm:"type:varchar(7)" json:"color,omitempty"`
+}
+
+var priorityRanks = map[string]int{
+	"low":    1,
+	"medium": 2,
+	"high":   3,
+	"urgent": 4,
+}
+
+// PriorityRank orders priorities from least to most important; unknown values rank lowest.
+func PriorityRank(priority string) int {
+	return priorityRanks[priority]
 }
 
 // BeforeCreate GORM hook to set UUID
@@ -32,5 +72,8 @@ func (t *Task) BeforeCreate(tx *gorm.DB) (err error) {
 	if t.ID == uuid.Nil {
 		t.ID = uuid.New()
 	}
+	if t.Version == 0 {
+		t.Version = 1
+	}
 	return nil
 }
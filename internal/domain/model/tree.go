@@ -0,0 +1,47 @@
+package model
+
+import "github.com/google/uuid"
+
+// TaskNode is a task with its nested subtasks. Done and Total count every
+// descendant, so a parent's progress rolls up the whole subtree.
+type TaskNode struct {
+	Task     Task
+	Children []*TaskNode
+	Done     int
+	Total    int
+}
+
+// BuildTaskTree assembles a flat subtree into nodes rooted at rootID, keeping
+// the input order for siblings.
+func BuildTaskTree(rootID uuid.UUID, tasks []Task) *TaskNode {
+	nodes := make(map[uuid.UUID]*TaskNode, len(tasks))
+	for _, t := range tasks {
+		nodes[t.ID] = &TaskNode{Task: t}
+	}
+	for _, t := range tasks {
+		if t.ID == rootID || t.ParentID == nil {
+			continue
+		}
+		if parent, ok := nodes[*t.ParentID]; ok {
+			parent.Children = append(parent.Children, nodes[t.ID])
+		}
+	}
+	root, ok := nodes[rootID]
+	if !ok {
+		return nil
+	}
+	root.rollUp()
+	return root
+}
+
+func (n *TaskNode) rollUp() {
+	n.Done, n.Total = 0, 0
+	for _, child := range n.Children {
+		child.rollUp()
+		n.Total += child.Total + 1
+		n.Done += child.Done
+		if child.Task.Status == "done" {
+			n.Done++
+		}
+	}
+}
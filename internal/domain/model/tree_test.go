@@ -0,0 +1,29 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestBuildTaskTree(t *testing.T) {
+	root, child, grandchild, sibling := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	tasks := []Task{
+		{ID: root, Status: "in_progress"},
+		{ID: child, ParentID: &root, Status: "todo"},
+		{ID: grandchild, ParentID: &child, Status: "done"},
+		{ID: sibling, ParentID: &root, Status: "done"},
+	}
+
+	tree := BuildTaskTree(root, tasks)
+	require.NotNil(t, tree)
+	require.Len(t, tree.Children, 2)
+	assert.Equal(t, child, tree.Children[0].Task.ID)
+	assert.Equal(t, 3, tree.Total)
+	assert.Equal(t, 2, tree.Done)
+	assert.Equal(t, 1, tree.Children[0].Total)
+	assert.Equal(t, 1, tree.Children[0].Done)
+
+	assert.Nil(t, BuildTaskTree(uuid.New(), tasks))
+}
@@ -9,5 +9,7 @@ type User struct {
 	ID           uuid.UUID `gorm:"type:uuid;primaryKey"`
 	Email        string    `gorm:"uniqueIndex;not null"`
 	PasswordHash string    `gorm:"not null"`
-	CreatedAt    time.Time
+	// Timezone is an IANA name such as Europe/Moscow; empty means UTC.
+	Timezone  string `gorm:"type:varchar(64);not null;default:''"`
+	CreatedAt time.Time
 }
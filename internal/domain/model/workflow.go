@@ -0,0 +1,129 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Statuses the system assigns on its own: new tasks start in todo, open
+// blockers move dependents to blocked, and completing a parent moves its
+// subtasks to done. Every workflow has to contain them.
+const (
+	StatusTodo       = "todo"
+	StatusInProgress = "in_progress"
+	StatusBlocked    = "blocked"
+	StatusDone       = "done"
+)
+
+var statusName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// ValidStatusName reports whether s could name a status in some workflow.
+func ValidStatusName(s string) bool {
+	return statusName.MatchString(s)
+}
+
+// Workflow lists the statuses tasks may take and the moves allowed between
+// them. Without Transitions any listed status may follow any other.
+type Workflow struct {
+	Statuses    []string            `json:"statuses"`
+	Transitions map[string][]string `json:"transitions,omitempty"`
+}
+
+// DefaultWorkflow applies to tasks whose project or workspace does not define
+// a workflow of its own.
+var DefaultWorkflow = Workflow{
+	Statuses: []string{StatusTodo, StatusInProgress, StatusBlocked, StatusDone},
+}
+
+// Has reports whether status is one of the workflow's statuses.
+func (w Workflow) Has(status string) bool {
+	for _, s := range w.Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Allowed lists the statuses a task may move to from status. A status the
+// workflow does not know, such as one left over from an earlier definition,
+// may move anywhere.
+func (w Workflow) Allowed(from string) []string {
+	if w.Transitions == nil || !w.Has(from) {
+		out := make([]string, 0, len(w.Statuses))
+		for _, s := range w.Statuses {
+			if s != from {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return append([]string{}, w.Transitions[from]...)
+}
+
+// CanMove reports whether a task may go from one status to another. Staying
+// put is always allowed.
+func (w Workflow) CanMove(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, s := range w.Allowed(from) {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that statuses are unique lower_snake_case names that
+// include the system statuses, and that transitions only mention them.
+func (w Workflow) Validate() error {
+	seen := make(map[string]bool, len(w.Statuses))
+	for _, s := range w.Statuses {
+		if !ValidStatusName(s) {
+			return fmt.Errorf("status %q must be lower_snake_case and at most 50 characters", s)
+		}
+		if seen[s] {
+			return fmt.Errorf("status %q is listed twice", s)
+		}
+		seen[s] = true
+	}
+	for _, s := range []string{StatusTodo, StatusBlocked, StatusDone} {
+		if !seen[s] {
+			return fmt.Errorf("statuses must include %q", s)
+		}
+	}
+	for from, targets := range w.Transitions {
+		if !seen[from] {
+			return fmt.Errorf("transition from unknown status %q", from)
+		}
+		for _, to := range targets {
+			if !seen[to] {
+				return fmt.Errorf("transition from %q to unknown status %q", from, to)
+			}
+		}
+	}
+	return nil
+}
+
+// SetStatus moves the task to status. StartedAt records when the task first
+// entered a working status, that is anything other than todo, blocked or
+// done; CompletedAt is set while the task is done.
+func (t *Task) SetStatus(status string, now time.Time) {
+	if status == t.Status {
+		return
+	}
+	t.Status = status
+	switch status {
+	case StatusDone:
+		t.CompletedAt = &now
+	case StatusTodo, StatusBlocked:
+		t.CompletedAt = nil
+	default:
+		t.CompletedAt = nil
+		if t.StartedAt == nil {
+			t.StartedAt = &now
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWorkflow(t *testing.T) {
+	review := Workflow{
+		Statuses: []string{"todo", "in_progress", "review", "blocked", "done"},
+		Transitions: map[string][]string{
+			"todo":        {"in_progress"},
+			"in_progress": {"review", "todo"},
+			"review":      {"done", "in_progress"},
+			"done":        {"todo"},
+		},
+	}
+	assert.NoError(t, review.Validate())
+	assert.True(t, review.CanMove("in_progress", "review"))
+	assert.True(t, review.CanMove("todo", "todo"))
+	assert.False(t, review.CanMove("todo", "done"))
+	assert.Equal(t, []string{"in_progress"}, review.Allowed("todo"))
+	// Статус из прежнего описания можно сменить на любой
+	assert.True(t, review.CanMove("legacy", "done"))
+
+	// Без переходов разрешено всё
+	assert.True(t, DefaultWorkflow.CanMove("done", "blocked"))
+	assert.False(t, DefaultWorkflow.CanMove("todo", "Done"))
+
+	for _, bad := range []Workflow{
+		{Statuses: []string{"todo", "done"}},
+		{Statuses: []string{"todo", "blocked", "done", "todo"}},
+		{Statuses: []string{"todo", "blocked", "done", "In Review"}},
+		{Statuses: []string{"todo", "blocked", "done"}, Transitions: map[string][]string{"todo": {"doing"}}},
+	} {
+		assert.Error(t, bad.Validate(), bad.Statuses)
+	}
+}
+
+func TestTask_SetStatus(t *testing.T) {
+	monday := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	tuesday := monday.AddDate(0, 0, 1)
+	var task Task
+
+	task.SetStatus(StatusTodo, monday)
+	assert.Nil(t, task.StartedAt)
+
+	task.SetStatus(StatusInProgress, monday)
+	task.SetStatus(StatusDone, tuesday)
+	assert.Equal(t, monday, *task.StartedAt)
+	assert.Equal(t, tuesday, *task.CompletedAt)
+
+	// Повторное начало работы не сдвигает started_at
+	task.SetStatus(StatusInProgress, tuesday)
+	assert.Equal(t, monday, *task.StartedAt)
+	assert.Nil(t, task.CompletedAt)
+}
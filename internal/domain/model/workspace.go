@@ -0,0 +1,83 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// ValidRole reports whether role is one of the workspace roles.
+func ValidRole(role string) bool {
+	return roleRanks[role] > 0
+}
+
+// RoleAllows reports whether role grants at least the rights of need.
+func RoleAllows(role, need string) bool {
+	return ValidRole(role) && roleRanks[role] >= roleRanks[need]
+}
+
+// Workspace shares its tasks with every member. Tasks outside a workspace stay
+// private to the user who created them.
+type Workspace struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name      string    `gorm:"type:varchar(100);not null"`
+	CreatedBy uuid.UUID `gorm:"type:uuid;not null"`
+	// Workflow overrides DefaultWorkflow for the workspace's tasks.
+	Workflow  *Workflow `gorm:"type:jsonb;serializer:json"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type WorkspaceMember struct {
+	WorkspaceID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID      uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	Role        string    `gorm:"type:varchar(20);not null"`
+	CreatedAt   time.Time
+}
+
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+	InvitationRevoked  = "revoked"
+)
+
+// WorkspaceInvitation offers a role in a workspace to whoever owns Email. The
+// token sent to the invitee is not stored; it is signed over ID and ExpiresAt.
+type WorkspaceInvitation struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	WorkspaceID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Email       string    `gorm:"type:varchar(255);not null"`
+	Role        string    `gorm:"type:varchar(20);not null"`
+	Status      string    `gorm:"type:varchar(20);not null;default:pending"`
+	InvitedBy   uuid.UUID `gorm:"type:uuid;not null"`
+	ExpiresAt   time.Time `gorm:"not null"`
+	RespondedAt *time.Time
+	CreatedAt   time.Time
+}
+
+// Open reports whether the invitation can still be accepted or declined.
+func (i WorkspaceInvitation) Open(now time.Time) bool {
+	return i.Status == InvitationPending && now.Before(i.ExpiresAt)
+}
+
+// InvitationMail is what a mailer delivers when someone is invited. Link is
+// either a URL carrying the token or the bare token.
+type InvitationMail struct {
+	To        string
+	Workspace string
+	Role      string
+	Link      string
+	ExpiresAt time.Time
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRoleAllows(t *testing.T) {
+	assert.True(t, RoleAllows(RoleOwner, RoleEditor))
+	assert.True(t, RoleAllows(RoleEditor, RoleEditor))
+	assert.False(t, RoleAllows(RoleViewer, RoleEditor))
+	assert.False(t, RoleAllows("admin", RoleViewer))
+}
+
+func TestWorkspaceInvitation_Open(t *testing.T) {
+	now := time.Now()
+	inv := WorkspaceInvitation{Status: InvitationPending, ExpiresAt: now.Add(time.Hour)}
+	assert.True(t, inv.Open(now))
+	assert.False(t, inv.Open(now.Add(2*time.Hour)))
+
+	inv.Status = InvitationRevoked
+	assert.False(t, inv.Open(now))
+}
@@ -0,0 +1,38 @@
+package netguard
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/netip"
+	"testing"
+)
+
+func TestPublicAddr(t *testing.T) {
+	for _, ip := range []string{"8.8.8.8", "93.184.216.34", "2606:4700:4700::1111"} {
+		assert.True(t, PublicAddr(netip.MustParseAddr(ip)), ip)
+	}
+	for _, ip := range []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"0.0.0.0", "100.64.0.1", "::1", "fe80::1", "fd00::1", "::ffff:127.0.0.1", "64:ff9b::a00:1",
+	} {
+		assert.False(t, PublicAddr(netip.MustParseAddr(ip)), ip)
+	}
+}
+
+func TestPublicURL(t *testing.T) {
+	for _, u := range []string{"https://example.com/hook", "http://8.8.8.8:8080/", "https://hooks.example.com."} {
+		assert.True(t, PublicURL(u), u)
+	}
+	for _, u := range []string{
+		"ftp://example.com", "https://", "http://localhost:8080", "http://127.0.0.1/",
+		"http://169.254.169.254/latest/meta-data", "http://[::1]/", "http://redis:6379",
+		"http://api.svc.internal/", "http://printer.local", "http://0x7f000001/",
+	} {
+		assert.False(t, PublicURL(u), u)
+	}
+}
+
+func TestControl(t *testing.T) {
+	assert.NoError(t, Control("tcp4", "8.8.8.8:443", nil))
+	assert.ErrorIs(t, Control("tcp4", "10.0.0.5:80", nil), ErrNotPublic)
+	assert.ErrorIs(t, Control("tcp6", "[::1]:80", nil), ErrNotPublic)
+}
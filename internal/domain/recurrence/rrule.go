@@ -0,0 +1,486 @@
+// Package recurrence implements the subset of RFC 5545 recurrence rules used
+// for repeating tasks: FREQ, INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY,
+// BYMONTH and WKST.
+package recurrence
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"todo-list/internal/domain/apperr"
+)
+
+var ErrInvalidRule = apperr.New(apperr.Invalid, "invalid recurrence rule")
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// maxPeriods bounds the search for rules that rarely or never match,
+// e.g. FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30.
+const maxPeriods = 50000
+
+// WeekdayNum is a BYDAY entry such as MO, 2TU or -1FR. N is zero when the
+// entry applies to every such weekday in the period.
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int
+}
+
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+	WeekStart  time.Weekday
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidRule, fmt.Sprintf(format, args...))
+}
+
+// Parse reads an RRULE value, with or without the "RRULE:" prefix.
+func Parse(s string) (Rule, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 6 && strings.EqualFold(s[:6], "RRULE:") {
+		s = s[6:]
+	}
+	r := Rule{Interval: 1, WeekStart: time.Monday}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || value == "" {
+			return Rule{}, invalid("malformed part %q", part)
+		}
+		if seen[key] {
+			return Rule{}, invalid("%s given more than once", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			r.Freq = Frequency(value)
+			if r.Freq != Daily && r.Freq != Weekly && r.Freq != Monthly && r.Freq != Yearly {
+				return Rule{}, invalid("unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			r.Interval, err = positiveInt(value)
+		case "COUNT":
+			r.Count, err = positiveInt(value)
+		case "UNTIL":
+			var until time.Time
+			until, err = parseUntil(value)
+			r.Until = &until
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseIntList(value, func(n int) bool { return n != 0 && n >= -31 && n <= 31 })
+		case "BYMONTH":
+			var months []int
+			months, err = parseIntList(value, func(n int) bool { return n >= 1 && n <= 12 })
+			for _, m := range months {
+				r.ByMonth = append(r.ByMonth, time.Month(m))
+			}
+		case "WKST":
+			wd, ok := weekdayCodes[value]
+			if !ok {
+				return Rule{}, invalid("bad WKST %q", value)
+			}
+			r.WeekStart = wd
+		default:
+			return Rule{}, invalid("unsupported part %s", key)
+		}
+		if err != nil {
+			return Rule{}, invalid("%s: %v", key, err)
+		}
+	}
+	if r.Freq == "" {
+		return Rule{}, invalid("FREQ is required")
+	}
+	if r.Count > 0 && r.Until != nil {
+		return Rule{}, invalid("COUNT and UNTIL are mutually exclusive")
+	}
+	if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+		return Rule{}, invalid("BYMONTHDAY cannot be used with FREQ=WEEKLY")
+	}
+	if r.Freq == Daily || r.Freq == Weekly {
+		for _, d := range r.ByDay {
+			if d.N != 0 {
+				return Rule{}, invalid("numbered BYDAY requires FREQ=MONTHLY or FREQ=YEARLY")
+			}
+		}
+	}
+	return r, nil
+}
+
+func positiveInt(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%q is not a positive integer", s)
+	}
+	return n, nil
+}
+
+func parseIntList(s string, valid func(int) bool) ([]int, error) {
+	var out []int
+	for _, item := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil || !valid(n) {
+			return nil, fmt.Errorf("bad value %q", item)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func parseByDay(s string) ([]WeekdayNum, error) {
+	var out []WeekdayNum
+	for _, item := range strings.Split(s, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("bad weekday %q", item)
+		}
+		wd, ok := weekdayCodes[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("bad weekday %q", item)
+		}
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			if n, err = strconv.Atoi(prefix); err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("bad weekday %q", item)
+			}
+		}
+		out = append(out, WeekdayNum{Weekday: wd, N: n})
+	}
+	return out, nil
+}
+
+// parseUntil accepts a UTC date-time, a floating date-time (read as UTC) or a
+// date, which includes the whole day.
+func parseUntil(s string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("20060102T150405", s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("20060102", s); err == nil {
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("bad date %q", s)
+}
+
+// String renders the rule in canonical form, without the "RRULE:" prefix.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByMonth) > 0 {
+		items := make([]string, len(r.ByMonth))
+		for i, m := range r.ByMonth {
+			items[i] = strconv.Itoa(int(m))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(items, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		items := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			items[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(items, ","))
+	}
+	if len(r.ByDay) > 0 {
+		items := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			items[i] = weekdayCode(d.Weekday)
+			if d.N != 0 {
+				items[i] = strconv.Itoa(d.N) + items[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(items, ","))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayCode(r.WeekStart))
+	}
+	return strings.Join(parts, ";")
+}
+
+func weekdayCode(wd time.Weekday) string {
+	return strings.ToUpper(wd.String()[:2])
+}
+
+// Between returns occurrences in [from, to), at most limit of them when limit
+// is positive. dtstart is always the first occurrence, as in RFC 5545.
+func (r Rule) Between(dtstart, from, to time.Time, limit int) []time.Time {
+	var out []time.Time
+	r.iterate(dtstart, func(t time.Time) bool {
+		if !t.Before(to) {
+			return false
+		}
+		if !t.Before(from) {
+			out = append(out, t)
+		}
+		return limit <= 0 || len(out) < limit
+	})
+	return out
+}
+
+// After returns the first occurrence strictly after t.
+func (r Rule) After(dtstart, t time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+	r.iterate(dtstart, func(o time.Time) bool {
+		if o.After(t) {
+			next, found = o, true
+			return false
+		}
+		return true
+	})
+	return next, found
+}
+
+func (r Rule) iterate(dtstart time.Time, yield func(time.Time) bool) {
+	if r.Until != nil && dtstart.After(*r.Until) {
+		return
+	}
+	if !yield(dtstart) || r.Count == 1 {
+		return
+	}
+	count := 1
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	period := r.periodStart(dtstart)
+	for i := 0; i < maxPeriods; i++ {
+		for _, c := range r.expand(period, dtstart) {
+			if !c.After(dtstart) {
+				continue
+			}
+			if r.Until != nil && c.After(*r.Until) {
+				return
+			}
+			count++
+			if !yield(c) || (r.Count > 0 && count >= r.Count) {
+				return
+			}
+		}
+		switch r.Freq {
+		case Daily:
+			period = period.AddDate(0, 0, interval)
+		case Weekly:
+			period = period.AddDate(0, 0, 7*interval)
+		case Monthly:
+			period = period.AddDate(0, interval, 0)
+		default:
+			period = period.AddDate(interval, 0, 0)
+		}
+	}
+}
+
+// periodStart returns the first day of the period containing dtstart, at
+// dtstart's time of day.
+func (r Rule) periodStart(dtstart time.Time) time.Time {
+	y, m, d := dtstart.Date()
+	switch r.Freq {
+	case Weekly:
+		offset := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		return at(dtstart, y, m, d-offset)
+	case Monthly:
+		return at(dtstart, y, m, 1)
+	case Yearly:
+		return at(dtstart, y, time.January, 1)
+	}
+	return at(dtstart, y, m, d)
+}
+
+// at builds a date in dtstart's location at dtstart's time of day.
+func at(dtstart time.Time, y int, m time.Month, d int) time.Time {
+	h, min, s := dtstart.Clock()
+	return time.Date(y, m, d, h, min, s, dtstart.Nanosecond(), dtstart.Location())
+}
+
+// expand lists the candidate occurrences of one period in ascending order.
+func (r Rule) expand(period, dtstart time.Time) []time.Time {
+	var out []time.Time
+	switch r.Freq {
+	case Daily:
+		if r.matchMonth(period.Month()) && r.matchMonthDay(period) && r.matchWeekday(period) {
+			out = append(out, period)
+		}
+	case Weekly:
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []WeekdayNum{{Weekday: dtstart.Weekday()}}
+		}
+		for i := 0; i < 7; i++ {
+			day := period.AddDate(0, 0, i)
+			if !r.matchMonth(day.Month()) {
+				continue
+			}
+			for _, wd := range days {
+				if wd.Weekday == day.Weekday() {
+					out = append(out, day)
+					break
+				}
+			}
+		}
+	case Monthly:
+		if r.matchMonth(period.Month()) {
+			out = r.monthDays(period, dtstart)
+		}
+	case Yearly:
+		months := r.ByMonth
+		if len(months) == 0 {
+			switch {
+			case len(r.ByMonthDay) > 0:
+				months = []time.Month{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+			case len(r.ByDay) > 0:
+				yearEnd := period.AddDate(1, 0, 0)
+				out = weekdaysIn(period, daysBetween(period, yearEnd), r.ByDay)
+			default:
+				months = []time.Month{dtstart.Month()}
+			}
+		}
+		for _, m := range months {
+			out = append(out, r.monthDays(at(dtstart, period.Year(), m, 1), dtstart)...)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return dedupe(out)
+}
+
+// monthDays expands BYMONTHDAY / BYDAY within the month starting at first.
+// Months without dtstart's day (e.g. the 31st) are skipped, per RFC 5545.
+func (r Rule) monthDays(first, dtstart time.Time) []time.Time {
+	last := daysBetween(first, first.AddDate(0, 1, 0))
+	var out []time.Time
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, md := range r.ByMonthDay {
+			d := md
+			if d < 0 {
+				d = last + md + 1
+			}
+			if d < 1 || d > last {
+				continue
+			}
+			day := first.AddDate(0, 0, d-1)
+			if r.matchWeekday(day) {
+				out = append(out, day)
+			}
+		}
+	case len(r.ByDay) > 0:
+		out = weekdaysIn(first, last, r.ByDay)
+	default:
+		if d := dtstart.Day(); d <= last {
+			out = append(out, first.AddDate(0, 0, d-1))
+		}
+	}
+	return out
+}
+
+// weekdaysIn expands BYDAY entries over n days starting at start; numbered
+// entries pick the Nth (or Nth from last) matching weekday.
+func weekdaysIn(start time.Time, n int, byDay []WeekdayNum) []time.Time {
+	var out []time.Time
+	for _, wd := range byDay {
+		var matches []time.Time
+		offset := (int(wd.Weekday) - int(start.Weekday()) + 7) % 7
+		for d := offset; d < n; d += 7 {
+			matches = append(matches, start.AddDate(0, 0, d))
+		}
+		switch {
+		case wd.N == 0:
+			out = append(out, matches...)
+		case wd.N > 0 && wd.N <= len(matches):
+			out = append(out, matches[wd.N-1])
+		case wd.N < 0 && -wd.N <= len(matches):
+			out = append(out, matches[len(matches)+wd.N])
+		}
+	}
+	return out
+}
+
+func daysBetween(from, to time.Time) int {
+	y1, m1, d1 := from.Date()
+	y2, m2, d2 := to.Date()
+	a := time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)
+	b := time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+func (r Rule) matchMonth(m time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, bm := range r.ByMonth {
+		if bm == m {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) matchMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	last := daysBetween(at(day, day.Year(), day.Month(), 1), at(day, day.Year(), day.Month()+1, 1))
+	for _, md := range r.ByMonthDay {
+		if md == day.Day() || last+md+1 == day.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) matchWeekday(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Weekday == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func dedupe(times []time.Time) []time.Time {
+	out := times[:0]
+	for i, t := range times {
+		if i == 0 || !t.Equal(times[i-1]) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
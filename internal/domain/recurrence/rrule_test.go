@@ -0,0 +1,79 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dates(ts []time.Time) []string {
+	out := make([]string, len(ts))
+	for i, t := range ts {
+		out[i] = t.Format("2006-01-02")
+	}
+	return out
+}
+
+func TestParse(t *testing.T) {
+	r, err := Parse("RRULE:freq=weekly;interval=2;byday=MO,FR;count=4")
+	require.NoError(t, err)
+	assert.Equal(t, Weekly, r.Freq)
+	assert.Equal(t, 2, r.Interval)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=MO,FR", r.String())
+
+	for _, bad := range []string{"", "INTERVAL=2", "FREQ=HOURLY", "FREQ=DAILY;COUNT=0", "FREQ=DAILY;COUNT=2;UNTIL=20261231",
+		"FREQ=WEEKLY;BYDAY=1MO", "FREQ=MONTHLY;BYSETPOS=1", "FREQ=MONTHLY;BYMONTHDAY=32"} {
+		_, err := Parse(bad)
+		assert.ErrorIs(t, err, ErrInvalidRule, bad)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC) // понедельник
+	far := start.AddDate(5, 0, 0)
+
+	t.Run("Weekly_ByDay", func(t *testing.T) {
+		r, _ := Parse("FREQ=WEEKLY;BYDAY=MO,WE;COUNT=5")
+		got := r.Between(start, start, far, 0)
+		assert.Equal(t, []string{"2026-01-05", "2026-01-07", "2026-01-12", "2026-01-14", "2026-01-19"}, dates(got))
+		assert.Equal(t, 9, got[1].Hour())
+	})
+
+	t.Run("Monthly_SkipsShortMonths", func(t *testing.T) {
+		r, _ := Parse("FREQ=MONTHLY;COUNT=3")
+		got := r.Between(time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC), start, far, 0)
+		assert.Equal(t, []string{"2026-01-31", "2026-03-31", "2026-05-31"}, dates(got))
+	})
+
+	t.Run("Monthly_LastFriday", func(t *testing.T) {
+		r, _ := Parse("FREQ=MONTHLY;BYDAY=-1FR")
+		got := r.Between(time.Date(2026, 1, 30, 0, 0, 0, 0, time.UTC), start, far, 3)
+		assert.Equal(t, []string{"2026-01-30", "2026-02-27", "2026-03-27"}, dates(got))
+	})
+
+	t.Run("Until_And_Range", func(t *testing.T) {
+		r, _ := Parse("FREQ=DAILY;INTERVAL=3;UNTIL=20260115")
+		got := r.Between(start, start.AddDate(0, 0, 1), far, 0)
+		assert.Equal(t, []string{"2026-01-08", "2026-01-11", "2026-01-14"}, dates(got))
+	})
+
+	t.Run("Yearly_LeapDay", func(t *testing.T) {
+		r, _ := Parse("FREQ=YEARLY")
+		got := r.Between(time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC), start, start.AddDate(10, 0, 0), 0)
+		assert.Equal(t, []string{"2028-02-29", "2032-02-29"}, dates(got))
+	})
+}
+
+func TestAfter(t *testing.T) {
+	r, _ := Parse("FREQ=MONTHLY;BYMONTHDAY=1,15")
+	start := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	next, ok := r.After(start, start)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC), next)
+
+	r, _ = Parse("FREQ=DAILY;COUNT=1")
+	_, ok = r.After(start, start)
+	assert.False(t, ok)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/model"
+)
+
+var ErrAttachmentNotFound = apperr.New(apperr.NotFound, "attachment not found")
+
+type AttachmentRepository interface {
+	// Create passes fits the uploader's current usage and stores the
+	// attachment unless fits fails. Uploads by the same user are serialised,
+	// so two of them cannot both squeeze under a quota.
+	Create(ctx context.Context, a *model.Attachment, fits func(used int64) error) error
+	GetByID(ctx context.Context, id string, taskID string) (model.Attachment, error)
+	ListByTask(ctx context.Context, taskID string) ([]model.Attachment, error)
+	Delete(ctx context.Context, id string, taskID string) error
+
+	// UsageByUser sums the sizes of everything the user has uploaded.
+	UsageByUser(ctx context.Context, userID string) (int64, error)
+	// Orphans returns attachments whose task row no longer exists at all;
+	// soft-deleted tasks keep their files until they are purged.
+	Orphans(ctx context.Context, limit int) ([]model.Attachment, error)
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/model"
+)
+
+var ErrCommentNotFound = apperr.New(apperr.NotFound, "comment not found")
+
+// MentionCandidate is a user who can see a task and so can be mentioned on it.
+type MentionCandidate struct {
+	UserID uuid.UUID
+	Email  string
+}
+
+type CommentRepository interface {
+	// Create stores the comment together with its mentions.
+	Create(ctx context.Context, comment *model.Comment) error
+	GetByID(ctx context.Context, id string, taskID string) (model.Comment, error)
+	ListByTask(ctx context.Context, taskID string) ([]model.Comment, error)
+	// Update saves the body and replaces the mentions.
+	Update(ctx context.Context, comment *model.Comment) error
+	Delete(ctx context.Context, id string, taskID string) error
+
+	// MentionCandidates lists the task's owner, or every member of its workspace.
+	MentionCandidates(ctx context.Context, task model.Task) ([]MentionCandidate, error)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// TaskFilter is a conjunction of conditions on a user's tasks. Values inside
+// one slice are alternatives (OR); every group in TagGroups must match.
+type TaskFilter struct {
+	Statuses          []string
+	ExcludeStatuses   []string
+	Priorities        []string
+	ExcludePriorities []string
+	TagGroups         [][]string
+	ExcludeTags       []string
+	DueFrom           *time.Time // inclusive
+	DueBefore         *time.Time // exclusive
+	HasDueDate        *bool
+	Archived          *bool
+	Terms             []string // full-text, every word has to match
+	ProjectID         *uuid.UUID
+	HasProject        *bool
+	WorkspaceID       *uuid.UUID
+	AssigneeID        *uuid.UUID
+	WatcherID         *uuid.UUID
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/model"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+const (
+	SortCreatedAt = "created_at"
+	SortUpdatedAt = "updated_at"
+	SortDueDate   = "due_date"
+	SortPriority  = "priority"
+	SortTitle     = "title"
+	// SortDeletedAt is only meaningful for the trash and is the default there.
+	SortDeletedAt = "deleted_at"
+	// SortRelevance ranks full-text matches and is the default when a list
+	// is searched; lists without search text reject it.
+	SortRelevance = "relevance"
+)
+
+var (
+	ErrInvalidCursor = apperr.New(apperr.Invalid, "invalid cursor")
+	ErrInvalidSort   = apperr.New(apperr.Invalid, "invalid sort field")
+)
+
+// PageRequest describes one page of a keyset-paginated list. Cursor is the
+// opaque value returned as NextCursor by the previous page and is only valid
+// together with the same Sort and Desc.
+type PageRequest struct {
+	Limit  int
+	Cursor string
+	Sort   string
+	Desc   bool
+}
+
+type TaskPage struct {
+	Tasks      []model.Task
+	NextCursor string
+}
+
+func ValidSort(sort string) bool {
+	switch sort {
+	case "", SortCreatedAt, SortUpdatedAt, SortDueDate, SortPriority, SortTitle, SortRelevance:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/model"
+)
+
+var (
+	ErrProjectNotFound = apperr.New(apperr.NotFound, "project not found")
+	ErrProjectExists   = apperr.New(apperr.Conflict, "project with this name already exists")
+	ErrProjectOrder    = apperr.New(apperr.Invalid, "order must list every project exactly once")
+)
+
+type ProjectRepository interface {
+	// Create appends the project after the user's existing ones.
+	Create(ctx context.Context, project *model.Project) error
+	GetByID(ctx context.Context, id string, userID string) (model.Project, error)
+	List(ctx context.Context, userID string, includeArchived bool) ([]model.Project, error)
+	Update(ctx context.Context, project *model.Project) error
+	// Delete removes the project; its tasks move back to the inbox.
+	Delete(ctx context.Context, id string, userID string) error
+	// Reorder sets positions following ids, which must list every project of the user.
+	Reorder(ctx context.Context, userID string, ids []string) error
+	Stats(ctx context.Context, id string, userID string) (map[string]int64, error)
+
+	// MoveTasks moves the tasks with their subtasks to the project, or to the
+	// inbox when projectID is nil, and returns the ids of every moved task.
+	MoveTasks(ctx context.Context, ids []string, projectID *string, userID string) ([]uuid.UUID, error)
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/model"
+)
+
+var ErrReminderNotFound = apperr.New(apperr.NotFound, "reminder not found")
+
+// DueReminder is a claimed reminder together with what is needed to deliver it.
+type DueReminder struct {
+	Reminder model.Reminder
+	Task     model.Task
+	Email    string
+}
+
+type ReminderRepository interface {
+	Create(ctx context.Context, reminder *model.Reminder) error
+	ListByTask(ctx context.Context, taskID string, userID string) ([]model.Reminder, error)
+	Delete(ctx context.Context, id string, taskID string, userID string) error
+
+	// ClaimDue leases up to limit due reminders until now+lease so that other
+	// instances skip them, and counts the attempt.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]DueReminder, error)
+	MarkSent(ctx context.Context, id uuid.UUID, at time.Time) error
+	// MarkFailed records a failed attempt; a nil retryAt gives up for good.
+	MarkFailed(ctx context.Context, id uuid.UUID, reason string, retryAt *time.Time) error
+}
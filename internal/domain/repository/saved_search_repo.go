@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/model"
+)
+
+var (
+	ErrSavedSearchNotFound = apperr.New(apperr.NotFound, "saved search not found")
+	ErrSavedSearchExists   = apperr.New(apperr.Conflict, "saved search with this name already exists")
+)
+
+type SavedSearchRepository interface {
+	List(ctx context.Context, userID string) ([]model.SavedSearch, error)
+	GetByID(ctx context.Context, id string, userID string) (model.SavedSearch, error)
+	Create(ctx context.Context, search *model.SavedSearch) error
+	Update(ctx context.Context, search *model.SavedSearch) error
+	Delete(ctx context.Context, id string, userID string) error
+}
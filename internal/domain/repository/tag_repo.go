@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/model"
+)
+
+var (
+	ErrTagNotFound = apperr.New(apperr.NotFound, "tag not found")
+	ErrTagExists   = apperr.New(apperr.Conflict, "tag with this name already exists")
+)
+
+type TagUsage struct {
+	Tag       model.Tag
+	TaskCount int64
+}
+
+type TagRepository interface {
+	List(ctx context.Context, userID string) ([]TagUsage, error)
+	GetByID(ctx context.Context, id uint, userID string) (model.Tag, error)
+	Create(ctx context.Context, tag *model.Tag) error
+	// Update, Merge and Delete record the change in the history of every
+	// task that shows the tag's name and return those tasks' IDs.
+	Update(ctx context.Context, tag *model.Tag) ([]uuid.UUID, error)
+	Merge(ctx context.Context, sourceID, targetID uint, userID string) ([]uuid.UUID, error)
+	Delete(ctx context.Context, id uint, userID string) ([]uuid.UUID, error)
+}
@@ -2,28 +2,86 @@ package repository
 
 import (
 	"context"
+	"github.com/google/uuid"
+	"time"
+	"todo-list/internal/domain/apperr"
 	"todo-list/internal/domain/model"
 )
 
+var (
+	ErrParentNotFound  = apperr.New(apperr.NotFound, "parent task not found")
+	ErrHierarchyCycle  = apperr.New(apperr.Invalid, "task cannot be moved under itself or its own subtask")
+	ErrDependencyCycle = apperr.New(apperr.Invalid, "dependency would create a cycle")
+	ErrAssigneeAccess  = apperr.New(apperr.Invalid, "assignee cannot access this task")
+	ErrVersionConflict = apperr.New(apperr.Precondition, "task was changed by someone else")
+)
+
 type TaskRepository interface {
 	Create(ctx context.Context, task *model.Task) error
-	GetAll(ctx context.Context, userID string) ([]model.Task, error)
+	GetAll(ctx context.Context, userID string, page PageRequest) (TaskPage, error)
+	List(ctx context.Context, userID string, filter TaskFilter, page PageRequest) (TaskPage, error)
 	GetByID(ctx context.Context, id string, userID string) (model.Task, error)
-	Update(ctx context.Context, task *model.Task) error
+	// Update records what changed in the task's history, attributed to userID.
+	// It fails with ErrVersionConflict unless task.Version is still current
+	// and leaves the new version in task.Version.
+	Update(ctx context.Context, task *model.Task, userID string) error
+	// UpdateFields is Update limited to the given columns.
+	UpdateFields(ctx context.Context, task *model.Task, fields []string, userID string) error
+	// Complete is Update, or UpdateFields when fields is not nil, for a task
+	// that has just been moved to done. In the same transaction it completes
+	// the task's open subtasks and creates next, the following occurrence of a
+	// recurring task, unless it is nil.
+	Complete(ctx context.Context, task *model.Task, fields []string, next *model.Task, userID string) error
 	Delete(ctx context.Context, id string, userID string) error
 
-	FindByStatus(ctx context.Context, status string, userID string) ([]model.Task, error)
-	FindByPriority(ctx context.Context, priority string, userID string) ([]model.Task, error)
-	FindByTag(ctx context.Context, tag string, userID string) ([]model.Task, error)
-	Search(ctx context.Context, q string, userID string) ([]model.Task, error)
-	GetToday(ctx context.Context, userID string) ([]model.Task, error)
-	GetOverdue(ctx context.Context, userID string) ([]model.Task, error)
+	FindByStatus(ctx context.Context, status string, userID string, page PageRequest) (TaskPage, error)
+	FindByPriority(ctx context.Context, priority string, userID string, page PageRequest) (TaskPage, error)
+	FindByTag(ctx context.Context, tag string, userID string, page PageRequest) (TaskPage, error)
+	Search(ctx context.Context, q string, userID string, page PageRequest) (TaskPage, error)
 
 	AddTag(ctx context.Context, id string, tag string, userID string) (model.Task, error)
 	RemoveTag(ctx context.Context, id string, tag string, userID string) (model.Task, error)
-	BulkDelete(ctx context.Context, ids []string, userID string) error
-	BulkUpdateStatus(ctx context.Context, ids []string, status string, userID string) error
+	// AddAssignee refuses users who cannot see the task.
+	AddAssignee(ctx context.Context, id, assigneeID string, userID string) (model.Task, error)
+	RemoveAssignee(ctx context.Context, id, assigneeID string, userID string) (model.Task, error)
+	AddWatcher(ctx context.Context, id string, userID string) (model.Task, error)
+	RemoveWatcher(ctx context.Context, id string, userID string) (model.Task, error)
+	// DeleteMany deletes the tasks with their subtasks and returns the ids
+	// among ids it deleted.
+	DeleteMany(ctx context.Context, ids []string, userID string) ([]uuid.UUID, error)
+	// UpdateStatus moves the tasks to status, completing the open subtasks of
+	// tasks moved to done, and returns the ids among ids whose status changed.
+	// check sees every visible task under its row lock before anything is
+	// written; an error from it aborts the whole change.
+	UpdateStatus(ctx context.Context, ids []string, status string, userID string, check func(model.Task) error) ([]uuid.UUID, error)
 	Archive(ctx context.Context, id string, userID string) (model.Task, error)
 	Unarchive(ctx context.Context, id string, userID string) (model.Task, error)
 	Stats(ctx context.Context, userID string) (map[string]int64, error)
+	// Workflow returns the workflow defined on the project, or failing that
+	// on the workspace; nil when neither defines one.
+	Workflow(ctx context.Context, projectID, workspaceID *uuid.UUID) (*model.Workflow, error)
+
+	// GetSubtree returns the task together with all of its descendants.
+	GetSubtree(ctx context.Context, id string, userID string) ([]model.Task, error)
+	Move(ctx context.Context, id string, parentID *string, userID string) (model.Task, error)
+
+	AddDependency(ctx context.Context, blockerID, blockedID string, userID string) error
+	RemoveDependency(ctx context.Context, blockerID, blockedID string, userID string) error
+	GetDependencyGraph(ctx context.Context, id string, userID string) (model.DependencyGraph, error)
+
+	// ListRevisions returns the task's recorded changes, newest first.
+	ListRevisions(ctx context.Context, id string, userID string) ([]model.TaskRevision, error)
+
+	// ListTrash returns deleted tasks that were not deleted together with
+	// their parent; restoring one brings its subtree back with it.
+	ListTrash(ctx context.Context, userID string, page PageRequest) (TaskPage, error)
+	GetDeleted(ctx context.Context, id string, userID string) (model.Task, error)
+	// Restore undeletes the tasks and the descendants deleted with them. A
+	// task whose parent is still deleted is moved to the top level.
+	Restore(ctx context.Context, ids []string, userID string) error
+	// Purge erases deleted tasks and their deleted descendants for good.
+	Purge(ctx context.Context, ids []string, userID string) error
+	// PurgeDeletedBefore erases up to limit tasks of any user deleted before
+	// the given time and reports how many it removed.
+	PurgeDeletedBefore(ctx context.Context, before time.Time, limit int) (int64, error)
 }
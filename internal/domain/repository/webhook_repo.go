@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/model"
+)
+
+var (
+	ErrWebhookNotFound  = apperr.New(apperr.NotFound, "webhook not found")
+	ErrDeliveryNotFound = apperr.New(apperr.NotFound, "webhook delivery not found")
+)
+
+// PendingDelivery is a claimed delivery together with where and how to send it.
+type PendingDelivery struct {
+	Delivery model.WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, sub *model.WebhookSubscription) error
+	GetByID(ctx context.Context, id string, userID string) (model.WebhookSubscription, error)
+	List(ctx context.Context, userID string) ([]model.WebhookSubscription, error)
+	ListActive(ctx context.Context, userID string) ([]model.WebhookSubscription, error)
+	Update(ctx context.Context, sub *model.WebhookSubscription) error
+	// Delete removes the subscription together with its delivery log.
+	Delete(ctx context.Context, id string, userID string) error
+
+	CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, subscriptionID string, userID string, limit int) ([]model.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, id string, subscriptionID string, userID string) (model.WebhookDelivery, error)
+
+	// ClaimDeliveries leases up to limit pending deliveries of active
+	// subscriptions until now+lease and counts the attempt.
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]PendingDelivery, error)
+	MarkDelivered(ctx context.Context, id uuid.UUID, statusCode int, at time.Time) error
+	// MarkDeliveryFailed records a failed attempt; a nil retryAt gives up for good.
+	MarkDeliveryFailed(ctx context.Context, id uuid.UUID, statusCode int, reason string, retryAt *time.Time) error
+}
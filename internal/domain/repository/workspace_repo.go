@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/model"
+)
+
+var (
+	ErrWorkspaceNotFound = apperr.New(apperr.NotFound, "workspace not found")
+	ErrMemberNotFound    = apperr.New(apperr.NotFound, "workspace member not found")
+	ErrMemberExists      = apperr.New(apperr.Conflict, "user is already a member of this workspace")
+	ErrUserNotFound      = apperr.New(apperr.NotFound, "no user with this email")
+	ErrLastOwner         = apperr.New(apperr.Conflict, "workspace must keep at least one owner")
+	ErrCrossWorkspace    = apperr.New(apperr.Invalid, "tasks belong to different workspaces")
+
+	ErrInvitationNotFound = apperr.New(apperr.NotFound, "invitation not found")
+	ErrInvitationClosed   = apperr.New(apperr.Gone, "invitation has expired or was already answered")
+	ErrInvitationEmail    = apperr.New(apperr.Forbidden, "invitation was sent to a different email address")
+)
+
+// WorkspaceSummary is a workspace as seen by one of its members.
+type WorkspaceSummary struct {
+	model.Workspace
+	Role        string
+	MemberCount int64
+}
+
+type Member struct {
+	model.WorkspaceMember
+	Email string
+}
+
+type WorkspaceRepository interface {
+	// Create stores the workspace and makes its creator the owner.
+	Create(ctx context.Context, ws *model.Workspace) error
+	// GetByID returns the workspace only to its members.
+	GetByID(ctx context.Context, id string, userID string) (WorkspaceSummary, error)
+	List(ctx context.Context, userID string) ([]WorkspaceSummary, error)
+	Update(ctx context.Context, ws *model.Workspace) error
+	// Delete removes the workspace together with its tasks.
+	Delete(ctx context.Context, id string) error
+
+	// Role returns the user's role, or ErrWorkspaceNotFound for non-members.
+	Role(ctx context.Context, id string, userID string) (string, error)
+	ListMembers(ctx context.Context, id string) ([]Member, error)
+	AddMember(ctx context.Context, id, email, role string) (Member, error)
+	UpdateMemberRole(ctx context.Context, id, userID, role string) (Member, error)
+	// RemoveMember refuses to remove the last owner.
+	RemoveMember(ctx context.Context, id, userID string) error
+
+	// CreateInvitation revokes any open invitation for the same email first.
+	CreateInvitation(ctx context.Context, inv *model.WorkspaceInvitation) error
+	GetInvitation(ctx context.Context, invitationID string) (model.WorkspaceInvitation, error)
+	// ListInvitations returns the workspace's open invitations.
+	ListInvitations(ctx context.Context, id string) ([]model.WorkspaceInvitation, error)
+	RevokeInvitation(ctx context.Context, id, invitationID string) error
+	// AcceptInvitation adds the user as a member if their email matches the
+	// invitation and it is still open.
+	AcceptInvitation(ctx context.Context, invitationID, userID string) (Member, error)
+	DeclineInvitation(ctx context.Context, invitationID string) error
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"todo-list/internal/domain/event"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/domain/usertime"
+)
+
+func isAssigned(task model.Task, userID string) bool {
+	for _, a := range task.Assignees {
+		if a.UserID.String() == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// publishAssignment also tells the affected user, who is no longer among the
+// task's assignees once unassigned, so their own webhooks and streams hear
+// about it too.
+func (s *taskServiceImpl) publishAssignment(ctx context.Context, t event.Type, task model.Task, userID, assigneeID string) {
+	data := map[string]interface{}{"task": task, "assignee_id": assigneeID, "by": userID}
+	assignee, _ := uuid.Parse(assigneeID)
+	s.publish(ctx, t, task, userID, data, assignee)
+}
+
+func (s *taskServiceImpl) AssignTask(ctx context.Context, id, userID, assigneeID string, version int64) (model.Task, error) {
+	task, err := s.editableAt(ctx, id, userID, version)
+	if err != nil {
+		return model.Task{}, err
+	}
+	if _, err := uuid.Parse(assigneeID); err != nil {
+		return model.Task{}, repository.ErrAssigneeAccess
+	}
+	if isAssigned(task, assigneeID) {
+		return task, nil
+	}
+	task, err = s.repo.AddAssignee(ctx, id, assigneeID, userID)
+	if err != nil {
+		return model.Task{}, err
+	}
+	s.publishAssignment(ctx, event.TaskAssigned, task, userID, assigneeID)
+	return task, nil
+}
+
+// UnassignTask needs editor rights unless users take themselves off a task.
+func (s *taskServiceImpl) UnassignTask(ctx context.Context, id, userID, assigneeID string, version int64) (model.Task, error) {
+	task, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return model.Task{}, err
+	}
+	if err := checkVersion(task, version); err != nil {
+		return model.Task{}, err
+	}
+	if assigneeID != userID {
+		if err := s.authorize(ctx, task.WorkspaceID, userID, model.RoleEditor); err != nil {
+			return model.Task{}, err
+		}
+	}
+	if !isAssigned(task, assigneeID) {
+		return task, nil
+	}
+	task, err = s.repo.RemoveAssignee(ctx, id, assigneeID, userID)
+	if err != nil {
+		return model.Task{}, err
+	}
+	s.publishAssignment(ctx, event.TaskUnassigned, task, userID, assigneeID)
+	return task, nil
+}
+
+// WatchTask only needs the task to be visible; watching changes nothing for
+// other members.
+func (s *taskServiceImpl) WatchTask(ctx context.Context, id, userID string) (model.Task, error) {
+	return s.repo.AddWatcher(ctx, id, userID)
+}
+
+func (s *taskServiceImpl) UnwatchTask(ctx context.Context, id, userID string) (model.Task, error) {
+	return s.repo.RemoveWatcher(ctx, id, userID)
+}
+
+func (s *taskServiceImpl) ListAssignedTasks(ctx context.Context, userID, filter string, page repository.PageRequest) (repository.TaskPage, error) {
+	f, err := ParseTaskFilter(filter, usertime.Now(ctx))
+	if err != nil {
+		return repository.TaskPage{}, err
+	}
+	uID, _ := uuid.Parse(userID)
+	f.AssigneeID = &uID
+	return s.repo.List(ctx, userID, f, page)
+}
+
+func (s *taskServiceImpl) ListWatchedTasks(ctx context.Context, userID, filter string, page repository.PageRequest) (repository.TaskPage, error) {
+	f, err := ParseTaskFilter(filter, usertime.Now(ctx))
+	if err != nil {
+		return repository.TaskPage{}, err
+	}
+	uID, _ := uuid.Parse(userID)
+	f.WatcherID = &uID
+	return s.repo.List(ctx, userID, f, page)
+}
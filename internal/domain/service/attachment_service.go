@@ -0,0 +1,312 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"todo-list/config"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/event"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"unicode"
+)
+
+var (
+	ErrAttachmentTooLarge = apperr.New(apperr.TooLarge, "attachment is too large")
+	ErrAttachmentType     = apperr.New(apperr.Unsupported, "attachment type is not allowed")
+	ErrStorageQuota       = apperr.New(apperr.TooLarge, "storage quota exceeded")
+	ErrAttachmentMissing  = apperr.New(apperr.Gone, "attachment file is missing from storage")
+)
+
+// BlobStore keeps attachment bytes outside the database. Keys are
+// slash-separated paths built by model.AttachmentKey.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get returns an error wrapping fs.ErrNotExist for unknown keys.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete succeeds for keys that do not exist.
+	Delete(ctx context.Context, key string) error
+}
+
+type AttachmentService interface {
+	ListAttachments(ctx context.Context, taskID, userID string) ([]model.Attachment, error)
+	// UploadAttachment needs edit rights on the task.
+	UploadAttachment(ctx context.Context, taskID, userID string, upload model.AttachmentUpload) (model.Attachment, error)
+	// OpenAttachment returns the metadata and the content; the caller closes it.
+	OpenAttachment(ctx context.Context, id, taskID, userID string) (model.Attachment, io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, id, taskID, userID string) error
+	// PurgeOrphans removes the files of tasks that were purged from the
+	// database and reports how many were removed.
+	PurgeOrphans(ctx context.Context) (int, error)
+}
+
+const (
+	maxAttachmentName = 255
+	orphanBatch       = 100
+)
+
+type attachmentServiceImpl struct {
+	repo       repository.AttachmentRepository
+	tasks      repository.TaskRepository
+	workspaces repository.WorkspaceRepository
+	blobs      BlobStore
+	cfg        *config.AttachmentConfig
+	events     event.Publisher
+}
+
+// NewAttachmentService wires the service; a nil publisher discards events.
+func NewAttachmentService(repo repository.AttachmentRepository, tasks repository.TaskRepository, workspaces repository.WorkspaceRepository, blobs BlobStore, cfg *config.AttachmentConfig, events event.Publisher) AttachmentService {
+	if events == nil {
+		events = event.Nop
+	}
+	return &attachmentServiceImpl{repo: repo, tasks: tasks, workspaces: workspaces, blobs: blobs, cfg: cfg, events: events}
+}
+
+func (s *attachmentServiceImpl) ListAttachments(ctx context.Context, taskID, userID string) ([]model.Attachment, error) {
+	task, err := s.tasks.GetByID(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListByTask(ctx, task.ID.String())
+}
+
+func (s *attachmentServiceImpl) UploadAttachment(ctx context.Context, taskID, userID string, upload model.AttachmentUpload) (model.Attachment, error) {
+	if upload.Size > s.cfg.MaxSize {
+		return model.Attachment{}, ErrAttachmentTooLarge
+	}
+	task, err := s.editable(ctx, taskID, userID)
+	if err != nil {
+		return model.Attachment{}, err
+	}
+	contentType, body, err := s.contentType(upload)
+	if err != nil {
+		return model.Attachment{}, err
+	}
+	// Checked again when the row is written; this spares storing a file
+	// that plainly does not fit.
+	used, err := s.repo.UsageByUser(ctx, userID)
+	if err != nil {
+		return model.Attachment{}, err
+	}
+	if err := s.fits(used, upload.Size); err != nil {
+		return model.Attachment{}, err
+	}
+
+	uID, _ := uuid.Parse(userID)
+	a := model.Attachment{
+		ID:          uuid.New(),
+		TaskID:      task.ID,
+		UploadedBy:  uID,
+		Name:        attachmentName(upload.Name),
+		ContentType: contentType,
+		Size:        upload.Size,
+	}
+	a.StorageKey = model.AttachmentKey(task.ID, a.ID)
+	if err := s.blobs.Put(ctx, a.StorageKey, body, a.Size, a.ContentType); err != nil {
+		return model.Attachment{}, err
+	}
+	err = s.repo.Create(ctx, &a, func(used int64) error {
+		return s.fits(used, a.Size)
+	})
+	if err != nil {
+		s.removeBlob(ctx, a)
+		return model.Attachment{}, err
+	}
+	s.publish(ctx, event.TaskFileAttached, task, userID, a)
+	return a, nil
+}
+
+func (s *attachmentServiceImpl) fits(used, size int64) error {
+	if used+size > s.cfg.Quota {
+		return ErrStorageQuota
+	}
+	return nil
+}
+
+func (s *attachmentServiceImpl) OpenAttachment(ctx context.Context, id, taskID, userID string) (model.Attachment, io.ReadCloser, error) {
+	task, err := s.tasks.GetByID(ctx, taskID, userID)
+	if err != nil {
+		return model.Attachment{}, nil, err
+	}
+	a, err := s.get(ctx, id, task.ID.String())
+	if err != nil {
+		return model.Attachment{}, nil, err
+	}
+	body, err := s.blobs.Get(ctx, a.StorageKey)
+	if errors.Is(err, fs.ErrNotExist) {
+		return model.Attachment{}, nil, ErrAttachmentMissing
+	}
+	if err != nil {
+		return model.Attachment{}, nil, err
+	}
+	return a, body, nil
+}
+
+// DeleteAttachment drops the row first: a blob left behind by a failed
+// removal is only wasted space, while a row without its blob is a broken
+// download.
+func (s *attachmentServiceImpl) DeleteAttachment(ctx context.Context, id, taskID, userID string) error {
+	task, err := s.editable(ctx, taskID, userID)
+	if err != nil {
+		return err
+	}
+	a, err := s.get(ctx, id, task.ID.String())
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, a.ID.String(), task.ID.String()); err != nil {
+		return err
+	}
+	s.removeBlob(ctx, a)
+	s.publish(ctx, event.TaskFileRemoved, task, userID, a)
+	return nil
+}
+
+func (s *attachmentServiceImpl) PurgeOrphans(ctx context.Context) (int, error) {
+	orphans, err := s.repo.Orphans(ctx, orphanBatch)
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, a := range orphans {
+		if err := s.blobs.Delete(ctx, a.StorageKey); err != nil {
+			log.Printf("[ERROR] attachments: delete blob %s: %v", a.StorageKey, err)
+			continue
+		}
+		if err := s.repo.Delete(ctx, a.ID.String(), a.TaskID.String()); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// editable loads the task and checks that the user may change it.
+func (s *attachmentServiceImpl) editable(ctx context.Context, taskID, userID string) (model.Task, error) {
+	task, err := s.tasks.GetByID(ctx, taskID, userID)
+	if err != nil {
+		return model.Task{}, err
+	}
+	if task.WorkspaceID == nil {
+		return task, nil
+	}
+	role, err := s.workspaces.Role(ctx, task.WorkspaceID.String(), userID)
+	if err != nil {
+		return model.Task{}, err
+	}
+	if !model.RoleAllows(role, model.RoleEditor) {
+		return model.Task{}, ErrForbidden
+	}
+	return task, nil
+}
+
+func (s *attachmentServiceImpl) get(ctx context.Context, id, taskID string) (model.Attachment, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return model.Attachment{}, repository.ErrAttachmentNotFound
+	}
+	return s.repo.GetByID(ctx, id, taskID)
+}
+
+// contentType trusts the type declared by the client unless it is missing or
+// generic, in which case the first bytes of the file decide. The returned
+// reader replays whatever was consumed for sniffing.
+func (s *attachmentServiceImpl) contentType(upload model.AttachmentUpload) (string, io.Reader, error) {
+	body := upload.Body
+	ct, _, err := mime.ParseMediaType(upload.ContentType)
+	if err != nil || ct == "application/octet-stream" {
+		head := make([]byte, 512)
+		n, err := io.ReadFull(body, head)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return "", nil, err
+		}
+		ct, _, _ = mime.ParseMediaType(http.DetectContentType(head[:n]))
+		body = io.MultiReader(bytes.NewReader(head[:n]), body)
+	}
+	if !typeAllowed(ct, s.cfg.AllowedTypes) {
+		return "", nil, fmt.Errorf("%w: %s", ErrAttachmentType, ct)
+	}
+	return ct, body, nil
+}
+
+func typeAllowed(ct string, allowed []string) bool {
+	major, _, _ := strings.Cut(ct, "/")
+	for _, a := range allowed {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == ct || a == major+"/*" {
+			return true
+		}
+	}
+	return false
+}
+
+// attachmentName keeps only the base name of the uploaded file, without
+// control characters, so that it is safe to echo in Content-Disposition.
+func attachmentName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	if r := []rune(name); len(r) > maxAttachmentName {
+		name = string(r[:maxAttachmentName])
+	}
+	return name
+}
+
+func (s *attachmentServiceImpl) removeBlob(ctx context.Context, a model.Attachment) {
+	if err := s.blobs.Delete(context.WithoutCancel(ctx), a.StorageKey); err != nil {
+		log.Printf("[ERROR] attachments: delete blob %s: %v", a.StorageKey, err)
+	}
+}
+
+func (s *attachmentServiceImpl) publish(ctx context.Context, t event.Type, task model.Task, userID string, a model.Attachment) {
+	publishTask(ctx, s.events, t, task, userID, map[string]interface{}{"task_id": a.TaskID, "attachment": a})
+}
+
+// AttachmentJanitor periodically removes the files of purged tasks.
+type AttachmentJanitor struct {
+	service  AttachmentService
+	interval time.Duration
+}
+
+func NewAttachmentJanitor(service AttachmentService, interval time.Duration) *AttachmentJanitor {
+	return &AttachmentJanitor{service: service, interval: interval}
+}
+
+// Run sweeps until ctx is cancelled, draining full batches back to back.
+func (j *AttachmentJanitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := j.service.PurgeOrphans(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("[ERROR] attachments: %v", err)
+			}
+			if err != nil || n < orphanBatch {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
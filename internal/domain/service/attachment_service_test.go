@@ -0,0 +1,146 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"todo-list/config"
+	"todo-list/internal/domain/event"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/testutils"
+)
+
+type memoryBlobStore struct {
+	blobs map[string][]byte
+}
+
+func (m *memoryBlobStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	m.blobs[key] = data
+	return nil
+}
+
+func (m *memoryBlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, ok := m.blobs[key]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memoryBlobStore) Delete(ctx context.Context, key string) error {
+	delete(m.blobs, key)
+	return nil
+}
+
+func TestAttachmentService(t *testing.T) {
+	ctx := context.Background()
+	uID := uuid.New()
+	task := model.Task{ID: uuid.New(), UserID: uID}
+	cfg := &config.AttachmentConfig{MaxSize: 1 << 10, Quota: 4 << 10, AllowedTypes: []string{"image/*", "application/pdf"}}
+	png := "\x89PNG\r\n\x1a\n" + strings.Repeat("x", 100)
+
+	setup := func() (*testutils.AttachmentMocks, *testutils.AllMocks, *memoryBlobStore, *recordingPublisher, AttachmentService) {
+		repo, tasks := new(testutils.AttachmentMocks), new(testutils.AllMocks)
+		blobs, events := &memoryBlobStore{blobs: map[string][]byte{}}, &recordingPublisher{}
+		return repo, tasks, blobs, events, NewAttachmentService(repo, tasks, new(testutils.WorkspaceMocks), blobs, cfg, events)
+	}
+
+	t.Run("Upload_Sniffs_Generic_Type", func(t *testing.T) {
+		repo, tasks, blobs, events, s := setup()
+		tasks.On("GetByID", ctx, task.ID.String(), uID.String()).Return(task, nil).Once()
+		repo.On("UsageByUser", ctx, uID.String()).Return(int64(0), nil).Once()
+		repo.On("Create", ctx, mock.MatchedBy(func(a *model.Attachment) bool {
+			return a.ContentType == "image/png" && a.Name == "shot.png" && a.StorageKey == model.AttachmentKey(task.ID, a.ID)
+		})).Return(nil).Once()
+
+		a, err := s.UploadAttachment(ctx, task.ID.String(), uID.String(), model.AttachmentUpload{
+			Name: `C:\Users\me\shot.png`, ContentType: "application/octet-stream", Size: int64(len(png)), Body: strings.NewReader(png),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, png, string(blobs.blobs[a.StorageKey]))
+		if assert.Len(t, events.events, 1) {
+			assert.Equal(t, event.TaskFileAttached, events.events[0].Type)
+		}
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Upload_Limits", func(t *testing.T) {
+		repo, tasks, blobs, _, s := setup()
+		tasks.On("GetByID", ctx, task.ID.String(), uID.String()).Return(task, nil)
+
+		_, err := s.UploadAttachment(ctx, task.ID.String(), uID.String(), model.AttachmentUpload{Size: cfg.MaxSize + 1})
+		assert.ErrorIs(t, err, ErrAttachmentTooLarge)
+
+		_, err = s.UploadAttachment(ctx, task.ID.String(), uID.String(), model.AttachmentUpload{
+			Name: "page.html", ContentType: "text/html", Size: 6, Body: strings.NewReader("<html>"),
+		})
+		assert.ErrorIs(t, err, ErrAttachmentType)
+
+		repo.On("UsageByUser", ctx, uID.String()).Return(cfg.Quota-10, nil).Once()
+		_, err = s.UploadAttachment(ctx, task.ID.String(), uID.String(), model.AttachmentUpload{
+			Name: "doc.pdf", ContentType: "application/pdf", Size: 11, Body: strings.NewReader("%PDF-1.7 xx"),
+		})
+		assert.ErrorIs(t, err, ErrStorageQuota)
+		assert.Empty(t, blobs.blobs)
+
+		// Параллельная загрузка заняла место между проверкой и записью
+		repo.On("UsageByUser", ctx, uID.String()).Return(int64(0), nil).Once()
+		repo.On("Create", ctx, mock.AnythingOfType("*model.Attachment")).Return(nil, cfg.Quota-10).Once()
+		_, err = s.UploadAttachment(ctx, task.ID.String(), uID.String(), model.AttachmentUpload{
+			Name: "doc.pdf", ContentType: "application/pdf", Size: 11, Body: strings.NewReader("%PDF-1.7 xx"),
+		})
+		assert.ErrorIs(t, err, ErrStorageQuota)
+		assert.Empty(t, blobs.blobs)
+	})
+
+	t.Run("Delete_Removes_Blob", func(t *testing.T) {
+		repo, tasks, blobs, _, s := setup()
+		a := model.Attachment{ID: uuid.New(), TaskID: task.ID}
+		a.StorageKey = model.AttachmentKey(task.ID, a.ID)
+		blobs.blobs[a.StorageKey] = []byte("data")
+		tasks.On("GetByID", ctx, task.ID.String(), uID.String()).Return(task, nil)
+		repo.On("GetByID", ctx, a.ID.String(), task.ID.String()).Return(a, nil).Once()
+		repo.On("Delete", ctx, a.ID.String(), task.ID.String()).Return(nil).Once()
+
+		require.NoError(t, s.DeleteAttachment(ctx, a.ID.String(), task.ID.String(), uID.String()))
+		assert.Empty(t, blobs.blobs)
+
+		err := s.DeleteAttachment(ctx, "junk", task.ID.String(), uID.String())
+		assert.ErrorIs(t, err, repository.ErrAttachmentNotFound)
+	})
+
+	t.Run("Open_Missing_Blob", func(t *testing.T) {
+		repo, tasks, _, _, s := setup()
+		a := model.Attachment{ID: uuid.New(), TaskID: task.ID, StorageKey: "tasks/gone"}
+		tasks.On("GetByID", ctx, task.ID.String(), uID.String()).Return(task, nil).Once()
+		repo.On("GetByID", ctx, a.ID.String(), task.ID.String()).Return(a, nil).Once()
+
+		_, _, err := s.OpenAttachment(ctx, a.ID.String(), task.ID.String(), uID.String())
+		assert.ErrorIs(t, err, ErrAttachmentMissing)
+	})
+
+	t.Run("PurgeOrphans", func(t *testing.T) {
+		repo, _, blobs, _, s := setup()
+		orphan := model.Attachment{ID: uuid.New(), TaskID: uuid.New(), StorageKey: "tasks/orphan"}
+		blobs.blobs[orphan.StorageKey] = []byte("data")
+		repo.On("Orphans", ctx, orphanBatch).Return([]model.Attachment{orphan}, nil).Once()
+		repo.On("Delete", ctx, orphan.ID.String(), orphan.TaskID.String()).Return(nil).Once()
+
+		n, err := s.PurgeOrphans(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Empty(t, blobs.blobs)
+	})
+}
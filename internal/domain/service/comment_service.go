@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/event"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"unicode/utf8"
+)
+
+var ErrInvalidComment = apperr.New(apperr.Invalid, "comment must be between 1 and 10000 characters")
+
+const maxCommentLength = 10000
+
+type CommentService interface {
+	ListComments(ctx context.Context, taskID, userID string) ([]model.Comment, error)
+	AddComment(ctx context.Context, taskID, userID, body string) (model.Comment, error)
+	// EditComment is limited to the author.
+	EditComment(ctx context.Context, id, taskID, userID, body string) (model.Comment, error)
+	// DeleteComment is allowed to the author and to workspace owners.
+	DeleteComment(ctx context.Context, id, taskID, userID string) error
+}
+
+type commentServiceImpl struct {
+	repo       repository.CommentRepository
+	tasks      repository.TaskRepository
+	workspaces repository.WorkspaceRepository
+	events     event.Publisher
+}
+
+// NewCommentService wires the service; a nil publisher discards events.
+func NewCommentService(repo repository.CommentRepository, tasks repository.TaskRepository, workspaces repository.WorkspaceRepository, events event.Publisher) CommentService {
+	if events == nil {
+		events = event.Nop
+	}
+	return &commentServiceImpl{repo: repo, tasks: tasks, workspaces: workspaces, events: events}
+}
+
+func (s *commentServiceImpl) ListComments(ctx context.Context, taskID, userID string) ([]model.Comment, error) {
+	task, err := s.tasks.GetByID(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListByTask(ctx, task.ID.String())
+}
+
+// AddComment is open to everyone who can see the task, viewers included.
+func (s *commentServiceImpl) AddComment(ctx context.Context, taskID, userID, body string) (model.Comment, error) {
+	body, err := validateComment(body)
+	if err != nil {
+		return model.Comment{}, err
+	}
+	task, err := s.tasks.GetByID(ctx, taskID, userID)
+	if err != nil {
+		return model.Comment{}, err
+	}
+	authorID, _ := uuid.Parse(userID)
+	comment := model.Comment{ID: uuid.New(), TaskID: task.ID, AuthorID: authorID, Body: body}
+	if comment.Mentions, err = s.mentions(ctx, task, comment.ID, body); err != nil {
+		return model.Comment{}, err
+	}
+	if err := s.repo.Create(ctx, &comment); err != nil {
+		return model.Comment{}, err
+	}
+	s.publish(ctx, event.TaskCommented, userID, task, comment)
+	s.notifyMentioned(ctx, userID, task, comment, nil)
+	return comment, nil
+}
+
+func (s *commentServiceImpl) EditComment(ctx context.Context, id, taskID, userID, body string) (model.Comment, error) {
+	body, err := validateComment(body)
+	if err != nil {
+		return model.Comment{}, err
+	}
+	task, comment, err := s.load(ctx, id, taskID, userID)
+	if err != nil {
+		return model.Comment{}, err
+	}
+	if comment.AuthorID.String() != userID {
+		return model.Comment{}, ErrForbidden
+	}
+	before := comment.Mentions
+	now := time.Now()
+	comment.Body = body
+	comment.EditedAt = &now
+	if comment.Mentions, err = s.mentions(ctx, task, comment.ID, body); err != nil {
+		return model.Comment{}, err
+	}
+	if err := s.repo.Update(ctx, &comment); err != nil {
+		return model.Comment{}, err
+	}
+	s.notifyMentioned(ctx, userID, task, comment, before)
+	return comment, nil
+}
+
+func (s *commentServiceImpl) DeleteComment(ctx context.Context, id, taskID, userID string) error {
+	task, comment, err := s.load(ctx, id, taskID, userID)
+	if err != nil {
+		return err
+	}
+	if comment.AuthorID.String() != userID {
+		if task.WorkspaceID == nil {
+			return ErrForbidden
+		}
+		role, err := s.workspaces.Role(ctx, task.WorkspaceID.String(), userID)
+		if err != nil {
+			return err
+		}
+		if !model.RoleAllows(role, model.RoleOwner) {
+			return ErrForbidden
+		}
+	}
+	return s.repo.Delete(ctx, comment.ID.String(), task.ID.String())
+}
+
+func (s *commentServiceImpl) load(ctx context.Context, id, taskID, userID string) (model.Task, model.Comment, error) {
+	task, err := s.tasks.GetByID(ctx, taskID, userID)
+	if err != nil {
+		return model.Task{}, model.Comment{}, err
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return model.Task{}, model.Comment{}, repository.ErrCommentNotFound
+	}
+	comment, err := s.repo.GetByID(ctx, id, task.ID.String())
+	return task, comment, err
+}
+
+// mentions resolves @handles against the users who can see the task. A bare
+// handle matches the part of an email before the @ and is dropped when more
+// than one candidate shares it.
+func (s *commentServiceImpl) mentions(ctx context.Context, task model.Task, commentID uuid.UUID, body string) ([]model.CommentMention, error) {
+	handles := model.ParseMentions(body)
+	if len(handles) == 0 {
+		return nil, nil
+	}
+	candidates, err := s.repo.MentionCandidates(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	byEmail := make(map[string]uuid.UUID, len(candidates))
+	byLocal := make(map[string][]uuid.UUID, len(candidates))
+	for _, c := range candidates {
+		email := strings.ToLower(c.Email)
+		byEmail[email] = c.UserID
+		local, _, _ := strings.Cut(email, "@")
+		byLocal[local] = append(byLocal[local], c.UserID)
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	var out []model.CommentMention
+	for _, h := range handles {
+		id, ok := byEmail[h]
+		if !ok && len(byLocal[h]) == 1 {
+			id, ok = byLocal[h][0], true
+		}
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, model.CommentMention{CommentID: commentID, UserID: id})
+	}
+	return out, nil
+}
+
+func (s *commentServiceImpl) publish(ctx context.Context, t event.Type, userID string, task model.Task, comment model.Comment) {
+	publishTask(ctx, s.events, t, task, userID, map[string]interface{}{"task_id": task.ID, "comment": comment})
+}
+
+// notifyMentioned sends task.mentioned to every user newly mentioned by the
+// comment, skipping the author and anyone in already.
+func (s *commentServiceImpl) notifyMentioned(ctx context.Context, userID string, task model.Task, comment model.Comment, already []model.CommentMention) {
+	skip := map[string]bool{userID: true}
+	for _, m := range already {
+		skip[m.UserID.String()] = true
+	}
+	for _, m := range comment.Mentions {
+		if !skip[m.UserID.String()] {
+			e := event.New(event.TaskMentioned, m.UserID, map[string]interface{}{"task_id": task.ID, "comment": comment})
+			s.events.Publish(ctx, e)
+		}
+	}
+}
+
+func validateComment(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if n := utf8.RuneCountInString(body); n == 0 || n > maxCommentLength {
+		return "", ErrInvalidComment
+	}
+	return body, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"todo-list/internal/domain/event"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/testutils"
+)
+
+func TestCommentService(t *testing.T) {
+	ctx := context.Background()
+	author, other := uuid.New(), uuid.New()
+	wsID := uuid.New()
+	task := model.Task{ID: uuid.New(), UserID: author, WorkspaceID: &wsID}
+
+	t.Run("AddComment_Resolves_Mentions", func(t *testing.T) {
+		repo, tasks := new(testutils.CommentMocks), new(testutils.AllMocks)
+		events := &recordingPublisher{}
+		s := NewCommentService(repo, tasks, new(testutils.WorkspaceMocks), events)
+		tasks.On("GetByID", ctx, task.ID.String(), author.String()).Return(task, nil).Once()
+		repo.On("MentionCandidates", ctx, task).Return([]repository.MentionCandidate{
+			{UserID: author, Email: "alex@example.com"},
+			{UserID: other, Email: "kim@example.com"},
+			{UserID: uuid.New(), Email: "sam@work.io"},
+			{UserID: uuid.New(), Email: "sam@home.io"},
+		}, nil).Once()
+		repo.On("Create", ctx, mock.MatchedBy(func(c *model.Comment) bool {
+			return len(c.Mentions) == 2
+		})).Return(nil).Once()
+
+		// @sam неоднозначен, @alex — автор и уведомление не получает
+		c, err := s.AddComment(ctx, task.ID.String(), author.String(), "  @Kim @alex @sam see `@nobody`  ")
+		require.NoError(t, err)
+		assert.Equal(t, "@Kim @alex @sam see `@nobody`", c.Body)
+		if assert.Len(t, events.events, 2) {
+			assert.Equal(t, event.TaskCommented, events.events[0].Type)
+			assert.Equal(t, event.TaskMentioned, events.events[1].Type)
+			assert.Equal(t, other, events.events[1].UserID)
+		}
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("AddComment_Validation", func(t *testing.T) {
+		s := NewCommentService(new(testutils.CommentMocks), new(testutils.AllMocks), nil, nil)
+		_, err := s.AddComment(ctx, task.ID.String(), author.String(), "   ")
+		assert.ErrorIs(t, err, ErrInvalidComment)
+		_, err = s.AddComment(ctx, task.ID.String(), author.String(), strings.Repeat("x", maxCommentLength+1))
+		assert.ErrorIs(t, err, ErrInvalidComment)
+	})
+
+	t.Run("EditComment_Only_Author", func(t *testing.T) {
+		repo, tasks := new(testutils.CommentMocks), new(testutils.AllMocks)
+		s := NewCommentService(repo, tasks, nil, nil)
+		cID := uuid.New()
+		tasks.On("GetByID", ctx, task.ID.String(), other.String()).Return(task, nil).Once()
+		repo.On("GetByID", ctx, cID.String(), task.ID.String()).
+			Return(model.Comment{ID: cID, TaskID: task.ID, AuthorID: author}, nil).Once()
+
+		_, err := s.EditComment(ctx, cID.String(), task.ID.String(), other.String(), "changed")
+		assert.ErrorIs(t, err, ErrForbidden)
+		repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("DeleteComment_Workspace_Owner", func(t *testing.T) {
+		repo, tasks, workspaces := new(testutils.CommentMocks), new(testutils.AllMocks), new(testutils.WorkspaceMocks)
+		s := NewCommentService(repo, tasks, workspaces, nil)
+		cID := uuid.New()
+		tasks.On("GetByID", ctx, task.ID.String(), other.String()).Return(task, nil).Twice()
+		repo.On("GetByID", ctx, cID.String(), task.ID.String()).
+			Return(model.Comment{ID: cID, TaskID: task.ID, AuthorID: author}, nil).Twice()
+		workspaces.On("Role", ctx, wsID.String(), other.String()).Return(model.RoleEditor, nil).Once()
+		workspaces.On("Role", ctx, wsID.String(), other.String()).Return(model.RoleOwner, nil).Once()
+		repo.On("Delete", ctx, cID.String(), task.ID.String()).Return(nil).Once()
+
+		assert.ErrorIs(t, s.DeleteComment(ctx, cID.String(), task.ID.String(), other.String()), ErrForbidden)
+		assert.NoError(t, s.DeleteComment(ctx, cID.String(), task.ID.String(), other.String()))
+		repo.AssertExpectations(t)
+	})
+}
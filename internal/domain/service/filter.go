@@ -0,0 +1,265 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/repository"
+)
+
+type FilterError struct {
+	Token  string
+	Reason string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("invalid filter term %q: %s", e.Token, e.Reason)
+}
+
+func (e *FilterError) Kind() apperr.Kind { return apperr.Invalid }
+
+func (e *FilterError) Violations() []apperr.Violation {
+	return []apperr.Violation{{Field: "filter", Reason: e.Error()}}
+}
+
+// ParseTaskFilter parses a filter expression such as
+//
+//	status:todo,in_progress priority:high tag:work due<2026-11-01 -archived "quarterly report"
+//
+// Terms are ANDed together; comma-separated values inside one term are ORed.
+// A leading "-" negates status, priority, tag and archived terms.
+// project:none and project:any select tasks outside or inside a project. Dates
+// are either YYYY-MM-DD, RFC 3339, or relative to now (now, today, tomorrow,
+// yesterday, +3d, -2w) and are interpreted in now's location. Bare words and
+// quoted phrases are matched against title and content; a quoted phrase is
+// never read as a field or flag.
+func ParseTaskFilter(expr string, now time.Time) (repository.TaskFilter, error) {
+	var f repository.TaskFilter
+	tokens, err := tokenizeFilter(expr)
+	if err != nil {
+		return f, err
+	}
+	for _, tok := range tokens {
+		if tok.quoted {
+			f.Terms = append(f.Terms, tok.text)
+			continue
+		}
+		if err := applyFilterToken(&f, tok.text, now); err != nil {
+			return repository.TaskFilter{}, err
+		}
+	}
+	return f, nil
+}
+
+// filterToken is one term of a filter expression. A term that opens with a
+// quote is free text whatever it contains, so "archived" or "10:30" can be
+// searched for.
+type filterToken struct {
+	text   string
+	quoted bool
+}
+
+func tokenizeFilter(expr string) ([]filterToken, error) {
+	var tokens []filterToken
+	var cur strings.Builder
+	inQuotes, quoted := false, false
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, filterToken{text: cur.String(), quoted: quoted})
+			cur.Reset()
+		}
+		quoted = false
+	}
+	for _, r := range expr {
+		switch {
+		case r == '"':
+			if !inQuotes && cur.Len() == 0 {
+				quoted = true
+			}
+			inQuotes = !inQuotes
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\n'):
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if inQuotes {
+		return nil, &FilterError{Token: expr, Reason: "unterminated quote"}
+	}
+	flush()
+	return tokens, nil
+}
+
+func applyFilterToken(f *repository.TaskFilter, tok string, now time.Time) error {
+	negated := strings.HasPrefix(tok, "-") && len(tok) > 1
+	body := tok
+	if negated {
+		body = tok[1:]
+	}
+
+	if body == "archived" {
+		archived := !negated
+		f.Archived = &archived
+		return nil
+	}
+
+	if strings.HasPrefix(body, "due") && len(body) > 3 && strings.ContainsRune(":<>", rune(body[3])) {
+		if negated {
+			return &FilterError{Token: tok, Reason: "due terms cannot be negated"}
+		}
+		return applyDueToken(f, tok, body[3:], now)
+	}
+
+	key, value, found := strings.Cut(body, ":")
+	if !found {
+		if negated {
+			return &FilterError{Token: tok, Reason: "free text cannot be negated"}
+		}
+		f.Terms = append(f.Terms, tok)
+		return nil
+	}
+	values := splitValues(value)
+	if len(values) == 0 {
+		return &FilterError{Token: tok, Reason: "missing value"}
+	}
+
+	if key == "project" {
+		if negated || len(values) != 1 || (values[0] != "none" && values[0] != "any") {
+			return &FilterError{Token: tok, Reason: "project must be none or any"}
+		}
+		has := values[0] == "any"
+		f.HasProject = &has
+		return nil
+	}
+
+	switch key {
+	case "status":
+		if negated {
+			f.ExcludeStatuses = append(f.ExcludeStatuses, values...)
+		} else {
+			f.Statuses = append(f.Statuses, values...)
+		}
+	case "priority":
+		if negated {
+			f.ExcludePriorities = append(f.ExcludePriorities, values...)
+		} else {
+			f.Priorities = append(f.Priorities, values...)
+		}
+	case "tag":
+		if negated {
+			f.ExcludeTags = append(f.ExcludeTags, values...)
+		} else {
+			f.TagGroups = append(f.TagGroups, values)
+		}
+	default:
+		return &FilterError{Token: tok, Reason: fmt.Sprintf("unknown field %q", key)}
+	}
+	return nil
+}
+
+func applyDueToken(f *repository.TaskFilter, tok, rest string, now time.Time) error {
+	op := rest[:1]
+	value := rest[1:]
+	if strings.HasPrefix(rest, "<=") || strings.HasPrefix(rest, ">=") {
+		op, value = rest[:2], rest[2:]
+	}
+
+	if op == ":" {
+		switch value {
+		case "none":
+			has := false
+			f.HasDueDate = &has
+			return nil
+		case "any":
+			has := true
+			f.HasDueDate = &has
+			return nil
+		}
+	}
+
+	start, end, err := parseFilterDate(value, now)
+	if err != nil {
+		return &FilterError{Token: tok, Reason: err.Error()}
+	}
+	switch op {
+	case ":":
+		f.DueFrom, f.DueBefore = &start, &end
+	case "<":
+		f.DueBefore = &start
+	case "<=":
+		f.DueBefore = &end
+	case ">":
+		f.DueFrom = &end
+	case ">=":
+		f.DueFrom = &start
+	}
+	return nil
+}
+
+// parseFilterDate returns the half-open interval [start, end) the value refers to:
+// a whole day for dates, a single instant for now and RFC 3339 timestamps.
+func parseFilterDate(value string, now time.Time) (time.Time, time.Time, error) {
+	loc := now.Location()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	var day time.Time
+	switch value {
+	case "now":
+		return now, now.Add(time.Nanosecond), nil
+	case "today":
+		day = dayStart
+	case "tomorrow":
+		day = dayStart.AddDate(0, 0, 1)
+	case "yesterday":
+		day = dayStart.AddDate(0, 0, -1)
+	default:
+		if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+			day = t
+			break
+		}
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t, t.Add(time.Nanosecond), nil
+		}
+		offset, ok := parseRelativeDays(value)
+		if !ok {
+			return time.Time{}, time.Time{}, fmt.Errorf("cannot parse date %q", value)
+		}
+		day = dayStart.AddDate(0, 0, offset)
+	}
+	return day, day.AddDate(0, 0, 1), nil
+}
+
+// parseRelativeDays understands +3d, -1d, +2w.
+func parseRelativeDays(value string) (int, bool) {
+	if len(value) < 3 || (value[0] != '+' && value[0] != '-') {
+		return 0, false
+	}
+	unit := value[len(value)-1]
+	n, err := strconv.Atoi(value[1 : len(value)-1])
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	switch unit {
+	case 'd':
+	case 'w':
+		n *= 7
+	default:
+		return 0, false
+	}
+	if value[0] == '-' {
+		n = -n
+	}
+	return n, true
+}
+
+func splitValues(value string) []string {
+	var out []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
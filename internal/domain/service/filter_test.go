@@ -0,0 +1,95 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTaskFilter(t *testing.T) {
+	now := time.Date(2026, 10, 17, 15, 4, 0, 0, time.UTC)
+
+	t.Run("Combined_Expression", func(t *testing.T) {
+		f, err := ParseTaskFilter(`status:todo priority:high,urgent tag:work due<2026-11-01 -archived`, now)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"todo"}, f.Statuses)
+		assert.Equal(t, []string{"high", "urgent"}, f.Priorities)
+		assert.Equal(t, [][]string{{"work"}}, f.TagGroups)
+		require.NotNil(t, f.DueBefore)
+		assert.Equal(t, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), *f.DueBefore)
+		require.NotNil(t, f.Archived)
+		assert.False(t, *f.Archived)
+	})
+
+	t.Run("Negations_And_Quoted_Text", func(t *testing.T) {
+		f, err := ParseTaskFilter(`-status:done -tag:"deep work" "quarterly report" молоко`, now)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"done"}, f.ExcludeStatuses)
+		assert.Equal(t, []string{"deep work"}, f.ExcludeTags)
+		assert.Equal(t, []string{"quarterly report", "молоко"}, f.Terms)
+	})
+
+	t.Run("Quoted_Terms_Are_Free_Text", func(t *testing.T) {
+		// В кавычках ни флаги, ни поля не распознаются
+		f, err := ParseTaskFilter(`"meeting 10:30" "archived" "status:done"`, now)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"meeting 10:30", "archived", "status:done"}, f.Terms)
+		assert.Nil(t, f.Archived)
+		assert.Empty(t, f.Statuses)
+	})
+
+	t.Run("Due_Operators", func(t *testing.T) {
+		day := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+		f, err := ParseTaskFilter("due:today", now)
+		require.NoError(t, err)
+		assert.Equal(t, day, *f.DueFrom)
+		assert.Equal(t, day.AddDate(0, 0, 1), *f.DueBefore)
+
+		f, err = ParseTaskFilter("due<=+1w", now)
+		require.NoError(t, err)
+		assert.Equal(t, day.AddDate(0, 0, 8), *f.DueBefore)
+
+		f, err = ParseTaskFilter("due>yesterday", now)
+		require.NoError(t, err)
+		assert.Equal(t, day, *f.DueFrom)
+
+		f, err = ParseTaskFilter("due:none", now)
+		require.NoError(t, err)
+		assert.False(t, *f.HasDueDate)
+
+		f, err = ParseTaskFilter("due<now", now)
+		require.NoError(t, err)
+		assert.Equal(t, now, *f.DueBefore)
+	})
+
+	t.Run("Project", func(t *testing.T) {
+		f, err := ParseTaskFilter("project:none", now)
+		require.NoError(t, err)
+		assert.False(t, *f.HasProject)
+
+		f, err = ParseTaskFilter("project:any", now)
+		require.NoError(t, err)
+		assert.True(t, *f.HasProject)
+	})
+
+	t.Run("Dates_Use_Location_Of_Now", func(t *testing.T) {
+		moscow := time.FixedZone("MSK", 3*60*60)
+		f, err := ParseTaskFilter("due>=2026-11-01", now.In(moscow))
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 10, 31, 21, 0, 0, 0, time.UTC), f.DueFrom.UTC())
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, expr := range []string{"color:red", "due<someday", "status:", `"unterminated`, "-due:today", "project:inbox", "-project:none"} {
+			_, err := ParseTaskFilter(expr, now)
+			var filterErr *FilterError
+			assert.ErrorAs(t, err, &filterErr, expr)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/event"
+	"todo-list/internal/domain/model"
+)
+
+var ErrInvalidRevision = apperr.New(apperr.Invalid, "no such revision")
+
+func (s *taskServiceImpl) GetTaskHistory(ctx context.Context, id, userID string) ([]model.TaskRevision, error) {
+	return s.repo.ListRevisions(ctx, id, userID)
+}
+
+// RevertTask restores the revertable fields to their values right after the
+// given revision; revision 0 is the state before the first recorded change.
+// History is never rewritten: the revert is recorded as a new revision.
+func (s *taskServiceImpl) RevertTask(ctx context.Context, id, userID string, revision int, version int64) (model.Task, error) {
+	task, err := s.editableAt(ctx, id, userID, version)
+	if err != nil {
+		return model.Task{}, err
+	}
+	history, err := s.repo.ListRevisions(ctx, id, userID)
+	if err != nil {
+		return model.Task{}, err
+	}
+	latest := 0
+	if len(history) > 0 {
+		latest = history[0].Revision
+	}
+	if revision < 0 || revision > latest {
+		return model.Task{}, ErrInvalidRevision
+	}
+
+	revertable := make(map[string]bool, len(model.RevertableFields))
+	for _, f := range model.RevertableFields {
+		revertable[f] = true
+	}
+	fields := model.FieldsOf(task)
+	for _, rev := range history {
+		if rev.Revision <= revision {
+			break
+		}
+		for _, c := range rev.Changes {
+			if !revertable[c.Field] {
+				continue
+			}
+			if err := fields.Set(c.Field, c.Old); err != nil {
+				return model.Task{}, err
+			}
+		}
+	}
+
+	task.Title = fields.Title
+	task.Content = fields.Content
+	task.Priority = fields.Priority
+	task.DueDate = fields.DueDate
+	task.RRule = fields.RRule
+	task, err = s.saveWithStatus(ctx, task, userID, fields.Status)
+	if err != nil {
+		return task, err
+	}
+	s.publish(ctx, event.TaskUpdated, task, userID, map[string]interface{}{"task": task, "reverted_to": revision})
+	return task, nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"github.com/google/uuid"
+	"log"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+)
+
+var (
+	ErrInvalidInvitation = apperr.New(apperr.Invalid, "invalid invitation token")
+	ErrInvitationTarget  = apperr.New(apperr.Invalid, "a valid email address is required")
+)
+
+// InvitationMailer delivers invitation links. Without one the link is only
+// returned to the owner who created the invitation.
+type InvitationMailer interface {
+	SendInvitation(ctx context.Context, mail model.InvitationMail) error
+}
+
+// Tokens carry the invitation ID and its expiry, signed with a key derived
+// from the JWT secret so they can never pass as access tokens. Whether the
+// invitation is still open is always checked against the database, which is
+// what makes revocation work.
+const invitationPayloadLen = 16 + 8
+
+func invitationKey(secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("workspace-invitation"))
+	return mac.Sum(nil)
+}
+
+func (s *workspaceServiceImpl) signInvitation(inv model.WorkspaceInvitation) string {
+	payload := make([]byte, invitationPayloadLen)
+	copy(payload, inv.ID[:])
+	binary.BigEndian.PutUint64(payload[16:], uint64(inv.ExpiresAt.Unix()))
+	mac := hmac.New(sha256.New, s.inviteKey)
+	mac.Write(payload)
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(mac.Sum(nil))
+}
+
+// parseInvitation returns the invitation ID from a token with a valid
+// signature that has not expired yet.
+func (s *workspaceServiceImpl) parseInvitation(token string) (string, error) {
+	p, sig, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok {
+		return "", ErrInvalidInvitation
+	}
+	enc := base64.RawURLEncoding
+	payload, err := enc.DecodeString(p)
+	if err != nil || len(payload) != invitationPayloadLen {
+		return "", ErrInvalidInvitation
+	}
+	got, err := enc.DecodeString(sig)
+	if err != nil {
+		return "", ErrInvalidInvitation
+	}
+	mac := hmac.New(sha256.New, s.inviteKey)
+	mac.Write(payload)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return "", ErrInvalidInvitation
+	}
+	if time.Now().Unix() >= int64(binary.BigEndian.Uint64(payload[16:])) {
+		return "", repository.ErrInvitationClosed
+	}
+	id, _ := uuid.FromBytes(payload[:16])
+	return id.String(), nil
+}
+
+func (s *workspaceServiceImpl) InviteMember(ctx context.Context, id, userID, email, role string) (model.WorkspaceInvitation, string, error) {
+	if role == "" {
+		role = model.RoleEditor
+	}
+	if !model.ValidRole(role) {
+		return model.WorkspaceInvitation{}, "", ErrInvalidRole
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return model.WorkspaceInvitation{}, "", ErrInvitationTarget
+	}
+	if err := s.require(ctx, id, userID, model.RoleOwner); err != nil {
+		return model.WorkspaceInvitation{}, "", err
+	}
+	ws, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return model.WorkspaceInvitation{}, "", err
+	}
+
+	uID, _ := uuid.Parse(userID)
+	inv := model.WorkspaceInvitation{
+		ID:          uuid.New(),
+		WorkspaceID: ws.ID,
+		Email:       email,
+		Role:        role,
+		Status:      model.InvitationPending,
+		InvitedBy:   uID,
+		ExpiresAt:   time.Now().Add(s.inviteTTL).Truncate(time.Second),
+	}
+	if err := s.repo.CreateInvitation(ctx, &inv); err != nil {
+		return model.WorkspaceInvitation{}, "", err
+	}
+	token := s.signInvitation(inv)
+
+	// The invitation stands even if the mail bounces; the owner still has the link.
+	if s.mailer != nil {
+		msg := model.InvitationMail{To: email, Workspace: ws.Name, Role: role, Link: s.invitationLink(token), ExpiresAt: inv.ExpiresAt}
+		if err := s.mailer.SendInvitation(ctx, msg); err != nil {
+			log.Printf("[ERROR] invitations: email %s to %s: %v", inv.ID, email, err)
+		}
+	}
+	return inv, token, nil
+}
+
+func (s *workspaceServiceImpl) invitationLink(token string) string {
+	if s.acceptURL == "" {
+		return token
+	}
+	sep := "?"
+	if strings.Contains(s.acceptURL, "?") {
+		sep = "&"
+	}
+	return s.acceptURL + sep + "token=" + url.QueryEscape(token)
+}
+
+func (s *workspaceServiceImpl) ListPendingInvitations(ctx context.Context, id, userID string) ([]model.WorkspaceInvitation, error) {
+	if err := s.require(ctx, id, userID, model.RoleOwner); err != nil {
+		return nil, err
+	}
+	return s.repo.ListInvitations(ctx, id)
+}
+
+func (s *workspaceServiceImpl) RevokeWorkspaceInvitation(ctx context.Context, id, userID, invitationID string) error {
+	if err := s.require(ctx, id, userID, model.RoleOwner); err != nil {
+		return err
+	}
+	if _, err := uuid.Parse(invitationID); err != nil {
+		return repository.ErrInvitationNotFound
+	}
+	return s.repo.RevokeInvitation(ctx, id, invitationID)
+}
+
+func (s *workspaceServiceImpl) InvitationByToken(ctx context.Context, token string) (model.WorkspaceInvitation, error) {
+	invID, err := s.parseInvitation(token)
+	if err != nil {
+		return model.WorkspaceInvitation{}, err
+	}
+	inv, err := s.repo.GetInvitation(ctx, invID)
+	if err != nil {
+		return model.WorkspaceInvitation{}, err
+	}
+	if !inv.Open(time.Now()) {
+		return model.WorkspaceInvitation{}, repository.ErrInvitationClosed
+	}
+	return inv, nil
+}
+
+func (s *workspaceServiceImpl) AcceptInvitationToken(ctx context.Context, token, userID string) (repository.Member, error) {
+	invID, err := s.parseInvitation(token)
+	if err != nil {
+		return repository.Member{}, err
+	}
+	return s.repo.AcceptInvitation(ctx, invID, userID)
+}
+
+func (s *workspaceServiceImpl) DeclineInvitationToken(ctx context.Context, token string) error {
+	invID, err := s.parseInvitation(token)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeclineInvitation(ctx, invID)
+}
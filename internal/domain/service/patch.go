@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/event"
+	"todo-list/internal/domain/jsonpatch"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/usertime"
+	"unicode/utf8"
+)
+
+// Patch formats accepted by PatchTask, named by their media types.
+const (
+	MergePatch = "application/merge-patch+json"
+	JSONPatch  = "application/json-patch+json"
+)
+
+var ErrPatchFormat = apperr.New(apperr.Unsupported, "unsupported patch format")
+
+// FieldError reports a task field that a patch left with an invalid value.
+type FieldError struct {
+	Field  string
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
+}
+
+func (e *FieldError) Kind() apperr.Kind { return apperr.Unprocessable }
+
+func (e *FieldError) Violations() []apperr.Violation {
+	return []apperr.Violation{{Field: e.Field, Reason: e.Reason}}
+}
+
+func validatePriority(priority string) error {
+	if model.PriorityRank(priority) == 0 {
+		return &FieldError{Field: "priority", Reason: "must be one of low, medium, high, urgent"}
+	}
+	return nil
+}
+
+// patchableFields are the members of the document a patch is applied to, in
+// the order they are validated.
+var patchableFields = []string{"title", "content", "status", "priority", "due_date"}
+
+// patchDocument renders the patchable fields of the task as decoded JSON.
+func patchDocument(task model.Task) map[string]interface{} {
+	doc := map[string]interface{}{
+		"title":    task.Title,
+		"content":  task.Content,
+		"status":   task.Status,
+		"priority": task.Priority,
+		"due_date": nil,
+	}
+	if task.DueDate != nil {
+		doc["due_date"] = task.DueDate.UTC().Format(time.RFC3339Nano)
+	}
+	return doc
+}
+
+// PatchTask applies a merge patch or a JSON patch to the task's editable
+// fields and writes only the columns it touched. In a merge patch an absent
+// member is left alone while null clears due_date and content.
+func (s *taskServiceImpl) PatchTask(ctx context.Context, id, userID, format string, patch []byte, version int64) (model.Task, error) {
+	task, err := s.editableAt(ctx, id, userID, version)
+	if err != nil {
+		return model.Task{}, err
+	}
+	before := patchDocument(task)
+	var patched interface{}
+	switch format {
+	case MergePatch:
+		patched, err = jsonpatch.Merge(before, patch)
+	case JSONPatch:
+		patched, err = jsonpatch.Apply(before, patch)
+	default:
+		return model.Task{}, ErrPatchFormat
+	}
+	if err != nil {
+		return model.Task{}, err
+	}
+	after, ok := patched.(map[string]interface{})
+	if !ok {
+		return model.Task{}, &FieldError{Field: "document", Reason: "must be an object"}
+	}
+
+	status, fields, err := applyPatch(&task, before, after, usertime.Location(ctx))
+	if err != nil {
+		return model.Task{}, err
+	}
+	if len(fields) == 0 {
+		return task, nil
+	}
+	if task.RRule != "" && task.DueDate == nil {
+		return model.Task{}, ErrRecurrenceNeedsDueDate
+	}
+	task, err = s.saveFields(ctx, task, userID, status, fields)
+	if err != nil {
+		return task, err
+	}
+	s.publish(ctx, event.TaskUpdated, task, userID, map[string]interface{}{"task": task, "fields": fields})
+	return task, nil
+}
+
+// applyPatch copies the members the patch changed onto the task and returns
+// the resulting status, which is saved separately, and the changed columns.
+// An all-day due_date falls due at the end of that day in loc.
+func applyPatch(task *model.Task, before, after map[string]interface{}, loc *time.Location) (string, []string, error) {
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			return "", nil, &FieldError{Field: k, Reason: "cannot be patched"}
+		}
+	}
+	status := task.Status
+	var fields []string
+	for _, k := range patchableFields {
+		// A removed member reads as null; null content is empty content.
+		v := after[k]
+		if k == "content" && v == nil {
+			v = ""
+		}
+		if reflect.DeepEqual(v, before[k]) {
+			continue
+		}
+		s, isString := v.(string)
+		switch k {
+		case "title":
+			if !isString || strings.TrimSpace(s) == "" {
+				return "", nil, &FieldError{Field: k, Reason: "must be a non-empty string"}
+			}
+			if utf8.RuneCountInString(s) > 255 {
+				return "", nil, &FieldError{Field: k, Reason: "must be at most 255 characters"}
+			}
+			task.Title = s
+		case "content":
+			if !isString {
+				return "", nil, &FieldError{Field: k, Reason: "must be a string or null"}
+			}
+			task.Content = s
+		case "status":
+			// Whether the workflow allows it is checked when saving.
+			if !isString || s == "" {
+				return "", nil, &FieldError{Field: k, Reason: "must be a non-empty string"}
+			}
+			status = s
+		case "priority":
+			if err := validatePriority(s); err != nil {
+				return "", nil, err
+			}
+			task.Priority = s
+		case "due_date":
+			if v == nil {
+				task.DueDate = nil
+				break
+			}
+			due, err := usertime.ParseDue(s, loc)
+			if !isString || err != nil {
+				return "", nil, &FieldError{Field: k, Reason: "must be a date, an RFC 3339 timestamp or null"}
+			}
+			task.DueDate = &due
+		}
+		fields = append(fields, k)
+	}
+	return status, fields, nil
+}
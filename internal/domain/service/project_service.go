@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"strings"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/event"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/domain/usertime"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidProjectName        = apperr.New(apperr.Invalid, "project name must be between 1 and 100 characters")
+	ErrInvalidProjectColor       = apperr.New(apperr.Invalid, "project color must be a hex color like #1e90ff")
+	ErrInvalidProjectDescription = apperr.New(apperr.Invalid, "project description must be at most 2000 characters")
+	ErrNoTasksToMove             = apperr.New(apperr.Invalid, "ids must list at least one task")
+)
+
+const maxProjectDescription = 2000
+
+type ProjectService interface {
+	CreateProject(ctx context.Context, userID, name, color, description string) (model.Project, error)
+	ListProjects(ctx context.Context, userID string, includeArchived bool) ([]model.Project, error)
+	GetProject(ctx context.Context, id, userID string) (model.Project, error)
+	UpdateProject(ctx context.Context, id, userID string, name, color, description *string) (model.Project, error)
+	ArchiveProject(ctx context.Context, id, userID string) (model.Project, error)
+	UnarchiveProject(ctx context.Context, id, userID string) (model.Project, error)
+	DeleteProject(ctx context.Context, id, userID string) error
+	ReorderProjects(ctx context.Context, userID string, ids []string) ([]model.Project, error)
+	// SetWorkflow replaces the project's workflow; nil restores the default.
+	SetWorkflow(ctx context.Context, id, userID string, wf *model.Workflow) (model.Project, error)
+
+	ListProjectTasks(ctx context.Context, id, userID, filter string, page repository.PageRequest) (repository.TaskPage, error)
+	SearchProjectTasks(ctx context.Context, id, userID, q string, page repository.PageRequest) (repository.TaskPage, error)
+	ProjectStats(ctx context.Context, id, userID string) (map[string]int64, error)
+	// MoveTasksToProject moves tasks and their subtasks; a nil projectID moves
+	// them back to the inbox.
+	MoveTasksToProject(ctx context.Context, userID string, projectID *string, ids []string) ([]uuid.UUID, error)
+}
+
+type projectServiceImpl struct {
+	repo   repository.ProjectRepository
+	tasks  repository.TaskRepository
+	events event.Publisher
+}
+
+func NewProjectService(repo repository.ProjectRepository, tasks repository.TaskRepository, events event.Publisher) ProjectService {
+	if events == nil {
+		events = event.Nop
+	}
+	return &projectServiceImpl{repo: repo, tasks: tasks, events: events}
+}
+
+func (s *projectServiceImpl) CreateProject(ctx context.Context, userID, name, color, description string) (model.Project, error) {
+	uID, _ := uuid.Parse(userID)
+	project := model.Project{
+		ID:          uuid.New(),
+		UserID:      uID,
+		Name:        strings.TrimSpace(name),
+		Color:       strings.ToLower(color),
+		Description: description,
+	}
+	if err := validateProject(project); err != nil {
+		return model.Project{}, err
+	}
+	return project, s.repo.Create(ctx, &project)
+}
+
+func (s *projectServiceImpl) ListProjects(ctx context.Context, userID string, includeArchived bool) ([]model.Project, error) {
+	return s.repo.List(ctx, userID, includeArchived)
+}
+
+func (s *projectServiceImpl) GetProject(ctx context.Context, id, userID string) (model.Project, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return model.Project{}, repository.ErrProjectNotFound
+	}
+	return s.repo.GetByID(ctx, id, userID)
+}
+
+func (s *projectServiceImpl) UpdateProject(ctx context.Context, id, userID string, name, color, description *string) (model.Project, error) {
+	project, err := s.GetProject(ctx, id, userID)
+	if err != nil {
+		return model.Project{}, err
+	}
+	if name != nil {
+		project.Name = strings.TrimSpace(*name)
+	}
+	if color != nil {
+		project.Color = strings.ToLower(*color)
+	}
+	if description != nil {
+		project.Description = *description
+	}
+	if err := validateProject(project); err != nil {
+		return model.Project{}, err
+	}
+	return project, s.repo.Update(ctx, &project)
+}
+
+func (s *projectServiceImpl) ArchiveProject(ctx context.Context, id, userID string) (model.Project, error) {
+	return s.setArchived(ctx, id, userID, true)
+}
+
+func (s *projectServiceImpl) UnarchiveProject(ctx context.Context, id, userID string) (model.Project, error) {
+	return s.setArchived(ctx, id, userID, false)
+}
+
+func (s *projectServiceImpl) setArchived(ctx context.Context, id, userID string, archived bool) (model.Project, error) {
+	project, err := s.GetProject(ctx, id, userID)
+	if err != nil {
+		return model.Project{}, err
+	}
+	project.Archived = archived
+	return project, s.repo.Update(ctx, &project)
+}
+
+func (s *projectServiceImpl) SetWorkflow(ctx context.Context, id, userID string, wf *model.Workflow) (model.Project, error) {
+	if err := validateWorkflow(wf); err != nil {
+		return model.Project{}, err
+	}
+	project, err := s.GetProject(ctx, id, userID)
+	if err != nil {
+		return model.Project{}, err
+	}
+	project.Workflow = wf
+	return project, s.repo.Update(ctx, &project)
+}
+
+func (s *projectServiceImpl) DeleteProject(ctx context.Context, id, userID string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return repository.ErrProjectNotFound
+	}
+	return s.repo.Delete(ctx, id, userID)
+}
+
+func (s *projectServiceImpl) ReorderProjects(ctx context.Context, userID string, ids []string) ([]model.Project, error) {
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, repository.ErrProjectOrder
+		}
+	}
+	if err := s.repo.Reorder(ctx, userID, ids); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, userID, true)
+}
+
+func (s *projectServiceImpl) ListProjectTasks(ctx context.Context, id, userID, filter string, page repository.PageRequest) (repository.TaskPage, error) {
+	project, err := s.GetProject(ctx, id, userID)
+	if err != nil {
+		return repository.TaskPage{}, err
+	}
+	f, err := ParseTaskFilter(filter, usertime.Now(ctx))
+	if err != nil {
+		return repository.TaskPage{}, err
+	}
+	f.ProjectID = &project.ID
+	return s.tasks.List(ctx, userID, f, page)
+}
+
+func (s *projectServiceImpl) SearchProjectTasks(ctx context.Context, id, userID, q string, page repository.PageRequest) (repository.TaskPage, error) {
+	project, err := s.GetProject(ctx, id, userID)
+	if err != nil {
+		return repository.TaskPage{}, err
+	}
+	f := repository.TaskFilter{ProjectID: &project.ID}
+	if q = strings.TrimSpace(q); q != "" {
+		f.Terms = []string{q}
+	}
+	return s.tasks.List(ctx, userID, f, page)
+}
+
+func (s *projectServiceImpl) ProjectStats(ctx context.Context, id, userID string) (map[string]int64, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, repository.ErrProjectNotFound
+	}
+	return s.repo.Stats(ctx, id, userID)
+}
+
+func (s *projectServiceImpl) MoveTasksToProject(ctx context.Context, userID string, projectID *string, ids []string) ([]uuid.UUID, error) {
+	valid := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err == nil {
+			valid = append(valid, id)
+		}
+	}
+	if len(valid) == 0 {
+		return nil, ErrNoTasksToMove
+	}
+	if projectID != nil {
+		if _, err := uuid.Parse(*projectID); err != nil {
+			return nil, repository.ErrProjectNotFound
+		}
+	}
+	moved, err := s.repo.MoveTasks(ctx, valid, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	uID, _ := uuid.Parse(userID)
+	for _, id := range moved {
+		s.events.Publish(ctx, event.New(event.TaskUpdated, uID, map[string]interface{}{"task_id": id, "project_id": projectID}))
+	}
+	return moved, nil
+}
+
+func validateProject(p model.Project) error {
+	if n := utf8.RuneCountInString(p.Name); n == 0 || n > 100 {
+		return ErrInvalidProjectName
+	}
+	if p.Color != "" && !tagColorPattern.MatchString(p.Color) {
+		return ErrInvalidProjectColor
+	}
+	if utf8.RuneCountInString(p.Description) > maxProjectDescription {
+		return ErrInvalidProjectDescription
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"todo-list/internal/domain/event"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/testutils"
+)
+
+func TestProjectService(t *testing.T) {
+	ctx := context.Background()
+	uID := uuid.New().String()
+	page := repository.PageRequest{Limit: 20}
+
+	t.Run("CreateProject_Validation", func(t *testing.T) {
+		repo := new(testutils.ProjectMocks)
+		s := NewProjectService(repo, new(testutils.AllMocks), nil)
+		repo.On("Create", ctx, mock.MatchedBy(func(p *model.Project) bool {
+			return p.Name == "Work" && p.Color == "#aabbcc"
+		})).Return(nil).Once()
+
+		_, err := s.CreateProject(ctx, uID, "  Work ", "#AABBCC", "")
+		require.NoError(t, err)
+
+		_, err = s.CreateProject(ctx, uID, " ", "", "")
+		assert.ErrorIs(t, err, ErrInvalidProjectName)
+		_, err = s.CreateProject(ctx, uID, "Home", "red", "")
+		assert.ErrorIs(t, err, ErrInvalidProjectColor)
+		_, err = s.CreateProject(ctx, uID, "Home", "", strings.Repeat("x", maxProjectDescription+1))
+		assert.ErrorIs(t, err, ErrInvalidProjectDescription)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("SetWorkflow_Validates", func(t *testing.T) {
+		repo := new(testutils.ProjectMocks)
+		s := NewProjectService(repo, new(testutils.AllMocks), nil)
+		pID := uuid.New()
+		wf := &model.Workflow{Statuses: []string{"todo", "in_progress", "review", "blocked", "done"}}
+		repo.On("GetByID", ctx, pID.String(), uID).Return(model.Project{ID: pID}, nil).Once()
+		repo.On("Update", ctx, mock.MatchedBy(func(p *model.Project) bool { return p.Workflow == wf })).Return(nil).Once()
+
+		p, err := s.SetWorkflow(ctx, pID.String(), uID, wf)
+		require.NoError(t, err)
+		assert.Equal(t, wf, p.Workflow)
+
+		_, err = s.SetWorkflow(ctx, pID.String(), uID, &model.Workflow{Statuses: []string{"todo", "Done"}})
+		assert.ErrorIs(t, err, ErrInvalidWorkflow)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("ListProjectTasks_Scopes_Filter", func(t *testing.T) {
+		repo, tasks := new(testutils.ProjectMocks), new(testutils.AllMocks)
+		s := NewProjectService(repo, tasks, nil)
+		pID := uuid.New()
+		repo.On("GetByID", ctx, pID.String(), uID).Return(model.Project{ID: pID}, nil).Once()
+		tasks.On("List", ctx, uID, mock.MatchedBy(func(f repository.TaskFilter) bool {
+			return f.ProjectID != nil && *f.ProjectID == pID && len(f.Statuses) == 1 && f.Statuses[0] == "todo"
+		}), page).Return(repository.TaskPage{}, nil).Once()
+
+		_, err := s.ListProjectTasks(ctx, pID.String(), uID, "status:todo", page)
+		require.NoError(t, err)
+
+		_, err = s.ListProjectTasks(ctx, "not-a-uuid", uID, "", page)
+		assert.ErrorIs(t, err, repository.ErrProjectNotFound)
+		tasks.AssertExpectations(t)
+	})
+
+	t.Run("MoveTasks_Publishes_Updates", func(t *testing.T) {
+		repo := new(testutils.ProjectMocks)
+		events := &recordingPublisher{}
+		s := NewProjectService(repo, new(testutils.AllMocks), events)
+		pID, parent, child := uuid.New().String(), uuid.New(), uuid.New()
+		repo.On("MoveTasks", ctx, []string{parent.String()}, &pID, uID).Return([]uuid.UUID{parent, child}, nil).Once()
+
+		moved, err := s.MoveTasksToProject(ctx, uID, &pID, []string{parent.String(), "junk"})
+		require.NoError(t, err)
+		assert.Len(t, moved, 2)
+		if assert.Len(t, events.events, 2) {
+			assert.Equal(t, event.TaskUpdated, events.events[1].Type)
+			assert.Equal(t, child, events.events[1].Data["task_id"])
+		}
+
+		_, err = s.MoveTasksToProject(ctx, uID, nil, nil)
+		assert.ErrorIs(t, err, ErrNoTasksToMove)
+	})
+}
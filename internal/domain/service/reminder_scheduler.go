@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"todo-list/config"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+)
+
+// Notifier delivers a fired reminder over one channel.
+type Notifier interface {
+	Notify(ctx context.Context, n model.Notification) error
+}
+
+// maxReminderBackoff caps the 2^attempts minutes retry delay.
+const maxReminderBackoff = time.Hour
+
+// ReminderScheduler polls for due reminders and hands them to the notifier
+// registered for their channel. Several instances may run side by side; the
+// repository lease keeps them from sending the same reminder twice.
+type ReminderScheduler struct {
+	repo      repository.ReminderRepository
+	notifiers map[string]Notifier
+	cfg       *config.ReminderConfig
+	now       func() time.Time
+}
+
+func NewReminderScheduler(repo repository.ReminderRepository, notifiers map[string]Notifier, cfg *config.ReminderConfig) *ReminderScheduler {
+	return &ReminderScheduler{repo: repo, notifiers: notifiers, cfg: cfg, now: time.Now}
+}
+
+// Run polls until ctx is cancelled.
+func (s *ReminderScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("[ERROR] reminders: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims and delivers one batch, returning how many were sent.
+func (s *ReminderScheduler) RunOnce(ctx context.Context) (int, error) {
+	now := s.now()
+	due, err := s.repo.ClaimDue(ctx, now, s.cfg.Lease, s.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, d := range due {
+		if err := s.deliver(ctx, d, now); err != nil {
+			s.fail(ctx, d.Reminder, err, now)
+			continue
+		}
+		if err := s.repo.MarkSent(ctx, d.Reminder.ID, s.now()); err != nil {
+			// The lease will expire and the reminder is sent again: at-least-once.
+			log.Printf("[ERROR] reminders: mark %s sent: %v", d.Reminder.ID, err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func (s *ReminderScheduler) deliver(ctx context.Context, d repository.DueReminder, now time.Time) error {
+	notifier, ok := s.notifiers[d.Reminder.Channel]
+	if !ok {
+		return errChannelUnavailable{channel: d.Reminder.Channel}
+	}
+	target := d.Reminder.Target
+	if target == "" && d.Reminder.Channel == model.ReminderChannelEmail {
+		target = d.Email
+	}
+	fireAt, ok := d.Reminder.FireAt(d.Task.DueDate)
+	if !ok {
+		fireAt = now
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Lease/2)
+	defer cancel()
+	return notifier.Notify(ctx, model.Notification{
+		ReminderID: d.Reminder.ID,
+		Channel:    d.Reminder.Channel,
+		Target:     target,
+		Task:       d.Task,
+		FireAt:     fireAt,
+	})
+}
+
+// fail schedules a retry with exponential backoff, or gives up once the
+// attempts are exhausted or the channel is not configured at all.
+func (s *ReminderScheduler) fail(ctx context.Context, r model.Reminder, cause error, now time.Time) {
+	var retryAt *time.Time
+	if _, permanent := cause.(errChannelUnavailable); !permanent && r.Attempts < s.cfg.MaxAttempts {
+		backoff := maxReminderBackoff
+		if r.Attempts < 6 {
+			backoff = time.Duration(1<<uint(r.Attempts)) * time.Minute
+		}
+		at := now.Add(backoff)
+		retryAt = &at
+	}
+	if err := s.repo.MarkFailed(ctx, r.ID, cause.Error(), retryAt); err != nil {
+		log.Printf("[ERROR] reminders: mark %s failed: %v", r.ID, err)
+	}
+}
+
+type errChannelUnavailable struct {
+	channel string
+}
+
+func (e errChannelUnavailable) Error() string {
+	return fmt.Sprintf("no notifier configured for channel %q", e.channel)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+	"todo-list/config"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/testutils"
+)
+
+type fakeNotifier struct {
+	sent []model.Notification
+	err  error
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, n model.Notification) error {
+	f.sent = append(f.sent, n)
+	return f.err
+}
+
+func TestReminderScheduler_RunOnce(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	cfg := &config.ReminderConfig{Lease: time.Minute, BatchSize: 10, MaxAttempts: 3}
+
+	due := func(channel, target string, attempts int) repository.DueReminder {
+		return repository.DueReminder{
+			Reminder: model.Reminder{ID: uuid.New(), Channel: channel, Target: target, RemindAt: &now, Attempts: attempts},
+			Task:     model.Task{Title: "Pay rent"},
+			Email:    "owner@example.com",
+		}
+	}
+
+	t.Run("Delivers_And_Marks_Sent", func(t *testing.T) {
+		repo := new(testutils.ReminderMocks)
+		email := &fakeNotifier{}
+		s := NewReminderScheduler(repo, map[string]Notifier{model.ReminderChannelEmail: email}, cfg)
+		s.now = func() time.Time { return now }
+
+		d := due(model.ReminderChannelEmail, "", 1)
+		repo.On("ClaimDue", ctx, now, time.Minute, 10).Return([]repository.DueReminder{d}, nil).Once()
+		repo.On("MarkSent", ctx, d.Reminder.ID, now).Return(nil).Once()
+
+		sent, err := s.RunOnce(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		assert.Equal(t, "owner@example.com", email.sent[0].Target)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Failure_Retries_Then_Gives_Up", func(t *testing.T) {
+		repo := new(testutils.ReminderMocks)
+		hook := &fakeNotifier{err: errors.New("503")}
+		s := NewReminderScheduler(repo, map[string]Notifier{model.ReminderChannelWebhook: hook}, cfg)
+		s.now = func() time.Time { return now }
+
+		retry, last, unknown := due(model.ReminderChannelWebhook, "https://x", 2), due(model.ReminderChannelWebhook, "https://x", 3), due("email", "", 1)
+		repo.On("ClaimDue", ctx, now, time.Minute, 10).Return([]repository.DueReminder{retry, last, unknown}, nil).Once()
+		repo.On("MarkFailed", ctx, retry.Reminder.ID, "503", mock.MatchedBy(func(at *time.Time) bool {
+			return at != nil && at.Equal(now.Add(4*time.Minute))
+		})).Return(nil).Once()
+		repo.On("MarkFailed", ctx, last.Reminder.ID, "503", (*time.Time)(nil)).Return(nil).Once()
+		repo.On("MarkFailed", ctx, unknown.Reminder.ID, mock.Anything, (*time.Time)(nil)).Return(nil).Once()
+
+		sent, err := s.RunOnce(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		repo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"net/mail"
+	"time"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/netguard"
+	"todo-list/internal/domain/repository"
+)
+
+var (
+	ErrReminderTiming   = apperr.New(apperr.Invalid, "exactly one of remind_at or offset_minutes is required")
+	ErrReminderOffset   = apperr.New(apperr.Invalid, "offset_minutes must be between 0 and 525600")
+	ErrReminderNeedsDue = apperr.New(apperr.Invalid, "offset reminders need a task with a due date")
+	ErrReminderChannel  = apperr.New(apperr.Invalid, "channel must be email or webhook")
+	ErrReminderTarget   = apperr.New(apperr.Invalid, "target must be an http(s) URL on a public host for webhooks or an email address")
+	ErrTooManyReminders = apperr.New(apperr.Invalid, "too many reminders for this task")
+)
+
+const (
+	maxRemindersPerTask   = 20
+	maxReminderOffsetMins = 60 * 24 * 365
+)
+
+type ReminderService interface {
+	AddReminder(ctx context.Context, taskID, userID string, remindAt *time.Time, offsetMinutes *int, channel, target string) (model.Reminder, error)
+	ListReminders(ctx context.Context, taskID, userID string) ([]model.Reminder, error)
+	DeleteReminder(ctx context.Context, id, taskID, userID string) error
+}
+
+type reminderServiceImpl struct {
+	repo  repository.ReminderRepository
+	tasks repository.TaskRepository
+}
+
+func NewReminderService(repo repository.ReminderRepository, tasks repository.TaskRepository) ReminderService {
+	return &reminderServiceImpl{repo: repo, tasks: tasks}
+}
+
+func (s *reminderServiceImpl) AddReminder(ctx context.Context, taskID, userID string, remindAt *time.Time, offsetMinutes *int, channel, target string) (model.Reminder, error) {
+	if (remindAt == nil) == (offsetMinutes == nil) {
+		return model.Reminder{}, ErrReminderTiming
+	}
+	if offsetMinutes != nil && (*offsetMinutes < 0 || *offsetMinutes > maxReminderOffsetMins) {
+		return model.Reminder{}, ErrReminderOffset
+	}
+	if channel == "" {
+		channel = model.ReminderChannelEmail
+	}
+	if err := validateReminderTarget(channel, target); err != nil {
+		return model.Reminder{}, err
+	}
+	task, err := s.tasks.GetByID(ctx, taskID, userID)
+	if err != nil {
+		return model.Reminder{}, err
+	}
+	if offsetMinutes != nil && task.DueDate == nil {
+		return model.Reminder{}, ErrReminderNeedsDue
+	}
+	existing, err := s.repo.ListByTask(ctx, taskID, userID)
+	if err != nil {
+		return model.Reminder{}, err
+	}
+	if len(existing) >= maxRemindersPerTask {
+		return model.Reminder{}, ErrTooManyReminders
+	}
+	reminder := model.Reminder{
+		ID:            uuid.New(),
+		TaskID:        task.ID,
+		UserID:        task.UserID,
+		RemindAt:      remindAt,
+		OffsetMinutes: offsetMinutes,
+		Channel:       channel,
+		Target:        target,
+	}
+	return reminder, s.repo.Create(ctx, &reminder)
+}
+
+func (s *reminderServiceImpl) ListReminders(ctx context.Context, taskID, userID string) ([]model.Reminder, error) {
+	if _, err := s.tasks.GetByID(ctx, taskID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByTask(ctx, taskID, userID)
+}
+
+func (s *reminderServiceImpl) DeleteReminder(ctx context.Context, id, taskID, userID string) error {
+	return s.repo.Delete(ctx, id, taskID, userID)
+}
+
+// validateReminderTarget allows an empty email target, which means the
+// account's own address.
+func validateReminderTarget(channel, target string) error {
+	switch channel {
+	case model.ReminderChannelEmail:
+		if target == "" {
+			return nil
+		}
+		if _, err := mail.ParseAddress(target); err != nil {
+			return ErrReminderTarget
+		}
+	case model.ReminderChannelWebhook:
+		if !netguard.PublicURL(target) {
+			return ErrReminderTarget
+		}
+	default:
+		return ErrReminderChannel
+	}
+	return nil
+}
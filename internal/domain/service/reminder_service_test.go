@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/testutils"
+)
+
+func TestReminderService(t *testing.T) {
+	reminders := new(testutils.ReminderMocks)
+	tasks := new(testutils.AllMocks)
+	svc := NewReminderService(reminders, tasks)
+	ctx := context.Background()
+	uID := uuid.New().String()
+	tID := uuid.New()
+	offset := 30
+
+	t.Run("Validation", func(t *testing.T) {
+		at := time.Now().Add(time.Hour)
+		_, err := svc.AddReminder(ctx, tID.String(), uID, nil, nil, "", "")
+		assert.ErrorIs(t, err, ErrReminderTiming)
+		_, err = svc.AddReminder(ctx, tID.String(), uID, &at, &offset, "", "")
+		assert.ErrorIs(t, err, ErrReminderTiming)
+		_, err = svc.AddReminder(ctx, tID.String(), uID, &at, nil, "sms", "")
+		assert.ErrorIs(t, err, ErrReminderChannel)
+		_, err = svc.AddReminder(ctx, tID.String(), uID, &at, nil, "webhook", "ftp://example.com")
+		assert.ErrorIs(t, err, ErrReminderTarget)
+		_, err = svc.AddReminder(ctx, tID.String(), uID, &at, nil, "webhook", "http://169.254.169.254/latest/meta-data")
+		assert.ErrorIs(t, err, ErrReminderTarget)
+	})
+
+	t.Run("Offset_NeedsDueDate", func(t *testing.T) {
+		tasks.On("GetByID", ctx, tID.String(), uID).Return(model.Task{ID: tID}, nil).Once()
+		_, err := svc.AddReminder(ctx, tID.String(), uID, nil, &offset, "email", "")
+		assert.ErrorIs(t, err, ErrReminderNeedsDue)
+	})
+
+	t.Run("Offset_Success", func(t *testing.T) {
+		due := time.Now().Add(24 * time.Hour)
+		tasks.On("GetByID", ctx, tID.String(), uID).Return(model.Task{ID: tID, DueDate: &due}, nil).Once()
+		reminders.On("ListByTask", ctx, tID.String(), uID).Return([]model.Reminder{}, nil).Once()
+		reminders.On("Create", ctx, mock.MatchedBy(func(r *model.Reminder) bool {
+			return r.TaskID == tID && *r.OffsetMinutes == 30 && r.Channel == model.ReminderChannelEmail
+		})).Return(nil).Once()
+
+		res, err := svc.AddReminder(ctx, tID.String(), uID, nil, &offset, "", "")
+		assert.NoError(t, err)
+		fireAt, ok := res.FireAt(&due)
+		assert.True(t, ok)
+		assert.Equal(t, due.Add(-30*time.Minute), fireAt)
+	})
+
+	reminders.AssertExpectations(t)
+	tasks.AssertExpectations(t)
+}
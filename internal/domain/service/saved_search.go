@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/domain/usertime"
+)
+
+var (
+	ErrSystemList           = apperr.New(apperr.Forbidden, "system smart lists cannot be changed")
+	ErrTooManySavedSearches = apperr.New(apperr.Invalid, "too many saved searches")
+)
+
+const maxSavedSearchesPerUser = 100
+
+// Keys of the system smart lists.
+const (
+	ListInbox     = "inbox"
+	ListToday     = "today"
+	ListUpcoming  = "upcoming"
+	ListOverdue   = "overdue"
+	ListNoDueDate = "no-due-date"
+)
+
+// systemLists are the smart lists every user has, in the order they are shown.
+var systemLists = []model.SavedSearch{
+	{Key: ListInbox, Name: "Inbox", Filter: "project:none -status:done"},
+	{Key: ListToday, Name: "Today", Filter: "due:today"},
+	{Key: ListUpcoming, Name: "Upcoming 7 days", Filter: "due>=today due<+7d -status:done", Sort: repository.SortDueDate},
+	{Key: ListOverdue, Name: "Overdue", Filter: "due<now -status:done", Sort: repository.SortDueDate},
+	{Key: ListNoDueDate, Name: "No due date", Filter: "due:none -status:done"},
+}
+
+func systemList(key string) (model.SavedSearch, bool) {
+	for _, l := range systemLists {
+		if l.Key == key {
+			return l, true
+		}
+	}
+	return model.SavedSearch{}, false
+}
+
+// runSavedSearch resolves the filter against the current time and lists the
+// matching tasks. The search's own order applies unless the page asks for a
+// sort.
+func runSavedSearch(ctx context.Context, tasks repository.TaskRepository, userID string, search model.SavedSearch, page repository.PageRequest) (repository.TaskPage, error) {
+	f, err := ParseTaskFilter(search.Filter, usertime.Now(ctx))
+	if err != nil {
+		return repository.TaskPage{}, err
+	}
+	if page.Sort == "" && search.Sort != "" {
+		page.Sort, page.Desc = search.Sort, search.Desc
+	}
+	return tasks.List(ctx, userID, f, page)
+}
+
+type SavedSearchService interface {
+	// ListSavedSearches returns the system smart lists followed by the
+	// user's own saved searches.
+	ListSavedSearches(ctx context.Context, userID string) ([]model.SavedSearch, error)
+	// GetSavedSearch accepts the ID of a saved search or the key of a system
+	// list, as do the other methods.
+	GetSavedSearch(ctx context.Context, id, userID string) (model.SavedSearch, error)
+	CreateSavedSearch(ctx context.Context, userID, name, filter, sort string, desc bool) (model.SavedSearch, error)
+	UpdateSavedSearch(ctx context.Context, id, userID, name, filter, sort string, desc bool) (model.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, id, userID string) error
+	RunSavedSearch(ctx context.Context, id, userID string, page repository.PageRequest) (repository.TaskPage, error)
+}
+
+type savedSearchServiceImpl struct {
+	repo  repository.SavedSearchRepository
+	tasks repository.TaskRepository
+}
+
+func NewSavedSearchService(repo repository.SavedSearchRepository, tasks repository.TaskRepository) SavedSearchService {
+	return &savedSearchServiceImpl{repo: repo, tasks: tasks}
+}
+
+func (s *savedSearchServiceImpl) ListSavedSearches(ctx context.Context, userID string) ([]model.SavedSearch, error) {
+	saved, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]model.SavedSearch{}, systemLists...), saved...), nil
+}
+
+func (s *savedSearchServiceImpl) GetSavedSearch(ctx context.Context, id, userID string) (model.SavedSearch, error) {
+	if l, ok := systemList(id); ok {
+		return l, nil
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return model.SavedSearch{}, repository.ErrSavedSearchNotFound
+	}
+	return s.repo.GetByID(ctx, id, userID)
+}
+
+func (s *savedSearchServiceImpl) CreateSavedSearch(ctx context.Context, userID, name, filter, sort string, desc bool) (model.SavedSearch, error) {
+	if err := validateSavedSearch(filter, sort); err != nil {
+		return model.SavedSearch{}, err
+	}
+	existing, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return model.SavedSearch{}, err
+	}
+	if len(existing) >= maxSavedSearchesPerUser {
+		return model.SavedSearch{}, ErrTooManySavedSearches
+	}
+	uID, _ := uuid.Parse(userID)
+	search := model.SavedSearch{
+		ID:     uuid.New(),
+		UserID: uID,
+		Name:   strings.TrimSpace(name),
+		Filter: strings.TrimSpace(filter),
+		Sort:   sort,
+		Desc:   desc,
+	}
+	return search, s.repo.Create(ctx, &search)
+}
+
+func (s *savedSearchServiceImpl) UpdateSavedSearch(ctx context.Context, id, userID, name, filter, sort string, desc bool) (model.SavedSearch, error) {
+	search, err := s.GetSavedSearch(ctx, id, userID)
+	if err != nil {
+		return model.SavedSearch{}, err
+	}
+	if search.System() {
+		return model.SavedSearch{}, ErrSystemList
+	}
+	if err := validateSavedSearch(filter, sort); err != nil {
+		return model.SavedSearch{}, err
+	}
+	search.Name = strings.TrimSpace(name)
+	search.Filter = strings.TrimSpace(filter)
+	search.Sort = sort
+	search.Desc = desc
+	return search, s.repo.Update(ctx, &search)
+}
+
+func (s *savedSearchServiceImpl) DeleteSavedSearch(ctx context.Context, id, userID string) error {
+	if _, ok := systemList(id); ok {
+		return ErrSystemList
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return repository.ErrSavedSearchNotFound
+	}
+	return s.repo.Delete(ctx, id, userID)
+}
+
+func (s *savedSearchServiceImpl) RunSavedSearch(ctx context.Context, id, userID string, page repository.PageRequest) (repository.TaskPage, error) {
+	search, err := s.GetSavedSearch(ctx, id, userID)
+	if err != nil {
+		return repository.TaskPage{}, err
+	}
+	return runSavedSearch(ctx, s.tasks, userID, search, page)
+}
+
+// validateSavedSearch parses the filter once so that a broken expression is
+// rejected when it is saved rather than every time it is run.
+func validateSavedSearch(filter, sort string) error {
+	f, err := ParseTaskFilter(filter, time.Now())
+	if err != nil {
+		return err
+	}
+	if !repository.ValidSort(sort) {
+		return repository.ErrInvalidSort
+	}
+	if sort == repository.SortRelevance && len(f.Terms) == 0 {
+		return apperr.Field("sort", "relevance needs search text in the filter")
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/testutils"
+)
+
+func TestSavedSearchService(t *testing.T) {
+	ctx := context.Background()
+	uID := uuid.New().String()
+
+	t.Run("List_Puts_System_Lists_First", func(t *testing.T) {
+		repo := new(testutils.SavedSearchMocks)
+		s := NewSavedSearchService(repo, new(testutils.AllMocks))
+		mine := model.SavedSearch{ID: uuid.New(), Name: "Работа", Filter: "tag:work"}
+		repo.On("List", ctx, uID).Return([]model.SavedSearch{mine}, nil).Once()
+
+		lists, err := s.ListSavedSearches(ctx, uID)
+		require.NoError(t, err)
+		require.Len(t, lists, len(systemLists)+1)
+		assert.Equal(t, ListInbox, lists[0].Key)
+		assert.Equal(t, mine, lists[len(lists)-1])
+	})
+
+	t.Run("Create_Validates_Filter", func(t *testing.T) {
+		repo := new(testutils.SavedSearchMocks)
+		s := NewSavedSearchService(repo, new(testutils.AllMocks))
+
+		_, err := s.CreateSavedSearch(ctx, uID, "Плохой", "color:red", "", false)
+		var filterErr *FilterError
+		assert.ErrorAs(t, err, &filterErr)
+
+		_, err = s.CreateSavedSearch(ctx, uID, "Без текста", "priority:high", repository.SortRelevance, true)
+		assert.Error(t, err)
+
+		repo.On("List", ctx, uID).Return([]model.SavedSearch{}, nil).Once()
+		repo.On("Create", ctx, mock.AnythingOfType("*model.SavedSearch")).Return(nil).Once()
+		created, err := s.CreateSavedSearch(ctx, uID, "  Срочное на неделе ", "priority:high,urgent due<=+7d", repository.SortDueDate, false)
+		require.NoError(t, err)
+		assert.Equal(t, "Срочное на неделе", created.Name)
+		assert.Equal(t, uID, created.UserID.String())
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("System_Lists_Are_Read_Only", func(t *testing.T) {
+		s := NewSavedSearchService(new(testutils.SavedSearchMocks), new(testutils.AllMocks))
+
+		_, err := s.UpdateSavedSearch(ctx, ListToday, uID, "Сегодня", "due:today", "", true)
+		assert.ErrorIs(t, err, ErrSystemList)
+		assert.ErrorIs(t, s.DeleteSavedSearch(ctx, ListOverdue, uID), ErrSystemList)
+
+		_, err = s.GetSavedSearch(ctx, "someday", uID)
+		assert.ErrorIs(t, err, repository.ErrSavedSearchNotFound)
+	})
+
+	t.Run("Run_Resolves_Dates_And_Applies_Saved_Order", func(t *testing.T) {
+		repo := new(testutils.SavedSearchMocks)
+		tasks := new(testutils.AllMocks)
+		s := NewSavedSearchService(repo, tasks)
+		id := uuid.New()
+		repo.On("GetByID", ctx, id.String(), uID).Return(model.SavedSearch{ID: id, Filter: "due<+7d", Sort: repository.SortDueDate}, nil).Twice()
+
+		week := time.Now().AddDate(0, 0, 6)
+		tasks.On("List", ctx, uID, mock.MatchedBy(func(f repository.TaskFilter) bool {
+			return f.DueBefore != nil && f.DueBefore.After(week)
+		}), repository.PageRequest{Limit: 10, Sort: repository.SortDueDate}).Return(repository.TaskPage{}, nil).Once()
+		_, err := s.RunSavedSearch(ctx, id.String(), uID, repository.PageRequest{Limit: 10, Desc: true})
+		require.NoError(t, err)
+
+		// Явная сортировка из запроса важнее сохранённой
+		explicit := repository.PageRequest{Sort: repository.SortPriority, Desc: true}
+		tasks.On("List", ctx, uID, mock.Anything, explicit).Return(repository.TaskPage{}, nil).Once()
+		_, err = s.RunSavedSearch(ctx, id.String(), uID, explicit)
+		require.NoError(t, err)
+		tasks.AssertExpectations(t)
+	})
+
+	t.Run("Run_System_List", func(t *testing.T) {
+		tasks := new(testutils.AllMocks)
+		s := NewSavedSearchService(new(testutils.SavedSearchMocks), tasks)
+		tasks.On("List", ctx, uID, mock.MatchedBy(func(f repository.TaskFilter) bool {
+			return f.HasProject != nil && !*f.HasProject && assert.ObjectsAreEqual([]string{"done"}, f.ExcludeStatuses)
+		}), mock.Anything).Return(repository.TaskPage{Tasks: []model.Task{{Title: "Разобрать"}}}, nil).Once()
+
+		res, err := s.RunSavedSearch(ctx, ListInbox, uID, repository.PageRequest{})
+		require.NoError(t, err)
+		assert.Len(t, res.Tasks, 1)
+	})
+}
+
+func TestSystemLists_Parse(t *testing.T) {
+	for _, l := range systemLists {
+		assert.NoError(t, validateSavedSearch(l.Filter, l.Sort), l.Key)
+	}
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"regexp"
+	"strings"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/event"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidTagName  = apperr.New(apperr.Invalid, "tag name must be between 1 and 100 characters")
+	ErrInvalidTagColor = apperr.New(apperr.Invalid, "tag color must be a hex color like #1e90ff")
+	ErrMergeIntoSelf   = apperr.New(apperr.Invalid, "cannot merge a tag into itself")
+)
+
+var tagColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type TagService interface {
+	ListTags(ctx context.Context, userID string) ([]repository.TagUsage, error)
+	CreateTag(ctx context.Context, userID, name, color string) (model.Tag, error)
+	UpdateTag(ctx context.Context, id uint, userID string, name, color *string) (model.Tag, error)
+	MergeTags(ctx context.Context, sourceID, targetID uint, userID string) (model.Tag, error)
+	DeleteTag(ctx context.Context, id uint, userID string) error
+}
+
+type tagServiceImpl struct {
+	repo   repository.TagRepository
+	tasks  repository.TaskRepository
+	events event.Publisher
+}
+
+// NewTagService wires the service; a nil publisher discards events.
+func NewTagService(repo repository.TagRepository, tasks repository.TaskRepository, events event.Publisher) TagService {
+	if events == nil {
+		events = event.Nop
+	}
+	return &tagServiceImpl{repo: repo, tasks: tasks, events: events}
+}
+
+// publishTagged tells everyone following the given tasks that the tags they
+// show have changed. Tasks in the trash are skipped.
+func (s *tagServiceImpl) publishTagged(ctx context.Context, ids []uuid.UUID, userID string) {
+	for _, id := range ids {
+		task, err := s.tasks.GetByID(ctx, id.String(), userID)
+		if err != nil {
+			continue
+		}
+		publishTask(ctx, s.events, event.TaskUpdated, task, userID, map[string]interface{}{"task": task})
+	}
+}
+
+func (s *tagServiceImpl) ListTags(ctx context.Context, userID string) ([]repository.TagUsage, error) {
+	return s.repo.List(ctx, userID)
+}
+
+func (s *tagServiceImpl) CreateTag(ctx context.Context, userID, name, color string) (model.Tag, error) {
+	uID, _ := uuid.Parse(userID)
+	tag := model.Tag{UserID: uID, Name: strings.TrimSpace(name), Color: strings.ToLower(color)}
+	if err := validateTag(tag); err != nil {
+		return model.Tag{}, err
+	}
+	return tag, s.repo.Create(ctx, &tag)
+}
+
+func (s *tagServiceImpl) UpdateTag(ctx context.Context, id uint, userID string, name, color *string) (model.Tag, error) {
+	tag, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return model.Tag{}, err
+	}
+	if name != nil {
+		tag.Name = strings.TrimSpace(*name)
+	}
+	if color != nil {
+		tag.Color = strings.ToLower(*color)
+	}
+	if err := validateTag(tag); err != nil {
+		return model.Tag{}, err
+	}
+	tagged, err := s.repo.Update(ctx, &tag)
+	if err != nil {
+		return tag, err
+	}
+	s.publishTagged(ctx, tagged, userID)
+	return tag, nil
+}
+
+func (s *tagServiceImpl) MergeTags(ctx context.Context, sourceID, targetID uint, userID string) (model.Tag, error) {
+	if sourceID == targetID {
+		return model.Tag{}, ErrMergeIntoSelf
+	}
+	tagged, err := s.repo.Merge(ctx, sourceID, targetID, userID)
+	if err != nil {
+		return model.Tag{}, err
+	}
+	s.publishTagged(ctx, tagged, userID)
+	return s.repo.GetByID(ctx, targetID, userID)
+}
+
+func (s *tagServiceImpl) DeleteTag(ctx context.Context, id uint, userID string) error {
+	tagged, err := s.repo.Delete(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	s.publishTagged(ctx, tagged, userID)
+	return nil
+}
+
+func validateTag(tag model.Tag) error {
+	if n := utf8.RuneCountInString(tag.Name); n == 0 || n > 100 {
+		return ErrInvalidTagName
+	}
+	if tag.Color != "" && !tagColorPattern.MatchString(tag.Color) {
+		return ErrInvalidTagColor
+	}
+	return nil
+}
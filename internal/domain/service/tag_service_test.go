@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"todo-list/internal/domain/event"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/testutils"
+)
+
+func TestTagService(t *testing.T) {
+	repo := new(testutils.TagMocks)
+	tasks := new(testutils.AllMocks)
+	events := &recordingPublisher{}
+	svc := NewTagService(repo, tasks, events)
+	ctx := context.Background()
+	uID := uuid.New().String()
+
+	t.Run("CreateTag_Normalizes", func(t *testing.T) {
+		repo.On("Create", ctx, mock.MatchedBy(func(tag *model.Tag) bool {
+			return tag.Name == "work" && tag.Color == "#1e90ff" && tag.UserID.String() == uID
+		})).Return(nil).Once()
+		res, err := svc.CreateTag(ctx, uID, "  work ", "#1E90FF")
+		assert.NoError(t, err)
+		assert.Equal(t, "work", res.Name)
+	})
+
+	t.Run("CreateTag_Invalid", func(t *testing.T) {
+		_, err := svc.CreateTag(ctx, uID, "  ", "")
+		assert.ErrorIs(t, err, ErrInvalidTagName)
+		_, err = svc.CreateTag(ctx, uID, "home", "red")
+		assert.ErrorIs(t, err, ErrInvalidTagColor)
+	})
+
+	t.Run("UpdateTag_Partial", func(t *testing.T) {
+		repo.On("GetByID", ctx, uint(7), uID).Return(model.Tag{ID: 7, Name: "old", Color: "#000000"}, nil).Once()
+		repo.On("Update", ctx, mock.MatchedBy(func(tag *model.Tag) bool {
+			return tag.Name == "new" && tag.Color == "#000000"
+		})).Return([]uuid.UUID(nil), nil).Once()
+		name := "new"
+		res, err := svc.UpdateTag(ctx, 7, uID, &name, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "new", res.Name)
+	})
+
+	// Задачи с переименованным тегом уходят подписчикам как task.updated
+	t.Run("UpdateTag_PublishesTasks", func(t *testing.T) {
+		tagged, trashed := uuid.New(), uuid.New()
+		watcher := uuid.New()
+		repo.On("GetByID", ctx, uint(9), uID).Return(model.Tag{ID: 9, Name: "old"}, nil).Once()
+		repo.On("Update", ctx, mock.Anything).Return([]uuid.UUID{tagged, trashed}, nil).Once()
+		tasks.On("GetByID", ctx, tagged.String(), uID).
+			Return(model.Task{ID: tagged, Watchers: []model.TaskWatcher{{UserID: watcher}}}, nil).Once()
+		tasks.On("GetByID", ctx, trashed.String(), uID).Return(model.Task{}, errors.New("not found")).Once()
+		events.events = nil
+
+		name := "renamed"
+		_, err := svc.UpdateTag(ctx, 9, uID, &name, nil)
+		assert.NoError(t, err)
+		require.Len(t, events.events, 2)
+		assert.Equal(t, event.TaskUpdated, events.events[0].Type)
+		assert.Equal(t, watcher, events.events[1].UserID)
+	})
+
+	t.Run("UpdateTag_NotFound", func(t *testing.T) {
+		repo.On("GetByID", ctx, uint(8), uID).Return(model.Tag{}, repository.ErrTagNotFound).Once()
+		_, err := svc.UpdateTag(ctx, 8, uID, nil, nil)
+		assert.ErrorIs(t, err, repository.ErrTagNotFound)
+	})
+
+	t.Run("MergeTags", func(t *testing.T) {
+		_, err := svc.MergeTags(ctx, 3, 3, uID)
+		assert.ErrorIs(t, err, ErrMergeIntoSelf)
+
+		repo.On("Merge", ctx, uint(3), uint(4), uID).Return([]uuid.UUID(nil), nil).Once()
+		repo.On("GetByID", ctx, uint(4), uID).Return(model.Tag{ID: 4, Name: "target"}, nil).Once()
+		res, err := svc.MergeTags(ctx, 3, 4, uID)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(4), res.ID)
+	})
+
+	t.Run("DeleteTag", func(t *testing.T) {
+		repo.On("Delete", ctx, uint(5), uID).Return([]uuid.UUID(nil), repository.ErrTagNotFound).Once()
+		assert.ErrorIs(t, svc.DeleteTag(ctx, 5, uID), repository.ErrTagNotFound)
+	})
+
+	repo.AssertExpectations(t)
+	tasks.AssertExpectations(t)
+}
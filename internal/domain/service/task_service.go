@@ -4,149 +4,656 @@ import (
 	"context"
 	"github.com/google/uuid"
 	"time"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/event"
 	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/recurrence"
 	"todo-list/internal/domain/repository"
+	"todo-list/internal/domain/usertime"
+)
+
+var (
+	ErrRecurrenceNeedsDueDate = apperr.New(apperr.Unprocessable, "recurring tasks need a due date")
+	ErrNotRecurring           = apperr.New(apperr.Invalid, "task does not repeat")
 )
 
 type TaskService interface {
-	CreateTask(ctx context.Context, userID, title, content, status, priority string, due *time.Time) (model.Task, error)
-	GetAllTasks(ctx context.Context, userID string) ([]model.Task, error)
+	CreateTask(ctx context.Context, userID, title, content, status, priority string, due *time.Time, projectID, workspaceID *string) (model.Task, error)
+	GetAllTasks(ctx context.Context, userID string, page repository.PageRequest) (repository.TaskPage, error)
+	ListTasks(ctx context.Context, userID, filter string, page repository.PageRequest) (repository.TaskPage, error)
 	GetTaskByID(ctx context.Context, id, userID string) (model.Task, error)
-	UpdateTask(ctx context.Context, id, userID, title, content, status, priority string, due *time.Time) (model.Task, error)
-	DeleteTask(ctx context.Context, id, userID string) error
-	ChangeStatus(ctx context.Context, id, userID, status string) (model.Task, error)
-	GetTasksByStatus(ctx context.Context, status, userID string) ([]model.Task, error)
-	SearchTasks(ctx context.Context, q, userID string) ([]model.Task, error)
-	GetTodayTasks(ctx context.Context, userID string) ([]model.Task, error)
-	GetOverdueTasks(ctx context.Context, userID string) ([]model.Task, error)
-	ArchiveTask(ctx context.Context, id, userID string) (model.Task, error)
-	UnarchiveTask(ctx context.Context, id, userID string) (model.Task, error)
-	ChangePriority(ctx context.Context, id, userID, priority string) (model.Task, error)
-	GetTasksByPriority(ctx context.Context, priority, userID string) ([]model.Task, error)
-	AddTag(ctx context.Context, id, userID, tag string) (model.Task, error)
-	RemoveTag(ctx context.Context, id, userID, tag string) (model.Task, error)
-	GetTasksByTag(ctx context.Context, tag, userID string) ([]model.Task, error)
+	// Methods that change a task take the version the client last saw and
+	// fail with repository.ErrVersionConflict when it is not zero and the task
+	// has moved on from it. CreateSubtask checks the parent's version.
+	UpdateTask(ctx context.Context, id, userID, title, content, status, priority string, due *time.Time, version int64) (model.Task, error)
+	DeleteTask(ctx context.Context, id, userID string, version int64) error
+	ChangeStatus(ctx context.Context, id, userID, status string, version int64) (model.Task, error)
+	GetTasksByStatus(ctx context.Context, status, userID string, page repository.PageRequest) (repository.TaskPage, error)
+	SearchTasks(ctx context.Context, q, userID string, page repository.PageRequest) (repository.TaskPage, error)
+	GetTodayTasks(ctx context.Context, userID string, page repository.PageRequest) (repository.TaskPage, error)
+	GetOverdueTasks(ctx context.Context, userID string, page repository.PageRequest) (repository.TaskPage, error)
+	ArchiveTask(ctx context.Context, id, userID string, version int64) (model.Task, error)
+	UnarchiveTask(ctx context.Context, id, userID string, version int64) (model.Task, error)
+	ChangePriority(ctx context.Context, id, userID, priority string, version int64) (model.Task, error)
+	GetTasksByPriority(ctx context.Context, priority, userID string, page repository.PageRequest) (repository.TaskPage, error)
+	AddTag(ctx context.Context, id, userID, tag string, version int64) (model.Task, error)
+	RemoveTag(ctx context.Context, id, userID, tag string, version int64) (model.Task, error)
+	GetTasksByTag(ctx context.Context, tag, userID string, page repository.PageRequest) (repository.TaskPage, error)
+	AssignTask(ctx context.Context, id, userID, assigneeID string, version int64) (model.Task, error)
+	UnassignTask(ctx context.Context, id, userID, assigneeID string, version int64) (model.Task, error)
+	WatchTask(ctx context.Context, id, userID string) (model.Task, error)
+	UnwatchTask(ctx context.Context, id, userID string) (model.Task, error)
+	ListAssignedTasks(ctx context.Context, userID, filter string, page repository.PageRequest) (repository.TaskPage, error)
+	ListWatchedTasks(ctx context.Context, userID, filter string, page repository.PageRequest) (repository.TaskPage, error)
 	BulkDelete(ctx context.Context, ids []string, userID string) error
 	BulkUpdateStatus(ctx context.Context, ids []string, status, userID string) error
 	Stats(ctx context.Context, userID string) (map[string]int64, error)
+	CreateSubtask(ctx context.Context, parentID, userID, title, content, status, priority string, due *time.Time, version int64) (model.Task, error)
+	MoveTask(ctx context.Context, id, userID string, parentID *string, version int64) (model.Task, error)
+	GetTaskTree(ctx context.Context, id, userID string) (*model.TaskNode, error)
+	AddTaskDependency(ctx context.Context, id, userID, blockerID string, version int64) (model.Task, error)
+	RemoveTaskDependency(ctx context.Context, id, userID, blockerID string, version int64) (model.Task, error)
+	GetTaskDependencies(ctx context.Context, id, userID string) (model.DependencyGraph, error)
+	SetRecurrence(ctx context.Context, id, userID, rrule string, version int64) (model.Task, error)
+	GetOccurrences(ctx context.Context, id, userID string, from, to time.Time, limit int) ([]time.Time, error)
+	// GetTaskHistory lists the task's revisions, newest first.
+	GetTaskHistory(ctx context.Context, id, userID string) ([]model.TaskRevision, error)
+	RevertTask(ctx context.Context, id, userID string, revision int, version int64) (model.Task, error)
+	// PatchTask takes a patch in one of the MergePatch or JSONPatch formats.
+	PatchTask(ctx context.Context, id, userID, format string, patch []byte, version int64) (model.Task, error)
+	ListTrash(ctx context.Context, userID string, page repository.PageRequest) (repository.TaskPage, error)
+	RestoreTask(ctx context.Context, id, userID string, version int64) (model.Task, error)
+	BulkRestore(ctx context.Context, ids []string, userID string) error
+	// PurgeTask erases a deleted task for good; in a workspace only owners may.
+	PurgeTask(ctx context.Context, id, userID string, version int64) error
 }
 
 type taskServiceImpl struct {
-	repo repository.TaskRepository
+	repo       repository.TaskRepository
+	workspaces repository.WorkspaceRepository
+	events     event.Publisher
+}
+
+// NewTaskService wires the service; a nil publisher discards events.
+func NewTaskService(repo repository.TaskRepository, workspaces repository.WorkspaceRepository, events event.Publisher) TaskService {
+	if events == nil {
+		events = event.Nop
+	}
+	return &taskServiceImpl{repo: repo, workspaces: workspaces, events: events}
+}
+
+func (s *taskServiceImpl) publish(ctx context.Context, t event.Type, task model.Task, userID string, data map[string]interface{}, also ...uuid.UUID) {
+	publishTask(ctx, s.events, t, task, userID, data, also...)
+}
+
+// publishTask sends the event to the acting user and to everyone who follows
+// the task: its owner, assignees and watchers, and any users in also. Each of
+// them gets a copy carrying the same event ID.
+func publishTask(ctx context.Context, events event.Publisher, t event.Type, task model.Task, userID string, data map[string]interface{}, also ...uuid.UUID) {
+	actor, _ := uuid.Parse(userID)
+	e := event.New(t, actor, data)
+	events.Publish(ctx, e)
+	sent := map[uuid.UUID]bool{actor: true, uuid.Nil: true}
+	recipients := []uuid.UUID{task.UserID}
+	for _, a := range task.Assignees {
+		recipients = append(recipients, a.UserID)
+	}
+	for _, w := range task.Watchers {
+		recipients = append(recipients, w.UserID)
+	}
+	for _, id := range append(recipients, also...) {
+		if sent[id] {
+			continue
+		}
+		sent[id] = true
+		e.UserID = id
+		events.Publish(ctx, e)
+	}
 }
 
-func NewTaskService(repo repository.TaskRepository) TaskService {
-	return &taskServiceImpl{repo: repo}
+// authorize checks that the user holds at least the needed role in the
+// workspace. Personal tasks are only visible to their owner, who may do
+// anything with them.
+func (s *taskServiceImpl) authorize(ctx context.Context, workspaceID *uuid.UUID, userID, need string) error {
+	if workspaceID == nil {
+		return nil
+	}
+	role, err := s.workspaces.Role(ctx, workspaceID.String(), userID)
+	if err != nil {
+		return err
+	}
+	if !model.RoleAllows(role, need) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// editable loads the task and checks that the user may change it.
+func (s *taskServiceImpl) editable(ctx context.Context, id, userID string) (model.Task, error) {
+	task, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return model.Task{}, err
+	}
+	if err := s.authorize(ctx, task.WorkspaceID, userID, model.RoleEditor); err != nil {
+		return model.Task{}, err
+	}
+	return task, nil
 }
 
-func (s *taskServiceImpl) CreateTask(ctx context.Context, userID, title, content, status, priority string, due *time.Time) (model.Task, error) {
+// editableAt is editable with a precondition on the task's version.
+func (s *taskServiceImpl) editableAt(ctx context.Context, id, userID string, version int64) (model.Task, error) {
+	task, err := s.editable(ctx, id, userID)
+	if err != nil {
+		return model.Task{}, err
+	}
+	if err := checkVersion(task, version); err != nil {
+		return model.Task{}, err
+	}
+	return task, nil
+}
+
+// checkVersion fails unless the task is still at the version the client last
+// saw; zero accepts any version.
+func checkVersion(task model.Task, version int64) error {
+	if version != 0 && task.Version != version {
+		return repository.ErrVersionConflict
+	}
+	return nil
+}
+
+// ensureEditable checks every visible task among ids and returns them by ID;
+// ids the user cannot see are left for the repository to skip.
+func (s *taskServiceImpl) ensureEditable(ctx context.Context, ids []string, userID string) (map[uuid.UUID]model.Task, error) {
+	tasks := make(map[uuid.UUID]model.Task, len(ids))
+	for _, id := range ids {
+		task, err := s.repo.GetByID(ctx, id, userID)
+		if err != nil {
+			continue
+		}
+		if err := s.authorize(ctx, task.WorkspaceID, userID, model.RoleEditor); err != nil {
+			return nil, err
+		}
+		tasks[task.ID] = task
+	}
+	return tasks, nil
+}
+
+func (s *taskServiceImpl) CreateTask(ctx context.Context, userID, title, content, status, priority string, due *time.Time, projectID, workspaceID *string) (model.Task, error) {
 	uID, _ := uuid.Parse(userID)
 	if status == "" {
-		status = "todo"
+		status = model.StatusTodo
 	}
 	if priority == "" {
 		priority = "medium"
 	}
+	if err := validatePriority(priority); err != nil {
+		return model.Task{}, err
+	}
 	task := model.Task{
-		UserID: uID, Title: title, Content: content, Status: status, Priority: priority, DueDate: due,
+		UserID: uID, Title: title, Content: content, Priority: priority, DueDate: due,
 	}
-	return task, s.repo.Create(ctx, &task)
+	if projectID != nil {
+		pID, err := uuid.Parse(*projectID)
+		if err != nil {
+			return model.Task{}, repository.ErrProjectNotFound
+		}
+		task.ProjectID = &pID
+	}
+	if workspaceID != nil {
+		wsID, err := uuid.Parse(*workspaceID)
+		if err != nil {
+			return model.Task{}, repository.ErrWorkspaceNotFound
+		}
+		task.WorkspaceID = &wsID
+	}
+	if err := s.authorize(ctx, task.WorkspaceID, userID, model.RoleEditor); err != nil {
+		return model.Task{}, err
+	}
+	if err := s.checkTransition(ctx, task, status); err != nil {
+		return model.Task{}, err
+	}
+	task.SetStatus(status, time.Now())
+	if err := s.repo.Create(ctx, &task); err != nil {
+		return task, err
+	}
+	s.publish(ctx, event.TaskCreated, task, userID, map[string]interface{}{"task": task})
+	return task, nil
 }
 
-func (s *taskServiceImpl) GetAllTasks(ctx context.Context, userID string) ([]model.Task, error) {
-	return s.repo.GetAll(ctx, userID)
+func (s *taskServiceImpl) GetAllTasks(ctx context.Context, userID string, page repository.PageRequest) (repository.TaskPage, error) {
+	return s.repo.GetAll(ctx, userID, page)
+}
+
+func (s *taskServiceImpl) ListTasks(ctx context.Context, userID, filter string, page repository.PageRequest) (repository.TaskPage, error) {
+	f, err := ParseTaskFilter(filter, usertime.Now(ctx))
+	if err != nil {
+		return repository.TaskPage{}, err
+	}
+	return s.repo.List(ctx, userID, f, page)
 }
 
 func (s *taskServiceImpl) GetTaskByID(ctx context.Context, id, userID string) (model.Task, error) {
 	return s.repo.GetByID(ctx, id, userID)
 }
 
-func (s *taskServiceImpl) UpdateTask(ctx context.Context, id, userID, title, content, status, priority string, due *time.Time) (model.Task, error) {
-	task, err := s.repo.GetByID(ctx, id, userID)
+func (s *taskServiceImpl) UpdateTask(ctx context.Context, id, userID, title, content, status, priority string, due *time.Time, version int64) (model.Task, error) {
+	task, err := s.editableAt(ctx, id, userID, version)
 	if err != nil {
 		return model.Task{}, err
 	}
+	// Status and priority left out of the request keep their current values.
+	if status == "" {
+		status = task.Status
+	}
+	if priority == "" {
+		priority = task.Priority
+	}
+	if err := validatePriority(priority); err != nil {
+		return model.Task{}, err
+	}
 	task.Title = title
 	task.Content = content
-	task.Status = status
 	task.Priority = priority
 	task.DueDate = due
-	err = s.repo.Update(ctx, &task)
-	return task, err
+	task, err = s.saveWithStatus(ctx, task, userID, status)
+	if err != nil {
+		return task, err
+	}
+	s.publish(ctx, event.TaskUpdated, task, userID, map[string]interface{}{"task": task})
+	return task, nil
 }
 
-func (s *taskServiceImpl) DeleteTask(ctx context.Context, id, userID string) error {
-	return s.repo.Delete(ctx, id, userID)
+func (s *taskServiceImpl) DeleteTask(ctx context.Context, id, userID string, version int64) error {
+	task, err := s.editableAt(ctx, id, userID, version)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id, userID); err != nil {
+		return err
+	}
+	s.publish(ctx, event.TaskDeleted, task, userID, map[string]interface{}{"task_id": id})
+	return nil
 }
 
-func (s *taskServiceImpl) ChangeStatus(ctx context.Context, id, userID, status string) (model.Task, error) {
-	task, err := s.repo.GetByID(ctx, id, userID)
+func (s *taskServiceImpl) ChangeStatus(ctx context.Context, id, userID, status string, version int64) (model.Task, error) {
+	task, err := s.editableAt(ctx, id, userID, version)
 	if err != nil {
 		return model.Task{}, err
 	}
-	task.Status = status
-	err = s.repo.Update(ctx, &task)
-	return task, err
+	return s.saveWithStatus(ctx, task, userID, status)
+}
+
+// saveWithStatus persists the task after checking the status change against
+// the task's workflow; completing a parent completes all of its open subtasks
+// as well, and completing a recurring task schedules the next occurrence,
+// which takes over the recurrence rule.
+func (s *taskServiceImpl) saveWithStatus(ctx context.Context, task model.Task, userID, status string) (model.Task, error) {
+	return s.saveFields(ctx, task, userID, status, nil)
+}
+
+// saveFields is saveWithStatus limited to the given columns; nil saves the
+// whole task.
+func (s *taskServiceImpl) saveFields(ctx context.Context, task model.Task, userID, status string, fields []string) (model.Task, error) {
+	previous := task.Status
+	if status != previous {
+		if err := s.checkTransition(ctx, task, status); err != nil {
+			return model.Task{}, err
+		}
+		if fields != nil {
+			fields = append(fields, "started_at", "completed_at")
+		}
+	}
+	completed := status == model.StatusDone && previous != model.StatusDone
+	var next *model.Task
+	if completed && task.RRule != "" {
+		next = nextOccurrence(task, usertime.Location(ctx))
+		task.RRule = ""
+		if fields != nil {
+			fields = append(fields, "rrule")
+		}
+	}
+	task.SetStatus(status, time.Now())
+	var err error
+	switch {
+	case completed:
+		err = s.repo.Complete(ctx, &task, fields, next, userID)
+	case fields == nil:
+		err = s.repo.Update(ctx, &task, userID)
+	default:
+		err = s.repo.UpdateFields(ctx, &task, fields, userID)
+	}
+	if err != nil {
+		return task, err
+	}
+	if task.Status != previous {
+		s.publish(ctx, event.TaskStatusChanged, task, userID, map[string]interface{}{"task": task, "from": previous, "to": task.Status})
+	}
+	if next != nil {
+		s.publish(ctx, event.TaskCreated, *next, userID, map[string]interface{}{"task": *next})
+	}
+	return task, nil
 }
 
-func (s *taskServiceImpl) GetTasksByStatus(ctx context.Context, status, userID string) ([]model.Task, error) {
-	return s.repo.FindByStatus(ctx, status, userID)
+// nextOccurrence copies a recurring task to its next due date, or returns nil
+// when the rule is exhausted. The rule is followed on the calendar of loc, so
+// a weekly task stays on the user's weekday across midnight UTC and DST.
+func nextOccurrence(task model.Task, loc *time.Location) *model.Task {
+	if task.DueDate == nil {
+		return nil
+	}
+	rule, err := recurrence.Parse(task.RRule)
+	if err != nil {
+		return nil
+	}
+	dtstart := task.DueDate.In(loc)
+	due, ok := rule.After(dtstart, dtstart)
+	if !ok {
+		return nil
+	}
+	if rule.Count > 0 {
+		rule.Count--
+	}
+	return &model.Task{
+		UserID:      task.UserID,
+		ParentID:    task.ParentID,
+		ProjectID:   task.ProjectID,
+		WorkspaceID: task.WorkspaceID,
+		Title:       task.Title,
+		Content:     task.Content,
+		Status:      model.StatusTodo,
+		Priority:    task.Priority,
+		Tags:        task.Tags,
+		DueDate:     &due,
+		RRule:       rule.String(),
+	}
 }
 
-func (s *taskServiceImpl) SearchTasks(ctx context.Context, q, userID string) ([]model.Task, error) {
-	return s.repo.Search(ctx, q, userID)
+func (s *taskServiceImpl) GetTasksByStatus(ctx context.Context, status, userID string, page repository.PageRequest) (repository.TaskPage, error) {
+	return s.repo.FindByStatus(ctx, status, userID, page)
 }
 
-func (s *taskServiceImpl) GetTodayTasks(ctx context.Context, userID string) ([]model.Task, error) {
-	return s.repo.GetToday(ctx, userID)
+func (s *taskServiceImpl) SearchTasks(ctx context.Context, q, userID string, page repository.PageRequest) (repository.TaskPage, error) {
+	return s.repo.Search(ctx, q, userID, page)
 }
 
-func (s *taskServiceImpl) GetOverdueTasks(ctx context.Context, userID string) ([]model.Task, error) {
-	return s.repo.GetOverdue(ctx, userID)
+// GetTodayTasks runs the Today smart list.
+func (s *taskServiceImpl) GetTodayTasks(ctx context.Context, userID string, page repository.PageRequest) (repository.TaskPage, error) {
+	today, _ := systemList(ListToday)
+	return runSavedSearch(ctx, s.repo, userID, today, page)
 }
 
-func (s *taskServiceImpl) ArchiveTask(ctx context.Context, id, userID string) (model.Task, error) {
-	return s.repo.Archive(ctx, id, userID)
+// GetOverdueTasks runs the Overdue smart list.
+func (s *taskServiceImpl) GetOverdueTasks(ctx context.Context, userID string, page repository.PageRequest) (repository.TaskPage, error) {
+	overdue, _ := systemList(ListOverdue)
+	return runSavedSearch(ctx, s.repo, userID, overdue, page)
 }
 
-func (s *taskServiceImpl) UnarchiveTask(ctx context.Context, id, userID string) (model.Task, error) {
-	return s.repo.Unarchive(ctx, id, userID)
+func (s *taskServiceImpl) ArchiveTask(ctx context.Context, id, userID string, version int64) (model.Task, error) {
+	if _, err := s.editableAt(ctx, id, userID, version); err != nil {
+		return model.Task{}, err
+	}
+	task, err := s.repo.Archive(ctx, id, userID)
+	if err != nil {
+		return task, err
+	}
+	s.publish(ctx, event.TaskArchived, task, userID, map[string]interface{}{"task": task})
+	return task, nil
 }
 
-func (s *taskServiceImpl) ChangePriority(ctx context.Context, id, userID, priority string) (model.Task, error) {
-	task, err := s.repo.GetByID(ctx, id, userID)
+func (s *taskServiceImpl) UnarchiveTask(ctx context.Context, id, userID string, version int64) (model.Task, error) {
+	if _, err := s.editableAt(ctx, id, userID, version); err != nil {
+		return model.Task{}, err
+	}
+	task, err := s.repo.Unarchive(ctx, id, userID)
+	if err != nil {
+		return task, err
+	}
+	s.publish(ctx, event.TaskUnarchived, task, userID, map[string]interface{}{"task": task})
+	return task, nil
+}
+
+func (s *taskServiceImpl) ChangePriority(ctx context.Context, id, userID, priority string, version int64) (model.Task, error) {
+	if err := validatePriority(priority); err != nil {
+		return model.Task{}, err
+	}
+	task, err := s.editableAt(ctx, id, userID, version)
 	if err != nil {
 		return model.Task{}, err
 	}
 	task.Priority = priority
-	err = s.repo.Update(ctx, &task)
-	return task, err
+	if err := s.repo.Update(ctx, &task, userID); err != nil {
+		return task, err
+	}
+	s.publish(ctx, event.TaskUpdated, task, userID, map[string]interface{}{"task": task})
+	return task, nil
 }
 
-func (s *taskServiceImpl) GetTasksByPriority(ctx context.Context, priority, userID string) ([]model.Task, error) {
-	return s.repo.FindByPriority(ctx, priority, userID)
+func (s *taskServiceImpl) GetTasksByPriority(ctx context.Context, priority, userID string, page repository.PageRequest) (repository.TaskPage, error) {
+	return s.repo.FindByPriority(ctx, priority, userID, page)
 }
 
-func (s *taskServiceImpl) AddTag(ctx context.Context, id, userID, tag string) (model.Task, error) {
-	return s.repo.AddTag(ctx, id, tag, userID)
+func (s *taskServiceImpl) AddTag(ctx context.Context, id, userID, tag string, version int64) (model.Task, error) {
+	if _, err := s.editableAt(ctx, id, userID, version); err != nil {
+		return model.Task{}, err
+	}
+	task, err := s.repo.AddTag(ctx, id, tag, userID)
+	if err != nil {
+		return task, err
+	}
+	s.publish(ctx, event.TaskTagAdded, task, userID, map[string]interface{}{"task": task, "tag": tag})
+	return task, nil
 }
 
-func (s *taskServiceImpl) RemoveTag(ctx context.Context, id, userID, tag string) (model.Task, error) {
-	return s.repo.RemoveTag(ctx, id, tag, userID)
+func (s *taskServiceImpl) RemoveTag(ctx context.Context, id, userID, tag string, version int64) (model.Task, error) {
+	if _, err := s.editableAt(ctx, id, userID, version); err != nil {
+		return model.Task{}, err
+	}
+	task, err := s.repo.RemoveTag(ctx, id, tag, userID)
+	if err != nil {
+		return task, err
+	}
+	s.publish(ctx, event.TaskTagRemoved, task, userID, map[string]interface{}{"task": task, "tag": tag})
+	return task, nil
 }
 
-func (s *taskServiceImpl) GetTasksByTag(ctx context.Context, tag, userID string) ([]model.Task, error) {
-	return s.repo.FindByTag(ctx, tag, userID)
+func (s *taskServiceImpl) GetTasksByTag(ctx context.Context, tag, userID string, page repository.PageRequest) (repository.TaskPage, error) {
+	return s.repo.FindByTag(ctx, tag, userID, page)
 }
 
 func (s *taskServiceImpl) BulkDelete(ctx context.Context, ids []string, userID string) error {
-	return s.repo.BulkDelete(ctx, ids, userID)
+	tasks, err := s.ensureEditable(ctx, ids, userID)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.repo.DeleteMany(ctx, ids, userID)
+	if err != nil {
+		return err
+	}
+	for _, id := range deleted {
+		s.publish(ctx, event.TaskDeleted, tasks[id], userID, map[string]interface{}{"task_id": id.String()})
+	}
+	return nil
 }
 
+// BulkUpdateStatus changes nothing unless every visible task may move to
+// status. The repository runs the check under the tasks' row locks, so a
+// concurrent status change cannot slip past the workflow.
 func (s *taskServiceImpl) BulkUpdateStatus(ctx context.Context, ids []string, status, userID string) error {
-	return s.repo.BulkUpdateStatus(ctx, ids, status, userID)
+	tasks := make(map[uuid.UUID]model.Task, len(ids))
+	check := func(task model.Task) error {
+		if err := s.authorize(ctx, task.WorkspaceID, userID, model.RoleEditor); err != nil {
+			return err
+		}
+		tasks[task.ID] = task
+		if task.Status == status {
+			return nil
+		}
+		return s.checkTransition(ctx, task, status)
+	}
+	changed, err := s.repo.UpdateStatus(ctx, ids, status, userID, check)
+	if err != nil {
+		return err
+	}
+	for _, id := range changed {
+		s.publish(ctx, event.TaskStatusChanged, tasks[id], userID, map[string]interface{}{"task_id": id.String(), "to": status})
+	}
+	return nil
 }
 
 func (s *taskServiceImpl) Stats(ctx context.Context, userID string) (map[string]int64, error) {
 	return s.repo.Stats(ctx, userID)
 }
+
+func (s *taskServiceImpl) CreateSubtask(ctx context.Context, parentID, userID, title, content, status, priority string, due *time.Time, version int64) (model.Task, error) {
+	if _, err := uuid.Parse(parentID); err != nil {
+		return model.Task{}, repository.ErrParentNotFound
+	}
+	parent, err := s.repo.GetByID(ctx, parentID, userID)
+	if err != nil {
+		return model.Task{}, repository.ErrParentNotFound
+	}
+	if err := s.authorize(ctx, parent.WorkspaceID, userID, model.RoleEditor); err != nil {
+		return model.Task{}, err
+	}
+	if err := checkVersion(parent, version); err != nil {
+		return model.Task{}, err
+	}
+	uID, _ := uuid.Parse(userID)
+	if status == "" {
+		status = model.StatusTodo
+	}
+	if priority == "" {
+		priority = "medium"
+	}
+	if err := validatePriority(priority); err != nil {
+		return model.Task{}, err
+	}
+	task := model.Task{
+		UserID: uID, ParentID: &parent.ID, ProjectID: parent.ProjectID, WorkspaceID: parent.WorkspaceID,
+		Title: title, Content: content, Priority: priority, DueDate: due,
+	}
+	if err := s.checkTransition(ctx, task, status); err != nil {
+		return model.Task{}, err
+	}
+	task.SetStatus(status, time.Now())
+	if err := s.repo.Create(ctx, &task); err != nil {
+		return task, err
+	}
+	s.publish(ctx, event.TaskCreated, task, userID, map[string]interface{}{"task": task})
+	return task, nil
+}
+
+func (s *taskServiceImpl) MoveTask(ctx context.Context, id, userID string, parentID *string, version int64) (model.Task, error) {
+	if parentID != nil {
+		if *parentID == id {
+			return model.Task{}, repository.ErrHierarchyCycle
+		}
+		if _, err := uuid.Parse(*parentID); err != nil {
+			return model.Task{}, repository.ErrParentNotFound
+		}
+	}
+	if _, err := s.editableAt(ctx, id, userID, version); err != nil {
+		return model.Task{}, err
+	}
+	task, err := s.repo.Move(ctx, id, parentID, userID)
+	if err != nil {
+		return task, err
+	}
+	s.publish(ctx, event.TaskUpdated, task, userID, map[string]interface{}{"task": task})
+	return task, nil
+}
+
+func (s *taskServiceImpl) GetTaskTree(ctx context.Context, id, userID string) (*model.TaskNode, error) {
+	tasks, err := s.repo.GetSubtree(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	rootID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+	return model.BuildTaskTree(rootID, tasks), nil
+}
+
+// AddTaskDependency marks task id as blocked by blockerID.
+func (s *taskServiceImpl) AddTaskDependency(ctx context.Context, id, userID, blockerID string, version int64) (model.Task, error) {
+	if id == blockerID {
+		return model.Task{}, repository.ErrDependencyCycle
+	}
+	before, err := s.editableAt(ctx, id, userID, version)
+	if err != nil {
+		return model.Task{}, err
+	}
+	if err := s.repo.AddDependency(ctx, blockerID, id, userID); err != nil {
+		return model.Task{}, err
+	}
+	return s.afterDependencyChange(ctx, id, before, userID)
+}
+
+func (s *taskServiceImpl) RemoveTaskDependency(ctx context.Context, id, userID, blockerID string, version int64) (model.Task, error) {
+	before, err := s.editableAt(ctx, id, userID, version)
+	if err != nil {
+		return model.Task{}, err
+	}
+	if err := s.repo.RemoveDependency(ctx, blockerID, id, userID); err != nil {
+		return model.Task{}, err
+	}
+	return s.afterDependencyChange(ctx, id, before, userID)
+}
+
+// afterDependencyChange reloads the blocked task and announces the status
+// change when the repository blocked or unblocked it.
+func (s *taskServiceImpl) afterDependencyChange(ctx context.Context, id string, before model.Task, userID string) (model.Task, error) {
+	task, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return model.Task{}, err
+	}
+	if task.Status != before.Status {
+		s.publish(ctx, event.TaskStatusChanged, task, userID, map[string]interface{}{"task": task, "from": before.Status, "to": task.Status})
+	}
+	return task, nil
+}
+
+func (s *taskServiceImpl) GetTaskDependencies(ctx context.Context, id, userID string) (model.DependencyGraph, error) {
+	return s.repo.GetDependencyGraph(ctx, id, userID)
+}
+
+// SetRecurrence stores rrule in canonical form; an empty rule stops the task
+// from repeating.
+func (s *taskServiceImpl) SetRecurrence(ctx context.Context, id, userID, rrule string, version int64) (model.Task, error) {
+	task, err := s.editableAt(ctx, id, userID, version)
+	if err != nil {
+		return model.Task{}, err
+	}
+	task.RRule = ""
+	if rrule != "" {
+		rule, err := recurrence.Parse(rrule)
+		if err != nil {
+			return model.Task{}, err
+		}
+		if task.DueDate == nil {
+			return model.Task{}, ErrRecurrenceNeedsDueDate
+		}
+		task.RRule = rule.String()
+	}
+	if err := s.repo.Update(ctx, &task, userID); err != nil {
+		return task, err
+	}
+	s.publish(ctx, event.TaskUpdated, task, userID, map[string]interface{}{"task": task})
+	return task, nil
+}
+
+func (s *taskServiceImpl) GetOccurrences(ctx context.Context, id, userID string, from, to time.Time, limit int) ([]time.Time, error) {
+	task, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if task.RRule == "" || task.DueDate == nil {
+		return nil, ErrNotRecurring
+	}
+	rule, err := recurrence.Parse(task.RRule)
+	if err != nil {
+		return nil, err
+	}
+	return rule.Between(task.DueDate.In(usertime.Location(ctx)), from, to, limit), nil
+}
@@ -6,20 +6,30 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 	"testing"
+	"time"
+	"todo-list/config"
+	"todo-list/internal/domain/event"
+	"todo-list/internal/domain/jsonpatch"
 	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/recurrence"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/domain/usertime"
 	"todo-list/internal/testutils"
 )
 
 func TestTaskService_FullSuite(t *testing.T) {
 	repo := new(testutils.AllMocks)
-	svc := NewTaskService(repo)
+	svc := NewTaskService(repo, new(testutils.WorkspaceMocks), nil)
 	ctx := context.Background()
 	uID := uuid.New().String()
+	page := repository.PageRequest{Limit: 20, Sort: repository.SortCreatedAt, Desc: true}
 
 	t.Run("CreateTask_Valid", func(t *testing.T) {
 		repo.On("Create", ctx, mock.AnythingOfType("*model.Task")).Return(nil).Once()
-		res, err := svc.CreateTask(ctx, uID, "Title", "Content", "todo", "high", nil)
+		res, err := svc.CreateTask(ctx, uID, "Title", "Content", "todo", "high", nil, nil, nil)
 		assert.NoError(t, err)
 		assert.Equal(t, "Title", res.Title)
 	})
@@ -28,7 +38,7 @@ func TestTaskService_FullSuite(t *testing.T) {
 		repo.On("Create", ctx, mock.MatchedBy(func(task *model.Task) bool {
 			return task.Status == "todo" && task.Priority == "medium"
 		})).Return(nil).Once()
-		res, err := svc.CreateTask(ctx, uID, "T", "C", "", "", nil)
+		res, err := svc.CreateTask(ctx, uID, "T", "C", "", "", nil, nil, nil)
 		assert.NoError(t, err)
 		assert.Equal(t, "todo", res.Status)
 	})
@@ -57,18 +67,20 @@ func TestTaskService_FullSuite(t *testing.T) {
 
 	t.Run("BulkDelete_Execute", func(t *testing.T) {
 		ids := []string{"1", "2"}
-		repo.On("BulkDelete", ctx, ids, uID).Return(nil).Once()
+		repo.On("GetByID", ctx, "1", uID).Return(model.Task{}, nil).Once()
+		repo.On("GetByID", ctx, "2", uID).Return(model.Task{}, errors.New("not found")).Once()
+		repo.On("DeleteMany", ctx, ids, uID).Return([]uuid.UUID(nil), nil).Once()
 		err := svc.BulkDelete(ctx, ids, uID)
 		assert.NoError(t, err)
 	})
 
 	t.Run("SearchTasks_Success", func(t *testing.T) {
 		query := "milk"
-		repo.On("Search", ctx, query, uID).Return([]model.Task{{Title: "Buy milk"}}, nil).Once()
-		res, err := svc.SearchTasks(ctx, query, uID)
+		repo.On("Search", ctx, query, uID, page).Return(repository.TaskPage{Tasks: []model.Task{{Title: "Buy milk"}}}, nil).Once()
+		res, err := svc.SearchTasks(ctx, query, uID, page)
 		assert.NoError(t, err)
-		assert.Len(t, res, 1)
-		assert.Equal(t, "Buy milk", res[0].Title)
+		assert.Len(t, res.Tasks, 1)
+		assert.Equal(t, "Buy milk", res.Tasks[0].Title)
 	})
 
 	t.Run("Tag_Operations", func(t *testing.T) {
@@ -76,45 +88,60 @@ func TestTaskService_FullSuite(t *testing.T) {
 		uID := uuid.New().String()
 		tagName := "urgent"
 
+		repo.On("GetByID", ctx, tID, uID).Return(model.Task{Title: "T"}, nil).Twice()
 		repo.On("AddTag", ctx, tID, tagName, uID).Return(model.Task{Title: "T"}, nil).Once()
 
-		_, err := svc.AddTag(ctx, tID, uID, tagName)
+		_, err := svc.AddTag(ctx, tID, uID, tagName, 0)
 		assert.NoError(t, err)
 
 		repo.On("RemoveTag", ctx, tID, tagName, uID).Return(model.Task{Title: "T"}, nil).Once()
-		_, err = svc.RemoveTag(ctx, tID, uID, tagName)
+		_, err = svc.RemoveTag(ctx, tID, uID, tagName, 0)
 		assert.NoError(t, err)
 	})
 
 	t.Run("Archive_Lifecycle", func(t *testing.T) {
 		tID := uuid.New().String()
 
+		repo.On("GetByID", ctx, tID, uID).Return(model.Task{}, nil).Twice()
+
 		// Archive
 		repo.On("Archive", ctx, tID, uID).Return(model.Task{Archived: true}, nil).Once()
-		res, err := svc.ArchiveTask(ctx, tID, uID)
+		res, err := svc.ArchiveTask(ctx, tID, uID, 0)
 		assert.NoError(t, err)
 		assert.True(t, res.Archived)
 
 		// Unarchive
 		repo.On("Unarchive", ctx, tID, uID).Return(model.Task{Archived: false}, nil).Once()
-		res, err = svc.UnarchiveTask(ctx, tID, uID)
+		res, err = svc.UnarchiveTask(ctx, tID, uID, 0)
 		assert.NoError(t, err)
 		assert.False(t, res.Archived)
 	})
 
+	t.Run("Stale_Version", func(t *testing.T) {
+		tID := uuid.New().String()
+
+		// Репозиторий не вызывается, если задачу уже изменили
+		repo.On("GetByID", ctx, tID, uID).Return(model.Task{Version: 3}, nil).Twice()
+		_, err := svc.ArchiveTask(ctx, tID, uID, 2)
+		assert.ErrorIs(t, err, repository.ErrVersionConflict)
+		assert.ErrorIs(t, svc.DeleteTask(ctx, tID, uID, 2), repository.ErrVersionConflict)
+		repo.AssertNotCalled(t, "Archive", ctx, tID, uID)
+		repo.AssertNotCalled(t, "Delete", ctx, tID, uID)
+	})
+
 	t.Run("Priority_And_Status_Changes", func(t *testing.T) {
 		tID := uuid.New().String()
 		uID := uuid.New().String()
 
 		// ChangePriority
 		repo.On("GetByID", ctx, tID, uID).Return(model.Task{ID: [16]byte{1}}, nil).Once()
-		repo.On("Update", ctx, mock.Anything).Return(nil).Once()
-		_, err := svc.ChangePriority(ctx, tID, uID, "high")
+		repo.On("Update", ctx, mock.Anything, uID).Return(nil).Once()
+		_, err := svc.ChangePriority(ctx, tID, uID, "high", 0)
 		assert.NoError(t, err)
 
 		// BulkUpdateStatus
 		ids := []string{tID}
-		repo.On("BulkUpdateStatus", ctx, ids, "done", uID).Return(nil).Once()
+		repo.On("UpdateStatus", ctx, ids, "done", uID).Return([]model.Task{{Status: "todo"}}, nil).Once()
 		err = svc.BulkUpdateStatus(ctx, ids, "done", uID)
 		assert.NoError(t, err)
 	})
@@ -122,20 +149,38 @@ func TestTaskService_FullSuite(t *testing.T) {
 	t.Run("List_Filters", func(t *testing.T) {
 		uID := uuid.New().String()
 
-		repo.On("FindByStatus", ctx, "todo", uID).Return([]model.Task{}, nil).Once()
-		_, err := svc.GetTasksByStatus(ctx, "todo", uID)
+		repo.On("FindByStatus", ctx, "todo", uID, page).Return(repository.TaskPage{}, nil).Once()
+		_, err := svc.GetTasksByStatus(ctx, "todo", uID, page)
 		assert.NoError(t, err)
 
-		repo.On("GetToday", ctx, uID).Return([]model.Task{}, nil).Once()
-		_, err = svc.GetTodayTasks(ctx, uID)
+		repo.On("List", ctx, uID, mock.MatchedBy(func(f repository.TaskFilter) bool {
+			return f.DueFrom != nil && f.DueBefore != nil && f.DueBefore.Sub(*f.DueFrom) == 24*time.Hour
+		}), page).Return(repository.TaskPage{}, nil).Once()
+		_, err = svc.GetTodayTasks(ctx, uID, page)
+		assert.NoError(t, err)
+	})
+
+	t.Run("GetTodayTasks_UserTimezone", func(t *testing.T) {
+		// «Сегодня» москвича начинается в полночь по Москве, а не в 03:00
+		moscow, err := usertime.Load("Europe/Moscow")
+		require.NoError(t, err)
+		mctx := usertime.WithLocation(ctx, moscow)
+		repo.On("List", mctx, uID, mock.MatchedBy(func(f repository.TaskFilter) bool {
+			if f.DueFrom == nil {
+				return false
+			}
+			from := f.DueFrom.In(moscow)
+			return from.Hour() == 0 && from.Minute() == 0 && from.Day() == usertime.Now(mctx).Day()
+		}), page).Return(repository.TaskPage{}, nil).Once()
+		_, err = svc.GetTodayTasks(mctx, uID, page)
 		assert.NoError(t, err)
 	})
 
 	t.Run("GetAllTasks_Success", func(t *testing.T) {
-		repo.On("GetAll", ctx, uID).Return([]model.Task{{Title: "Task 1"}, {Title: "Task 2"}}, nil).Once()
-		res, err := svc.GetAllTasks(ctx, uID)
+		repo.On("GetAll", ctx, uID, page).Return(repository.TaskPage{Tasks: []model.Task{{Title: "Task 1"}, {Title: "Task 2"}}}, nil).Once()
+		res, err := svc.GetAllTasks(ctx, uID, page)
 		assert.NoError(t, err)
-		assert.Len(t, res, 2)
+		assert.Len(t, res.Tasks, 2)
 	})
 
 	t.Run("UpdateTask_Success", func(t *testing.T) {
@@ -143,20 +188,37 @@ func TestTaskService_FullSuite(t *testing.T) {
 		existingTask := model.Task{Title: "Old Title", UserID: uuid.New()}
 
 		repo.On("GetByID", ctx, tID, uID).Return(existingTask, nil).Once()
-		repo.On("Update", ctx, mock.MatchedBy(func(task *model.Task) bool {
+		repo.On("Complete", ctx, mock.MatchedBy(func(task *model.Task) bool {
 			return task.Title == "New Title" && task.Priority == "high"
-		})).Return(nil).Once()
+		}), []string(nil), (*model.Task)(nil), uID).Return(nil).Once()
 
-		res, err := svc.UpdateTask(ctx, tID, uID, "New Title", "New Content", "done", "high", nil)
+		res, err := svc.UpdateTask(ctx, tID, uID, "New Title", "New Content", "done", "high", nil, 0)
 		assert.NoError(t, err)
 		assert.Equal(t, "New Title", res.Title)
 		assert.Equal(t, "high", res.Priority)
 	})
 
+	t.Run("UpdateTask_KeepsStatusAndPriority", func(t *testing.T) {
+		tID := uuid.New().String()
+		existingTask := model.Task{Title: "Old Title", Status: "in_progress", Priority: "low", UserID: uuid.New()}
+
+		// PUT без status и priority не сбрасывает их
+		repo.On("GetByID", ctx, tID, uID).Return(existingTask, nil).Once()
+		repo.On("Update", ctx, mock.MatchedBy(func(task *model.Task) bool {
+			return task.Title == "Renamed" && task.Status == "in_progress" && task.Priority == "low"
+		}), uID).Return(nil).Once()
+
+		res, err := svc.UpdateTask(ctx, tID, uID, "Renamed", "", "", "", nil, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "in_progress", res.Status)
+		assert.Equal(t, "low", res.Priority)
+	})
+
 	t.Run("DeleteTask_Execute", func(t *testing.T) {
 		tID := uuid.New().String()
+		repo.On("GetByID", ctx, tID, uID).Return(model.Task{}, nil).Once()
 		repo.On("Delete", ctx, tID, uID).Return(nil).Once()
-		err := svc.DeleteTask(ctx, tID, uID)
+		err := svc.DeleteTask(ctx, tID, uID, 0)
 		assert.NoError(t, err)
 	})
 
@@ -165,32 +227,636 @@ func TestTaskService_FullSuite(t *testing.T) {
 		repo.On("GetByID", ctx, tID, uID).Return(model.Task{Status: "todo"}, nil).Once()
 		repo.On("Update", ctx, mock.MatchedBy(func(task *model.Task) bool {
 			return task.Status == "in_progress"
-		})).Return(nil).Once()
+		}), uID).Return(nil).Once()
 
-		res, err := svc.ChangeStatus(ctx, tID, uID, "in_progress")
+		res, err := svc.ChangeStatus(ctx, tID, uID, "in_progress", 0)
 		assert.NoError(t, err)
 		assert.Equal(t, "in_progress", res.Status)
 	})
 
+	t.Run("ChangeStatus_Done_CompletesSubtasks", func(t *testing.T) {
+		tID := uuid.New()
+		repo.On("GetByID", ctx, tID.String(), uID).Return(model.Task{ID: tID, Status: "in_progress"}, nil).Once()
+		// Подзадачи завершаются в той же транзакции, что и сама задача
+		repo.On("Complete", ctx, mock.AnythingOfType("*model.Task"), []string(nil), (*model.Task)(nil), uID).Return(nil).Once()
+
+		res, err := svc.ChangeStatus(ctx, tID.String(), uID, "done", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "done", res.Status)
+	})
+
+	t.Run("CreateSubtask_SetsParent", func(t *testing.T) {
+		parentID := uuid.New()
+		repo.On("GetByID", ctx, parentID.String(), uID).Return(model.Task{ID: parentID}, nil).Once()
+		repo.On("Create", ctx, mock.MatchedBy(func(task *model.Task) bool {
+			return task.ParentID != nil && *task.ParentID == parentID && task.Status == "todo"
+		})).Return(nil).Once()
+
+		res, err := svc.CreateSubtask(ctx, parentID.String(), uID, "Step", "", "", "", nil, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, parentID, *res.ParentID)
+
+		_, err = svc.CreateSubtask(ctx, "not-a-uuid", uID, "Step", "", "", "", nil, 0)
+		assert.ErrorIs(t, err, repository.ErrParentNotFound)
+	})
+
+	t.Run("MoveTask_Validation", func(t *testing.T) {
+		tID := uuid.New().String()
+		_, err := svc.MoveTask(ctx, tID, uID, &tID, 0)
+		assert.ErrorIs(t, err, repository.ErrHierarchyCycle)
+
+		parentID := uuid.New().String()
+		repo.On("GetByID", ctx, tID, uID).Return(model.Task{}, nil).Once()
+		repo.On("Move", ctx, tID, &parentID, uID).Return(model.Task{}, repository.ErrHierarchyCycle).Once()
+		_, err = svc.MoveTask(ctx, tID, uID, &parentID, 0)
+		assert.ErrorIs(t, err, repository.ErrHierarchyCycle)
+	})
+
+	t.Run("AddTaskDependency", func(t *testing.T) {
+		tID, blockerID := uuid.New().String(), uuid.New().String()
+		_, err := svc.AddTaskDependency(ctx, tID, uID, tID, 0)
+		assert.ErrorIs(t, err, repository.ErrDependencyCycle)
+
+		repo.On("GetByID", ctx, tID, uID).Return(model.Task{Status: "todo"}, nil).Once()
+		repo.On("AddDependency", ctx, blockerID, tID, uID).Return(nil).Once()
+		repo.On("GetByID", ctx, tID, uID).Return(model.Task{Status: "blocked"}, nil).Once()
+		res, err := svc.AddTaskDependency(ctx, tID, uID, blockerID, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "blocked", res.Status)
+	})
+
+	t.Run("ChangeStatus_Recurring_SchedulesNext", func(t *testing.T) {
+		tID := uuid.New()
+		due := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+		tags := []model.Tag{{ID: 3, Name: "reports"}}
+		recurring := model.Task{ID: tID, Title: "Weekly report", Content: "KPIs", Status: "todo", DueDate: &due, Tags: tags, RRule: "FREQ=WEEKLY;COUNT=3"}
+
+		repo.On("GetByID", ctx, tID.String(), uID).Return(recurring, nil).Once()
+		// Следующее повторение создаётся в той же транзакции, что и завершение
+		repo.On("Complete", ctx, mock.MatchedBy(func(task *model.Task) bool {
+			return task.ID == tID && task.RRule == ""
+		}), []string(nil), mock.MatchedBy(func(next *model.Task) bool {
+			return next != nil && next.Status == "todo" && next.Content == "KPIs" && len(next.Tags) == 1 &&
+				next.DueDate.Equal(due.AddDate(0, 0, 7)) && next.RRule == "FREQ=WEEKLY;COUNT=2"
+		}), uID).Return(nil).Once()
+
+		_, err := svc.ChangeStatus(ctx, tID.String(), uID, "done", 0)
+		assert.NoError(t, err)
+	})
+
+	t.Run("SetRecurrence_Validation", func(t *testing.T) {
+		tID := uuid.New().String()
+		repo.On("GetByID", ctx, tID, uID).Return(model.Task{}, nil).Twice()
+
+		_, err := svc.SetRecurrence(ctx, tID, uID, "FREQ=FORTNIGHTLY", 0)
+		assert.ErrorIs(t, err, recurrence.ErrInvalidRule)
+		_, err = svc.SetRecurrence(ctx, tID, uID, "FREQ=DAILY", 0)
+		assert.ErrorIs(t, err, ErrRecurrenceNeedsDueDate)
+	})
+
 	t.Run("GetOverdueTasks_Success", func(t *testing.T) {
-		repo.On("GetOverdue", ctx, uID).Return([]model.Task{{Title: "Late Task"}}, nil).Once()
-		res, err := svc.GetOverdueTasks(ctx, uID)
+		repo.On("List", ctx, uID, mock.MatchedBy(func(f repository.TaskFilter) bool {
+			return f.DueBefore != nil && f.DueFrom == nil && assert.ObjectsAreEqual([]string{"done"}, f.ExcludeStatuses)
+		}), page).Return(repository.TaskPage{Tasks: []model.Task{{Title: "Late Task"}}}, nil).Once()
+		res, err := svc.GetOverdueTasks(ctx, uID, page)
 		assert.NoError(t, err)
-		assert.Len(t, res, 1)
+		assert.Len(t, res.Tasks, 1)
 	})
 
 	t.Run("GetTasksByPriority_Success", func(t *testing.T) {
-		repo.On("FindByPriority", ctx, "high", uID).Return([]model.Task{{Priority: "high"}}, nil).Once()
-		res, err := svc.GetTasksByPriority(ctx, "high", uID)
+		repo.On("FindByPriority", ctx, "high", uID, page).Return(repository.TaskPage{Tasks: []model.Task{{Priority: "high"}}}, nil).Once()
+		res, err := svc.GetTasksByPriority(ctx, "high", uID, page)
 		assert.NoError(t, err)
-		assert.Equal(t, "high", res[0].Priority)
+		assert.Equal(t, "high", res.Tasks[0].Priority)
 	})
 
 	t.Run("GetTasksByTag_Success", func(t *testing.T) {
 		tagName := "work"
-		repo.On("FindByTag", ctx, tagName, uID).Return([]model.Task{{Title: "Job"}}, nil).Once()
-		res, err := svc.GetTasksByTag(ctx, tagName, uID)
+		repo.On("FindByTag", ctx, tagName, uID, page).Return(repository.TaskPage{Tasks: []model.Task{{Title: "Job"}}}, nil).Once()
+		res, err := svc.GetTasksByTag(ctx, tagName, uID, page)
 		assert.NoError(t, err)
-		assert.Len(t, res, 1)
+		assert.Len(t, res.Tasks, 1)
 	})
+
+	t.Run("ListTasks_ParsesFilter", func(t *testing.T) {
+		repo.On("List", ctx, uID, mock.MatchedBy(func(f repository.TaskFilter) bool {
+			return len(f.Statuses) == 1 && f.Statuses[0] == "todo" && len(f.TagGroups) == 1
+		}), page).Return(repository.TaskPage{Tasks: []model.Task{{Title: "Work"}}}, nil).Once()
+
+		res, err := svc.ListTasks(ctx, uID, "status:todo tag:work", page)
+		assert.NoError(t, err)
+		assert.Len(t, res.Tasks, 1)
+	})
+
+	t.Run("Workspace_Roles", func(t *testing.T) {
+		workspaces := new(testutils.WorkspaceMocks)
+		svc := NewTaskService(repo, workspaces, nil)
+		wsID := uuid.New()
+		tID := uuid.New().String()
+		shared := model.Task{ID: uuid.New(), WorkspaceID: &wsID, Status: "todo"}
+
+		workspaces.On("Role", ctx, wsID.String(), uID).Return(model.RoleViewer, nil).Times(3)
+		repo.On("GetByID", ctx, tID, uID).Return(shared, nil).Times(2)
+
+		ws := wsID.String()
+		_, err := svc.CreateTask(ctx, uID, "T", "", "", "", nil, nil, &ws)
+		assert.ErrorIs(t, err, ErrForbidden)
+		_, err = svc.ChangeStatus(ctx, tID, uID, "done", 0)
+		assert.ErrorIs(t, err, ErrForbidden)
+		assert.ErrorIs(t, svc.DeleteTask(ctx, tID, uID, 0), ErrForbidden)
+
+		workspaces.On("Role", ctx, wsID.String(), uID).Return(model.RoleEditor, nil).Once()
+		repo.On("Workflow", ctx, (*uuid.UUID)(nil), &wsID).Return((*model.Workflow)(nil), nil).Once()
+		repo.On("Create", ctx, mock.MatchedBy(func(task *model.Task) bool {
+			return task.WorkspaceID != nil && *task.WorkspaceID == wsID
+		})).Return(nil).Once()
+		_, err = svc.CreateTask(ctx, uID, "T", "", "", "", nil, nil, &ws)
+		assert.NoError(t, err)
+
+		bad := "nope"
+		_, err = svc.CreateTask(ctx, uID, "T", "", "", "", nil, nil, &bad)
+		assert.ErrorIs(t, err, repository.ErrWorkspaceNotFound)
+		workspaces.AssertExpectations(t)
+	})
+
+	t.Run("ListTasks_InvalidFilter", func(t *testing.T) {
+		_, err := svc.ListTasks(ctx, uID, "color:red", page)
+		var filterErr *FilterError
+		assert.ErrorAs(t, err, &filterErr)
+	})
+}
+
+type recordingPublisher struct {
+	events []event.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, e event.Event) {
+	p.events = append(p.events, e)
+}
+
+func TestNextOccurrence_UserTimezone(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	// Понедельник 01:00 по Москве — ещё воскресенье по UTC
+	due := time.Date(2024, 3, 4, 1, 0, 0, 0, moscow)
+	task := model.Task{Title: "Standup", DueDate: &due, RRule: "FREQ=WEEKLY;BYDAY=MO"}
+
+	next := nextOccurrence(task, moscow)
+	require.NotNil(t, next)
+	assert.True(t, next.DueDate.Equal(time.Date(2024, 3, 11, 1, 0, 0, 0, moscow)), next.DueDate)
+
+	// В UTC то же правило сдвинулось бы на вторник по Москве
+	next = nextOccurrence(task, time.UTC)
+	require.NotNil(t, next)
+	assert.Equal(t, time.Tuesday, next.DueDate.In(moscow).Weekday())
+}
+
+func TestTaskService_PublishesEvents(t *testing.T) {
+	repo := new(testutils.AllMocks)
+	events := &recordingPublisher{}
+	svc := NewTaskService(repo, new(testutils.WorkspaceMocks), events)
+	ctx := context.Background()
+	uID := uuid.New()
+	tID := uuid.New()
+
+	repo.On("GetByID", ctx, tID.String(), uID.String()).Return(model.Task{ID: tID, Status: "todo"}, nil).Once()
+	repo.On("Update", ctx, mock.AnythingOfType("*model.Task"), uID.String()).Return(nil).Once()
+	_, err := svc.ChangeStatus(ctx, tID.String(), uID.String(), "in_progress", 0)
+	assert.NoError(t, err)
+
+	repo.On("GetByID", ctx, tID.String(), uID.String()).Return(model.Task{ID: tID}, nil).Twice()
+	repo.On("AddTag", ctx, tID.String(), "work", uID.String()).Return(model.Task{ID: tID}, nil).Once()
+	_, err = svc.AddTag(ctx, tID.String(), uID.String(), "work", 0)
+	assert.NoError(t, err)
+
+	repo.On("Delete", ctx, tID.String(), uID.String()).Return(errors.New("db down")).Once()
+	assert.Error(t, svc.DeleteTask(ctx, tID.String(), uID.String(), 0))
+
+	if assert.Len(t, events.events, 2) {
+		assert.Equal(t, event.TaskStatusChanged, events.events[0].Type)
+		assert.Equal(t, uID, events.events[0].UserID)
+		assert.Equal(t, "todo", events.events[0].Data["from"])
+		assert.Equal(t, event.TaskTagAdded, events.events[1].Type)
+		assert.Equal(t, "work", events.events[1].Data["tag"])
+	}
+}
+
+func TestTaskService_EventsReachFollowers(t *testing.T) {
+	repo := new(testutils.AllMocks)
+	events := &recordingPublisher{}
+	svc := NewTaskService(repo, new(testutils.WorkspaceMocks), events)
+	ctx := context.Background()
+	owner, actor, assignee, watcher := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	tID := uuid.New()
+	task := model.Task{
+		ID:        tID,
+		UserID:    owner,
+		Status:    "todo",
+		Assignees: []model.TaskAssignee{{TaskID: tID, UserID: assignee}, {TaskID: tID, UserID: actor}},
+		Watchers:  []model.TaskWatcher{{TaskID: tID, UserID: watcher}, {TaskID: tID, UserID: assignee}},
+	}
+
+	repo.On("GetByID", ctx, tID.String(), actor.String()).Return(task, nil).Once()
+	repo.On("Update", ctx, mock.AnythingOfType("*model.Task"), actor.String()).Return(nil).Once()
+	_, err := svc.ChangeStatus(ctx, tID.String(), actor.String(), "in_progress", 0)
+	require.NoError(t, err)
+
+	// Каждый получает одну копию события с общим ID, первым — автор изменения
+	require.Len(t, events.events, 4)
+	var recipients []uuid.UUID
+	for _, e := range events.events {
+		assert.Equal(t, events.events[0].ID, e.ID)
+		recipients = append(recipients, e.UserID)
+	}
+	assert.Equal(t, []uuid.UUID{actor, owner, assignee, watcher}, recipients)
+}
+
+func TestTaskService_BulkEvents(t *testing.T) {
+	repo := new(testutils.AllMocks)
+	events := &recordingPublisher{}
+	svc := NewTaskService(repo, new(testutils.WorkspaceMocks), events)
+	ctx := context.Background()
+	uID := uuid.New().String()
+	todo, done, missing := uuid.New(), uuid.New(), uuid.New()
+	ids := []string{todo.String(), done.String(), missing.String()}
+
+	// События только для задач, статус которых действительно изменился
+	repo.On("UpdateStatus", ctx, ids, "done", uID).
+		Return([]model.Task{{ID: todo, Status: "todo"}, {ID: done, Status: "done"}}, nil).Once()
+	require.NoError(t, svc.BulkUpdateStatus(ctx, ids, "done", uID))
+	require.Len(t, events.events, 1)
+	assert.Equal(t, event.TaskStatusChanged, events.events[0].Type)
+	assert.Equal(t, todo.String(), events.events[0].Data["task_id"])
+
+	// И только для реально удалённых задач
+	for _, id := range ids {
+		repo.On("GetByID", ctx, id, uID).Return(model.Task{}, nil).Once()
+	}
+	repo.On("DeleteMany", ctx, ids, uID).Return([]uuid.UUID{todo}, nil).Once()
+	require.NoError(t, svc.BulkDelete(ctx, ids, uID))
+	require.Len(t, events.events, 2)
+	assert.Equal(t, event.TaskDeleted, events.events[1].Type)
+	assert.Equal(t, todo.String(), events.events[1].Data["task_id"])
+}
+
+func TestTaskService_DependencyStatusEvents(t *testing.T) {
+	repo := new(testutils.AllMocks)
+	events := &recordingPublisher{}
+	svc := NewTaskService(repo, new(testutils.WorkspaceMocks), events)
+	ctx := context.Background()
+	uID := uuid.New().String()
+	tID, blocker, other := uuid.New(), uuid.New().String(), uuid.New().String()
+
+	// Новая зависимость блокирует задачу — об этом сообщается как о смене статуса
+	repo.On("GetByID", ctx, tID.String(), uID).Return(model.Task{ID: tID, Status: "todo"}, nil).Once()
+	repo.On("AddDependency", ctx, blocker, tID.String(), uID).Return(nil).Once()
+	repo.On("GetByID", ctx, tID.String(), uID).Return(model.Task{ID: tID, Status: "blocked"}, nil).Once()
+	_, err := svc.AddTaskDependency(ctx, tID.String(), uID, blocker, 0)
+	require.NoError(t, err)
+	require.Len(t, events.events, 1)
+	assert.Equal(t, event.TaskStatusChanged, events.events[0].Type)
+	assert.Equal(t, "todo", events.events[0].Data["from"])
+	assert.Equal(t, "blocked", events.events[0].Data["to"])
+
+	// Задача всё ещё заблокирована другой — статус не менялся, события нет
+	repo.On("GetByID", ctx, tID.String(), uID).Return(model.Task{ID: tID, Status: "blocked"}, nil).Twice()
+	repo.On("RemoveDependency", ctx, other, tID.String(), uID).Return(nil).Once()
+	_, err = svc.RemoveTaskDependency(ctx, tID.String(), uID, other, 0)
+	require.NoError(t, err)
+	assert.Len(t, events.events, 1)
+
+	// Последний блокер снят — задача вернулась в todo
+	repo.On("GetByID", ctx, tID.String(), uID).Return(model.Task{ID: tID, Status: "blocked"}, nil).Once()
+	repo.On("RemoveDependency", ctx, blocker, tID.String(), uID).Return(nil).Once()
+	repo.On("GetByID", ctx, tID.String(), uID).Return(model.Task{ID: tID, Status: "todo"}, nil).Once()
+	_, err = svc.RemoveTaskDependency(ctx, tID.String(), uID, blocker, 0)
+	require.NoError(t, err)
+	require.Len(t, events.events, 2)
+	assert.Equal(t, "todo", events.events[1].Data["to"])
+}
+
+func TestTaskService_Assignments(t *testing.T) {
+	ctx := context.Background()
+	uID := uuid.New().String()
+	assignee := uuid.New()
+	tID := uuid.New()
+
+	t.Run("Assign_Notifies_Assignee", func(t *testing.T) {
+		repo, events := new(testutils.AllMocks), &recordingPublisher{}
+		svc := NewTaskService(repo, new(testutils.WorkspaceMocks), events)
+		repo.On("GetByID", ctx, tID.String(), uID).Return(model.Task{ID: tID}, nil).Once()
+		assigned := model.Task{ID: tID, Assignees: []model.TaskAssignee{{TaskID: tID, UserID: assignee}}}
+		repo.On("AddAssignee", ctx, tID.String(), assignee.String(), uID).Return(assigned, nil).Once()
+
+		_, err := svc.AssignTask(ctx, tID.String(), uID, assignee.String(), 0)
+		require.NoError(t, err)
+		require.Len(t, events.events, 2)
+		assert.Equal(t, event.TaskAssigned, events.events[0].Type)
+		assert.Equal(t, assignee, events.events[1].UserID)
+
+		// Повторное назначение ничего не меняет и не шлёт событий
+		repo.On("GetByID", ctx, tID.String(), uID).Return(assigned, nil).Once()
+		_, err = svc.AssignTask(ctx, tID.String(), uID, assignee.String(), 0)
+		require.NoError(t, err)
+		assert.Len(t, events.events, 2)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Viewer_Can_Only_Unassign_Self", func(t *testing.T) {
+		repo, workspaces := new(testutils.AllMocks), new(testutils.WorkspaceMocks)
+		svc := NewTaskService(repo, workspaces, nil)
+		wsID := uuid.New()
+		self, _ := uuid.Parse(uID)
+		task := model.Task{ID: tID, WorkspaceID: &wsID, Assignees: []model.TaskAssignee{{UserID: self}, {UserID: assignee}}}
+		repo.On("GetByID", ctx, tID.String(), uID).Return(task, nil)
+		workspaces.On("Role", ctx, wsID.String(), uID).Return(model.RoleViewer, nil)
+
+		_, err := svc.AssignTask(ctx, tID.String(), uID, assignee.String(), 0)
+		assert.ErrorIs(t, err, ErrForbidden)
+		_, err = svc.UnassignTask(ctx, tID.String(), uID, assignee.String(), 0)
+		assert.ErrorIs(t, err, ErrForbidden)
+
+		repo.On("RemoveAssignee", ctx, tID.String(), uID, uID).Return(model.Task{ID: tID}, nil).Once()
+		_, err = svc.UnassignTask(ctx, tID.String(), uID, uID, 0)
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Assigned_View_Filters_By_User", func(t *testing.T) {
+		repo := new(testutils.AllMocks)
+		svc := NewTaskService(repo, new(testutils.WorkspaceMocks), nil)
+		page := repository.PageRequest{Limit: 20}
+		repo.On("List", ctx, uID, mock.MatchedBy(func(f repository.TaskFilter) bool {
+			return f.AssigneeID != nil && f.AssigneeID.String() == uID && len(f.Statuses) == 1
+		}), page).Return(repository.TaskPage{}, nil).Once()
+
+		_, err := svc.ListAssignedTasks(ctx, uID, "status:todo", page)
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestTaskService_RevertTask(t *testing.T) {
+	ctx := context.Background()
+	uID := uuid.New().String()
+	tID := uuid.New()
+	project := uuid.New()
+	current := model.Task{ID: tID, Title: "Third", Content: "body", Status: "in_progress", Priority: "high", ProjectID: &project}
+	change := func(rev int, from, to model.TaskFields) model.TaskRevision {
+		return model.TaskRevision{TaskID: tID, Revision: rev, Changes: from.Diff(to)}
+	}
+	// Ревизии идут от новых к старым, как их отдаёт репозиторий
+	history := []model.TaskRevision{
+		change(3, model.TaskFields{Title: "Second", Status: "todo"}, model.TaskFields{Title: "Third", Status: "in_progress"}),
+		change(2, model.TaskFields{Title: "First", Priority: "medium"}, model.TaskFields{Title: "Second", Priority: "high", ProjectID: &project}),
+		change(1, model.TaskFields{Content: ""}, model.TaskFields{Content: "body"}),
+	}
+
+	repo := new(testutils.AllMocks)
+	svc := NewTaskService(repo, new(testutils.WorkspaceMocks), nil)
+	repo.On("GetByID", ctx, tID.String(), uID).Return(current, nil)
+	repo.On("ListRevisions", ctx, tID.String(), uID).Return(history, nil)
+	repo.On("Workflow", ctx, &project, (*uuid.UUID)(nil)).Return((*model.Workflow)(nil), nil)
+	repo.On("Update", ctx, mock.MatchedBy(func(task *model.Task) bool {
+		// Проект не откатывается: перемещения только видны в истории
+		return task.Title == "First" && task.Priority == "medium" && task.Status == "todo" &&
+			task.Content == "body" && task.ProjectID != nil
+	}), uID).Return(nil).Once()
+
+	reverted, err := svc.RevertTask(ctx, tID.String(), uID, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "First", reverted.Title)
+
+	_, err = svc.RevertTask(ctx, tID.String(), uID, 4, 0)
+	assert.ErrorIs(t, err, ErrInvalidRevision)
+	_, err = svc.RevertTask(ctx, tID.String(), uID, -1, 0)
+	assert.ErrorIs(t, err, ErrInvalidRevision)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskService_Workflow(t *testing.T) {
+	ctx := context.Background()
+	uID := uuid.New().String()
+	tID := uuid.New().String()
+	project := uuid.New()
+	review := &model.Workflow{
+		Statuses: []string{"todo", "in_progress", "review", "blocked", "done"},
+		Transitions: map[string][]string{
+			"todo":        {"in_progress"},
+			"in_progress": {"review"},
+			"review":      {"done", "in_progress"},
+		},
+	}
+	repo := new(testutils.AllMocks)
+	svc := NewTaskService(repo, new(testutils.WorkspaceMocks), nil)
+	repo.On("Workflow", ctx, &project, (*uuid.UUID)(nil)).Return(review, nil)
+	repo.On("GetByID", ctx, tID, uID).Return(model.Task{Status: "todo", Priority: "low", ProjectID: &project}, nil)
+
+	var transition *TransitionError
+	_, err := svc.ChangeStatus(ctx, tID, uID, "done", 0)
+	require.ErrorAs(t, err, &transition)
+	assert.Equal(t, []string{"in_progress"}, transition.Allowed)
+
+	// Переход проверяется по задачам, заблокированным в транзакции репозитория
+	repo.On("UpdateStatus", ctx, []string{tID}, "review", uID).
+		Return([]model.Task{{Status: "todo", ProjectID: &project}}, nil).Once()
+	assert.ErrorAs(t, svc.BulkUpdateStatus(ctx, []string{tID}, "review", uID), &transition)
+
+	p := project.String()
+	_, err = svc.CreateTask(ctx, uID, "T", "", "Done", "", nil, &p, nil)
+	assert.ErrorAs(t, err, &transition)
+	var field *FieldError
+	_, err = svc.ChangePriority(ctx, tID, uID, "asap", 0)
+	assert.ErrorAs(t, err, &field)
+
+	repo.On("Update", ctx, mock.MatchedBy(func(task *model.Task) bool {
+		return task.Status == "in_progress" && task.StartedAt != nil && task.CompletedAt == nil
+	}), uID).Return(nil).Once()
+	task, err := svc.ChangeStatus(ctx, tID, uID, "in_progress", 0)
+	require.NoError(t, err)
+	assert.NotNil(t, task.StartedAt)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskService_VersionPrecondition(t *testing.T) {
+	ctx := context.Background()
+	uID := uuid.New().String()
+	tID := uuid.New().String()
+	repo := new(testutils.AllMocks)
+	svc := NewTaskService(repo, new(testutils.WorkspaceMocks), nil)
+	repo.On("GetByID", ctx, tID, uID).Return(model.Task{Title: "Old", Status: "todo", Version: 5}, nil)
+
+	_, err := svc.UpdateTask(ctx, tID, uID, "New", "", "todo", "low", nil, 4)
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+
+	repo.On("Update", ctx, mock.MatchedBy(func(task *model.Task) bool {
+		return task.Priority == "high" && task.Version == 5
+	}), uID).Return(nil).Once()
+	_, err = svc.ChangePriority(ctx, tID, uID, "high", 5)
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestTaskService_PatchTask(t *testing.T) {
+	ctx := context.Background()
+	uID := uuid.New().String()
+	tID := uuid.New().String()
+	due := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	current := model.Task{Title: "Report", Content: "draft", Status: "todo", Priority: "high", DueDate: &due, Version: 2}
+
+	t.Run("Merge_NullClearsOnlyDueDate", func(t *testing.T) {
+		repo := new(testutils.AllMocks)
+		svc := NewTaskService(repo, new(testutils.WorkspaceMocks), nil)
+		repo.On("GetByID", ctx, tID, uID).Return(current, nil).Once()
+		repo.On("UpdateFields", ctx, mock.MatchedBy(func(task *model.Task) bool {
+			return task.DueDate == nil && task.Priority == "high" && task.Content == "draft"
+		}), []string{"due_date"}, uID).Return(nil).Once()
+
+		_, err := svc.PatchTask(ctx, tID, uID, MergePatch, []byte(`{"due_date":null}`), 0)
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Merge_AllDayDueDate", func(t *testing.T) {
+		repo := new(testutils.AllMocks)
+		svc := NewTaskService(repo, new(testutils.WorkspaceMocks), nil)
+		moscow, err := usertime.Load("Europe/Moscow")
+		require.NoError(t, err)
+		mctx := usertime.WithLocation(ctx, moscow)
+		repo.On("GetByID", mctx, tID, uID).Return(current, nil).Once()
+		// Дата без времени — конец дня по Москве
+		repo.On("UpdateFields", mctx, mock.MatchedBy(func(task *model.Task) bool {
+			return task.DueDate.Equal(time.Date(2026, 11, 2, 20, 59, 59, 0, time.UTC))
+		}), []string{"due_date"}, uID).Return(nil).Once()
+
+		_, err = svc.PatchTask(mctx, tID, uID, MergePatch, []byte(`{"due_date":"2026-11-02"}`), 0)
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("JSONPatch_StatusAndTitle", func(t *testing.T) {
+		repo := new(testutils.AllMocks)
+		svc := NewTaskService(repo, new(testutils.WorkspaceMocks), nil)
+		repo.On("GetByID", ctx, tID, uID).Return(current, nil).Once()
+		repo.On("UpdateFields", ctx, mock.MatchedBy(func(task *model.Task) bool {
+			return task.Title == "Final report" && task.Status == "in_progress" && task.StartedAt != nil
+		}), []string{"title", "status", "started_at", "completed_at"}, uID).Return(nil).Once()
+
+		patch := `[{"op":"test","path":"/title","value":"Report"},
+			{"op":"replace","path":"/title","value":"Final report"},
+			{"op":"replace","path":"/status","value":"in_progress"}]`
+		task, err := svc.PatchTask(ctx, tID, uID, JSONPatch, []byte(patch), 2)
+		require.NoError(t, err)
+		assert.Equal(t, "in_progress", task.Status)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		repo := new(testutils.AllMocks)
+		svc := NewTaskService(repo, new(testutils.WorkspaceMocks), nil)
+		repo.On("GetByID", ctx, tID, uID).Return(current, nil)
+
+		var fieldErr *FieldError
+		for _, patch := range []string{`{"status":1}`, `{"title":null}`, `{"title":"  "}`, `{"due_date":"tomorrow"}`, `{"id":"x"}`, `{"priority":1}`} {
+			_, err := svc.PatchTask(ctx, tID, uID, MergePatch, []byte(patch), 0)
+			assert.ErrorAs(t, err, &fieldErr, patch)
+		}
+		var transition *TransitionError
+		_, err := svc.PatchTask(ctx, tID, uID, MergePatch, []byte(`{"status":"nope"}`), 0)
+		assert.ErrorAs(t, err, &transition)
+		_, err = svc.PatchTask(ctx, tID, uID, JSONPatch, []byte(`[{"op":"test","path":"/status","value":"done"}]`), 0)
+		assert.ErrorIs(t, err, jsonpatch.ErrTestFailed)
+		_, err = svc.PatchTask(ctx, tID, uID, "text/plain", []byte(`{}`), 0)
+		assert.ErrorIs(t, err, ErrPatchFormat)
+		_, err = svc.PatchTask(ctx, tID, uID, MergePatch, []byte(`{}`), 1)
+		assert.ErrorIs(t, err, repository.ErrVersionConflict)
+
+		// Патч без изменений ничего не пишет
+		task, err := svc.PatchTask(ctx, tID, uID, MergePatch, []byte(`{"title":"Report","content":"draft"}`), 0)
+		require.NoError(t, err)
+		assert.Equal(t, "Report", task.Title)
+		repo.AssertNotCalled(t, "UpdateFields", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Recurring_Done_ClearsRule", func(t *testing.T) {
+		repo := new(testutils.AllMocks)
+		svc := NewTaskService(repo, new(testutils.WorkspaceMocks), nil)
+		recurring := current
+		recurring.ID = uuid.New()
+		recurring.RRule = "FREQ=WEEKLY"
+		repo.On("GetByID", ctx, tID, uID).Return(recurring, nil).Once()
+		repo.On("Complete", ctx, mock.AnythingOfType("*model.Task"), []string{"status", "started_at", "completed_at", "rrule"},
+			mock.MatchedBy(func(next *model.Task) bool { return next != nil && next.RRule == "FREQ=WEEKLY" }), uID).Return(nil).Once()
+
+		_, err := svc.PatchTask(ctx, tID, uID, MergePatch, []byte(`{"status":"done"}`), 0)
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestTaskService_Trash(t *testing.T) {
+	ctx := context.Background()
+	uID := uuid.New().String()
+	wsID := uuid.New()
+	personal := model.Task{ID: uuid.New()}
+	shared := model.Task{ID: uuid.New(), WorkspaceID: &wsID}
+
+	t.Run("Restore", func(t *testing.T) {
+		repo := new(testutils.AllMocks)
+		events := &recordingPublisher{}
+		svc := NewTaskService(repo, new(testutils.WorkspaceMocks), events)
+		repo.On("GetDeleted", ctx, personal.ID.String(), uID).Return(personal, nil).Once()
+		repo.On("Restore", ctx, []string{personal.ID.String()}, uID).Return(nil).Once()
+		repo.On("GetByID", ctx, personal.ID.String(), uID).Return(personal, nil).Once()
+
+		task, err := svc.RestoreTask(ctx, personal.ID.String(), uID, 0)
+		require.NoError(t, err)
+		assert.Equal(t, personal.ID, task.ID)
+		require.Len(t, events.events, 1)
+		assert.Equal(t, event.TaskRestored, events.events[0].Type)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("BulkRestore_SkipsUnknown", func(t *testing.T) {
+		repo := new(testutils.AllMocks)
+		svc := NewTaskService(repo, new(testutils.WorkspaceMocks), nil)
+		repo.On("GetDeleted", ctx, personal.ID.String(), uID).Return(personal, nil).Once()
+		repo.On("GetDeleted", ctx, "missing", uID).Return(model.Task{}, gorm.ErrRecordNotFound).Once()
+		repo.On("Restore", ctx, []string{personal.ID.String()}, uID).Return(nil).Once()
+
+		assert.NoError(t, svc.BulkRestore(ctx, []string{personal.ID.String(), "missing"}, uID))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Purge_NeedsOwner", func(t *testing.T) {
+		repo := new(testutils.AllMocks)
+		workspaces := new(testutils.WorkspaceMocks)
+		svc := NewTaskService(repo, workspaces, nil)
+		repo.On("GetDeleted", ctx, shared.ID.String(), uID).Return(shared, nil)
+		workspaces.On("Role", ctx, wsID.String(), uID).Return(model.RoleEditor, nil).Once()
+
+		// Редактор может удалить задачу, но стереть её навсегда может только владелец
+		assert.ErrorIs(t, svc.PurgeTask(ctx, shared.ID.String(), uID, 0), ErrForbidden)
+		repo.AssertNotCalled(t, "Purge", mock.Anything, mock.Anything, mock.Anything)
+
+		workspaces.On("Role", ctx, wsID.String(), uID).Return(model.RoleOwner, nil).Once()
+		repo.On("Purge", ctx, []string{shared.ID.String()}, uID).Return(nil).Once()
+		assert.NoError(t, svc.PurgeTask(ctx, shared.ID.String(), uID, 0))
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestTrashPurger_RunOnce(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	repo := new(testutils.AllMocks)
+	p := NewTrashPurger(repo, &config.TrashConfig{Retention: 30 * 24 * time.Hour, BatchSize: 100})
+	p.now = func() time.Time { return now }
+
+	repo.On("PurgeDeletedBefore", ctx, now.AddDate(0, 0, -30), 100).Return(int64(3), nil).Once()
+	n, err := p.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	repo.AssertExpectations(t)
 }
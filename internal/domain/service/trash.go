@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+	"todo-list/config"
+	"todo-list/internal/domain/event"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+)
+
+func (s *taskServiceImpl) ListTrash(ctx context.Context, userID string, page repository.PageRequest) (repository.TaskPage, error) {
+	return s.repo.ListTrash(ctx, userID, page)
+}
+
+func (s *taskServiceImpl) RestoreTask(ctx context.Context, id, userID string, version int64) (model.Task, error) {
+	task, err := s.repo.GetDeleted(ctx, id, userID)
+	if err != nil {
+		return model.Task{}, err
+	}
+	if err := s.authorize(ctx, task.WorkspaceID, userID, model.RoleEditor); err != nil {
+		return model.Task{}, err
+	}
+	if err := checkVersion(task, version); err != nil {
+		return model.Task{}, err
+	}
+	if err := s.repo.Restore(ctx, []string{id}, userID); err != nil {
+		return model.Task{}, err
+	}
+	s.publish(ctx, event.TaskRestored, task, userID, map[string]interface{}{"task_id": id})
+	return s.repo.GetByID(ctx, id, userID)
+}
+
+// BulkRestore skips ids that are not in the user's trash, like BulkDelete
+// skips ids the user cannot see.
+func (s *taskServiceImpl) BulkRestore(ctx context.Context, ids []string, userID string) error {
+	var restore []string
+	tasks := make(map[string]model.Task, len(ids))
+	for _, id := range ids {
+		task, err := s.repo.GetDeleted(ctx, id, userID)
+		if err != nil {
+			continue
+		}
+		if err := s.authorize(ctx, task.WorkspaceID, userID, model.RoleEditor); err != nil {
+			return err
+		}
+		restore = append(restore, id)
+		tasks[id] = task
+	}
+	if len(restore) == 0 {
+		return nil
+	}
+	if err := s.repo.Restore(ctx, restore, userID); err != nil {
+		return err
+	}
+	for _, id := range restore {
+		s.publish(ctx, event.TaskRestored, tasks[id], userID, map[string]interface{}{"task_id": id})
+	}
+	return nil
+}
+
+func (s *taskServiceImpl) PurgeTask(ctx context.Context, id, userID string, version int64) error {
+	task, err := s.repo.GetDeleted(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.authorize(ctx, task.WorkspaceID, userID, model.RoleOwner); err != nil {
+		return err
+	}
+	if err := checkVersion(task, version); err != nil {
+		return err
+	}
+	if err := s.repo.Purge(ctx, []string{id}, userID); err != nil {
+		return err
+	}
+	s.publish(ctx, event.TaskPurged, task, userID, map[string]interface{}{"task_id": id})
+	return nil
+}
+
+// TrashPurger erases tasks that have been in the trash for longer than the
+// configured retention.
+type TrashPurger struct {
+	repo repository.TaskRepository
+	cfg  *config.TrashConfig
+	now  func() time.Time
+}
+
+func NewTrashPurger(repo repository.TaskRepository, cfg *config.TrashConfig) *TrashPurger {
+	return &TrashPurger{repo: repo, cfg: cfg, now: time.Now}
+}
+
+// Run sweeps until ctx is cancelled, draining full batches back to back.
+func (p *TrashPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := p.RunOnce(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("[ERROR] trash: %v", err)
+			}
+			if err != nil || n < int64(p.cfg.BatchSize) {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce erases one batch of expired tasks and reports how many it removed.
+func (p *TrashPurger) RunOnce(ctx context.Context) (int64, error) {
+	return p.repo.PurgeDeletedBefore(ctx, p.now().Add(-p.cfg.Retention), p.cfg.BatchSize)
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/event"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/netguard"
+	"todo-list/internal/domain/repository"
+)
+
+var (
+	ErrWebhookURL      = apperr.New(apperr.Invalid, "url must be an absolute http(s) URL on a public host")
+	ErrWebhookEvents   = apperr.New(apperr.Invalid, "events must list known event types or \"*\"")
+	ErrWebhookSecret   = apperr.New(apperr.Invalid, "secret must be between 16 and 128 characters")
+	ErrTooManyWebhooks = apperr.New(apperr.Invalid, "too many webhooks")
+)
+
+const (
+	maxWebhooksPerUser     = 20
+	defaultDeliveryLogSize = 50
+	maxDeliveryLogSize     = 200
+)
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, userID, url, secret string, events []string) (model.WebhookSubscription, error)
+	ListWebhooks(ctx context.Context, userID string) ([]model.WebhookSubscription, error)
+	GetWebhook(ctx context.Context, id, userID string) (model.WebhookSubscription, error)
+	// UpdateWebhook changes only the fields that are not nil.
+	UpdateWebhook(ctx context.Context, id, userID string, url, secret *string, events []string, active *bool) (model.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id, userID string) error
+	ListWebhookDeliveries(ctx context.Context, id, userID string, limit int) ([]model.WebhookDelivery, error)
+	// Redeliver queues the payload of an earlier delivery again as a new delivery.
+	Redeliver(ctx context.Context, id, deliveryID, userID string) (model.WebhookDelivery, error)
+}
+
+type webhookServiceImpl struct {
+	repo repository.WebhookRepository
+}
+
+func NewWebhookService(repo repository.WebhookRepository) WebhookService {
+	return &webhookServiceImpl{repo: repo}
+}
+
+func (s *webhookServiceImpl) CreateWebhook(ctx context.Context, userID, rawURL, secret string, events []string) (model.WebhookSubscription, error) {
+	if err := validateWebhookURL(rawURL); err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	if secret == "" {
+		secret = newWebhookSecret()
+	} else if err := validateWebhookSecret(secret); err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	if len(events) == 0 {
+		events = []string{"*"}
+	}
+	list, err := normalizeWebhookEvents(events)
+	if err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	existing, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	if len(existing) >= maxWebhooksPerUser {
+		return model.WebhookSubscription{}, ErrTooManyWebhooks
+	}
+	uID, _ := uuid.Parse(userID)
+	sub := model.WebhookSubscription{
+		ID:     uuid.New(),
+		UserID: uID,
+		URL:    rawURL,
+		Secret: secret,
+		Events: list,
+		Active: true,
+	}
+	return sub, s.repo.Create(ctx, &sub)
+}
+
+func (s *webhookServiceImpl) ListWebhooks(ctx context.Context, userID string) ([]model.WebhookSubscription, error) {
+	return s.repo.List(ctx, userID)
+}
+
+func (s *webhookServiceImpl) GetWebhook(ctx context.Context, id, userID string) (model.WebhookSubscription, error) {
+	return s.repo.GetByID(ctx, id, userID)
+}
+
+func (s *webhookServiceImpl) UpdateWebhook(ctx context.Context, id, userID string, rawURL, secret *string, events []string, active *bool) (model.WebhookSubscription, error) {
+	sub, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return model.WebhookSubscription{}, err
+	}
+	if rawURL != nil {
+		if err := validateWebhookURL(*rawURL); err != nil {
+			return model.WebhookSubscription{}, err
+		}
+		sub.URL = *rawURL
+	}
+	if secret != nil {
+		if err := validateWebhookSecret(*secret); err != nil {
+			return model.WebhookSubscription{}, err
+		}
+		sub.Secret = *secret
+	}
+	if events != nil {
+		if sub.Events, err = normalizeWebhookEvents(events); err != nil {
+			return model.WebhookSubscription{}, err
+		}
+	}
+	if active != nil {
+		sub.Active = *active
+	}
+	return sub, s.repo.Update(ctx, &sub)
+}
+
+func (s *webhookServiceImpl) DeleteWebhook(ctx context.Context, id, userID string) error {
+	return s.repo.Delete(ctx, id, userID)
+}
+
+func (s *webhookServiceImpl) ListWebhookDeliveries(ctx context.Context, id, userID string, limit int) ([]model.WebhookDelivery, error) {
+	if _, err := s.repo.GetByID(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultDeliveryLogSize
+	}
+	if limit > maxDeliveryLogSize {
+		limit = maxDeliveryLogSize
+	}
+	return s.repo.ListDeliveries(ctx, id, userID, limit)
+}
+
+func (s *webhookServiceImpl) Redeliver(ctx context.Context, id, deliveryID, userID string) (model.WebhookDelivery, error) {
+	original, err := s.repo.GetDelivery(ctx, deliveryID, id, userID)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	d := model.WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: original.SubscriptionID,
+		UserID:         original.UserID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         model.WebhookDeliveryPending,
+		NextAttemptAt:  time.Now(),
+	}
+	return d, s.repo.CreateDeliveries(ctx, []model.WebhookDelivery{d})
+}
+
+// validateWebhookURL refuses internal hosts, which would let users probe the
+// server's network through the delivery log.
+func validateWebhookURL(raw string) error {
+	if len(raw) > 2048 || !netguard.PublicURL(raw) {
+		return ErrWebhookURL
+	}
+	return nil
+}
+
+func validateWebhookSecret(secret string) error {
+	if n := len(secret); n < 16 || n > 128 {
+		return ErrWebhookSecret
+	}
+	return nil
+}
+
+// normalizeWebhookEvents de-duplicates the list and collapses it to "*" when
+// the wildcard is present.
+func normalizeWebhookEvents(events []string) (string, error) {
+	seen := make(map[string]bool, len(events))
+	out := make([]string, 0, len(events))
+	for _, e := range events {
+		e = strings.TrimSpace(e)
+		if e == "*" {
+			return "*", nil
+		}
+		if !event.Known(event.Type(e)) {
+			return "", ErrWebhookEvents
+		}
+		if !seen[e] {
+			seen[e] = true
+			out = append(out, e)
+		}
+	}
+	if len(out) == 0 {
+		return "", ErrWebhookEvents
+	}
+	return strings.Join(out, ","), nil
+}
+
+func newWebhookSecret() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/testutils"
+)
+
+func TestWebhookService_CreateWebhook(t *testing.T) {
+	ctx := context.Background()
+	uID := uuid.New().String()
+
+	t.Run("Defaults_To_All_Events_And_Generates_Secret", func(t *testing.T) {
+		repo := new(testutils.WebhookMocks)
+		s := NewWebhookService(repo)
+		repo.On("List", ctx, uID).Return([]model.WebhookSubscription{}, nil).Once()
+		repo.On("Create", ctx, mock.AnythingOfType("*model.WebhookSubscription")).Return(nil).Once()
+
+		sub, err := s.CreateWebhook(ctx, uID, "https://hooks.example.com/todo", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "*", sub.Events)
+		assert.True(t, strings.HasPrefix(sub.Secret, "whsec_"))
+		assert.True(t, sub.Active)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Normalizes_Event_List", func(t *testing.T) {
+		repo := new(testutils.WebhookMocks)
+		s := NewWebhookService(repo)
+		repo.On("List", ctx, uID).Return([]model.WebhookSubscription{}, nil).Once()
+		repo.On("Create", ctx, mock.Anything).Return(nil).Once()
+
+		sub, err := s.CreateWebhook(ctx, uID, "https://hooks.example.com", "0123456789abcdef", []string{"task.created", " task.deleted", "task.created"})
+		require.NoError(t, err)
+		assert.Equal(t, "task.created,task.deleted", sub.Events)
+		assert.True(t, sub.Wants("task.deleted"))
+		assert.False(t, sub.Wants("task.updated"))
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		s := NewWebhookService(new(testutils.WebhookMocks))
+
+		_, err := s.CreateWebhook(ctx, uID, "ftp://example.com", "", nil)
+		assert.ErrorIs(t, err, ErrWebhookURL)
+		for _, internal := range []string{"http://127.0.0.1:8080/", "http://169.254.169.254/latest/meta-data", "http://redis:6379"} {
+			_, err = s.CreateWebhook(ctx, uID, internal, "", nil)
+			assert.ErrorIs(t, err, ErrWebhookURL, internal)
+		}
+		_, err = s.CreateWebhook(ctx, uID, "https://example.com", "short", nil)
+		assert.ErrorIs(t, err, ErrWebhookSecret)
+		_, err = s.CreateWebhook(ctx, uID, "https://example.com", "", []string{"task.exploded"})
+		assert.ErrorIs(t, err, ErrWebhookEvents)
+	})
+}
+
+func TestWebhookService_Redeliver(t *testing.T) {
+	ctx := context.Background()
+	repo := new(testutils.WebhookMocks)
+	s := NewWebhookService(repo)
+
+	original := model.WebhookDelivery{
+		ID: uuid.New(), SubscriptionID: uuid.New(), EventID: uuid.New(), EventType: "task.created",
+		Payload: `{"type":"task.created"}`, Status: model.WebhookDeliveryFailed, Attempts: 8,
+	}
+	repo.On("GetDelivery", ctx, original.ID.String(), "sub", "u1").Return(original, nil).Once()
+	repo.On("CreateDeliveries", ctx, mock.MatchedBy(func(d []model.WebhookDelivery) bool {
+		return len(d) == 1 && d[0].ID != original.ID && d[0].Payload == original.Payload &&
+			d[0].EventID == original.EventID && d[0].Status == model.WebhookDeliveryPending && d[0].Attempts == 0
+	})).Return(nil).Once()
+
+	d, err := s.Redeliver(ctx, "sub", original.ID.String(), "u1")
+	require.NoError(t, err)
+	assert.Equal(t, model.WebhookDeliveryPending, d.Status)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"log"
+	"time"
+	"todo-list/config"
+	"todo-list/internal/domain/event"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+)
+
+// WebhookDispatcher queues an event for every active subscription of its
+// owner that asks for it. Deliveries are written to the database so that the
+// event survives restarts; WebhookWorker sends them.
+type WebhookDispatcher struct {
+	repo repository.WebhookRepository
+}
+
+func NewWebhookDispatcher(repo repository.WebhookRepository) *WebhookDispatcher {
+	return &WebhookDispatcher{repo: repo}
+}
+
+func (d *WebhookDispatcher) Publish(ctx context.Context, e event.Event) {
+	// The request may finish before the deliveries are stored.
+	ctx = context.WithoutCancel(ctx)
+	subs, err := d.repo.ListActive(ctx, e.UserID.String())
+	if err != nil {
+		log.Printf("[ERROR] webhooks: list subscriptions for %s: %v", e.Type, err)
+		return
+	}
+	var deliveries []model.WebhookDelivery
+	var payload []byte
+	for _, sub := range subs {
+		if !sub.Wants(string(e.Type)) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(e); err != nil {
+				log.Printf("[ERROR] webhooks: encode %s: %v", e.Type, err)
+				return
+			}
+		}
+		deliveries = append(deliveries, model.WebhookDelivery{
+			ID:             uuid.New(),
+			SubscriptionID: sub.ID,
+			UserID:         e.UserID,
+			EventID:        e.ID,
+			EventType:      string(e.Type),
+			Payload:        string(payload),
+			Status:         model.WebhookDeliveryPending,
+			NextAttemptAt:  e.OccurredAt,
+		})
+	}
+	if err := d.repo.CreateDeliveries(ctx, deliveries); err != nil {
+		log.Printf("[ERROR] webhooks: queue %s: %v", e.Type, err)
+	}
+}
+
+// WebhookSender performs one HTTP delivery and reports the response status,
+// zero when no response was received.
+type WebhookSender interface {
+	Send(ctx context.Context, url, secret string, d model.WebhookDelivery) (int, error)
+}
+
+const (
+	webhookBaseBackoff = 30 * time.Second
+	maxWebhookBackoff  = 6 * time.Hour
+)
+
+// WebhookWorker polls for pending deliveries, the same way ReminderScheduler
+// polls for reminders, and retries failures with exponential backoff.
+type WebhookWorker struct {
+	repo   repository.WebhookRepository
+	sender WebhookSender
+	cfg    *config.WebhookConfig
+	now    func() time.Time
+}
+
+func NewWebhookWorker(repo repository.WebhookRepository, sender WebhookSender, cfg *config.WebhookConfig) *WebhookWorker {
+	return &WebhookWorker{repo: repo, sender: sender, cfg: cfg, now: time.Now}
+}
+
+// Run polls until ctx is cancelled.
+func (w *WebhookWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := w.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("[ERROR] webhooks: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims and sends one batch, returning how many were delivered.
+func (w *WebhookWorker) RunOnce(ctx context.Context) (int, error) {
+	now := w.now()
+	pending, err := w.repo.ClaimDeliveries(ctx, now, w.cfg.Lease, w.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for _, p := range pending {
+		sendCtx, cancel := context.WithTimeout(ctx, w.cfg.Timeout)
+		status, err := w.sender.Send(sendCtx, p.URL, p.Secret, p.Delivery)
+		cancel()
+		if err != nil {
+			w.fail(ctx, p.Delivery, status, err, now)
+			continue
+		}
+		if err := w.repo.MarkDelivered(ctx, p.Delivery.ID, status, w.now()); err != nil {
+			log.Printf("[ERROR] webhooks: mark %s delivered: %v", p.Delivery.ID, err)
+			continue
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// fail schedules the next attempt 30s·2^(attempts-1) later, capped at six
+// hours, until MaxAttempts is reached.
+func (w *WebhookWorker) fail(ctx context.Context, d model.WebhookDelivery, status int, cause error, now time.Time) {
+	var retryAt *time.Time
+	if d.Attempts < w.cfg.MaxAttempts {
+		backoff := maxWebhookBackoff
+		if d.Attempts < 12 {
+			if b := webhookBaseBackoff << uint(max(d.Attempts-1, 0)); b < maxWebhookBackoff {
+				backoff = b
+			}
+		}
+		at := now.Add(backoff)
+		retryAt = &at
+	}
+	if err := w.repo.MarkDeliveryFailed(ctx, d.ID, status, cause.Error(), retryAt); err != nil {
+		log.Printf("[ERROR] webhooks: mark %s failed: %v", d.ID, err)
+	}
+}
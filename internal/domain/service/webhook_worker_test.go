@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"todo-list/config"
+	"todo-list/internal/domain/event"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/testutils"
+)
+
+type fakeSender struct {
+	status int
+	err    error
+	sent   []string
+}
+
+func (f *fakeSender) Send(ctx context.Context, url, secret string, d model.WebhookDelivery) (int, error) {
+	f.sent = append(f.sent, url)
+	return f.status, f.err
+}
+
+func TestWebhookDispatcher_Publish(t *testing.T) {
+	repo := new(testutils.WebhookMocks)
+	d := NewWebhookDispatcher(repo)
+	uID := uuid.New()
+	all := model.WebhookSubscription{ID: uuid.New(), Events: "*"}
+	deletesOnly := model.WebhookSubscription{ID: uuid.New(), Events: "task.deleted"}
+	e := event.New(event.TaskCreated, uID, map[string]interface{}{"task_id": "t1"})
+
+	repo.On("ListActive", mock.Anything, uID.String()).Return([]model.WebhookSubscription{all, deletesOnly}, nil).Once()
+	repo.On("CreateDeliveries", mock.Anything, mock.MatchedBy(func(ds []model.WebhookDelivery) bool {
+		if len(ds) != 1 || ds[0].SubscriptionID != all.ID || ds[0].EventID != e.ID {
+			return false
+		}
+		var payload map[string]interface{}
+		return json.Unmarshal([]byte(ds[0].Payload), &payload) == nil && payload["type"] == "task.created"
+	})).Return(nil).Once()
+
+	d.Publish(context.Background(), e)
+	repo.AssertExpectations(t)
+}
+
+func TestWebhookWorker_RunOnce(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	cfg := &config.WebhookConfig{Lease: time.Minute, BatchSize: 10, MaxAttempts: 3, Timeout: time.Second}
+
+	pending := func(attempts int) repository.PendingDelivery {
+		return repository.PendingDelivery{
+			Delivery: model.WebhookDelivery{ID: uuid.New(), Attempts: attempts},
+			URL:      "https://hooks.example.com",
+			Secret:   "secret",
+		}
+	}
+
+	t.Run("Delivers", func(t *testing.T) {
+		repo := new(testutils.WebhookMocks)
+		sender := &fakeSender{status: 200}
+		w := NewWebhookWorker(repo, sender, cfg)
+		w.now = func() time.Time { return now }
+
+		p := pending(1)
+		repo.On("ClaimDeliveries", ctx, now, time.Minute, 10).Return([]repository.PendingDelivery{p}, nil).Once()
+		repo.On("MarkDelivered", ctx, p.Delivery.ID, 200, now).Return(nil).Once()
+
+		n, err := w.RunOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, []string{"https://hooks.example.com"}, sender.sent)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Backs_Off_Then_Gives_Up", func(t *testing.T) {
+		repo := new(testutils.WebhookMocks)
+		w := NewWebhookWorker(repo, &fakeSender{status: 503, err: errors.New("503")}, cfg)
+		w.now = func() time.Time { return now }
+
+		first, second, last := pending(1), pending(2), pending(3)
+		repo.On("ClaimDeliveries", ctx, now, time.Minute, 10).Return([]repository.PendingDelivery{first, second, last}, nil).Once()
+		retryIn := func(d time.Duration) interface{} {
+			return mock.MatchedBy(func(at *time.Time) bool { return at != nil && at.Equal(now.Add(d)) })
+		}
+		repo.On("MarkDeliveryFailed", ctx, first.Delivery.ID, 503, "503", retryIn(30*time.Second)).Return(nil).Once()
+		repo.On("MarkDeliveryFailed", ctx, second.Delivery.ID, 503, "503", retryIn(time.Minute)).Return(nil).Once()
+		repo.On("MarkDeliveryFailed", ctx, last.Delivery.ID, 503, "503", (*time.Time)(nil)).Return(nil).Once()
+
+		n, err := w.RunOnce(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, n)
+		repo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/model"
+)
+
+var ErrInvalidWorkflow = apperr.New(apperr.Invalid, "invalid workflow")
+
+// TransitionError rejects a status the task's workflow does not allow. From
+// is empty for a task that is being created.
+type TransitionError struct {
+	From    string
+	To      string
+	Allowed []string
+}
+
+func (e *TransitionError) Error() string {
+	if e.From == "" {
+		return fmt.Sprintf("status %q is not part of the workflow", e.To)
+	}
+	return fmt.Sprintf("cannot move task from %q to %q", e.From, e.To)
+}
+
+func (e *TransitionError) Kind() apperr.Kind { return apperr.Unprocessable }
+
+func (e *TransitionError) Violations() []apperr.Violation {
+	return []apperr.Violation{{Field: "status", Reason: e.Error()}}
+}
+
+// validateWorkflow accepts nil, which resets to model.DefaultWorkflow.
+func validateWorkflow(wf *model.Workflow) error {
+	if wf == nil {
+		return nil
+	}
+	if err := wf.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWorkflow, err)
+	}
+	return nil
+}
+
+// workflowFor returns the workflow that governs the task. Personal tasks
+// outside a project always follow the default one.
+func (s *taskServiceImpl) workflowFor(ctx context.Context, task model.Task) (model.Workflow, error) {
+	if task.ProjectID == nil && task.WorkspaceID == nil {
+		return model.DefaultWorkflow, nil
+	}
+	wf, err := s.repo.Workflow(ctx, task.ProjectID, task.WorkspaceID)
+	if err != nil || wf == nil {
+		return model.DefaultWorkflow, err
+	}
+	return *wf, nil
+}
+
+// checkTransition fails with a *TransitionError unless the task may move to
+// status.
+func (s *taskServiceImpl) checkTransition(ctx context.Context, task model.Task, status string) error {
+	wf, err := s.workflowFor(ctx, task)
+	if err != nil {
+		return err
+	}
+	if task.Status == "" {
+		if !wf.Has(status) {
+			return &TransitionError{To: status, Allowed: wf.Statuses}
+		}
+		return nil
+	}
+	if !wf.CanMove(task.Status, status) {
+		return &TransitionError{From: task.Status, To: status, Allowed: wf.Allowed(task.Status)}
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+	"todo-list/config"
+	"todo-list/internal/domain/apperr"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/domain/usertime"
+	"unicode/utf8"
+)
+
+var (
+	ErrForbidden            = apperr.New(apperr.Forbidden, "your workspace role does not allow this")
+	ErrInvalidWorkspaceName = apperr.New(apperr.Invalid, "workspace name must be between 1 and 100 characters")
+	ErrInvalidRole          = apperr.New(apperr.Invalid, "role must be one of owner, editor, viewer")
+)
+
+type WorkspaceService interface {
+	CreateWorkspace(ctx context.Context, userID, name string) (repository.WorkspaceSummary, error)
+	ListWorkspaces(ctx context.Context, userID string) ([]repository.WorkspaceSummary, error)
+	GetWorkspace(ctx context.Context, id, userID string) (repository.WorkspaceSummary, error)
+	RenameWorkspace(ctx context.Context, id, userID, name string) (repository.WorkspaceSummary, error)
+	DeleteWorkspace(ctx context.Context, id, userID string) error
+	// SetWorkflow replaces the workspace's workflow; nil restores the default.
+	SetWorkflow(ctx context.Context, id, userID string, wf *model.Workflow) (repository.WorkspaceSummary, error)
+	ListWorkspaceTasks(ctx context.Context, id, userID, filter string, page repository.PageRequest) (repository.TaskPage, error)
+
+	ListWorkspaceMembers(ctx context.Context, id, userID string) ([]repository.Member, error)
+	AddWorkspaceMember(ctx context.Context, id, userID, email, role string) (repository.Member, error)
+	ChangeMemberRole(ctx context.Context, id, userID, memberID, role string) (repository.Member, error)
+	// RemoveWorkspaceMember lets owners remove anyone and any member leave.
+	RemoveWorkspaceMember(ctx context.Context, id, userID, memberID string) error
+
+	// InviteMember returns the invitation together with its signed token.
+	InviteMember(ctx context.Context, id, userID, email, role string) (model.WorkspaceInvitation, string, error)
+	ListPendingInvitations(ctx context.Context, id, userID string) ([]model.WorkspaceInvitation, error)
+	RevokeWorkspaceInvitation(ctx context.Context, id, userID, invitationID string) error
+	// InvitationByToken returns the open invitation a token refers to.
+	InvitationByToken(ctx context.Context, token string) (model.WorkspaceInvitation, error)
+	AcceptInvitationToken(ctx context.Context, token, userID string) (repository.Member, error)
+	DeclineInvitationToken(ctx context.Context, token string) error
+}
+
+type workspaceServiceImpl struct {
+	repo      repository.WorkspaceRepository
+	tasks     repository.TaskRepository
+	mailer    InvitationMailer
+	inviteKey []byte
+	inviteTTL time.Duration
+	acceptURL string
+}
+
+// NewWorkspaceService signs invitation tokens with a key derived from secret.
+// mailer may be nil.
+func NewWorkspaceService(repo repository.WorkspaceRepository, tasks repository.TaskRepository, secret string, cfg *config.InvitationConfig, mailer InvitationMailer) WorkspaceService {
+	return &workspaceServiceImpl{
+		repo:      repo,
+		tasks:     tasks,
+		mailer:    mailer,
+		inviteKey: invitationKey(secret),
+		inviteTTL: cfg.TTL,
+		acceptURL: cfg.AcceptURL,
+	}
+}
+
+// require returns ErrWorkspaceNotFound to non-members and ErrForbidden to
+// members whose role is below need.
+func (s *workspaceServiceImpl) require(ctx context.Context, id, userID, need string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return repository.ErrWorkspaceNotFound
+	}
+	role, err := s.repo.Role(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !model.RoleAllows(role, need) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func (s *workspaceServiceImpl) CreateWorkspace(ctx context.Context, userID, name string) (repository.WorkspaceSummary, error) {
+	uID, _ := uuid.Parse(userID)
+	ws := model.Workspace{ID: uuid.New(), Name: strings.TrimSpace(name), CreatedBy: uID}
+	if err := validateWorkspaceName(ws.Name); err != nil {
+		return repository.WorkspaceSummary{}, err
+	}
+	if err := s.repo.Create(ctx, &ws); err != nil {
+		return repository.WorkspaceSummary{}, err
+	}
+	return repository.WorkspaceSummary{Workspace: ws, Role: model.RoleOwner, MemberCount: 1}, nil
+}
+
+func (s *workspaceServiceImpl) ListWorkspaces(ctx context.Context, userID string) ([]repository.WorkspaceSummary, error) {
+	return s.repo.List(ctx, userID)
+}
+
+func (s *workspaceServiceImpl) GetWorkspace(ctx context.Context, id, userID string) (repository.WorkspaceSummary, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return repository.WorkspaceSummary{}, repository.ErrWorkspaceNotFound
+	}
+	return s.repo.GetByID(ctx, id, userID)
+}
+
+func (s *workspaceServiceImpl) RenameWorkspace(ctx context.Context, id, userID, name string) (repository.WorkspaceSummary, error) {
+	if err := s.require(ctx, id, userID, model.RoleOwner); err != nil {
+		return repository.WorkspaceSummary{}, err
+	}
+	ws, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return repository.WorkspaceSummary{}, err
+	}
+	ws.Name = strings.TrimSpace(name)
+	if err := validateWorkspaceName(ws.Name); err != nil {
+		return repository.WorkspaceSummary{}, err
+	}
+	return ws, s.repo.Update(ctx, &ws.Workspace)
+}
+
+func (s *workspaceServiceImpl) SetWorkflow(ctx context.Context, id, userID string, wf *model.Workflow) (repository.WorkspaceSummary, error) {
+	if err := s.require(ctx, id, userID, model.RoleOwner); err != nil {
+		return repository.WorkspaceSummary{}, err
+	}
+	if err := validateWorkflow(wf); err != nil {
+		return repository.WorkspaceSummary{}, err
+	}
+	ws, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return repository.WorkspaceSummary{}, err
+	}
+	ws.Workflow = wf
+	return ws, s.repo.Update(ctx, &ws.Workspace)
+}
+
+func (s *workspaceServiceImpl) DeleteWorkspace(ctx context.Context, id, userID string) error {
+	if err := s.require(ctx, id, userID, model.RoleOwner); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *workspaceServiceImpl) ListWorkspaceTasks(ctx context.Context, id, userID, filter string, page repository.PageRequest) (repository.TaskPage, error) {
+	if err := s.require(ctx, id, userID, model.RoleViewer); err != nil {
+		return repository.TaskPage{}, err
+	}
+	f, err := ParseTaskFilter(filter, usertime.Now(ctx))
+	if err != nil {
+		return repository.TaskPage{}, err
+	}
+	wsID, _ := uuid.Parse(id)
+	f.WorkspaceID = &wsID
+	return s.tasks.List(ctx, userID, f, page)
+}
+
+func (s *workspaceServiceImpl) ListWorkspaceMembers(ctx context.Context, id, userID string) ([]repository.Member, error) {
+	if err := s.require(ctx, id, userID, model.RoleViewer); err != nil {
+		return nil, err
+	}
+	return s.repo.ListMembers(ctx, id)
+}
+
+func (s *workspaceServiceImpl) AddWorkspaceMember(ctx context.Context, id, userID, email, role string) (repository.Member, error) {
+	if role == "" {
+		role = model.RoleEditor
+	}
+	if !model.ValidRole(role) {
+		return repository.Member{}, ErrInvalidRole
+	}
+	if err := s.require(ctx, id, userID, model.RoleOwner); err != nil {
+		return repository.Member{}, err
+	}
+	return s.repo.AddMember(ctx, id, email, role)
+}
+
+func (s *workspaceServiceImpl) ChangeMemberRole(ctx context.Context, id, userID, memberID, role string) (repository.Member, error) {
+	if !model.ValidRole(role) {
+		return repository.Member{}, ErrInvalidRole
+	}
+	if err := s.require(ctx, id, userID, model.RoleOwner); err != nil {
+		return repository.Member{}, err
+	}
+	if _, err := uuid.Parse(memberID); err != nil {
+		return repository.Member{}, repository.ErrMemberNotFound
+	}
+	return s.repo.UpdateMemberRole(ctx, id, memberID, role)
+}
+
+func (s *workspaceServiceImpl) RemoveWorkspaceMember(ctx context.Context, id, userID, memberID string) error {
+	need := model.RoleOwner
+	if memberID == userID {
+		need = model.RoleViewer
+	}
+	if err := s.require(ctx, id, userID, need); err != nil {
+		return err
+	}
+	if _, err := uuid.Parse(memberID); err != nil {
+		return repository.ErrMemberNotFound
+	}
+	return s.repo.RemoveMember(ctx, id, memberID)
+}
+
+func validateWorkspaceName(name string) error {
+	if n := utf8.RuneCountInString(name); n == 0 || n > 100 {
+		return ErrInvalidWorkspaceName
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+	"todo-list/config"
+	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
+	"todo-list/internal/testutils"
+)
+
+func TestWorkspaceService(t *testing.T) {
+	ctx := context.Background()
+	uID := uuid.New().String()
+	wsID := uuid.New().String()
+	memberID := uuid.New().String()
+	inviteCfg := &config.InvitationConfig{TTL: time.Hour}
+
+	t.Run("Create_Makes_Owner", func(t *testing.T) {
+		repo := new(testutils.WorkspaceMocks)
+		s := NewWorkspaceService(repo, new(testutils.AllMocks), "secret", inviteCfg, nil)
+		repo.On("Create", ctx, mock.MatchedBy(func(ws *model.Workspace) bool {
+			return ws.Name == "Team" && ws.CreatedBy.String() == uID
+		})).Return(nil).Once()
+
+		ws, err := s.CreateWorkspace(ctx, uID, " Team ")
+		require.NoError(t, err)
+		assert.Equal(t, model.RoleOwner, ws.Role)
+
+		_, err = s.CreateWorkspace(ctx, uID, "")
+		assert.ErrorIs(t, err, ErrInvalidWorkspaceName)
+	})
+
+	t.Run("Members_Require_Owner", func(t *testing.T) {
+		repo := new(testutils.WorkspaceMocks)
+		s := NewWorkspaceService(repo, new(testutils.AllMocks), "secret", inviteCfg, nil)
+		repo.On("Role", ctx, wsID, uID).Return(model.RoleEditor, nil)
+
+		_, err := s.AddWorkspaceMember(ctx, wsID, uID, "bob@example.com", model.RoleViewer)
+		assert.ErrorIs(t, err, ErrForbidden)
+		_, err = s.ChangeMemberRole(ctx, wsID, uID, memberID, model.RoleOwner)
+		assert.ErrorIs(t, err, ErrForbidden)
+		assert.ErrorIs(t, s.RemoveWorkspaceMember(ctx, wsID, uID, memberID), ErrForbidden)
+		assert.ErrorIs(t, s.DeleteWorkspace(ctx, wsID, uID), ErrForbidden)
+		_, err = s.SetWorkflow(ctx, wsID, uID, nil)
+		assert.ErrorIs(t, err, ErrForbidden)
+
+		// Любой участник может выйти сам
+		repo.On("RemoveMember", ctx, wsID, uID).Return(nil).Once()
+		assert.NoError(t, s.RemoveWorkspaceMember(ctx, wsID, uID, uID))
+
+		_, err = s.AddWorkspaceMember(ctx, wsID, uID, "bob@example.com", "admin")
+		assert.ErrorIs(t, err, ErrInvalidRole)
+	})
+
+	t.Run("AddMember_Defaults_To_Editor", func(t *testing.T) {
+		repo := new(testutils.WorkspaceMocks)
+		s := NewWorkspaceService(repo, new(testutils.AllMocks), "secret", inviteCfg, nil)
+		repo.On("Role", ctx, wsID, uID).Return(model.RoleOwner, nil).Once()
+		repo.On("AddMember", ctx, wsID, "bob@example.com", model.RoleEditor).Return(repository.Member{}, nil).Once()
+
+		_, err := s.AddWorkspaceMember(ctx, wsID, uID, "bob@example.com", "")
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Tasks_Scoped_To_Workspace", func(t *testing.T) {
+		repo, tasks := new(testutils.WorkspaceMocks), new(testutils.AllMocks)
+		s := NewWorkspaceService(repo, tasks, "secret", inviteCfg, nil)
+		page := repository.PageRequest{Limit: 20}
+		repo.On("Role", ctx, wsID, uID).Return(model.RoleViewer, nil).Once()
+		tasks.On("List", ctx, uID, mock.MatchedBy(func(f repository.TaskFilter) bool {
+			return f.WorkspaceID != nil && f.WorkspaceID.String() == wsID
+		}), page).Return(repository.TaskPage{}, nil).Once()
+
+		_, err := s.ListWorkspaceTasks(ctx, wsID, uID, "", page)
+		require.NoError(t, err)
+
+		_, err = s.ListWorkspaceTasks(ctx, "nope", uID, "", page)
+		assert.ErrorIs(t, err, repository.ErrWorkspaceNotFound)
+		tasks.AssertExpectations(t)
+	})
+}
+
+type fakeInvitationMailer struct {
+	sent []model.InvitationMail
+}
+
+func (f *fakeInvitationMailer) SendInvitation(ctx context.Context, mail model.InvitationMail) error {
+	f.sent = append(f.sent, mail)
+	return nil
+}
+
+func TestWorkspaceService_Invitations(t *testing.T) {
+	ctx := context.Background()
+	uID := uuid.New().String()
+	inviteeID := uuid.New().String()
+	wsID := uuid.New()
+	cfg := &config.InvitationConfig{TTL: time.Hour, AcceptURL: "https://app.example.com/invite"}
+	summary := repository.WorkspaceSummary{Workspace: model.Workspace{ID: wsID, Name: "Team"}, Role: model.RoleOwner}
+
+	t.Run("Invite_Signs_And_Mails_Token", func(t *testing.T) {
+		repo, mailer := new(testutils.WorkspaceMocks), &fakeInvitationMailer{}
+		s := NewWorkspaceService(repo, new(testutils.AllMocks), "secret", cfg, mailer)
+		repo.On("Role", ctx, wsID.String(), uID).Return(model.RoleOwner, nil).Once()
+		repo.On("GetByID", ctx, wsID.String(), uID).Return(summary, nil).Once()
+		repo.On("CreateInvitation", ctx, mock.MatchedBy(func(inv *model.WorkspaceInvitation) bool {
+			return inv.Email == "bob@example.com" && inv.Role == model.RoleViewer && inv.WorkspaceID == wsID
+		})).Return(nil).Once()
+
+		inv, token, err := s.InviteMember(ctx, wsID.String(), uID, " Bob@Example.com ", model.RoleViewer)
+		require.NoError(t, err)
+		require.Len(t, mailer.sent, 1)
+		assert.Equal(t, "bob@example.com", mailer.sent[0].To)
+		assert.True(t, strings.HasPrefix(mailer.sent[0].Link, cfg.AcceptURL+"?token="))
+
+		repo.On("AcceptInvitation", ctx, inv.ID.String(), inviteeID).Return(repository.Member{}, nil).Once()
+		_, err = s.AcceptInvitationToken(ctx, token, inviteeID)
+		require.NoError(t, err)
+
+		// Подделанная подпись или чужой секрет не проходят
+		tampered := []byte(token)
+		tampered[len(tampered)-5] ^= 1
+		_, err = s.AcceptInvitationToken(ctx, string(tampered), inviteeID)
+		assert.ErrorIs(t, err, ErrInvalidInvitation)
+		other := NewWorkspaceService(repo, new(testutils.AllMocks), "other", cfg, nil)
+		assert.ErrorIs(t, other.DeclineInvitationToken(ctx, token), ErrInvalidInvitation)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Invite_Validation", func(t *testing.T) {
+		repo := new(testutils.WorkspaceMocks)
+		s := NewWorkspaceService(repo, new(testutils.AllMocks), "secret", cfg, nil)
+		repo.On("Role", ctx, wsID.String(), uID).Return(model.RoleEditor, nil)
+
+		_, _, err := s.InviteMember(ctx, wsID.String(), uID, "not-an-email", "")
+		assert.ErrorIs(t, err, ErrInvitationTarget)
+		_, _, err = s.InviteMember(ctx, wsID.String(), uID, "Bob <bob@example.com>", "")
+		assert.ErrorIs(t, err, ErrInvitationTarget)
+		_, _, err = s.InviteMember(ctx, wsID.String(), uID, "bob@example.com", "admin")
+		assert.ErrorIs(t, err, ErrInvalidRole)
+		_, _, err = s.InviteMember(ctx, wsID.String(), uID, "bob@example.com", "")
+		assert.ErrorIs(t, err, ErrForbidden)
+		_, err = s.ListPendingInvitations(ctx, wsID.String(), uID)
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+
+	t.Run("Expired_Token", func(t *testing.T) {
+		repo := new(testutils.WorkspaceMocks)
+		s := NewWorkspaceService(repo, new(testutils.AllMocks), "secret", &config.InvitationConfig{TTL: -time.Minute}, nil)
+		repo.On("Role", ctx, wsID.String(), uID).Return(model.RoleOwner, nil).Once()
+		repo.On("GetByID", ctx, wsID.String(), uID).Return(summary, nil).Once()
+		repo.On("CreateInvitation", ctx, mock.Anything).Return(nil).Once()
+
+		_, token, err := s.InviteMember(ctx, wsID.String(), uID, "bob@example.com", "")
+		require.NoError(t, err)
+		assert.ErrorIs(t, s.DeclineInvitationToken(ctx, token), repository.ErrInvitationClosed)
+	})
+
+	t.Run("Revoked_Invitation_Is_Closed", func(t *testing.T) {
+		repo := new(testutils.WorkspaceMocks)
+		s := NewWorkspaceService(repo, new(testutils.AllMocks), "secret", cfg, nil)
+		repo.On("Role", ctx, wsID.String(), uID).Return(model.RoleOwner, nil)
+		repo.On("GetByID", ctx, wsID.String(), uID).Return(summary, nil).Once()
+		repo.On("CreateInvitation", ctx, mock.Anything).Return(nil).Once()
+
+		inv, token, err := s.InviteMember(ctx, wsID.String(), uID, "bob@example.com", "")
+		require.NoError(t, err)
+		repo.On("RevokeInvitation", ctx, wsID.String(), inv.ID.String()).Return(nil).Once()
+		require.NoError(t, s.RevokeWorkspaceInvitation(ctx, wsID.String(), uID, inv.ID.String()))
+
+		inv.Status = model.InvitationRevoked
+		repo.On("GetInvitation", ctx, inv.ID.String()).Return(inv, nil).Once()
+		_, err = s.InvitationByToken(ctx, token)
+		assert.ErrorIs(t, err, repository.ErrInvitationClosed)
+	})
+}
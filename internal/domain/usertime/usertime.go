@@ -0,0 +1,73 @@
+// Package usertime carries the time zone a request is served in, so that
+// "today", overdue and all-day dates follow the user's calendar rather than
+// the server's.
+package usertime
+
+import (
+	"context"
+	"time"
+	"todo-list/internal/domain/apperr"
+
+	// Time zones must resolve even where the host has no zoneinfo database.
+	_ "time/tzdata"
+)
+
+var ErrInvalidTimezone = apperr.New(apperr.Invalid, "timezone must be an IANA time zone name such as Europe/Moscow")
+
+type locationKey struct{}
+
+// WithLocation returns a copy of ctx that carries loc.
+func WithLocation(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, locationKey{}, loc)
+}
+
+// Location returns the location carried by ctx, or UTC.
+func Location(ctx context.Context) *time.Location {
+	if loc, ok := ctx.Value(locationKey{}).(*time.Location); ok && loc != nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// Now returns the current time in the location carried by ctx.
+func Now(ctx context.Context) time.Time {
+	return time.Now().In(Location(ctx))
+}
+
+// Load resolves an IANA time zone name. The empty name stands for UTC;
+// "Local" is refused because it depends on the server.
+func Load(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	if name == "Local" {
+		return nil, ErrInvalidTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	return loc, nil
+}
+
+// EndOfDay is the last second of the given calendar day in loc, the moment
+// an all-day due date falls due.
+func EndOfDay(year int, month time.Month, day int, loc *time.Location) time.Time {
+	return time.Date(year, month, day+1, 0, 0, 0, 0, loc).Add(-time.Second)
+}
+
+// DateLayout is how all-day dates are written.
+const DateLayout = "2006-01-02"
+
+// ParseDue reads a due date given either as an RFC 3339 timestamp or as an
+// all-day date, which falls due at the end of that day in loc.
+func ParseDue(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return EndOfDay(d.Year(), d.Month(), d.Day(), loc), nil
+}
@@ -0,0 +1,56 @@
+package usertime
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestLocation(t *testing.T) {
+	assert.Equal(t, time.UTC, Location(context.Background()))
+
+	moscow, err := Load("Europe/Moscow")
+	require.NoError(t, err)
+	ctx := WithLocation(context.Background(), moscow)
+	assert.Equal(t, moscow, Location(ctx))
+	assert.Equal(t, moscow, Now(ctx).Location())
+}
+
+func TestLoad(t *testing.T) {
+	loc, err := Load("")
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, loc)
+
+	for _, name := range []string{"Local", "Mars/Olympus", "../etc/passwd"} {
+		_, err := Load(name)
+		assert.ErrorIs(t, err, ErrInvalidTimezone, name)
+	}
+}
+
+func TestEndOfDay(t *testing.T) {
+	moscow, err := Load("Europe/Moscow")
+	require.NoError(t, err)
+
+	// Конец 1 ноября по Москве — 20:59:59 UTC
+	assert.Equal(t, time.Date(2026, 11, 1, 20, 59, 59, 0, time.UTC), EndOfDay(2026, time.November, 1, moscow).UTC())
+	assert.Equal(t, time.Date(2026, 12, 31, 23, 59, 59, 0, time.UTC), EndOfDay(2026, time.December, 31, time.UTC))
+}
+
+func TestParseDue(t *testing.T) {
+	moscow, err := Load("Europe/Moscow")
+	require.NoError(t, err)
+
+	due, err := ParseDue("2026-11-01", moscow)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 11, 1, 20, 59, 59, 0, time.UTC), due.UTC())
+
+	// Метка времени не зависит от часового пояса пользователя
+	due, err = ParseDue("2026-11-01T09:00:00+03:00", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 11, 1, 6, 0, 0, 0, time.UTC), due.UTC())
+
+	_, err = ParseDue("01.11.2026", moscow)
+	assert.Error(t, err)
+}
@@ -0,0 +1,38 @@
+package blob
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, s.Put(ctx, "tasks/a/b", strings.NewReader("hello"), 5, "text/plain"))
+	r, err := s.Get(ctx, "tasks/a/b")
+	require.NoError(t, err)
+	data, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "hello", string(data))
+
+	// Неполная загрузка не оставляет файл под ключом
+	assert.Error(t, s.Put(ctx, "tasks/a/c", strings.NewReader("hi"), 5, "text/plain"))
+	_, err = s.Get(ctx, "tasks/a/c")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	for _, key := range []string{"../escape", "/abs", "tasks/../../x", "", "a\\b"} {
+		assert.Error(t, s.Put(ctx, key, strings.NewReader("x"), 1, ""), key)
+	}
+
+	require.NoError(t, s.Delete(ctx, "tasks/a/b"))
+	require.NoError(t, s.Delete(ctx, "tasks/a/b"))
+	_, err = s.Get(ctx, "tasks/a/b")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
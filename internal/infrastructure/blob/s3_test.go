@@ -0,0 +1,105 @@
+package blob
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"todo-list/config"
+)
+
+// fakeS3 stands in for MinIO: it keeps objects in memory and re-signs every
+// request it receives to check the Authorization header.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]string
+	signer  *S3Store
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	at, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		http.Error(w, "missing date", http.StatusForbidden)
+		return
+	}
+	check, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.EscapedPath(), nil)
+	f.signer.sign(check, r.Header.Get("X-Amz-Content-Sha256"), at)
+	if check.Header.Get("Authorization") != r.Header.Get("Authorization") {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = string(body)
+	case http.MethodGet:
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		_, _ = io.WriteString(w, body)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.S3Config{Region: "us-east-1", Bucket: "files", AccessKey: "minio", SecretKey: "minio-secret", PathStyle: true}
+	fake := &fakeS3{objects: map[string]string{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	cfg.Endpoint = srv.URL
+	s, err := NewS3Store(&cfg)
+	require.NoError(t, err)
+	fake.signer = s
+
+	require.NoError(t, s.Put(ctx, "tasks/a/b", strings.NewReader("hello"), 5, "text/plain"))
+	assert.Equal(t, "hello", fake.objects["/files/tasks/a/b"])
+
+	r, err := s.Get(ctx, "tasks/a/b")
+	require.NoError(t, err)
+	data, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "hello", string(data))
+
+	_, err = s.Get(ctx, "tasks/a/missing")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	require.NoError(t, s.Delete(ctx, "tasks/a/b"))
+	assert.Empty(t, fake.objects)
+
+	// Неверный секретный ключ даёт ошибку подписи
+	other := cfg
+	other.SecretKey = "wrong"
+	bad, _ := NewS3Store(&other)
+	err = bad.Put(ctx, "tasks/a/b", strings.NewReader("x"), 1, "")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "SignatureDoesNotMatch")
+	}
+
+	_, err = NewS3Store(&config.S3Config{Endpoint: "localhost:9000", Bucket: "files"})
+	assert.Error(t, err)
+}
+
+func TestS3Store_ObjectURL(t *testing.T) {
+	s, err := NewS3Store(&config.S3Config{Endpoint: "https://s3.example.com", Bucket: "files"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://files.s3.example.com/tasks/a%20b/c", s.objectURL("tasks/a b/c").String())
+
+	s.cfg.PathStyle = true
+	assert.Equal(t, "https://s3.example.com/files/tasks/a%20b/c", s.objectURL("tasks/a b/c").String())
+}
@@ -0,0 +1,164 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"todo-list/internal/domain/event"
+)
+
+// eventStreamTTL drops the history of users that have been idle for a day.
+const eventStreamTTL = 24 * time.Hour
+
+// EventStream keeps a capped Redis stream of events per user for resuming
+// and fans new events out to every instance over pub/sub.
+type EventStream struct {
+	client  *redis.Client
+	history int64
+}
+
+func NewEventStream(client *redis.Client, history int) *EventStream {
+	return &EventStream{client: client, history: int64(history)}
+}
+
+func eventStreamKey(userID uuid.UUID) string {
+	return fmt.Sprintf("task_events_%s", userID)
+}
+
+func eventChannelKey(userID uuid.UUID) string {
+	return fmt.Sprintf("task_events_live_%s", userID)
+}
+
+// liveMessage is what goes over pub/sub: the stream entry ID and the event.
+type liveMessage struct {
+	ID    string          `json:"id"`
+	Event json.RawMessage `json:"event"`
+}
+
+func (s *EventStream) Publish(ctx context.Context, e event.Event) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("[ERROR] event stream: encode %s: %v", e.Type, err)
+		return
+	}
+	key := eventStreamKey(e.UserID)
+	id, err := s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: s.history,
+		Approx: true,
+		Values: map[string]interface{}{"event": payload},
+	}).Result()
+	if err != nil {
+		log.Printf("[ERROR] event stream: append %s: %v", e.Type, err)
+		return
+	}
+	s.client.Expire(ctx, key, eventStreamTTL)
+	live, _ := json.Marshal(liveMessage{ID: id, Event: payload})
+	if err := s.client.Publish(ctx, eventChannelKey(e.UserID), live).Err(); err != nil {
+		log.Printf("[ERROR] event stream: publish %s: %v", e.Type, err)
+	}
+}
+
+// Subscribe listens on the channel before reading the history, so nothing
+// published in between is lost; messages seen in both are skipped by ID.
+func (s *EventStream) Subscribe(ctx context.Context, userID uuid.UUID, lastID string) (<-chan event.Message, error) {
+	sub := s.client.Subscribe(ctx, eventChannelKey(userID))
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, err
+	}
+	var replay []event.Message
+	if _, ok := parseStreamID(lastID); ok {
+		entries, err := s.client.XRange(ctx, eventStreamKey(userID), "("+lastID, "+").Result()
+		if err != nil {
+			sub.Close()
+			return nil, err
+		}
+		for _, entry := range entries {
+			raw, _ := entry.Values["event"].(string)
+			if msg, ok := decodeMessage(entry.ID, []byte(raw), userID); ok {
+				replay = append(replay, msg)
+			}
+		}
+	} else {
+		lastID = ""
+	}
+
+	out := make(chan event.Message)
+	go func() {
+		defer close(out)
+		defer sub.Close()
+		send := func(msg event.Message) bool {
+			select {
+			case out <- msg:
+				lastID = msg.ID
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for _, msg := range replay {
+			if !send(msg) {
+				return
+			}
+		}
+		live := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case m, ok := <-live:
+				if !ok {
+					return
+				}
+				var lm liveMessage
+				if err := json.Unmarshal([]byte(m.Payload), &lm); err != nil {
+					continue
+				}
+				if lastID != "" && !streamIDAfter(lm.ID, lastID) {
+					continue
+				}
+				msg, ok := decodeMessage(lm.ID, lm.Event, userID)
+				if ok && !send(msg) {
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+func decodeMessage(id string, raw []byte, userID uuid.UUID) (event.Message, bool) {
+	var e event.Event
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return event.Message{}, false
+	}
+	e.UserID = userID
+	return event.Message{ID: id, Event: e}, true
+}
+
+// parseStreamID splits a Redis stream ID "<ms>-<seq>".
+func parseStreamID(id string) ([2]uint64, bool) {
+	ms, seq, found := strings.Cut(id, "-")
+	if !found {
+		return [2]uint64{}, false
+	}
+	a, err1 := strconv.ParseUint(ms, 10, 64)
+	b, err2 := strconv.ParseUint(seq, 10, 64)
+	return [2]uint64{a, b}, err1 == nil && err2 == nil
+}
+
+func streamIDAfter(id, last string) bool {
+	a, ok1 := parseStreamID(id)
+	b, ok2 := parseStreamID(last)
+	if !ok1 || !ok2 {
+		return true
+	}
+	return a[0] > b[0] || (a[0] == b[0] && a[1] > b[1])
+}
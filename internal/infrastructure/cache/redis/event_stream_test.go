@@ -0,0 +1,44 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"todo-list/internal/domain/event"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventStream_Publish(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	s := NewEventStream(db, 100)
+	uid := uuid.New()
+	e := event.New(event.TaskCreated, uid, map[string]interface{}{"task_id": "t1"})
+	payload, _ := json.Marshal(e)
+	live, _ := json.Marshal(liveMessage{ID: "1-0", Event: payload})
+
+	mock.ExpectXAdd(&redis.XAddArgs{
+		Stream: eventStreamKey(uid),
+		MaxLen: 100,
+		Approx: true,
+		Values: map[string]interface{}{"event": payload},
+	}).SetVal("1-0")
+	mock.ExpectExpire(eventStreamKey(uid), eventStreamTTL).SetVal(true)
+	mock.ExpectPublish(eventChannelKey(uid), live).SetVal(1)
+
+	s.Publish(context.Background(), e)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStreamIDAfter(t *testing.T) {
+	assert.True(t, streamIDAfter("1700000000001-0", "1700000000000-5"))
+	assert.True(t, streamIDAfter("1700000000000-6", "1700000000000-5"))
+	assert.False(t, streamIDAfter("1700000000000-5", "1700000000000-5"))
+	assert.False(t, streamIDAfter("1699999999999-9", "1700000000000-0"))
+
+	_, ok := parseStreamID("42")
+	assert.False(t, ok)
+}
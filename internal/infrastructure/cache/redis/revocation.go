@@ -0,0 +1,38 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// RevocationList keeps revoked session IDs in Redis for as long as an access
+// token issued for them could still be valid.
+type RevocationList struct {
+	client *redis.Client
+}
+
+func NewRevocationList(client *redis.Client) *RevocationList {
+	return &RevocationList{client: client}
+}
+
+func revocationKey(sessionID string) string {
+	return fmt.Sprintf("revoked_session_%s", sessionID)
+}
+
+func (r *RevocationList) Revoke(ctx context.Context, sessionID string, ttl time.Duration) error {
+	return r.client.Set(ctx, revocationKey(sessionID), 1, ttl).Err()
+}
+
+func (r *RevocationList) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	err := r.client.Get(ctx, revocationKey(sessionID)).Err()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -40,7 +40,13 @@ func createConnection(cfg *config.DatabaseConfig) (*PostgresDB, error) {
 		return nil, err
 	}
 	// automigrate
-	if err := db.AutoMigrate(&model.Task{}, &model.Tag{}); err != nil {
+	if err := migrateTagOwnership(db); err != nil {
+		return nil, err
+	}
+	if err := db.AutoMigrate(&model.User{}, &model.Workspace{}, &model.WorkspaceMember{}, &model.WorkspaceInvitation{}, &model.Project{}, &model.Task{}, &model.Tag{}, &model.TaskAssignee{}, &model.TaskWatcher{}, &model.Comment{}, &model.CommentMention{}, &model.Attachment{}, &model.TaskRevision{}, &model.TaskDependency{}, &model.Reminder{}, &model.WebhookSubscription{}, &model.WebhookDelivery{}, &model.SavedSearch{}, &model.Session{}, &model.RefreshToken{}); err != nil {
+		return nil, err
+	}
+	if err := MigrateSearch(db); err != nil {
 		return nil, err
 	}
 	return &PostgresDB{db: db}, nil
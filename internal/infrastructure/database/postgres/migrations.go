@@ -0,0 +1,117 @@
+package postgres
+
+import "gorm.io/gorm"
+
+// migrateTagOwnership turns the legacy global tags into per-user tags before
+// AutoMigrate adds the (user_id, name) unique index. Every legacy tag is copied
+// once for each user whose tasks use it, task_tags rows are re-pointed at the
+// copies, and the legacy rows are removed. It is a no-op once user_id is set.
+func migrateTagOwnership(db *gorm.DB) error {
+	if !db.Migrator().HasTable("tags") || !db.Migrator().HasTable("task_tags") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		stmts := []string{
+			`ALTER TABLE tags ADD COLUMN IF NOT EXISTS user_id uuid`,
+			`ALTER TABLE tags ADD COLUMN IF NOT EXISTS color varchar(7)`,
+			`DROP INDEX IF EXISTS idx_tags_name`,
+			`INSERT INTO tags (user_id, name)
+				SELECT DISTINCT tasks.user_id, legacy.name
+				FROM task_tags
+				JOIN tags legacy ON legacy.id = task_tags.tag_id
+				JOIN tasks ON tasks.id = task_tags.task_id
+				WHERE legacy.user_id IS NULL
+				AND NOT EXISTS (SELECT 1 FROM tags owned WHERE owned.user_id = tasks.user_id AND owned.name = legacy.name)`,
+			`INSERT INTO task_tags (task_id, tag_id)
+				SELECT task_tags.task_id, owned.id
+				FROM task_tags
+				JOIN tags legacy ON legacy.id = task_tags.tag_id
+				JOIN tasks ON tasks.id = task_tags.task_id
+				JOIN tags owned ON owned.user_id = tasks.user_id AND owned.name = legacy.name
+				WHERE legacy.user_id IS NULL
+				ON CONFLICT DO NOTHING`,
+			`DELETE FROM task_tags WHERE tag_id IN (SELECT id FROM tags WHERE user_id IS NULL)`,
+			`DELETE FROM tags WHERE user_id IS NULL`,
+			`ALTER TABLE tags ALTER COLUMN user_id SET NOT NULL`,
+		}
+		for _, stmt := range stmts {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// MigrateSearch maintains tasks.search_vector, the full-text document task
+// search runs against. Titles weigh most, then tag names, content and comment
+// bodies. The russian configuration stems Cyrillic words as Russian and Latin
+// words as English, so one document serves both; ё is folded into е first.
+// Triggers keep the document current as tasks, tags and comments change, and
+// rows that predate the column are filled in once.
+func MigrateSearch(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		stmts := []string{
+			`ALTER TABLE tasks ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+			`CREATE OR REPLACE FUNCTION task_search_text(doc text) RETURNS tsvector AS $$
+				SELECT to_tsvector('russian', translate(coalesce(doc, ''), 'ёЁ', 'еЕ'))
+			$$ LANGUAGE sql IMMUTABLE`,
+			// Long discussions are cut short so the document stays well
+			// below the tsvector size limit.
+			`CREATE OR REPLACE FUNCTION task_search_document(p_task uuid, p_title text, p_content text) RETURNS tsvector AS $$
+				SELECT setweight(task_search_text(p_title), 'A')
+					|| setweight(task_search_text((SELECT string_agg(tags.name, ' ')
+						FROM task_tags JOIN tags ON tags.id = task_tags.tag_id
+						WHERE task_tags.task_id = p_task)), 'B')
+					|| setweight(task_search_text(p_content), 'C')
+					|| setweight(task_search_text(left((SELECT string_agg(comments.body, ' ')
+						FROM comments
+						WHERE comments.task_id = p_task AND comments.deleted_at IS NULL), 100000)), 'D')
+			$$ LANGUAGE sql STABLE`,
+			`CREATE OR REPLACE FUNCTION tasks_search_update() RETURNS trigger AS $$
+			BEGIN
+				NEW.search_vector := task_search_document(NEW.id, NEW.title, NEW.content);
+				RETURN NEW;
+			END
+			$$ LANGUAGE plpgsql`,
+			`CREATE OR REPLACE FUNCTION task_children_search_update() RETURNS trigger AS $$
+			BEGIN
+				IF TG_OP <> 'INSERT' THEN
+					UPDATE tasks SET search_vector = task_search_document(id, title, content) WHERE id = OLD.task_id;
+				END IF;
+				IF TG_OP <> 'DELETE' THEN
+					UPDATE tasks SET search_vector = task_search_document(id, title, content) WHERE id = NEW.task_id;
+				END IF;
+				RETURN NULL;
+			END
+			$$ LANGUAGE plpgsql`,
+			`CREATE OR REPLACE FUNCTION tags_search_update() RETURNS trigger AS $$
+			BEGIN
+				UPDATE tasks SET search_vector = task_search_document(id, title, content)
+				WHERE id IN (SELECT task_id FROM task_tags WHERE tag_id = NEW.id);
+				RETURN NULL;
+			END
+			$$ LANGUAGE plpgsql`,
+			`DROP TRIGGER IF EXISTS tasks_search ON tasks`,
+			`CREATE TRIGGER tasks_search BEFORE INSERT OR UPDATE OF title, content ON tasks
+				FOR EACH ROW EXECUTE FUNCTION tasks_search_update()`,
+			`DROP TRIGGER IF EXISTS task_tags_search ON task_tags`,
+			`CREATE TRIGGER task_tags_search AFTER INSERT OR DELETE ON task_tags
+				FOR EACH ROW EXECUTE FUNCTION task_children_search_update()`,
+			`DROP TRIGGER IF EXISTS comments_search ON comments`,
+			`CREATE TRIGGER comments_search AFTER INSERT OR UPDATE OF body, deleted_at OR DELETE ON comments
+				FOR EACH ROW EXECUTE FUNCTION task_children_search_update()`,
+			`DROP TRIGGER IF EXISTS tags_search ON tags`,
+			`CREATE TRIGGER tags_search AFTER UPDATE OF name ON tags
+				FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name) EXECUTE FUNCTION tags_search_update()`,
+			`UPDATE tasks SET search_vector = task_search_document(id, title, content) WHERE search_vector IS NULL`,
+			`CREATE INDEX IF NOT EXISTS idx_tasks_search ON tasks USING GIN (search_vector)`,
+		}
+		for _, stmt := range stmts {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,26 @@
+package notify
+
+import (
+	"net"
+	"net/http"
+	"time"
+	"todo-list/internal/domain/netguard"
+)
+
+// newPublicClient returns the client webhooks are posted with. It only dials
+// public addresses and does not follow redirects, so a subscriber cannot make
+// the server reach into its own network.
+func newPublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: netguard.Control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed instead of the target and defeat the guard.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
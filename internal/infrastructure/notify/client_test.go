@@ -0,0 +1,30 @@
+package notify
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-list/internal/domain/netguard"
+)
+
+func TestPublicClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	// Локальный адрес отклоняется ещё при подключении
+	_, err := newPublicClient(time.Second).Get(srv.URL)
+	assert.ErrorIs(t, err, netguard.ErrNotPublic)
+
+	// Перенаправления не выполняются
+	c := newPublicClient(time.Second)
+	c.Transport = srv.Client().Transport
+	resp, err := c.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+}
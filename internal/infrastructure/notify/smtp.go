@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+	"todo-list/config"
+	"todo-list/internal/domain/model"
+)
+
+type SMTPNotifier struct {
+	host     string
+	addr     string
+	from     string
+	username string
+	password string
+}
+
+func NewSMTPNotifier(cfg *config.SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{
+		host:     cfg.Host,
+		addr:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		from:     cfg.From,
+		username: cfg.Username,
+		password: cfg.Password,
+	}
+}
+
+// Notify emails a due reminder to msg.Target.
+func (n *SMTPNotifier) Notify(ctx context.Context, msg model.Notification) error {
+	return n.send(ctx, msg.Target, n.message(msg))
+}
+
+// SendInvitation emails a workspace invitation link.
+func (n *SMTPNotifier) SendInvitation(ctx context.Context, mail model.InvitationMail) error {
+	return n.send(ctx, mail.To, n.invitation(mail))
+}
+
+// send delivers a prepared message. Unlike smtp.SendMail it honours the
+// context deadline, and it upgrades to TLS whenever the server offers STARTTLS.
+func (n *SMTPNotifier) send(ctx context.Context, to string, body []byte) error {
+	if to == "" {
+		return errors.New("no recipient address")
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+			return err
+		}
+	}
+	if n.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", n.username, n.password, n.host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(n.from); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func (n *SMTPNotifier) header(b *strings.Builder, to, subject string) {
+	fmt.Fprintf(b, "From: %s\r\n", n.from)
+	fmt.Fprintf(b, "To: %s\r\n", to)
+	fmt.Fprintf(b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+}
+
+func (n *SMTPNotifier) message(msg model.Notification) []byte {
+	var b strings.Builder
+	n.header(&b, msg.Target, "Reminder: "+oneLine(msg.Task.Title))
+	fmt.Fprintf(&b, "%s\r\n", oneLine(msg.Task.Title))
+	if msg.Task.DueDate != nil {
+		fmt.Fprintf(&b, "Due: %s\r\n", msg.Task.DueDate.Format(time.RFC1123))
+	}
+	if msg.Task.Content != "" {
+		b.WriteString("\r\n")
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Task.Content, "\r\n", "\n"), "\n", "\r\n"))
+		b.WriteString("\r\n")
+	}
+	return []byte(b.String())
+}
+
+func (n *SMTPNotifier) invitation(mail model.InvitationMail) []byte {
+	var b strings.Builder
+	n.header(&b, mail.To, "Invitation to "+oneLine(mail.Workspace))
+	fmt.Fprintf(&b, "You have been invited to join %s as %s.\r\n\r\n", oneLine(mail.Workspace), mail.Role)
+	fmt.Fprintf(&b, "%s\r\n\r\n", oneLine(mail.Link))
+	fmt.Fprintf(&b, "The invitation expires %s.\r\n", mail.ExpiresAt.Format(time.RFC1123))
+	return []byte(b.String())
+}
+
+// oneLine keeps user input from injecting extra headers.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
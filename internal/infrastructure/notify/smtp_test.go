@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"todo-list/config"
+	"todo-list/internal/domain/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMail struct {
+	from string
+	to   []string
+	data string
+}
+
+// startFakeSMTP accepts one session and reports the received message.
+func startFakeSMTP(t *testing.T) (string, int, <-chan fakeMail) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	out := make(chan fakeMail, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		var m fakeMail
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.TrimRight(line, "\r\n")
+			switch upper := strings.ToUpper(cmd); {
+			case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+				reply("250 fake")
+			case strings.HasPrefix(upper, "MAIL FROM:"):
+				m.from = strings.Trim(cmd[len("MAIL FROM:"):], "<>")
+				reply("250 OK")
+			case strings.HasPrefix(upper, "RCPT TO:"):
+				m.to = append(m.to, strings.Trim(cmd[len("RCPT TO:"):], "<>"))
+				reply("250 OK")
+			case upper == "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				m.data = data.String()
+				reply("250 queued")
+			case upper == "QUIT":
+				reply("221 bye")
+				out <- m
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return host, p, out
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	host, port, received := startFakeSMTP(t)
+	n := NewSMTPNotifier(&config.SMTPConfig{Host: host, Port: port, From: "todo@example.com"})
+
+	due := time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := n.Notify(ctx, model.Notification{
+		ReminderID: uuid.New(),
+		Channel:    model.ReminderChannelEmail,
+		Target:     "user@example.com",
+		Task:       model.Task{Title: "Отчёт\r\nBcc: evil@example.com", Content: "line one\nline two", DueDate: &due},
+	})
+	require.NoError(t, err)
+
+	select {
+	case m := <-received:
+		assert.Equal(t, "todo@example.com", m.from)
+		assert.Equal(t, []string{"user@example.com"}, m.to)
+		assert.Contains(t, m.data, "Subject: =?utf-8?q?")
+		assert.NotContains(t, m.data, "\r\nBcc:")
+		assert.Contains(t, m.data, "line one\r\nline two")
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake SMTP server received nothing")
+	}
+}
+
+func TestSMTPNotifier_SendInvitation(t *testing.T) {
+	host, port, received := startFakeSMTP(t)
+	n := NewSMTPNotifier(&config.SMTPConfig{Host: host, Port: port, From: "todo@example.com"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := n.SendInvitation(ctx, model.InvitationMail{
+		To:        "bob@example.com",
+		Workspace: "Team\r\nBcc: evil@example.com",
+		Role:      model.RoleEditor,
+		Link:      "https://app.example.com/invite?token=abc.def",
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	select {
+	case m := <-received:
+		assert.Equal(t, []string{"bob@example.com"}, m.to)
+		assert.NotContains(t, m.data, "\r\nBcc:")
+		assert.Contains(t, m.data, "https://app.example.com/invite?token=abc.def")
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake SMTP server received nothing")
+	}
+}
@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"todo-list/internal/domain/model"
+)
+
+type WebhookNotifier struct {
+	client *http.Client
+}
+
+func NewWebhookNotifier(timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{client: newPublicClient(timeout)}
+}
+
+type webhookPayload struct {
+	Type       string      `json:"type"`
+	ReminderID string      `json:"reminder_id"`
+	RemindAt   time.Time   `json:"remind_at"`
+	Task       webhookTask `json:"task"`
+}
+
+type webhookTask struct {
+	ID       string     `json:"id"`
+	Title    string     `json:"title"`
+	Content  string     `json:"content"`
+	Status   string     `json:"status"`
+	Priority string     `json:"priority"`
+	DueDate  *time.Time `json:"due_date,omitempty"`
+}
+
+// Notify POSTs the reminder as JSON; any non-2xx response is a failure.
+func (n *WebhookNotifier) Notify(ctx context.Context, msg model.Notification) error {
+	body, err := json.Marshal(webhookPayload{
+		Type:       "task.reminder",
+		ReminderID: msg.ReminderID.String(),
+		RemindAt:   msg.FireAt,
+		Task: webhookTask{
+			ID:       msg.Task.ID.String(),
+			Title:    msg.Task.Title,
+			Content:  msg.Task.Content,
+			Status:   msg.Task.Status,
+			Priority: msg.Task.Priority,
+			DueDate:  msg.Task.DueDate,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.Target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-list-reminders")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-list/internal/domain/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	sig := Sign("whsec_test", at, []byte(`{"a":1}`))
+	assert.Equal(t, "t=1700000000,v1=38877139021993b830af32feea6e18a8da83eb2f6e49ee50bd9e4cf4ca4d3789", sig)
+}
+
+func TestWebhookSender(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	d := model.WebhookDelivery{ID: uuid.New(), EventType: "task.created", Payload: `{"type":"task.created"}`}
+
+	var status int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, d.Payload, string(body))
+		assert.Equal(t, "task.created", r.Header.Get(EventHeader))
+		assert.Equal(t, d.ID.String(), r.Header.Get(DeliveryHeader))
+		assert.Equal(t, Sign("secret", at, body), r.Header.Get(SignatureHeader))
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	s := NewWebhookSender(time.Second)
+	s.now = func() time.Time { return at }
+	// Тестовый сервер слушает loopback, который настоящий клиент не пропускает
+	s.client = srv.Client()
+
+	status = http.StatusAccepted
+	code, err := s.Send(context.Background(), srv.URL, "secret", d)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, code)
+
+	status = http.StatusInternalServerError
+	code, err = s.Send(context.Background(), srv.URL, "secret", d)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusInternalServerError, code)
+}
@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-list/internal/domain/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var got webhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		if got.Task.Title == "fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	n := NewWebhookNotifier(time.Second)
+	// Тестовый сервер слушает loopback, который настоящий клиент не пропускает
+	n.client = srv.Client()
+	msg := model.Notification{ReminderID: uuid.New(), Target: srv.URL, Task: model.Task{ID: uuid.New(), Title: "Pay invoice"}}
+	require.NoError(t, n.Notify(context.Background(), msg))
+	assert.Equal(t, "task.reminder", got.Type)
+	assert.Equal(t, "Pay invoice", got.Task.Title)
+
+	msg.Task.Title = "fail"
+	assert.Error(t, n.Notify(context.Background(), msg))
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"todo-list/internal/domain/model"
+	drepo "todo-list/internal/domain/repository"
+)
+
+func (r *taskRepositoryImpl) AddAssignee(ctx context.Context, id, assigneeID string, userID string) (model.Task, error) {
+	assignee, err := uuid.Parse(assigneeID)
+	if err != nil {
+		return model.Task{}, drepo.ErrAssigneeAccess
+	}
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var task model.Task
+		if err := tx.Where("tasks.id = ?", id).Scopes(visibleTo(userID)).First(&task).Error; err != nil {
+			return err
+		}
+		var visible int64
+		err := tx.Model(&model.Task{}).Where("tasks.id = ?", id).Scopes(visibleTo(assigneeID)).Count(&visible).Error
+		if err != nil {
+			return err
+		}
+		if visible == 0 {
+			return drepo.ErrAssigneeAccess
+		}
+		by, _ := uuid.Parse(userID)
+		a := model.TaskAssignee{TaskID: task.ID, UserID: assignee, AssignedBy: by}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&a)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return bumpVersion(tx, task.ID)
+	})
+	if err != nil {
+		return model.Task{}, err
+	}
+	return r.GetByID(ctx, id, userID)
+}
+
+func (r *taskRepositoryImpl) RemoveAssignee(ctx context.Context, id, assigneeID string, userID string) (model.Task, error) {
+	var task model.Task
+	if err := r.db.WithContext(ctx).Where("tasks.id = ?", id).Scopes(visibleTo(userID)).First(&task).Error; err != nil {
+		return model.Task{}, err
+	}
+	if _, err := uuid.Parse(assigneeID); err == nil {
+		if err := r.removeRelation(ctx, task.ID, assigneeID, &model.TaskAssignee{}); err != nil {
+			return model.Task{}, err
+		}
+	}
+	return r.GetByID(ctx, id, userID)
+}
+
+func (r *taskRepositoryImpl) AddWatcher(ctx context.Context, id string, userID string) (model.Task, error) {
+	var task model.Task
+	if err := r.db.WithContext(ctx).Where("tasks.id = ?", id).Scopes(visibleTo(userID)).First(&task).Error; err != nil {
+		return model.Task{}, err
+	}
+	uID, _ := uuid.Parse(userID)
+	w := model.TaskWatcher{TaskID: task.ID, UserID: uID}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&w)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return bumpVersion(tx, task.ID)
+	})
+	if err != nil {
+		return model.Task{}, err
+	}
+	return r.GetByID(ctx, id, userID)
+}
+
+func (r *taskRepositoryImpl) RemoveWatcher(ctx context.Context, id string, userID string) (model.Task, error) {
+	var task model.Task
+	if err := r.db.WithContext(ctx).Where("tasks.id = ?", id).Scopes(visibleTo(userID)).First(&task).Error; err != nil {
+		return model.Task{}, err
+	}
+	if err := r.removeRelation(ctx, task.ID, userID, &model.TaskWatcher{}); err != nil {
+		return model.Task{}, err
+	}
+	return r.GetByID(ctx, id, userID)
+}
+
+// removeRelation deletes the user's assignee or watcher row and, when there
+// was one, moves the task to a new version.
+func (r *taskRepositoryImpl) removeRelation(ctx context.Context, taskID uuid.UUID, userID string, row interface{}) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("task_id = ? AND user_id = ?", taskID, userID).Delete(row)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return bumpVersion(tx, taskID)
+	})
+}
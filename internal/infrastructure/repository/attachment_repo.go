@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"todo-list/internal/domain/model"
+	drepo "todo-list/internal/domain/repository"
+)
+
+type attachmentRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) drepo.AttachmentRepository {
+	return &attachmentRepositoryImpl{db: db}
+}
+
+func (r *attachmentRepositoryImpl) Create(ctx context.Context, a *model.Attachment, fits func(used int64) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "attachments:"+a.UploadedBy.String()).Error
+		if err != nil {
+			return err
+		}
+		var used int64
+		err = tx.Model(&model.Attachment{}).
+			Where("uploaded_by = ?", a.UploadedBy).
+			Select("COALESCE(SUM(size), 0)").
+			Scan(&used).Error
+		if err != nil {
+			return err
+		}
+		if err := fits(used); err != nil {
+			return err
+		}
+		return tx.Create(a).Error
+	})
+}
+
+func (r *attachmentRepositoryImpl) GetByID(ctx context.Context, id string, taskID string) (model.Attachment, error) {
+	var a model.Attachment
+	err := r.db.WithContext(ctx).Where("id = ? AND task_id = ?", id, taskID).First(&a).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return a, drepo.ErrAttachmentNotFound
+	}
+	return a, err
+}
+
+func (r *attachmentRepositoryImpl) ListByTask(ctx context.Context, taskID string) ([]model.Attachment, error) {
+	var out []model.Attachment
+	err := r.db.WithContext(ctx).Where("task_id = ?", taskID).Order("created_at, id").Find(&out).Error
+	return out, err
+}
+
+func (r *attachmentRepositoryImpl) Delete(ctx context.Context, id string, taskID string) error {
+	res := r.db.WithContext(ctx).Where("id = ? AND task_id = ?", id, taskID).Delete(&model.Attachment{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return drepo.ErrAttachmentNotFound
+	}
+	return nil
+}
+
+func (r *attachmentRepositoryImpl) UsageByUser(ctx context.Context, userID string) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&model.Attachment{}).
+		Where("uploaded_by = ?", userID).
+		Select("COALESCE(SUM(size), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *attachmentRepositoryImpl) Orphans(ctx context.Context, limit int) ([]model.Attachment, error) {
+	var out []model.Attachment
+	err := r.db.WithContext(ctx).
+		Where("NOT EXISTS (SELECT 1 FROM tasks WHERE tasks.id = attachments.task_id)").
+		Order("created_at").
+		Limit(limit).
+		Find(&out).Error
+	return out, err
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"todo-list/internal/domain/model"
+	drepo "todo-list/internal/domain/repository"
+)
+
+type commentRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) drepo.CommentRepository {
+	return &commentRepositoryImpl{db: db}
+}
+
+// Comments show in their task's comment count, so every write moves the task
+// to a new version.
+func (r *commentRepositoryImpl) Create(ctx context.Context, comment *model.Comment) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		return bumpVersion(tx, comment.TaskID)
+	})
+}
+
+func (r *commentRepositoryImpl) GetByID(ctx context.Context, id string, taskID string) (model.Comment, error) {
+	var c model.Comment
+	err := r.db.WithContext(ctx).Preload("Mentions").Where("id = ? AND task_id = ?", id, taskID).First(&c).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c, drepo.ErrCommentNotFound
+	}
+	return c, err
+}
+
+func (r *commentRepositoryImpl) ListByTask(ctx context.Context, taskID string) ([]model.Comment, error) {
+	var out []model.Comment
+	err := r.db.WithContext(ctx).Preload("Mentions").
+		Where("task_id = ?", taskID).
+		Order("created_at, id").
+		Find(&out).Error
+	return out, err
+}
+
+func (r *commentRepositoryImpl) Update(ctx context.Context, comment *model.Comment) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(comment).Updates(map[string]interface{}{"body": comment.Body, "edited_at": comment.EditedAt}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("comment_id = ?", comment.ID).Delete(&model.CommentMention{}).Error; err != nil {
+			return err
+		}
+		if len(comment.Mentions) > 0 {
+			if err := tx.Create(&comment.Mentions).Error; err != nil {
+				return err
+			}
+		}
+		return bumpVersion(tx, comment.TaskID)
+	})
+}
+
+func (r *commentRepositoryImpl) Delete(ctx context.Context, id string, taskID string) error {
+	task, err := uuid.Parse(taskID)
+	if err != nil {
+		return drepo.ErrCommentNotFound
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND task_id = ?", id, task).Delete(&model.Comment{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return drepo.ErrCommentNotFound
+		}
+		return bumpVersion(tx, task)
+	})
+}
+
+func (r *commentRepositoryImpl) MentionCandidates(ctx context.Context, task model.Task) ([]drepo.MentionCandidate, error) {
+	q := r.db.WithContext(ctx).Model(&model.User{}).Select("users.id AS user_id, users.email")
+	if task.WorkspaceID != nil {
+		q = q.Where("users.id IN (SELECT user_id FROM workspace_members WHERE workspace_id = ?)", *task.WorkspaceID)
+	} else {
+		q = q.Where("users.id = ?", task.UserID)
+	}
+	var out []drepo.MentionCandidate
+	err := q.Order("users.email").Scan(&out).Error
+	return out, err
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+	"todo-list/internal/domain/model"
+	drepo "todo-list/internal/domain/repository"
+)
+
+const openBlockerCond = `EXISTS (SELECT 1 FROM task_dependencies d JOIN tasks b ON b.id = d.blocker_id
+	WHERE d.blocked_id = tasks.id AND b.deleted_at IS NULL AND b.status <> 'done')`
+
+// syncBlocked moves the given tasks to "blocked" while any of their blockers is
+// open and back to "todo" once all of them are done. Tasks without dependency
+// links are left alone so a manually blocked task stays blocked.
+func syncBlocked(tx *gorm.DB, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	now := time.Now()
+	err := tx.Model(&model.Task{}).
+		Where("id IN ? AND status IN ?", ids, []string{"todo", "in_progress"}).
+		Where(openBlockerCond).
+		Updates(map[string]interface{}{"status": "blocked", "updated_at": now, "version": gorm.Expr("version + 1")}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&model.Task{}).
+		Where("id IN ? AND status = ?", ids, "blocked").
+		Where("EXISTS (SELECT 1 FROM task_dependencies d WHERE d.blocked_id = tasks.id)").
+		Where("NOT " + openBlockerCond).
+		Updates(map[string]interface{}{"status": "todo", "updated_at": now, "version": gorm.Expr("version + 1")}).Error
+}
+
+// refreshDependents re-evaluates every task blocked by one of blockerIDs.
+// Links never cross workspaces, so dependents share the blockers' visibility.
+func refreshDependents(tx *gorm.DB, blockerIDs []uuid.UUID) error {
+	if len(blockerIDs) == 0 {
+		return nil
+	}
+	var ids []uuid.UUID
+	err := tx.Model(&model.TaskDependency{}).
+		Where("blocker_id IN ?", blockerIDs).
+		Pluck("blocked_id", &ids).Error
+	if err != nil {
+		return err
+	}
+	return syncBlocked(tx, ids)
+}
+
+func (r *taskRepositoryImpl) AddDependency(ctx context.Context, blockerID, blockedID string, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockHierarchy(tx, []string{blockerID, blockedID}, userID); err != nil {
+			return err
+		}
+		var blocker, blocked model.Task
+		if err := tx.Where("tasks.id = ?", blockerID).Scopes(visibleTo(userID)).First(&blocker).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tasks.id = ?", blockedID).Scopes(visibleTo(userID)).First(&blocked).Error; err != nil {
+			return err
+		}
+		if blocker.ID == blocked.ID {
+			return drepo.ErrDependencyCycle
+		}
+		if !sameWorkspace(blocker, blocked) {
+			return drepo.ErrCrossWorkspace
+		}
+		// Linking would close a cycle if the blocker already (transitively) waits on blocked.
+		var cyclic bool
+		err := tx.Raw(`WITH RECURSIVE downstream AS (
+				SELECT blocked_id AS id FROM task_dependencies WHERE blocker_id = ?
+				UNION
+				SELECT d.blocked_id FROM task_dependencies d JOIN downstream s ON d.blocker_id = s.id
+			)
+			SELECT EXISTS (SELECT 1 FROM downstream WHERE id = ?)`, blocked.ID, blocker.ID).Scan(&cyclic).Error
+		if err != nil {
+			return err
+		}
+		if cyclic {
+			return drepo.ErrDependencyCycle
+		}
+		dep := model.TaskDependency{BlockerID: blocker.ID, BlockedID: blocked.ID, UserID: blocked.UserID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dep).Error; err != nil {
+			return err
+		}
+		return recordChanges(tx, []uuid.UUID{blocked.ID}, userID, func() error {
+			return syncBlocked(tx, []uuid.UUID{blocked.ID})
+		})
+	})
+}
+
+func (r *taskRepositoryImpl) RemoveDependency(ctx context.Context, blockerID, blockedID string, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var blocked model.Task
+		if err := tx.Where("tasks.id = ?", blockedID).Scopes(visibleTo(userID)).First(&blocked).Error; err != nil {
+			return err
+		}
+		res := tx.Where("blocker_id = ? AND blocked_id = ?", blockerID, blocked.ID).
+			Delete(&model.TaskDependency{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		// The removed link may have been the last one, so unblock without
+		// requiring remaining links.
+		return recordChanges(tx, []uuid.UUID{blocked.ID}, userID, func() error {
+			return tx.Model(&model.Task{}).
+				Where("id = ? AND status = ?", blocked.ID, "blocked").
+				Where("NOT " + openBlockerCond).
+				Updates(map[string]interface{}{"status": "todo", "updated_at": time.Now(), "version": gorm.Expr("version + 1")}).Error
+		})
+	})
+}
+
+// GetDependencyGraph returns the task with everything it transitively waits on
+// and everything that transitively waits on it.
+func (r *taskRepositoryImpl) GetDependencyGraph(ctx context.Context, id string, userID string) (model.DependencyGraph, error) {
+	db := r.db.WithContext(ctx)
+	var root model.Task
+	if err := db.Where("tasks.id = ?", id).Scopes(visibleTo(userID)).First(&root).Error; err != nil {
+		return model.DependencyGraph{}, err
+	}
+	var ids []uuid.UUID
+	err := db.Raw(`WITH RECURSIVE up AS (
+			SELECT CAST(? AS uuid) AS id
+			UNION
+			SELECT d.blocker_id FROM task_dependencies d JOIN up ON d.blocked_id = up.id
+		), down AS (
+			SELECT CAST(? AS uuid) AS id
+			UNION
+			SELECT d.blocked_id FROM task_dependencies d JOIN down ON d.blocker_id = down.id
+		)
+		SELECT id FROM up UNION SELECT id FROM down`, root.ID, root.ID).Scan(&ids).Error
+	if err != nil {
+		return model.DependencyGraph{}, err
+	}
+	graph := model.DependencyGraph{RootID: root.ID}
+	err = db.Scopes(withAssociations).Where("tasks.id IN ?", ids).Scopes(visibleTo(userID)).Order("created_at, id").Find(&graph.Tasks).Error
+	if err != nil {
+		return model.DependencyGraph{}, err
+	}
+	live := make([]uuid.UUID, 0, len(graph.Tasks))
+	for _, t := range graph.Tasks {
+		live = append(live, t.ID)
+	}
+	err = db.Where("blocker_id IN ? AND blocked_id IN ?", live, live).
+		Order("created_at").Find(&graph.Edges).Error
+	return graph, err
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	drepo "todo-list/internal/domain/repository"
+)
+
+const taskHasTagSQL = "EXISTS (SELECT 1 FROM task_tags JOIN tags ON tags.id = task_tags.tag_id WHERE task_tags.task_id = tasks.id AND tags.name IN ?)"
+
+// applyFilter translates a TaskFilter into WHERE conditions on the tasks table.
+// Terms are left to List, which turns them into a full-text search.
+func applyFilter(q *gorm.DB, f drepo.TaskFilter) *gorm.DB {
+	if len(f.Statuses) > 0 {
+		q = q.Where("tasks.status IN ?", f.Statuses)
+	}
+	if len(f.ExcludeStatuses) > 0 {
+		q = q.Where("tasks.status NOT IN ?", f.ExcludeStatuses)
+	}
+	if len(f.Priorities) > 0 {
+		q = q.Where("tasks.priority IN ?", f.Priorities)
+	}
+	if len(f.ExcludePriorities) > 0 {
+		q = q.Where("tasks.priority NOT IN ?", f.ExcludePriorities)
+	}
+	for _, group := range f.TagGroups {
+		q = q.Where(taskHasTagSQL, group)
+	}
+	if len(f.ExcludeTags) > 0 {
+		q = q.Where("NOT "+taskHasTagSQL, f.ExcludeTags)
+	}
+	if f.DueFrom != nil {
+		q = q.Where("tasks.due_date >= ?", *f.DueFrom)
+	}
+	if f.DueBefore != nil {
+		q = q.Where("tasks.due_date < ?", *f.DueBefore)
+	}
+	if f.HasDueDate != nil {
+		if *f.HasDueDate {
+			q = q.Where("tasks.due_date IS NOT NULL")
+		} else {
+			q = q.Where("tasks.due_date IS NULL")
+		}
+	}
+	if f.Archived != nil {
+		q = q.Where("tasks.archived = ?", *f.Archived)
+	}
+	if f.ProjectID != nil {
+		q = q.Where("tasks.project_id = ?", *f.ProjectID)
+	}
+	if f.HasProject != nil {
+		if *f.HasProject {
+			q = q.Where("tasks.project_id IS NOT NULL")
+		} else {
+			q = q.Where("tasks.project_id IS NULL")
+		}
+	}
+	if f.WorkspaceID != nil {
+		q = q.Where("tasks.workspace_id = ?", *f.WorkspaceID)
+	}
+	if f.AssigneeID != nil {
+		q = q.Where("EXISTS (SELECT 1 FROM task_assignees a WHERE a.task_id = tasks.id AND a.user_id = ?)", *f.AssigneeID)
+	}
+	if f.WatcherID != nil {
+		q = q.Where("EXISTS (SELECT 1 FROM task_watchers w WHERE w.task_id = tasks.id AND w.user_id = ?)", *f.WatcherID)
+	}
+	return q
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+	"todo-list/internal/domain/model"
+	drepo "todo-list/internal/domain/repository"
+)
+
+// subtreeIDs returns the given tasks and all of their live descendants.
+// UNION (rather than UNION ALL) keeps the recursion finite even if a cycle
+// slipped into the data.
+func subtreeIDs(tx *gorm.DB, ids []string, userID string) ([]uuid.UUID, error) {
+	var out []uuid.UUID
+	err := tx.Raw(`WITH RECURSIVE subtree AS (
+			SELECT id FROM tasks WHERE id IN ? AND `+taskVisibleSQL+` AND deleted_at IS NULL
+			UNION
+			SELECT t.id FROM tasks t JOIN subtree s ON t.parent_id = s.id WHERE t.deleted_at IS NULL
+		)
+		SELECT id FROM subtree`, ids, userID, userID).Scan(&out).Error
+	return out, err
+}
+
+// lockHierarchy serializes structural changes of the task trees the given
+// tasks belong to so two concurrent moves cannot produce a cycle. A tree is
+// either one workspace or one user's personal tasks; locks are taken in a
+// fixed order to avoid deadlocks.
+func lockHierarchy(tx *gorm.DB, ids []string, userID string) error {
+	var trees []uuid.UUID
+	err := tx.Raw(`SELECT DISTINCT COALESCE(workspace_id, user_id) FROM tasks
+		WHERE id IN ? AND `+taskVisibleSQL+` ORDER BY 1`, ids, userID, userID).Scan(&trees).Error
+	if err != nil {
+		return err
+	}
+	for _, tree := range trees {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "task_tree:"+tree.String()).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *taskRepositoryImpl) GetSubtree(ctx context.Context, id string, userID string) ([]model.Task, error) {
+	db := r.db.WithContext(ctx)
+	ids, err := subtreeIDs(db, []string{id}, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	var tasks []model.Task
+	err = db.Scopes(withAssociations).Where("id IN ?", ids).Order("created_at, id").Find(&tasks).Error
+	return tasks, err
+}
+
+func (r *taskRepositoryImpl) Move(ctx context.Context, id string, parentID *string, userID string) (model.Task, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockHierarchy(tx, []string{id}, userID); err != nil {
+			return err
+		}
+		var task model.Task
+		if err := tx.Where("tasks.id = ?", id).Scopes(visibleTo(userID)).First(&task).Error; err != nil {
+			return err
+		}
+		ids, err := subtreeIDs(tx, []string{id}, userID)
+		if err != nil {
+			return err
+		}
+		var parent *model.Task
+		if parentID != nil {
+			var p model.Task
+			err := tx.Where("tasks.id = ?", *parentID).Scopes(visibleTo(userID)).First(&p).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return drepo.ErrParentNotFound
+			}
+			if err != nil {
+				return err
+			}
+			if !sameWorkspace(task, p) {
+				return drepo.ErrCrossWorkspace
+			}
+			for _, sub := range ids {
+				if sub == p.ID {
+					return drepo.ErrHierarchyCycle
+				}
+			}
+			parent = &p
+		}
+		return recordChanges(tx, ids, userID, func() error {
+			if parent == nil {
+				return tx.Model(&task).Update("parent_id", nil).Error
+			}
+			// The subtree joins the parent's project.
+			err := tx.Model(&model.Task{}).Where("id IN ?", ids).Update("project_id", parent.ProjectID).Error
+			if err != nil {
+				return err
+			}
+			return tx.Model(&task).Update("parent_id", parent.ID).Error
+		})
+	})
+	if err != nil {
+		return model.Task{}, err
+	}
+	return r.GetByID(ctx, id, userID)
+}
+
+// completeOpen moves the tasks of a subtree that are not done yet to done.
+func completeOpen(q *gorm.DB) error {
+	return q.Where("status <> ?", model.StatusDone).Updates(statusColumns(model.StatusDone, time.Now())).Error
+}
+
+// applySubtree applies fn to a query scoped to the given tasks and their
+// descendants, recording what it changed, then re-evaluates the tasks they
+// block. The caller has to hold the hierarchy lock.
+func applySubtree(tx *gorm.DB, ids []string, userID string, fn func(q *gorm.DB) error) error {
+	sub, err := subtreeIDs(tx, ids, userID)
+	if err != nil {
+		return err
+	}
+	if len(sub) == 0 {
+		return nil
+	}
+	err = recordChanges(tx, sub, userID, func() error {
+		return fn(tx.Model(&model.Task{}).Where("id IN ?", sub))
+	})
+	if err != nil {
+		return err
+	}
+	return refreshDependents(tx, sub)
+}
+
+// updateSubtree runs applySubtree under the hierarchy lock in a transaction of
+// its own.
+func (r *taskRepositoryImpl) updateSubtree(ctx context.Context, ids []string, userID string, fn func(q *gorm.DB) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockHierarchy(tx, ids, userID); err != nil {
+			return err
+		}
+		return applySubtree(tx, ids, userID, fn)
+	})
+}
+
+// sameWorkspace reports whether two tasks may be linked: both personal or both
+// in the same workspace. Personal tasks visible to one user share an owner.
+func sameWorkspace(a, b model.Task) bool {
+	if a.WorkspaceID == nil || b.WorkspaceID == nil {
+		return a.WorkspaceID == nil && b.WorkspaceID == nil
+	}
+	return *a.WorkspaceID == *b.WorkspaceID
+}
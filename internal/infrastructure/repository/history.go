@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"todo-list/internal/domain/model"
+)
+
+// recordChanges runs fn and stores a revision, attributed to userID, for every
+// task among ids whose tracked fields it changed and moves those tasks to a
+// new version. It has to run inside the transaction that makes the change so
+// that history and data never diverge.
+// Tasks that fn deletes are not recorded.
+func recordChanges(tx *gorm.DB, ids []uuid.UUID, userID string, fn func() error) error {
+	if len(ids) == 0 {
+		return fn()
+	}
+	// Concurrent writers of the same task queue up here, which also keeps
+	// revision numbers gap-free.
+	if err := tx.Exec("SELECT 1 FROM tasks WHERE id IN ? ORDER BY id FOR UPDATE", ids).Error; err != nil {
+		return err
+	}
+	before, err := taskFields(tx, ids)
+	if err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	after, err := taskFields(tx, ids)
+	if err != nil {
+		return err
+	}
+
+	actor, _ := uuid.Parse(userID)
+	var revisions []model.TaskRevision
+	for _, id := range ids {
+		from, ok := before[id]
+		to, still := after[id]
+		if !ok || !still {
+			continue
+		}
+		if changes := from.Diff(to); len(changes) > 0 {
+			revisions = append(revisions, model.TaskRevision{ID: uuid.New(), TaskID: id, ChangedBy: actor, Changes: changes})
+		}
+	}
+	if len(revisions) == 0 {
+		return nil
+	}
+
+	changed := make([]uuid.UUID, 0, len(revisions))
+	for _, rev := range revisions {
+		changed = append(changed, rev.TaskID)
+	}
+	err = tx.Model(&model.Task{}).Where("id IN ?", changed).UpdateColumn("version", gorm.Expr("version + 1")).Error
+	if err != nil {
+		return err
+	}
+	var latest []struct {
+		TaskID   uuid.UUID
+		Revision int
+	}
+	err = tx.Model(&model.TaskRevision{}).
+		Select("task_id, MAX(revision) AS revision").
+		Where("task_id IN ?", changed).
+		Group("task_id").
+		Scan(&latest).Error
+	if err != nil {
+		return err
+	}
+	last := make(map[uuid.UUID]int, len(latest))
+	for _, l := range latest {
+		last[l.TaskID] = l.Revision
+	}
+	for i := range revisions {
+		revisions[i].Revision = last[revisions[i].TaskID] + 1
+	}
+	return tx.Create(&revisions).Error
+}
+
+// taskFields includes trashed tasks, whose tags can still be renamed or
+// removed.
+func taskFields(tx *gorm.DB, ids []uuid.UUID) (map[uuid.UUID]model.TaskFields, error) {
+	var tasks []model.Task
+	if err := tx.Unscoped().Preload("Tags").Where("id IN ?", ids).Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[uuid.UUID]model.TaskFields, len(tasks))
+	for _, t := range tasks {
+		out[t.ID] = model.FieldsOf(t)
+	}
+	return out, nil
+}
+
+func (r *taskRepositoryImpl) ListRevisions(ctx context.Context, id string, userID string) ([]model.TaskRevision, error) {
+	db := r.db.WithContext(ctx)
+	var task model.Task
+	if err := db.Where("tasks.id = ?", id).Scopes(visibleTo(userID)).First(&task).Error; err != nil {
+		return nil, err
+	}
+	var revisions []model.TaskRevision
+	err := db.Where("task_id = ?", task.ID).Order("revision DESC").Find(&revisions).Error
+	return revisions, err
+}
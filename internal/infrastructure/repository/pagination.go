@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+	"todo-list/internal/domain/model"
+	drepo "todo-list/internal/domain/repository"
+)
+
+// noDueDate stands in for NULL due dates so they sort after every real date.
+var noDueDate = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+var sortExpressions = map[string]string{
+	drepo.SortCreatedAt: "tasks.created_at",
+	drepo.SortUpdatedAt: "tasks.updated_at",
+	drepo.SortDueDate:   "COALESCE(tasks.due_date, '9999-12-31 00:00:00+00'::timestamptz)",
+	drepo.SortPriority:  "CASE tasks.priority WHEN 'low' THEN 1 WHEN 'medium' THEN 2 WHEN 'high' THEN 3 WHEN 'urgent' THEN 4 ELSE 0 END",
+	drepo.SortTitle:     "tasks.title",
+	drepo.SortDeletedAt: "tasks.deleted_at",
+}
+
+type pageCursor struct {
+	Sort  string    `json:"s"`
+	Desc  bool      `json:"d"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+func encodeCursor(sort string, desc bool, last model.Task) string {
+	c := pageCursor{Sort: sort, Desc: desc, Value: sortValue(sort, last), ID: last.ID}
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	var c pageCursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, drepo.ErrInvalidCursor
+	}
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return c, drepo.ErrInvalidCursor
+	}
+	return c, nil
+}
+
+func sortValue(sort string, t model.Task) string {
+	switch sort {
+	case drepo.SortUpdatedAt:
+		return t.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case drepo.SortDueDate:
+		if t.DueDate == nil {
+			return noDueDate.Format(time.RFC3339Nano)
+		}
+		return t.DueDate.UTC().Format(time.RFC3339Nano)
+	case drepo.SortPriority:
+		return fmt.Sprint(model.PriorityRank(t.Priority))
+	case drepo.SortTitle:
+		return t.Title
+	case drepo.SortDeletedAt:
+		return t.DeletedAt.Time.UTC().Format(time.RFC3339Nano)
+	case drepo.SortRelevance:
+		return strconv.FormatFloat(t.SearchRank, 'g', -1, 64)
+	default:
+		return t.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+}
+
+// cursorArg converts the textual cursor value back into the type the sort
+// expression compares against.
+func cursorArg(sort, value string) (interface{}, error) {
+	switch sort {
+	case drepo.SortPriority:
+		var rank int
+		if _, err := fmt.Sscan(value, &rank); err != nil {
+			return nil, drepo.ErrInvalidCursor
+		}
+		return rank, nil
+	case drepo.SortTitle:
+		return value, nil
+	case drepo.SortRelevance:
+		rank, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, drepo.ErrInvalidCursor
+		}
+		return rank, nil
+	default:
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, drepo.ErrInvalidCursor
+		}
+		return t, nil
+	}
+}
+
+func pageLimit(limit int) int {
+	if limit <= 0 {
+		return drepo.DefaultPageLimit
+	}
+	if limit > drepo.MaxPageLimit {
+		return drepo.MaxPageLimit
+	}
+	return limit
+}
+
+// paginate applies keyset ordering to q and fetches one page of tasks.
+// Ties on the sort value are broken by tasks.id, so pages never overlap.
+func paginate(q *gorm.DB, page drepo.PageRequest) (drepo.TaskPage, error) {
+	return paginateBy(q, page, sortExpressions)
+}
+
+// paginateBy is paginate with its own set of sort expressions.
+func paginateBy(q *gorm.DB, page drepo.PageRequest, exprs map[string]string) (drepo.TaskPage, error) {
+	sort := page.Sort
+	if sort == "" {
+		sort = drepo.SortCreatedAt
+	}
+	expr, ok := exprs[sort]
+	if !ok {
+		return drepo.TaskPage{}, drepo.ErrInvalidSort
+	}
+
+	if page.Cursor != "" {
+		c, err := decodeCursor(page.Cursor)
+		if err != nil {
+			return drepo.TaskPage{}, err
+		}
+		if c.Sort != sort || c.Desc != page.Desc {
+			return drepo.TaskPage{}, drepo.ErrInvalidCursor
+		}
+		arg, err := cursorArg(sort, c.Value)
+		if err != nil {
+			return drepo.TaskPage{}, err
+		}
+		op := ">"
+		if page.Desc {
+			op = "<"
+		}
+		q = q.Where(fmt.Sprintf("(%s, tasks.id) %s (?, ?)", expr, op), arg, c.ID)
+	}
+
+	dir := "ASC"
+	if page.Desc {
+		dir = "DESC"
+	}
+	limit := pageLimit(page.Limit)
+
+	var tasks []model.Task
+	err := q.Order(fmt.Sprintf("%s %s, tasks.id %s", expr, dir, dir)).Limit(limit + 1).Find(&tasks).Error
+	if err != nil {
+		return drepo.TaskPage{}, err
+	}
+
+	out := drepo.TaskPage{Tasks: tasks}
+	if len(tasks) > limit {
+		out.Tasks = tasks[:limit]
+		out.NextCursor = encodeCursor(sort, page.Desc, out.Tasks[limit-1])
+	}
+	return out, nil
+}
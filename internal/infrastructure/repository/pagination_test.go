@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"testing"
+	"time"
+	"todo-list/internal/domain/model"
+	drepo "todo-list/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	due := time.Date(2026, 11, 1, 9, 30, 0, 0, time.UTC)
+	task := model.Task{ID: uuid.New(), Title: "Отчёт", Priority: "high", DueDate: &due}
+
+	t.Run("DueDate", func(t *testing.T) {
+		c, err := decodeCursor(encodeCursor(drepo.SortDueDate, false, task))
+		require.NoError(t, err)
+		assert.Equal(t, task.ID, c.ID)
+
+		arg, err := cursorArg(c.Sort, c.Value)
+		require.NoError(t, err)
+		assert.True(t, due.Equal(arg.(time.Time)))
+	})
+
+	t.Run("NoDueDate_SortsLast", func(t *testing.T) {
+		c, err := decodeCursor(encodeCursor(drepo.SortDueDate, false, model.Task{ID: uuid.New()}))
+		require.NoError(t, err)
+
+		arg, err := cursorArg(c.Sort, c.Value)
+		require.NoError(t, err)
+		assert.True(t, noDueDate.Equal(arg.(time.Time)))
+	})
+
+	t.Run("Priority", func(t *testing.T) {
+		c, err := decodeCursor(encodeCursor(drepo.SortPriority, true, task))
+		require.NoError(t, err)
+		assert.True(t, c.Desc)
+
+		arg, err := cursorArg(c.Sort, c.Value)
+		require.NoError(t, err)
+		assert.Equal(t, 3, arg)
+	})
+
+	t.Run("DeletedAt", func(t *testing.T) {
+		deleted := model.Task{ID: uuid.New(), DeletedAt: gorm.DeletedAt{Time: due, Valid: true}}
+		c, err := decodeCursor(encodeCursor(drepo.SortDeletedAt, true, deleted))
+		require.NoError(t, err)
+
+		arg, err := cursorArg(c.Sort, c.Value)
+		require.NoError(t, err)
+		assert.True(t, due.Equal(arg.(time.Time)))
+	})
+
+	t.Run("Relevance", func(t *testing.T) {
+		ranked := model.Task{ID: uuid.New(), SearchRank: float64(float32(0.0607927))}
+		c, err := decodeCursor(encodeCursor(drepo.SortRelevance, true, ranked))
+		require.NoError(t, err)
+
+		arg, err := cursorArg(c.Sort, c.Value)
+		require.NoError(t, err)
+		assert.Equal(t, ranked.SearchRank, arg)
+
+		_, err = cursorArg(drepo.SortRelevance, "high")
+		assert.ErrorIs(t, err, drepo.ErrInvalidCursor)
+	})
+
+	t.Run("Garbage", func(t *testing.T) {
+		_, err := decodeCursor("not a cursor!")
+		assert.ErrorIs(t, err, drepo.ErrInvalidCursor)
+	})
+}
+
+func TestPageLimit(t *testing.T) {
+	assert.Equal(t, drepo.DefaultPageLimit, pageLimit(0))
+	assert.Equal(t, drepo.MaxPageLimit, pageLimit(10000))
+	assert.Equal(t, 10, pageLimit(10))
+}
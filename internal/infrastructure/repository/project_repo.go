@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+	"todo-list/internal/domain/model"
+	drepo "todo-list/internal/domain/repository"
+)
+
+type projectRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewProjectRepository(db *gorm.DB) drepo.ProjectRepository {
+	return &projectRepositoryImpl{db: db}
+}
+
+func (r *projectRepositoryImpl) Create(ctx context.Context, project *model.Project) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureProjectNameFree(tx, project); err != nil {
+			return err
+		}
+		err := tx.Model(&model.Project{}).Where("user_id = ?", project.UserID).
+			Select("COALESCE(MAX(position), 0) + 1").Scan(&project.Position).Error
+		if err != nil {
+			return err
+		}
+		return nameTaken(tx.Create(project).Error, drepo.ErrProjectExists)
+	})
+}
+
+func (r *projectRepositoryImpl) GetByID(ctx context.Context, id string, userID string) (model.Project, error) {
+	var project model.Project
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&project).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Project{}, drepo.ErrProjectNotFound
+	}
+	return project, err
+}
+
+func (r *projectRepositoryImpl) List(ctx context.Context, userID string, includeArchived bool) ([]model.Project, error) {
+	q := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if !includeArchived {
+		q = q.Where("NOT archived")
+	}
+	var out []model.Project
+	err := q.Order("position, created_at").Find(&out).Error
+	return out, err
+}
+
+func (r *projectRepositoryImpl) Update(ctx context.Context, project *model.Project) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureProjectNameFree(tx, project); err != nil {
+			return err
+		}
+		err := tx.Model(project).Where("user_id = ?", project.UserID).
+			Select("name", "color", "description", "archived", "workflow", "updated_at").Updates(project).Error
+		return nameTaken(err, drepo.ErrProjectExists)
+	})
+}
+
+func (r *projectRepositoryImpl) Delete(ctx context.Context, id string, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Project{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return drepo.ErrProjectNotFound
+		}
+		return tx.Unscoped().Model(&model.Task{}).Where("project_id = ?", id).
+			Updates(map[string]interface{}{"project_id": nil, "version": gorm.Expr("version + 1")}).Error
+	})
+}
+
+func (r *projectRepositoryImpl) Reorder(ctx context.Context, userID string, ids []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []uuid.UUID
+		if err := tx.Model(&model.Project{}).Where("user_id = ?", userID).Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+		owned := make(map[string]bool, len(existing))
+		for _, id := range existing {
+			owned[id.String()] = true
+		}
+		if len(ids) != len(existing) {
+			return drepo.ErrProjectOrder
+		}
+		for i, id := range ids {
+			if !owned[id] {
+				return drepo.ErrProjectOrder
+			}
+			delete(owned, id)
+			err := tx.Model(&model.Project{}).Where("id = ?", id).
+				Updates(map[string]interface{}{"position": i + 1, "updated_at": time.Now()}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *projectRepositoryImpl) Stats(ctx context.Context, id string, userID string) (map[string]int64, error) {
+	if _, err := r.GetByID(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := r.db.WithContext(ctx).Model(&model.Task{}).
+		Select("status, COUNT(*) AS count").
+		Where("project_id = ? AND user_id = ?", id, userID).
+		Group("status").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]int64{"todo": 0, "in_progress": 0, "done": 0, "blocked": 0}
+	var total int64
+	for _, row := range rows {
+		out[row.Status] = row.Count
+		total += row.Count
+	}
+	out["total"] = total
+	return out, nil
+}
+
+// MoveTasks keeps a subtree inside one project: subtasks travel with their
+// parent, and a subtask moved on its own is detached from a parent that stays
+// behind.
+func (r *projectRepositoryImpl) MoveTasks(ctx context.Context, ids []string, projectID *string, userID string) ([]uuid.UUID, error) {
+	var moved []uuid.UUID
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockHierarchy(tx, ids, userID); err != nil {
+			return err
+		}
+		var target *uuid.UUID
+		if projectID != nil {
+			var p model.Project
+			err := tx.Where("id = ? AND user_id = ?", *projectID, userID).First(&p).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return drepo.ErrProjectNotFound
+			}
+			if err != nil {
+				return err
+			}
+			target = &p.ID
+		}
+		// Only personal tasks can join a project; their subtasks are personal too.
+		var roots []string
+		err := tx.Model(&model.Task{}).
+			Where("id IN ? AND user_id = ? AND workspace_id IS NULL", ids, userID).
+			Pluck("id", &roots).Error
+		if err != nil {
+			return err
+		}
+		if moved, err = subtreeIDs(tx, roots, userID); err != nil {
+			return err
+		}
+		if len(moved) == 0 {
+			return nil
+		}
+		return recordChanges(tx, moved, userID, func() error {
+			err := tx.Model(&model.Task{}).Where("id IN ?", moved).
+				Updates(map[string]interface{}{"project_id": target, "updated_at": time.Now()}).Error
+			if err != nil {
+				return err
+			}
+			// Only the roots can have a parent outside the moved set.
+			return tx.Model(&model.Task{}).
+				Where("id IN ? AND parent_id IS NOT NULL AND parent_id NOT IN ?", moved, moved).
+				Update("parent_id", nil).Error
+		})
+	})
+	return moved, err
+}
+
+// ensureProject checks that the project exists and belongs to the user.
+func ensureProject(tx *gorm.DB, projectID, userID uuid.UUID) error {
+	var count int64
+	if err := tx.Model(&model.Project{}).Where("id = ? AND user_id = ?", projectID, userID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return drepo.ErrProjectNotFound
+	}
+	return nil
+}
+
+func ensureProjectNameFree(tx *gorm.DB, project *model.Project) error {
+	var count int64
+	err := tx.Model(&model.Project{}).
+		Where("user_id = ? AND name = ? AND id <> ?", project.UserID, project.Name, project.ID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return drepo.ErrProjectExists
+	}
+	return nil
+}
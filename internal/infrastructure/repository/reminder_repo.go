@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+	"todo-list/internal/domain/model"
+	drepo "todo-list/internal/domain/repository"
+)
+
+type reminderRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewReminderRepository(db *gorm.DB) drepo.ReminderRepository {
+	return &reminderRepositoryImpl{db: db}
+}
+
+func (r *reminderRepositoryImpl) Create(ctx context.Context, reminder *model.Reminder) error {
+	return r.db.WithContext(ctx).Create(reminder).Error
+}
+
+func (r *reminderRepositoryImpl) ListByTask(ctx context.Context, taskID string, userID string) ([]model.Reminder, error) {
+	var out []model.Reminder
+	err := r.db.WithContext(ctx).
+		Where("task_id = ? AND user_id = ?", taskID, userID).
+		Order("created_at").Find(&out).Error
+	return out, err
+}
+
+func (r *reminderRepositoryImpl) Delete(ctx context.Context, id string, taskID string, userID string) error {
+	res := r.db.WithContext(ctx).
+		Where("id = ? AND task_id = ? AND user_id = ?", id, taskID, userID).
+		Delete(&model.Reminder{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return drepo.ErrReminderNotFound
+	}
+	return nil
+}
+
+// ClaimDue uses FOR UPDATE SKIP LOCKED so concurrent schedulers never lease
+// the same reminder; an expired lease makes the reminder claimable again,
+// which gives at-least-once delivery across restarts.
+func (r *reminderRepositoryImpl) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]drepo.DueReminder, error) {
+	db := r.db.WithContext(ctx)
+	var ids []uuid.UUID
+	err := db.Raw(`WITH due AS (
+			SELECT r.id FROM reminders r
+			JOIN tasks t ON t.id = r.task_id AND t.deleted_at IS NULL AND t.status <> 'done'
+			WHERE r.sent_at IS NULL AND r.failed_at IS NULL
+				AND (r.lease_until IS NULL OR r.lease_until <= ?)
+				AND COALESCE(r.remind_at, t.due_date - r.offset_minutes * interval '1 minute') <= ?
+			ORDER BY r.created_at
+			LIMIT ?
+			FOR UPDATE OF r SKIP LOCKED
+		)
+		UPDATE reminders SET lease_until = ?, attempts = reminders.attempts + 1
+		FROM due WHERE reminders.id = due.id
+		RETURNING reminders.id`, now, now, limit, now.Add(lease)).Scan(&ids).Error
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	var reminders []model.Reminder
+	if err := db.Where("id IN ?", ids).Order("created_at").Find(&reminders).Error; err != nil {
+		return nil, err
+	}
+	taskIDs := make([]uuid.UUID, 0, len(reminders))
+	userIDs := make([]uuid.UUID, 0, len(reminders))
+	for _, rem := range reminders {
+		taskIDs = append(taskIDs, rem.TaskID)
+		userIDs = append(userIDs, rem.UserID)
+	}
+	var tasks []model.Task
+	if err := db.Preload("Tags").Where("id IN ?", taskIDs).Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	var users []model.User
+	if err := db.Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	taskByID := make(map[uuid.UUID]model.Task, len(tasks))
+	for _, t := range tasks {
+		taskByID[t.ID] = t
+	}
+	emailByID := make(map[uuid.UUID]string, len(users))
+	for _, u := range users {
+		emailByID[u.ID] = u.Email
+	}
+
+	out := make([]drepo.DueReminder, 0, len(reminders))
+	for _, rem := range reminders {
+		out = append(out, drepo.DueReminder{Reminder: rem, Task: taskByID[rem.TaskID], Email: emailByID[rem.UserID]})
+	}
+	return out, nil
+}
+
+func (r *reminderRepositoryImpl) MarkSent(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.Reminder{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"sent_at": at, "lease_until": nil, "last_error": ""}).Error
+}
+
+func (r *reminderRepositoryImpl) MarkFailed(ctx context.Context, id uuid.UUID, reason string, retryAt *time.Time) error {
+	updates := map[string]interface{}{"last_error": reason, "lease_until": retryAt}
+	if retryAt == nil {
+		updates["failed_at"] = time.Now()
+	}
+	return r.db.WithContext(ctx).Model(&model.Reminder{}).Where("id = ?", id).Updates(updates).Error
+}
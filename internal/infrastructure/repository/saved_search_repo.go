@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"todo-list/internal/domain/model"
+	drepo "todo-list/internal/domain/repository"
+)
+
+type savedSearchRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewSavedSearchRepository(db *gorm.DB) drepo.SavedSearchRepository {
+	return &savedSearchRepositoryImpl{db: db}
+}
+
+func (r *savedSearchRepositoryImpl) List(ctx context.Context, userID string) ([]model.SavedSearch, error) {
+	var out []model.SavedSearch
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("name").Find(&out).Error
+	return out, err
+}
+
+func (r *savedSearchRepositoryImpl) GetByID(ctx context.Context, id string, userID string) (model.SavedSearch, error) {
+	var search model.SavedSearch
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&search).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.SavedSearch{}, drepo.ErrSavedSearchNotFound
+	}
+	return search, err
+}
+
+func (r *savedSearchRepositoryImpl) Create(ctx context.Context, search *model.SavedSearch) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureSavedSearchNameFree(tx, search); err != nil {
+			return err
+		}
+		return nameTaken(tx.Create(search).Error, drepo.ErrSavedSearchExists)
+	})
+}
+
+func (r *savedSearchRepositoryImpl) Update(ctx context.Context, search *model.SavedSearch) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureSavedSearchNameFree(tx, search); err != nil {
+			return err
+		}
+		res := tx.Model(search).Where("user_id = ?", search.UserID).Updates(map[string]interface{}{
+			"name": search.Name, "filter": search.Filter, "sort": search.Sort, "sort_desc": search.Desc,
+		})
+		if res.Error != nil {
+			return nameTaken(res.Error, drepo.ErrSavedSearchExists)
+		}
+		if res.RowsAffected == 0 {
+			return drepo.ErrSavedSearchNotFound
+		}
+		return nil
+	})
+}
+
+func (r *savedSearchRepositoryImpl) Delete(ctx context.Context, id string, userID string) error {
+	res := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&model.SavedSearch{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return drepo.ErrSavedSearchNotFound
+	}
+	return nil
+}
+
+func ensureSavedSearchNameFree(tx *gorm.DB, search *model.SavedSearch) error {
+	var count int64
+	err := tx.Model(&model.SavedSearch{}).
+		Where("user_id = ? AND name = ? AND id <> ?", search.UserID, search.Name, search.ID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return drepo.ErrSavedSearchExists
+	}
+	return nil
+}
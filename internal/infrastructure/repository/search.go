@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"regexp"
+	"strings"
+	"todo-list/internal/domain/model"
+	drepo "todo-list/internal/domain/repository"
+	"unicode"
+)
+
+// maxSearchWords bounds the size of the tsquery built from user input.
+const maxSearchWords = 16
+
+var searchWord = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// searchRankSQL weighs a match by where it was found; see
+// postgres.MigrateSearch for the weights. search_query is joined by matching.
+const searchRankSQL = "ts_rank(tasks.search_vector, search_query)"
+
+// Highlights use the same configuration as the search document. The snippet
+// shows up to two fragments of the content around the matched words.
+var searchColumnsSQL = fmt.Sprintf(`%[1]s AS search_rank,
+	ts_headline('russian', tasks.title, search_query, 'HighlightAll=true, StartSel=%[2]s, StopSel=%[3]s') AS title_highlight,
+	ts_headline('russian', tasks.content, search_query, 'MaxFragments=2, MinWords=5, MaxWords=20, StartSel=%[2]s, StopSel=%[3]s') AS content_snippet`,
+	searchRankSQL, model.HighlightStart, model.HighlightStop)
+
+// searchSortExpressions adds relevance to the sorts of a searched list.
+var searchSortExpressions = func() map[string]string {
+	exprs := map[string]string{drepo.SortRelevance: searchRankSQL}
+	for sort, expr := range sortExpressions {
+		exprs[sort] = expr
+	}
+	return exprs
+}()
+
+// searchQuery turns free-text terms into a to_tsquery expression in which
+// every word has to match. The last word is matched as a prefix, so results
+// keep up while the user is typing, unless the text ends with a space. It
+// returns "" when the terms contain no words.
+func searchQuery(terms []string) string {
+	var words []string
+	for _, term := range terms {
+		words = append(words, searchWord.FindAllString(foldYo(term), -1)...)
+	}
+	if len(words) == 0 {
+		return ""
+	}
+	last := terms[len(terms)-1]
+	prefix := strings.TrimRightFunc(last, unicode.IsSpace) == last
+	if len(words) > maxSearchWords {
+		words, prefix = words[:maxSearchWords], false
+	}
+	if prefix {
+		words[len(words)-1] += ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// foldYo spells ё as е, as the search document does.
+func foldYo(s string) string {
+	return strings.NewReplacer("ё", "е", "Ё", "Е").Replace(s)
+}
+
+// matching restricts a task query to full-text matches of the tsquery and
+// selects their rank and highlights next to the usual columns. It has to run
+// after withAssociations, whose column list it replaces.
+func matching(query string) func(*gorm.DB) *gorm.DB {
+	return func(q *gorm.DB) *gorm.DB {
+		return q.Select(taskCommentCountSQL+", "+searchColumnsSQL).
+			Joins("CROSS JOIN to_tsquery('russian', ?) AS search_query", query).
+			Where("tasks.search_vector @@ search_query")
+	}
+}
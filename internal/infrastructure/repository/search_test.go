@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchQuery(t *testing.T) {
+	cases := []struct {
+		terms []string
+		want  string
+	}{
+		{[]string{"молоко"}, "молоко:*"},
+		{[]string{"купить моло"}, "купить & моло:*"},
+		// Пробел в конце означает, что слово дописано
+		{[]string{"купить молоко "}, "купить & молоко"},
+		{[]string{"Ёлка"}, "Елка:*"},
+		{[]string{"quarterly report", "q4"}, "quarterly & report & q4:*"},
+		// Операторы tsquery не доходят до запроса
+		{[]string{"a & !b | (c:*)"}, "a & b & c:*"},
+		{[]string{"  ", "!!"}, ""},
+		{nil, ""},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, searchQuery(tc.terms), tc.terms)
+	}
+
+	long := searchQuery([]string{strings.Repeat("слово ", 20) + "конец"})
+	assert.Equal(t, maxSearchWords, strings.Count(long, "слово"))
+	assert.NotContains(t, long, ":*")
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"todo-list/internal/domain/model"
+	drepo "todo-list/internal/domain/repository"
+)
+
+type tagRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) drepo.TagRepository {
+	return &tagRepositoryImpl{db: db}
+}
+
+func (r *tagRepositoryImpl) List(ctx context.Context, userID string) ([]drepo.TagUsage, error) {
+	var rows []struct {
+		model.Tag
+		TaskCount int64
+	}
+	err := r.db.WithContext(ctx).Model(&model.Tag{}).
+		Select("tags.*, COUNT(tasks.id) AS task_count").
+		Joins("LEFT JOIN task_tags ON task_tags.tag_id = tags.id").
+		Joins("LEFT JOIN tasks ON tasks.id = task_tags.task_id AND tasks.deleted_at IS NULL").
+		Where("tags.user_id = ?", userID).
+		Group("tags.id").
+		Order("tags.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]drepo.TagUsage, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, drepo.TagUsage{Tag: row.Tag, TaskCount: row.TaskCount})
+	}
+	return out, nil
+}
+
+func (r *tagRepositoryImpl) GetByID(ctx context.Context, id uint, userID string) (model.Tag, error) {
+	var tag model.Tag
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&tag).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Tag{}, drepo.ErrTagNotFound
+	}
+	return tag, err
+}
+
+func (r *tagRepositoryImpl) Create(ctx context.Context, tag *model.Tag) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureTagNameFree(tx, tag); err != nil {
+			return err
+		}
+		return nameTaken(tx.Create(tag).Error, drepo.ErrTagExists)
+	})
+}
+
+func (r *tagRepositoryImpl) Update(ctx context.Context, tag *model.Tag) ([]uuid.UUID, error) {
+	var tagged []uuid.UUID
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureTagNameFree(tx, tag); err != nil {
+			return err
+		}
+		var current model.Tag
+		if err := tx.Select("name").Where("id = ? AND user_id = ?", tag.ID, tag.UserID).First(&current).Error; err != nil {
+			return err
+		}
+		update := func() error {
+			err := tx.Model(tag).Where("user_id = ?", tag.UserID).
+				Updates(map[string]interface{}{"name": tag.Name, "color": tag.Color}).Error
+			return nameTaken(err, drepo.ErrTagExists)
+		}
+		if current.Name == tag.Name {
+			return update()
+		}
+		// Tasks show tag names, so a rename changes them.
+		var err error
+		if tagged, err = taggedTasks(tx, tag.ID); err != nil {
+			return err
+		}
+		return recordChanges(tx, tagged, tag.UserID.String(), update)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tagged, nil
+}
+
+// Merge re-points every task tagged with source to target and removes source.
+func (r *tagRepositoryImpl) Merge(ctx context.Context, sourceID, targetID uint, userID string) ([]uuid.UUID, error) {
+	var tagged []uuid.UUID
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.Tag{}).Where("id IN ? AND user_id = ?", []uint{sourceID, targetID}, userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count != 2 {
+			return drepo.ErrTagNotFound
+		}
+		var err error
+		if tagged, err = taggedTasks(tx, sourceID); err != nil {
+			return err
+		}
+		return recordChanges(tx, tagged, userID, func() error {
+			err := tx.Exec(`INSERT INTO task_tags (task_id, tag_id)
+				SELECT task_id, ? FROM task_tags WHERE tag_id = ?
+				ON CONFLICT DO NOTHING`, targetID, sourceID).Error
+			if err != nil {
+				return err
+			}
+			if err := tx.Exec("DELETE FROM task_tags WHERE tag_id = ?", sourceID).Error; err != nil {
+				return err
+			}
+			return tx.Where("id = ? AND user_id = ?", sourceID, userID).Delete(&model.Tag{}).Error
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tagged, nil
+}
+
+func (r *tagRepositoryImpl) Delete(ctx context.Context, id uint, userID string) ([]uuid.UUID, error) {
+	var tagged []uuid.UUID
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tag model.Tag
+		err := tx.Where("id = ? AND user_id = ?", id, userID).First(&tag).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return drepo.ErrTagNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if tagged, err = taggedTasks(tx, tag.ID); err != nil {
+			return err
+		}
+		return recordChanges(tx, tagged, userID, func() error {
+			if err := tx.Exec("DELETE FROM task_tags WHERE tag_id = ?", id).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&tag).Error
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tagged, nil
+}
+
+// taggedTasks lists the tasks carrying the tag, trashed ones included.
+func taggedTasks(tx *gorm.DB, tagID uint) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := tx.Table("task_tags").Where("tag_id = ?", tagID).Order("task_id").Pluck("task_id", &ids).Error
+	return ids, err
+}
+
+// uniqueViolation is the SQLSTATE Postgres reports when a unique index
+// refuses a row.
+const uniqueViolation = "23505"
+
+// nameTaken turns the unique violation raised when a concurrent request takes
+// a name between the up-front name check and the write into exists.
+func nameTaken(err, exists error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return exists
+	}
+	return err
+}
+
+func ensureTagNameFree(tx *gorm.DB, tag *model.Tag) error {
+	var count int64
+	err := tx.Model(&model.Tag{}).
+		Where("user_id = ? AND name = ? AND id <> ?", tag.UserID, tag.Name, tag.ID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return drepo.ErrTagExists
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	drepo "todo-list/internal/domain/repository"
+)
+
+func TestNameTaken(t *testing.T) {
+	// Гонка двух запросов с одним именем упирается в уникальный индекс
+	dup := fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23505"})
+	assert.Equal(t, drepo.ErrTagExists, nameTaken(dup, drepo.ErrTagExists))
+
+	other := &pgconn.PgError{Code: "23503"}
+	assert.Equal(t, error(other), nameTaken(other, drepo.ErrTagExists))
+	assert.NoError(t, nameTaken(nil, drepo.ErrTagExists))
+	plain := errors.New("db down")
+	assert.Equal(t, plain, nameTaken(plain, drepo.ErrTagExists))
+}
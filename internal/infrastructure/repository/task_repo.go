@@ -2,14 +2,35 @@ package repository
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
-	"strings"
+	"gorm.io/gorm/clause"
 	"time"
 	"todo-list/internal/domain/model"
 	drepo "todo-list/internal/domain/repository"
 )
 
+// taskVisibleSQL matches the user's personal tasks and every task of the
+// workspaces the user is a member of. It takes the user id twice.
+const taskVisibleSQL = `((tasks.user_id = ? AND tasks.workspace_id IS NULL)
+	OR tasks.workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = ?))`
+
+// visibleTo scopes a tasks query to what the user may see.
+func visibleTo(userID string) func(*gorm.DB) *gorm.DB {
+	return func(q *gorm.DB) *gorm.DB {
+		return q.Where(taskVisibleSQL, userID, userID)
+	}
+}
+
+const taskCommentCountSQL = `tasks.*, (SELECT COUNT(*) FROM comments
+	WHERE comments.task_id = tasks.id AND comments.deleted_at IS NULL) AS comment_count`
+
+// withAssociations loads everything a task response shows besides the row.
+func withAssociations(q *gorm.DB) *gorm.DB {
+	return q.Select(taskCommentCountSQL).Preload("Tags").Preload("Assignees").Preload("Watchers")
+}
+
 type taskRepositoryImpl struct {
 	db *gorm.DB
 }
@@ -19,116 +40,273 @@ func NewTaskRepository(db *gorm.DB) drepo.TaskRepository {
 }
 
 func (r *taskRepositoryImpl) Create(ctx context.Context, task *model.Task) error {
-	return r.db.WithContext(ctx).Create(task).Error
+	return createTask(r.db.WithContext(ctx), task)
+}
+
+func createTask(db *gorm.DB, task *model.Task) error {
+	if task.ProjectID != nil {
+		// Projects are personal lists; shared tasks stay out of them.
+		if task.WorkspaceID != nil {
+			return drepo.ErrCrossWorkspace
+		}
+		if err := ensureProject(db, *task.ProjectID, task.UserID); err != nil {
+			return err
+		}
+	}
+	return db.Create(task).Error
 }
 
-func (r *taskRepositoryImpl) GetAll(ctx context.Context, userID string) ([]model.Task, error) {
-	var tasks []model.Task
-	err := r.db.WithContext(ctx).Preload("Tags").Where("user_id = ?", userID).Find(&tasks).Error
-	return tasks, err
+func (r *taskRepositoryImpl) GetAll(ctx context.Context, userID string, page drepo.PageRequest) (drepo.TaskPage, error) {
+	return r.List(ctx, userID, drepo.TaskFilter{}, page)
+}
+
+func (r *taskRepositoryImpl) List(ctx context.Context, userID string, filter drepo.TaskFilter, page drepo.PageRequest) (drepo.TaskPage, error) {
+	q := r.db.WithContext(ctx).Scopes(withAssociations, visibleTo(userID))
+	query := searchQuery(filter.Terms)
+	if query == "" {
+		return paginate(applyFilter(q, filter), page)
+	}
+	if page.Sort == "" {
+		page.Sort = drepo.SortRelevance
+	}
+	return paginateBy(applyFilter(q.Scopes(matching(query)), filter), page, searchSortExpressions)
 }
 
 func (r *taskRepositoryImpl) GetByID(ctx context.Context, id string, userID string) (model.Task, error) {
 	var task model.Task
-	err := r.db.WithContext(ctx).Preload("Tags").Where("id = ? AND user_id = ?", id, userID).First(&task).Error
+	err := r.db.WithContext(ctx).Scopes(withAssociations, visibleTo(userID)).Where("tasks.id = ?", id).First(&task).Error
 	return task, err
 }
 
-func (r *taskRepositoryImpl) Update(ctx context.Context, task *model.Task) error {
-	return r.db.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).Save(task).Error
+// Update saves the task and re-evaluates dependency-driven blocking for it and
+// for the tasks it blocks; task.Status reflects the outcome. The change is
+// recorded in the task's history under userID.
+func (r *taskRepositoryImpl) Update(ctx context.Context, task *model.Task, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return save(tx, task, nil, userID)
+	})
 }
 
-func (r *taskRepositoryImpl) Delete(ctx context.Context, id string, userID string) error {
-	return r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&model.Task{}).Error
+func (r *taskRepositoryImpl) UpdateFields(ctx context.Context, task *model.Task, fields []string, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return save(tx, task, fields, userID)
+	})
 }
 
-func (r *taskRepositoryImpl) FindByStatus(ctx context.Context, status string, userID string) ([]model.Task, error) {
-	var tasks []model.Task
-	err := r.db.WithContext(ctx).Preload("Tags").Where("status = ? AND user_id = ?", status, userID).Find(&tasks).Error
-	return tasks, err
+// Complete saves a task that has just been moved to done, completes its open
+// subtasks and creates its next occurrence in one transaction, so that a
+// failure can neither leave a done parent with open children nor end a
+// recurring series.
+func (r *taskRepositoryImpl) Complete(ctx context.Context, task *model.Task, fields []string, next *model.Task, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids := []string{task.ID.String()}
+		if err := lockHierarchy(tx, ids, userID); err != nil {
+			return err
+		}
+		if err := save(tx, task, fields, userID); err != nil {
+			return err
+		}
+		if err := applySubtree(tx, ids, userID, completeOpen); err != nil {
+			return err
+		}
+		if next == nil {
+			return nil
+		}
+		return createTask(tx, next)
+	})
 }
 
-func (r *taskRepositoryImpl) FindByPriority(ctx context.Context, priority string, userID string) ([]model.Task, error) {
-	var tasks []model.Task
-	err := r.db.WithContext(ctx).Preload("Tags").Where("priority = ? AND user_id = ?", priority, userID).Find(&tasks).Error
-	return tasks, err
+// save writes the task, or only fields when they are not nil, under the
+// task's version check and history recording.
+func save(tx *gorm.DB, task *model.Task, fields []string, userID string) error {
+	err := recordChanges(tx, []uuid.UUID{task.ID}, userID, func() error {
+		var current int64
+		if err := tx.Raw("SELECT version FROM tasks WHERE id = ? AND deleted_at IS NULL", task.ID).Scan(&current).Error; err != nil {
+			return err
+		}
+		if current != task.Version {
+			return drepo.ErrVersionConflict
+		}
+		if fields == nil {
+			// Assignees and watchers change through their own calls only.
+			err := tx.Session(&gorm.Session{FullSaveAssociations: true}).Omit("Assignees", "Watchers").Save(task).Error
+			if err != nil {
+				return err
+			}
+		} else {
+			columns := append(append([]string{}, fields...), "updated_at")
+			if err := tx.Model(task).Select(columns).Updates(task).Error; err != nil {
+				return err
+			}
+		}
+		return syncBlocked(tx, []uuid.UUID{task.ID})
+	})
+	if err != nil {
+		return err
+	}
+	if err := refreshDependents(tx, []uuid.UUID{task.ID}); err != nil {
+		return err
+	}
+	return tx.Raw("SELECT status, version FROM tasks WHERE id = ?", task.ID).Row().Scan(&task.Status, &task.Version)
+}
+
+// bumpVersion marks a change that is not recorded in the task's history.
+func bumpVersion(tx *gorm.DB, id uuid.UUID) error {
+	return tx.Model(&model.Task{}).Where("id = ?", id).UpdateColumn("version", gorm.Expr("version + 1")).Error
+}
+
+// Delete removes the task together with all of its subtasks.
+func (r *taskRepositoryImpl) Delete(ctx context.Context, id string, userID string) error {
+	_, err := r.DeleteMany(ctx, []string{id}, userID)
+	return err
 }
 
-func (r *taskRepositoryImpl) FindByTag(ctx context.Context, tag string, userID string) ([]model.Task, error) {
-	var tasks []model.Task
-	err := r.db.WithContext(ctx).
-		Joins("JOIN task_tags ON task_tags.task_id = tasks.id").
-		Joins("JOIN tags ON tags.id = task_tags.tag_id AND tags.name = ?", tag).
-		Preload("Tags").
-		Where("tasks.user_id = ?", userID).
-		Find(&tasks).Error
-	return tasks, err
+func (r *taskRepositoryImpl) FindByStatus(ctx context.Context, status string, userID string, page drepo.PageRequest) (drepo.TaskPage, error) {
+	return r.List(ctx, userID, drepo.TaskFilter{Statuses: []string{status}}, page)
 }
 
-func (r *taskRepositoryImpl) Search(ctx context.Context, q string, userID string) ([]model.Task, error) {
-	var tasks []model.Task
-	like := fmt.Sprintf("%%%s%%", strings.TrimSpace(q))
-	err := r.db.WithContext(ctx).Preload("Tags").
-		Where("user_id = ? AND (title ILIKE ? OR content ILIKE ?)", userID, like, like).
-		Find(&tasks).Error
-	return tasks, err
+func (r *taskRepositoryImpl) FindByPriority(ctx context.Context, priority string, userID string, page drepo.PageRequest) (drepo.TaskPage, error) {
+	return r.List(ctx, userID, drepo.TaskFilter{Priorities: []string{priority}}, page)
 }
 
-func (r *taskRepositoryImpl) GetToday(ctx context.Context, userID string) ([]model.Task, error) {
-	var tasks []model.Task
-	start := time.Now().Truncate(24 * time.Hour)
-	end := start.Add(24*time.Hour - time.Nanosecond)
-	err := r.db.WithContext(ctx).Preload("Tags").
-		Where("user_id = ? AND due_date >= ? AND due_date <= ?", userID, start, end).
-		Find(&tasks).Error
-	return tasks, err
+func (r *taskRepositoryImpl) FindByTag(ctx context.Context, tag string, userID string, page drepo.PageRequest) (drepo.TaskPage, error) {
+	return r.List(ctx, userID, drepo.TaskFilter{TagGroups: [][]string{{tag}}}, page)
 }
 
-func (r *taskRepositoryImpl) GetOverdue(ctx context.Context, userID string) ([]model.Task, error) {
-	var tasks []model.Task
-	err := r.db.WithContext(ctx).Preload("Tags").
-		Where("user_id = ? AND due_date < ? AND status <> ?", userID, time.Now(), "done").
-		Find(&tasks).Error
-	return tasks, err
+// Search ranks full-text matches of q by relevance unless the page asks for
+// another order. See searchQuery for how q is read.
+func (r *taskRepositoryImpl) Search(ctx context.Context, q string, userID string, page drepo.PageRequest) (drepo.TaskPage, error) {
+	return r.List(ctx, userID, drepo.TaskFilter{Terms: []string{q}}, page)
 }
 
 func (r *taskRepositoryImpl) AddTag(ctx context.Context, id string, tag string, userID string) (model.Task, error) {
-	var task model.Task
-	if err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&task).Error; err != nil {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var task model.Task
+		if err := tx.Where("tasks.id = ?", id).Scopes(visibleTo(userID)).First(&task).Error; err != nil {
+			return err
+		}
+		// A concurrent request may create the same tag between a lookup and
+		// an insert, so insert first and read back whichever row won.
+		t := model.Tag{UserID: task.UserID, Name: tag}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&t).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? AND name = ?", task.UserID, tag).First(&t).Error; err != nil {
+			return err
+		}
+		return recordChanges(tx, []uuid.UUID{task.ID}, userID, func() error {
+			return tx.Model(&task).Association("Tags").Append(&t)
+		})
+	})
+	if err != nil {
 		return model.Task{}, err
 	}
-	var t model.Tag
-	r.db.WithContext(ctx).FirstOrCreate(&t, model.Tag{Name: tag})
-	r.db.WithContext(ctx).Model(&task).Association("Tags").Append(&t)
 	return r.GetByID(ctx, id, userID)
 }
 
 func (r *taskRepositoryImpl) RemoveTag(ctx context.Context, id string, tag string, userID string) (model.Task, error) {
-	var task model.Task
-	if err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&task).Error; err != nil {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var task model.Task
+		if err := tx.Where("tasks.id = ?", id).Scopes(visibleTo(userID)).First(&task).Error; err != nil {
+			return err
+		}
+		var t model.Tag
+		if err := tx.Where("user_id = ? AND name = ?", task.UserID, tag).First(&t).Error; err != nil {
+			return nil
+		}
+		return recordChanges(tx, []uuid.UUID{task.ID}, userID, func() error {
+			return tx.Model(&task).Association("Tags").Delete(&t)
+		})
+	})
+	if err != nil {
 		return model.Task{}, err
 	}
-	var t model.Tag
-	if err := r.db.WithContext(ctx).Where("name = ?", tag).First(&t).Error; err == nil {
-		r.db.WithContext(ctx).Model(&task).Association("Tags").Delete(&t)
-	}
 	return r.GetByID(ctx, id, userID)
 }
 
-func (r *taskRepositoryImpl) BulkDelete(ctx context.Context, ids []string, userID string) error {
-	return r.db.WithContext(ctx).Where("id IN ? AND user_id = ?", ids, userID).Delete(&model.Task{}).Error
+// DeleteMany deletes the visible tasks among ids with their subtrees.
+func (r *taskRepositoryImpl) DeleteMany(ctx context.Context, ids []string, userID string) ([]uuid.UUID, error) {
+	var deleted []uuid.UUID
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockHierarchy(tx, ids, userID); err != nil {
+			return err
+		}
+		err := tx.Model(&model.Task{}).
+			Where("tasks.id IN ?", ids).Scopes(visibleTo(userID)).
+			Pluck("id", &deleted).Error
+		if err != nil {
+			return err
+		}
+		return applySubtree(tx, ids, userID, func(q *gorm.DB) error {
+			return q.Delete(&model.Task{}).Error
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
 }
 
-func (r *taskRepositoryImpl) BulkUpdateStatus(ctx context.Context, ids []string, status string, userID string) error {
-	return r.db.WithContext(ctx).Model(&model.Task{}).
-		Where("id IN ? AND user_id = ?", ids, userID).
-		Updates(map[string]interface{}{"status": status, "updated_at": time.Now()}).Error
+// UpdateStatus moves the visible tasks among ids to status. Moving them to
+// done completes their open subtasks too, as Complete does for one task.
+func (r *taskRepositoryImpl) UpdateStatus(ctx context.Context, ids []string, status string, userID string, check func(model.Task) error) ([]uuid.UUID, error) {
+	var changed []uuid.UUID
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if status == model.StatusDone {
+			if err := lockHierarchy(tx, ids, userID); err != nil {
+				return err
+			}
+		}
+		var updated []uuid.UUID
+		err := tx.Model(&model.Task{}).
+			Where("tasks.id IN ?", ids).Scopes(visibleTo(userID)).
+			Pluck("id", &updated).Error
+		if err != nil {
+			return err
+		}
+		err = recordChanges(tx, updated, userID, func() error {
+			// The rows are locked now, so the statuses checked are the ones replaced.
+			var tasks []model.Task
+			if err := tx.Where("id IN ?", updated).Find(&tasks).Error; err != nil {
+				return err
+			}
+			for _, task := range tasks {
+				if err := check(task); err != nil {
+					return err
+				}
+				if task.Status != status {
+					changed = append(changed, task.ID)
+				}
+			}
+			err := tx.Model(&model.Task{}).
+				Where("id IN ?", updated).
+				Updates(statusColumns(status, time.Now())).Error
+			if err != nil {
+				return err
+			}
+			return syncBlocked(tx, updated)
+		})
+		if err != nil {
+			return err
+		}
+		if err := refreshDependents(tx, updated); err != nil {
+			return err
+		}
+		if status != model.StatusDone {
+			return nil
+		}
+		return applySubtree(tx, ids, userID, completeOpen)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changed, nil
 }
 
 func (r *taskRepositoryImpl) Archive(ctx context.Context, id string, userID string) (model.Task, error) {
-	err := r.db.WithContext(ctx).Model(&model.Task{}).
-		Where("id = ? AND user_id = ?", id, userID).
-		Update("archived", true).Error
+	err := r.updateSubtree(ctx, []string{id}, userID, func(q *gorm.DB) error {
+		return q.Update("archived", true).Error
+	})
 	if err != nil {
 		return model.Task{}, err
 	}
@@ -136,25 +314,68 @@ func (r *taskRepositoryImpl) Archive(ctx context.Context, id string, userID stri
 }
 
 func (r *taskRepositoryImpl) Unarchive(ctx context.Context, id string, userID string) (model.Task, error) {
-	err := r.db.WithContext(ctx).Model(&model.Task{}).
-		Where("id = ? AND user_id = ?", id, userID).
-		Update("archived", false).Error
+	err := r.updateSubtree(ctx, []string{id}, userID, func(q *gorm.DB) error {
+		return q.Update("archived", false).Error
+	})
 	if err != nil {
 		return model.Task{}, err
 	}
 	return r.GetByID(ctx, id, userID)
 }
 
+// Stats counts every status in use, so statuses from custom workflows show up
+// next to the default ones.
 func (r *taskRepositoryImpl) Stats(ctx context.Context, userID string) (map[string]int64, error) {
-	out := map[string]int64{}
-	statuses := []string{"todo", "in_progress", "done", "blocked"}
-	for _, s := range statuses {
-		var cnt int64
-		r.db.WithContext(ctx).Model(&model.Task{}).Where("status = ? AND user_id = ?", s, userID).Count(&cnt)
-		out[s] = cnt
+	var rows []struct {
+		Status string
+		Count  int64
 	}
+	err := r.db.WithContext(ctx).Model(&model.Task{}).
+		Select("tasks.status, COUNT(*) AS count").Scopes(visibleTo(userID)).
+		Group("tasks.status").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]int64{"todo": 0, "in_progress": 0, "done": 0, "blocked": 0}
 	var total int64
-	r.db.WithContext(ctx).Model(&model.Task{}).Where("user_id = ?", userID).Count(&total)
+	for _, row := range rows {
+		out[row.Status] = row.Count
+		total += row.Count
+	}
 	out["total"] = total
 	return out, nil
 }
+
+func (r *taskRepositoryImpl) Workflow(ctx context.Context, projectID, workspaceID *uuid.UUID) (*model.Workflow, error) {
+	if projectID != nil {
+		var project model.Project
+		err := r.db.WithContext(ctx).Select("workflow").Where("id = ?", *projectID).Take(&project).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if project.Workflow != nil {
+			return project.Workflow, nil
+		}
+	}
+	if workspaceID != nil {
+		var ws model.Workspace
+		err := r.db.WithContext(ctx).Select("workflow").Where("id = ?", *workspaceID).Take(&ws).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		return ws.Workflow, nil
+	}
+	return nil, nil
+}
+
+// statusColumns moves tasks to status and stamps them the way
+// model.Task.SetStatus stamps a single task.
+func statusColumns(status string, now time.Time) map[string]interface{} {
+	columns := map[string]interface{}{"status": status, "updated_at": now, "completed_at": nil}
+	if status == model.StatusDone {
+		columns["completed_at"] = gorm.Expr("COALESCE(completed_at, ?)", now)
+	} else if status != model.StatusTodo && status != model.StatusBlocked {
+		columns["started_at"] = gorm.Expr("COALESCE(started_at, ?)", now)
+	}
+	return columns
+}
@@ -2,16 +2,19 @@ package repository
 
 import (
 	"context"
-	"testing"
-	"time"
-	"todo-list/internal/domain/model"
-
+	"errors"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"sync"
+	"testing"
+	"time"
+	"todo-list/internal/domain/model"
+	drepo "todo-list/internal/domain/repository"
+	pgdb "todo-list/internal/infrastructure/database/postgres"
 )
 
 func setupRealDB(t *testing.T) *gorm.DB {
@@ -25,10 +28,23 @@ func setupRealDB(t *testing.T) *gorm.DB {
 		t.Fatalf("Не удалось подключиться к тестовой БД: %v. Проверь, запущен ли Docker!", err)
 	}
 
-	err = db.AutoMigrate(&model.User{}, &model.Task{}, &model.Tag{})
+	err = db.AutoMigrate(&model.User{}, &model.Workspace{}, &model.WorkspaceMember{}, &model.WorkspaceInvitation{}, &model.Project{}, &model.Task{}, &model.Tag{}, &model.TaskAssignee{}, &model.TaskWatcher{}, &model.Comment{}, &model.CommentMention{}, &model.Attachment{}, &model.TaskRevision{}, &model.TaskDependency{}, &model.Reminder{}, &model.WebhookSubscription{}, &model.WebhookDelivery{})
 	require.NoError(t, err)
+	require.NoError(t, pgdb.MigrateSearch(db))
 
 	db.Exec("TRUNCATE TABLE task_tags CASCADE")
+	db.Exec("TRUNCATE TABLE tags CASCADE")
+	db.Exec("TRUNCATE TABLE comments CASCADE")
+	db.Exec("TRUNCATE TABLE attachments")
+	db.Exec("TRUNCATE TABLE task_revisions")
+	db.Exec("TRUNCATE TABLE task_dependencies")
+	db.Exec("TRUNCATE TABLE reminders")
+	db.Exec("TRUNCATE TABLE webhook_deliveries")
+	db.Exec("TRUNCATE TABLE webhook_subscriptions")
+	db.Exec("TRUNCATE TABLE projects CASCADE")
+	db.Exec("TRUNCATE TABLE workspace_members")
+	db.Exec("TRUNCATE TABLE workspace_invitations")
+	db.Exec("TRUNCATE TABLE workspaces")
 	db.Exec("TRUNCATE TABLE tasks CASCADE")
 	db.Exec("TRUNCATE TABLE users CASCADE")
 
@@ -78,8 +94,9 @@ func TestRepository_Integration(t *testing.T) {
 		db.Create(&model.Task{ID: id1, UserID: userID, Title: "T1"})
 		db.Create(&model.Task{ID: id2, UserID: userID, Title: "T2"})
 
-		err := repo.BulkDelete(ctx, []string{id1.String(), id2.String()}, userID.String())
+		deleted, err := repo.DeleteMany(ctx, []string{id1.String(), id2.String(), uuid.NewString()}, userID.String())
 		assert.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{id1, id2}, deleted)
 
 		var count int64
 		db.Model(&model.Task{}).Where("id IN ?", []uuid.UUID{id1, id2}).Count(&count)
@@ -128,9 +145,51 @@ func TestRepository_Features(t *testing.T) {
 	t.Run("Search", func(t *testing.T) {
 		repo.Create(ctx, &model.Task{ID: uuid.New(), UserID: uid, Title: "Купить молоко"})
 
-		results, err := repo.Search(ctx, "МОЛОКО", userID)
+		results, err := repo.Search(ctx, "МОЛОКО", userID, drepo.PageRequest{})
 		assert.NoError(t, err)
-		assert.NotEmpty(t, results)
+		assert.NotEmpty(t, results.Tasks)
+	})
+
+	t.Run("Search_Ranking", func(t *testing.T) {
+		inTitle := &model.Task{ID: uuid.New(), UserID: uid, Title: "Квартальный отчет", Content: "Свести цифры"}
+		inContent := &model.Task{ID: uuid.New(), UserID: uid, Title: "Финансы", Content: "Подготовить отчеты для <банка>"}
+		inComment := &model.Task{ID: uuid.New(), UserID: uid, Title: "Созвон"}
+		require.NoError(t, repo.Create(ctx, inTitle))
+		require.NoError(t, repo.Create(ctx, inContent))
+		require.NoError(t, repo.Create(ctx, inComment))
+		require.NoError(t, db.Create(&model.Comment{ID: uuid.New(), TaskID: inComment.ID, AuthorID: uid, Body: "Нужен отчет к пятнице"}).Error)
+
+		// Другая форма слова, совпадения в заголовке выше совпадений в тексте и комментариях
+		res, err := repo.Search(ctx, "отчеты ", userID, drepo.PageRequest{Desc: true})
+		require.NoError(t, err)
+		require.Len(t, res.Tasks, 3)
+		assert.Equal(t, []uuid.UUID{inTitle.ID, inContent.ID, inComment.ID}, []uuid.UUID{res.Tasks[0].ID, res.Tasks[1].ID, res.Tasks[2].ID})
+		assert.Contains(t, res.Tasks[0].TitleHighlight, model.HighlightStart+"отчет"+model.HighlightStop)
+		assert.Contains(t, res.Tasks[1].ContentSnippet, model.HighlightStart+"отчеты"+model.HighlightStop)
+
+		// Поиск по мере ввода и по тегам
+		res, err = repo.Search(ctx, "кварт", userID, drepo.PageRequest{})
+		require.NoError(t, err)
+		assert.Len(t, res.Tasks, 1)
+
+		_, err = repo.AddTag(ctx, inComment.ID.String(), "quarterly", userID)
+		require.NoError(t, err)
+		res, err = repo.Search(ctx, "Quarterly", userID, drepo.PageRequest{})
+		require.NoError(t, err)
+		require.Len(t, res.Tasks, 1)
+		assert.Equal(t, inComment.ID, res.Tasks[0].ID)
+
+		// Курсор по релевантности не теряет и не повторяет задачи
+		first, err := repo.Search(ctx, "отчет", userID, drepo.PageRequest{Limit: 2, Desc: true})
+		require.NoError(t, err)
+		require.NotEmpty(t, first.NextCursor)
+		rest, err := repo.Search(ctx, "отчет", userID, drepo.PageRequest{Limit: 2, Desc: true, Cursor: first.NextCursor})
+		require.NoError(t, err)
+		require.Len(t, rest.Tasks, 1)
+		assert.Equal(t, inComment.ID, rest.Tasks[0].ID)
+
+		_, err = repo.List(ctx, userID, drepo.TaskFilter{}, drepo.PageRequest{Sort: drepo.SortRelevance})
+		assert.ErrorIs(t, err, drepo.ErrInvalidSort)
 	})
 
 	t.Run("Tags", func(t *testing.T) {
@@ -141,9 +200,29 @@ func TestRepository_Features(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Len(t, updated.Tags, 1)
 
-		tasksByTag, err := repo.FindByTag(ctx, "urgent", userID)
+		tasksByTag, err := repo.FindByTag(ctx, "urgent", userID, drepo.PageRequest{})
 		assert.NoError(t, err)
-		assert.NotEmpty(t, tasksByTag)
+		assert.NotEmpty(t, tasksByTag.Tasks)
+
+		// Одновременное создание одного и того же тега не падает на уникальном индексе
+		var wg sync.WaitGroup
+		errs := make([]error, 4)
+		for i := range errs {
+			other := &model.Task{ID: uuid.New(), UserID: uid, Title: "Race"}
+			require.NoError(t, repo.Create(ctx, other))
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, errs[i] = repo.AddTag(ctx, other.ID.String(), "raced", userID)
+			}(i)
+		}
+		wg.Wait()
+		for _, err := range errs {
+			assert.NoError(t, err)
+		}
+		var tags int64
+		db.Model(&model.Tag{}).Where("user_id = ? AND name = ?", uid, "raced").Count(&tags)
+		assert.Equal(t, int64(1), tags)
 	})
 
 	t.Run("Stats", func(t *testing.T) {
@@ -160,20 +239,28 @@ func TestRepository_TimeQueries(t *testing.T) {
 	ctx := context.Background()
 	uid := uuid.New()
 
-	t.Run("GetToday_And_Overdue", func(t *testing.T) {
+	t.Run("Today_And_Overdue", func(t *testing.T) {
 		now := time.Now()
 		repo.Create(ctx, &model.Task{ID: uuid.New(), UserID: uid, Title: "Today", DueDate: &now, Status: "todo"})
 
 		yesterday := now.AddDate(0, 0, -1)
 		repo.Create(ctx, &model.Task{ID: uuid.New(), UserID: uid, Title: "Old", DueDate: &yesterday, Status: "todo"})
 
-		todayTasks, err := repo.GetToday(ctx, uid.String())
+		start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		end := start.AddDate(0, 0, 1)
+		todayTasks, err := repo.List(ctx, uid.String(), drepo.TaskFilter{DueFrom: &start, DueBefore: &end}, drepo.PageRequest{})
+		assert.NoError(t, err)
+		assert.Len(t, todayTasks.Tasks, 1)
+
+		overdueTasks, err := repo.List(ctx, uid.String(), drepo.TaskFilter{DueBefore: &now, ExcludeStatuses: []string{"done"}}, drepo.PageRequest{})
 		assert.NoError(t, err)
-		assert.Len(t, todayTasks, 1)
+		assert.NotEmpty(t, overdueTasks.Tasks)
 
-		overdueTasks, err := repo.GetOverdue(ctx, uid.String())
+		// Без проекта
+		noProject := false
+		inbox, err := repo.List(ctx, uid.String(), drepo.TaskFilter{HasProject: &noProject}, drepo.PageRequest{})
 		assert.NoError(t, err)
-		assert.NotEmpty(t, overdueTasks)
+		assert.Len(t, inbox.Tasks, 2)
 	})
 }
 
@@ -183,15 +270,24 @@ func TestRepository_BulkOperations(t *testing.T) {
 	ctx := context.Background()
 	uid := uuid.New()
 
-	id1, id2 := uuid.New(), uuid.New()
+	id1, id2, sub := uuid.New(), uuid.New(), uuid.New()
 	repo.Create(ctx, &model.Task{ID: id1, UserID: uid, Status: "todo"})
 	repo.Create(ctx, &model.Task{ID: id2, UserID: uid, Status: "todo"})
+	repo.Create(ctx, &model.Task{ID: sub, UserID: uid, ParentID: &id1, Status: "in_progress"})
 
-	err := repo.BulkUpdateStatus(ctx, []string{id1.String(), id2.String()}, "done", uid.String())
+	changed, err := repo.UpdateStatus(ctx, []string{id1.String(), id2.String()}, "done", uid.String(), allowAll)
 	assert.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{id1, id2}, changed)
+	// Повторная смена статуса ничего не меняет
+	changed, err = repo.UpdateStatus(ctx, []string{id1.String()}, "done", uid.String(), allowAll)
+	assert.NoError(t, err)
+	assert.Empty(t, changed)
 
 	task, _ := repo.GetByID(ctx, id1.String(), uid.String())
 	assert.Equal(t, "done", task.Status)
+	// Подзадачи завершаются вместе с родителем, как и при смене статуса одной задачи
+	task, _ = repo.GetByID(ctx, sub.String(), uid.String())
+	assert.Equal(t, "done", task.Status)
 }
 
 func TestRepository_Archive(t *testing.T) {
@@ -226,7 +322,7 @@ func TestRepository_CRUD_Operations(t *testing.T) {
 		repo.Create(ctx, task)
 
 		task.Title = "Updated Title"
-		err := repo.Update(ctx, task)
+		err := repo.Update(ctx, task, uid.String())
 		assert.NoError(t, err)
 
 		saved, _ := repo.GetByID(ctx, tid.String(), uid.String())
@@ -257,18 +353,18 @@ func TestRepository_Filtering_And_Tags(t *testing.T) {
 		repo.Create(ctx, &model.Task{ID: uuid.New(), UserID: uid, Status: "done", Priority: "high"})
 
 		// Test GetAll
-		all, _ := repo.GetAll(ctx, userID)
-		assert.Len(t, all, 2)
+		all, _ := repo.GetAll(ctx, userID, drepo.PageRequest{})
+		assert.Len(t, all.Tasks, 2)
 
 		// Test FindByStatus
-		todoTasks, _ := repo.FindByStatus(ctx, "todo", userID)
-		assert.Len(t, todoTasks, 1)
-		assert.Equal(t, "todo", todoTasks[0].Status)
+		todoTasks, _ := repo.FindByStatus(ctx, "todo", userID, drepo.PageRequest{})
+		assert.Len(t, todoTasks.Tasks, 1)
+		assert.Equal(t, "todo", todoTasks.Tasks[0].Status)
 
 		// Test FindByPriority
-		highTasks, _ := repo.FindByPriority(ctx, "high", userID)
-		assert.Len(t, highTasks, 1)
-		assert.Equal(t, "high", highTasks[0].Priority)
+		highTasks, _ := repo.FindByPriority(ctx, "high", userID, drepo.PageRequest{})
+		assert.Len(t, highTasks.Tasks, 1)
+		assert.Equal(t, "high", highTasks.Tasks[0].Priority)
 	})
 
 	t.Run("RemoveTag_Integration", func(t *testing.T) {
@@ -281,3 +377,825 @@ func TestRepository_Filtering_And_Tags(t *testing.T) {
 		assert.Len(t, res.Tags, 0)
 	})
 }
+
+func TestRepository_Pagination(t *testing.T) {
+	db := setupRealDB(t)
+	repo := NewTaskRepository(db)
+	ctx := context.Background()
+	uid := uuid.New()
+
+	for _, title := range []string{"C", "A", "B"} {
+		repo.Create(ctx, &model.Task{ID: uuid.New(), UserID: uid, Title: title})
+	}
+
+	first, err := repo.GetAll(ctx, uid.String(), drepo.PageRequest{Limit: 2, Sort: drepo.SortTitle})
+	require.NoError(t, err)
+	require.Len(t, first.Tasks, 2)
+	assert.Equal(t, "A", first.Tasks[0].Title)
+	assert.NotEmpty(t, first.NextCursor)
+
+	second, err := repo.GetAll(ctx, uid.String(), drepo.PageRequest{Limit: 2, Sort: drepo.SortTitle, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, second.Tasks, 1)
+	assert.Equal(t, "C", second.Tasks[0].Title)
+	assert.Empty(t, second.NextCursor)
+}
+
+func TestRepository_ListWithFilter(t *testing.T) {
+	db := setupRealDB(t)
+	repo := NewTaskRepository(db)
+	ctx := context.Background()
+	uid := uuid.New()
+	userID := uid.String()
+
+	soon := time.Now().Add(48 * time.Hour)
+	match := &model.Task{ID: uuid.New(), UserID: uid, Title: "Match", Status: "todo", Priority: "high", DueDate: &soon}
+	repo.Create(ctx, match)
+	repo.AddTag(ctx, match.ID.String(), "work", userID)
+	repo.Create(ctx, &model.Task{ID: uuid.New(), UserID: uid, Title: "Low", Status: "todo", Priority: "low", DueDate: &soon})
+	repo.Create(ctx, &model.Task{ID: uuid.New(), UserID: uid, Title: "Archived", Status: "todo", Priority: "high", Archived: true})
+
+	archived := false
+	weekAhead := time.Now().AddDate(0, 0, 7)
+	res, err := repo.List(ctx, userID, drepo.TaskFilter{
+		Statuses:   []string{"todo"},
+		Priorities: []string{"high"},
+		TagGroups:  [][]string{{"work"}},
+		DueBefore:  &weekAhead,
+		Archived:   &archived,
+	}, drepo.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, res.Tasks, 1)
+	assert.Equal(t, "Match", res.Tasks[0].Title)
+}
+
+func TestRepository_Tags(t *testing.T) {
+	db := setupRealDB(t)
+	repo := NewTaskRepository(db)
+	tags := NewTagRepository(db)
+	ctx := context.Background()
+	uid := uuid.New()
+	userID := uid.String()
+	other := uuid.New()
+
+	task := &model.Task{ID: uuid.New(), UserID: uid, Title: "Tagged", Status: "todo", Priority: "low"}
+	repo.Create(ctx, task)
+	_, err := repo.AddTag(ctx, task.ID.String(), "work", userID)
+	require.NoError(t, err)
+	_, err = repo.AddTag(ctx, task.ID.String(), "job", userID)
+	require.NoError(t, err)
+
+	// Одноимённый тег другого пользователя не конфликтует
+	require.NoError(t, tags.Create(ctx, &model.Tag{UserID: other, Name: "work"}))
+	assert.ErrorIs(t, tags.Create(ctx, &model.Tag{UserID: uid, Name: "work"}), drepo.ErrTagExists)
+
+	usage, err := tags.List(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, usage, 2)
+	assert.Equal(t, "job", usage[0].Tag.Name)
+	assert.Equal(t, int64(1), usage[1].TaskCount)
+
+	merged, err := tags.Merge(ctx, usage[0].Tag.ID, usage[1].Tag.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{task.ID}, merged)
+	usage, err = tags.List(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, usage, 1)
+	assert.Equal(t, int64(1), usage[0].TaskCount)
+
+	_, err = tags.Delete(ctx, usage[0].Tag.ID, other.String())
+	assert.ErrorIs(t, err, drepo.ErrTagNotFound)
+	deleted, err := tags.Delete(ctx, usage[0].Tag.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{task.ID}, deleted)
+	got, err := repo.GetByID(ctx, task.ID.String(), userID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Tags)
+
+	// Слияние и удаление тега видны в истории задачи
+	revisions, err := repo.ListRevisions(ctx, task.ID.String(), userID)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(revisions), 2)
+	assert.Equal(t, "tags", revisions[0].Changes[0].Field)
+	assert.JSONEq(t, `[]`, string(revisions[0].Changes[0].New))
+	assert.JSONEq(t, `["work"]`, string(revisions[1].Changes[0].New))
+}
+
+func TestRepository_Hierarchy(t *testing.T) {
+	db := setupRealDB(t)
+	repo := NewTaskRepository(db)
+	ctx := context.Background()
+	uid := uuid.New()
+	userID := uid.String()
+
+	root := &model.Task{ID: uuid.New(), UserID: uid, Title: "Root", Status: "todo"}
+	repo.Create(ctx, root)
+	child := &model.Task{ID: uuid.New(), UserID: uid, ParentID: &root.ID, Title: "Child", Status: "todo"}
+	repo.Create(ctx, child)
+	leaf := &model.Task{ID: uuid.New(), UserID: uid, ParentID: &child.ID, Title: "Leaf", Status: "todo"}
+	repo.Create(ctx, leaf)
+
+	subtree, err := repo.GetSubtree(ctx, root.ID.String(), userID)
+	require.NoError(t, err)
+	assert.Len(t, subtree, 3)
+
+	rootID := root.ID.String()
+	_, err = repo.Move(ctx, rootID, ptr(leaf.ID.String()), userID)
+	assert.ErrorIs(t, err, drepo.ErrHierarchyCycle)
+
+	moved, err := repo.Move(ctx, leaf.ID.String(), &rootID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, root.ID, *moved.ParentID)
+
+	current, err := repo.GetByID(ctx, rootID, userID)
+	require.NoError(t, err)
+	current.SetStatus(model.StatusDone, time.Now())
+	require.NoError(t, repo.Complete(ctx, &current, []string{"status", "started_at", "completed_at"}, nil, userID))
+	got, _ := repo.GetByID(ctx, child.ID.String(), userID)
+	assert.Equal(t, "done", got.Status)
+
+	_, err = repo.Archive(ctx, rootID, userID)
+	require.NoError(t, err)
+	got, _ = repo.GetByID(ctx, leaf.ID.String(), userID)
+	assert.True(t, got.Archived)
+
+	require.NoError(t, repo.Delete(ctx, rootID, userID))
+	_, err = repo.GetByID(ctx, child.ID.String(), userID)
+	assert.Error(t, err)
+}
+
+func ptr(s string) *string { return &s }
+
+func allowAll(model.Task) error { return nil }
+
+func TestRepository_Dependencies(t *testing.T) {
+	db := setupRealDB(t)
+	repo := NewTaskRepository(db)
+	ctx := context.Background()
+	uid := uuid.New()
+	userID := uid.String()
+
+	design := &model.Task{ID: uuid.New(), UserID: uid, Title: "Design", Status: "todo"}
+	build := &model.Task{ID: uuid.New(), UserID: uid, Title: "Build", Status: "todo"}
+	ship := &model.Task{ID: uuid.New(), UserID: uid, Title: "Ship", Status: "in_progress"}
+	for _, task := range []*model.Task{design, build, ship} {
+		require.NoError(t, repo.Create(ctx, task))
+	}
+
+	require.NoError(t, repo.AddDependency(ctx, design.ID.String(), build.ID.String(), userID))
+	require.NoError(t, repo.AddDependency(ctx, build.ID.String(), ship.ID.String(), userID))
+	assert.ErrorIs(t, repo.AddDependency(ctx, ship.ID.String(), design.ID.String(), userID), drepo.ErrDependencyCycle)
+
+	got, _ := repo.GetByID(ctx, ship.ID.String(), userID)
+	assert.Equal(t, "blocked", got.Status)
+
+	graph, err := repo.GetDependencyGraph(ctx, build.ID.String(), userID)
+	require.NoError(t, err)
+	assert.Len(t, graph.Tasks, 3)
+	assert.Len(t, graph.Edges, 2)
+
+	// Завершение блокера разблокирует зависимую задачу
+	design.Status = "done"
+	require.NoError(t, repo.Update(ctx, design, userID))
+	got, _ = repo.GetByID(ctx, build.ID.String(), userID)
+	assert.Equal(t, "todo", got.Status)
+
+	blockedShip, _ := repo.GetByID(ctx, ship.ID.String(), userID)
+	require.NoError(t, repo.RemoveDependency(ctx, build.ID.String(), ship.ID.String(), userID))
+	got, _ = repo.GetByID(ctx, ship.ID.String(), userID)
+	assert.Equal(t, "todo", got.Status)
+	// Снятие блокировки меняет версию и попадает в историю
+	assert.Greater(t, got.Version, blockedShip.Version)
+	revisions, err := repo.ListRevisions(ctx, ship.ID.String(), userID)
+	require.NoError(t, err)
+	require.NotEmpty(t, revisions)
+	assert.Equal(t, "status", revisions[0].Changes[0].Field)
+	assert.JSONEq(t, `"todo"`, string(revisions[0].Changes[0].New))
+}
+
+func TestRepository_ReminderLease(t *testing.T) {
+	db := setupRealDB(t)
+	tasks := NewTaskRepository(db)
+	reminders := NewReminderRepository(db)
+	ctx := context.Background()
+	uid := uuid.New()
+
+	due := time.Now().Add(30 * time.Minute)
+	task := &model.Task{ID: uuid.New(), UserID: uid, Title: "Call", Status: "todo", DueDate: &due}
+	require.NoError(t, tasks.Create(ctx, task))
+	offset := 60
+	rem := &model.Reminder{ID: uuid.New(), TaskID: task.ID, UserID: uid, OffsetMinutes: &offset, Channel: model.ReminderChannelEmail}
+	require.NoError(t, reminders.Create(ctx, rem))
+
+	now := time.Now()
+	claimed, err := reminders.ClaimDue(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, 1, claimed[0].Reminder.Attempts)
+
+	// Пока аренда активна, второй инстанс ничего не получает
+	again, err := reminders.ClaimDue(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+
+	// После истечения аренды напоминание снова доступно
+	again, err = reminders.ClaimDue(ctx, now.Add(2*time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, again, 1)
+
+	require.NoError(t, reminders.MarkSent(ctx, rem.ID, now))
+	again, err = reminders.ClaimDue(ctx, now.Add(time.Hour), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+}
+
+func TestRepository_WebhookDeliveries(t *testing.T) {
+	db := setupRealDB(t)
+	webhooks := NewWebhookRepository(db)
+	ctx := context.Background()
+	uid := uuid.New()
+
+	sub := &model.WebhookSubscription{ID: uuid.New(), UserID: uid, URL: "https://hooks.example.com", Secret: "whsec_test", Events: "*", Active: true}
+	require.NoError(t, webhooks.Create(ctx, sub))
+
+	now := time.Now()
+	d := model.WebhookDelivery{ID: uuid.New(), SubscriptionID: sub.ID, UserID: uid, EventID: uuid.New(),
+		EventType: "task.created", Payload: "{}", Status: model.WebhookDeliveryPending, NextAttemptAt: now}
+	require.NoError(t, webhooks.CreateDeliveries(ctx, []model.WebhookDelivery{d}))
+
+	claimed, err := webhooks.ClaimDeliveries(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, "whsec_test", claimed[0].Secret)
+	assert.Equal(t, 1, claimed[0].Delivery.Attempts)
+
+	// Пока аренда активна, доставка никому не выдается
+	again, err := webhooks.ClaimDeliveries(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+
+	retryAt := now.Add(30 * time.Second)
+	require.NoError(t, webhooks.MarkDeliveryFailed(ctx, d.ID, 503, "503", &retryAt))
+
+	// Приостановленная подписка не получает доставок
+	sub.Active = false
+	require.NoError(t, webhooks.Update(ctx, sub))
+	again, err = webhooks.ClaimDeliveries(ctx, now.Add(time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+
+	log, err := webhooks.ListDeliveries(ctx, sub.ID.String(), uid.String(), 10)
+	require.NoError(t, err)
+	require.Len(t, log, 1)
+	assert.Equal(t, 503, log[0].ResponseStatus)
+
+	require.NoError(t, webhooks.Delete(ctx, sub.ID.String(), uid.String()))
+	_, err = webhooks.GetDelivery(ctx, d.ID.String(), sub.ID.String(), uid.String())
+	assert.ErrorIs(t, err, drepo.ErrDeliveryNotFound)
+}
+
+func TestRepository_Projects(t *testing.T) {
+	db := setupRealDB(t)
+	tasks := NewTaskRepository(db)
+	projects := NewProjectRepository(db)
+	ctx := context.Background()
+	uid := uuid.New()
+	userID := uid.String()
+
+	work := &model.Project{ID: uuid.New(), UserID: uid, Name: "Work"}
+	require.NoError(t, projects.Create(ctx, work))
+	home := &model.Project{ID: uuid.New(), UserID: uid, Name: "Home"}
+	require.NoError(t, projects.Create(ctx, home))
+	assert.Equal(t, work.Position+1, home.Position)
+	assert.ErrorIs(t, projects.Create(ctx, &model.Project{ID: uuid.New(), UserID: uid, Name: "Work"}), drepo.ErrProjectExists)
+
+	root := &model.Task{ID: uuid.New(), UserID: uid, Title: "Root", Status: "todo"}
+	require.NoError(t, tasks.Create(ctx, root))
+	child := &model.Task{ID: uuid.New(), UserID: uid, ParentID: &root.ID, Title: "Child", Status: "done"}
+	require.NoError(t, tasks.Create(ctx, child))
+
+	// Подзадачи переезжают в проект вместе с родителем
+	workID := work.ID.String()
+	moved, err := projects.MoveTasks(ctx, []string{root.ID.String()}, &workID, userID)
+	require.NoError(t, err)
+	assert.Len(t, moved, 2)
+
+	stats, err := projects.Stats(ctx, workID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats["todo"])
+	assert.Equal(t, int64(1), stats["done"])
+	assert.Equal(t, int64(2), stats["total"])
+
+	require.NoError(t, projects.Reorder(ctx, userID, []string{home.ID.String(), workID}))
+	assert.ErrorIs(t, projects.Reorder(ctx, userID, []string{workID}), drepo.ErrProjectOrder)
+	list, err := projects.List(ctx, userID, false)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, home.ID, list[0].ID)
+
+	// Удаление проекта возвращает задачи во входящие
+	require.NoError(t, projects.Delete(ctx, workID, userID))
+	got, err := tasks.GetByID(ctx, child.ID.String(), userID)
+	require.NoError(t, err)
+	assert.Nil(t, got.ProjectID)
+}
+
+func TestRepository_Workspaces(t *testing.T) {
+	db := setupRealDB(t)
+	tasks := NewTaskRepository(db)
+	workspaces := NewWorkspaceRepository(db)
+	ctx := context.Background()
+
+	alice := model.User{ID: uuid.New(), Email: "alice@example.com", PasswordHash: "x"}
+	bob := model.User{ID: uuid.New(), Email: "bob@example.com", PasswordHash: "x"}
+	require.NoError(t, db.Create(&alice).Error)
+	require.NoError(t, db.Create(&bob).Error)
+
+	ws := &model.Workspace{ID: uuid.New(), Name: "Team", CreatedBy: alice.ID}
+	require.NoError(t, workspaces.Create(ctx, ws))
+	wsID := ws.ID.String()
+
+	role, err := workspaces.Role(ctx, wsID, bob.ID.String())
+	assert.ErrorIs(t, err, drepo.ErrWorkspaceNotFound)
+	assert.Empty(t, role)
+
+	member, err := workspaces.AddMember(ctx, wsID, "BOB@example.com", model.RoleViewer)
+	require.NoError(t, err)
+	assert.Equal(t, bob.ID, member.UserID)
+	_, err = workspaces.AddMember(ctx, wsID, "bob@example.com", model.RoleEditor)
+	assert.ErrorIs(t, err, drepo.ErrMemberExists)
+
+	shared := &model.Task{ID: uuid.New(), UserID: alice.ID, WorkspaceID: &ws.ID, Title: "Shared", Status: "todo"}
+	require.NoError(t, tasks.Create(ctx, shared))
+	private := &model.Task{ID: uuid.New(), UserID: alice.ID, Title: "Private", Status: "todo"}
+	require.NoError(t, tasks.Create(ctx, private))
+
+	// Участник видит задачи пространства, но не личные задачи других
+	got, err := tasks.GetByID(ctx, shared.ID.String(), bob.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "Shared", got.Title)
+	_, err = tasks.GetByID(ctx, private.ID.String(), bob.ID.String())
+	assert.Error(t, err)
+	res, err := tasks.List(ctx, bob.ID.String(), drepo.TaskFilter{}, drepo.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, res.Tasks, 1)
+
+	_, err = tasks.Move(ctx, private.ID.String(), ptr(shared.ID.String()), alice.ID.String())
+	assert.ErrorIs(t, err, drepo.ErrCrossWorkspace)
+
+	assert.ErrorIs(t, workspaces.RemoveMember(ctx, wsID, alice.ID.String()), drepo.ErrLastOwner)
+	_, err = workspaces.UpdateMemberRole(ctx, wsID, bob.ID.String(), model.RoleOwner)
+	require.NoError(t, err)
+	require.NoError(t, workspaces.RemoveMember(ctx, wsID, alice.ID.String()))
+
+	list, err := workspaces.List(ctx, bob.ID.String())
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, int64(1), list[0].MemberCount)
+
+	require.NoError(t, workspaces.Delete(ctx, wsID))
+	_, err = tasks.GetByID(ctx, shared.ID.String(), alice.ID.String())
+	assert.Error(t, err)
+}
+
+func TestRepository_WorkspaceInvitations(t *testing.T) {
+	db := setupRealDB(t)
+	workspaces := NewWorkspaceRepository(db)
+	ctx := context.Background()
+
+	alice := model.User{ID: uuid.New(), Email: "alice@example.com", PasswordHash: "x"}
+	bob := model.User{ID: uuid.New(), Email: "Bob@example.com", PasswordHash: "x"}
+	require.NoError(t, db.Create(&alice).Error)
+	require.NoError(t, db.Create(&bob).Error)
+
+	ws := &model.Workspace{ID: uuid.New(), Name: "Team", CreatedBy: alice.ID}
+	require.NoError(t, workspaces.Create(ctx, ws))
+	wsID := ws.ID.String()
+
+	invite := func(email string) model.WorkspaceInvitation {
+		inv := model.WorkspaceInvitation{
+			ID: uuid.New(), WorkspaceID: ws.ID, Email: email, Role: model.RoleViewer,
+			Status: model.InvitationPending, InvitedBy: alice.ID, ExpiresAt: time.Now().Add(time.Hour),
+		}
+		require.NoError(t, workspaces.CreateInvitation(ctx, &inv))
+		return inv
+	}
+
+	// Повторное приглашение на тот же адрес отзывает предыдущее
+	first := invite("bob@example.com")
+	second := invite("bob@example.com")
+	pending, err := workspaces.ListInvitations(ctx, wsID)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, second.ID, pending[0].ID)
+	_, err = workspaces.AcceptInvitation(ctx, first.ID.String(), bob.ID.String())
+	assert.ErrorIs(t, err, drepo.ErrInvitationClosed)
+
+	_, err = workspaces.AcceptInvitation(ctx, second.ID.String(), alice.ID.String())
+	assert.ErrorIs(t, err, drepo.ErrInvitationEmail)
+	member, err := workspaces.AcceptInvitation(ctx, second.ID.String(), bob.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, model.RoleViewer, member.Role)
+	assert.ErrorIs(t, workspaces.DeclineInvitation(ctx, second.ID.String()), drepo.ErrInvitationClosed)
+
+	carol := invite("carol@example.com")
+	require.NoError(t, workspaces.RevokeInvitation(ctx, wsID, carol.ID.String()))
+	assert.ErrorIs(t, workspaces.RevokeInvitation(ctx, wsID, carol.ID.String()), drepo.ErrInvitationNotFound)
+	got, err := workspaces.GetInvitation(ctx, carol.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, model.InvitationRevoked, got.Status)
+}
+
+func TestRepository_Assignments(t *testing.T) {
+	db := setupRealDB(t)
+	tasks := NewTaskRepository(db)
+	workspaces := NewWorkspaceRepository(db)
+	ctx := context.Background()
+
+	alice := model.User{ID: uuid.New(), Email: "alice@example.com", PasswordHash: "x"}
+	bob := model.User{ID: uuid.New(), Email: "bob@example.com", PasswordHash: "x"}
+	require.NoError(t, db.Create(&alice).Error)
+	require.NoError(t, db.Create(&bob).Error)
+
+	ws := &model.Workspace{ID: uuid.New(), Name: "Team", CreatedBy: alice.ID}
+	require.NoError(t, workspaces.Create(ctx, ws))
+	_, err := workspaces.AddMember(ctx, ws.ID.String(), bob.Email, model.RoleEditor)
+	require.NoError(t, err)
+
+	shared := &model.Task{ID: uuid.New(), UserID: alice.ID, WorkspaceID: &ws.ID, Title: "Shared", Status: "todo"}
+	private := &model.Task{ID: uuid.New(), UserID: alice.ID, Title: "Private", Status: "todo"}
+	require.NoError(t, tasks.Create(ctx, shared))
+	require.NoError(t, tasks.Create(ctx, private))
+
+	// Назначить можно только того, кто видит задачу
+	_, err = tasks.AddAssignee(ctx, private.ID.String(), bob.ID.String(), alice.ID.String())
+	assert.ErrorIs(t, err, drepo.ErrAssigneeAccess)
+	got, err := tasks.AddAssignee(ctx, shared.ID.String(), bob.ID.String(), alice.ID.String())
+	require.NoError(t, err)
+	require.Len(t, got.Assignees, 1)
+	assert.Equal(t, alice.ID, got.Assignees[0].AssignedBy)
+	_, err = tasks.AddAssignee(ctx, shared.ID.String(), bob.ID.String(), alice.ID.String())
+	require.NoError(t, err)
+
+	_, err = tasks.AddWatcher(ctx, private.ID.String(), alice.ID.String())
+	require.NoError(t, err)
+
+	assigned, err := tasks.List(ctx, bob.ID.String(), drepo.TaskFilter{AssigneeID: &bob.ID}, drepo.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, assigned.Tasks, 1)
+	assert.Equal(t, shared.ID, assigned.Tasks[0].ID)
+	watching, err := tasks.List(ctx, alice.ID.String(), drepo.TaskFilter{WatcherID: &alice.ID}, drepo.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, watching.Tasks, 1)
+	assert.Equal(t, private.ID, watching.Tasks[0].ID)
+
+	// Обновление задачи не трогает назначения
+	got.Title = "Renamed"
+	got.Assignees = nil
+	require.NoError(t, tasks.Update(ctx, &got, alice.ID.String()))
+	got, err = tasks.GetByID(ctx, shared.ID.String(), bob.ID.String())
+	require.NoError(t, err)
+	assert.Len(t, got.Assignees, 1)
+
+	got, err = tasks.RemoveAssignee(ctx, shared.ID.String(), bob.ID.String(), bob.ID.String())
+	require.NoError(t, err)
+	assert.Empty(t, got.Assignees)
+}
+
+func TestRepository_Comments(t *testing.T) {
+	db := setupRealDB(t)
+	tasks := NewTaskRepository(db)
+	comments := NewCommentRepository(db)
+	ctx := context.Background()
+
+	alice := model.User{ID: uuid.New(), Email: "alice@example.com", PasswordHash: "x"}
+	bob := model.User{ID: uuid.New(), Email: "bob@example.com", PasswordHash: "x"}
+	require.NoError(t, db.Create(&alice).Error)
+	require.NoError(t, db.Create(&bob).Error)
+
+	task := &model.Task{ID: uuid.New(), UserID: alice.ID, Title: "Discuss", Status: "todo"}
+	require.NoError(t, tasks.Create(ctx, task))
+
+	// Для личной задачи упомянуть можно только владельца
+	candidates, err := comments.MentionCandidates(ctx, *task)
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+	assert.Equal(t, alice.Email, candidates[0].Email)
+
+	first := &model.Comment{ID: uuid.New(), TaskID: task.ID, AuthorID: alice.ID, Body: "first"}
+	second := &model.Comment{ID: uuid.New(), TaskID: task.ID, AuthorID: alice.ID, Body: "@alice second"}
+	second.Mentions = []model.CommentMention{{CommentID: second.ID, UserID: alice.ID}}
+	require.NoError(t, comments.Create(ctx, first))
+	require.NoError(t, comments.Create(ctx, second))
+
+	got, err := tasks.GetByID(ctx, task.ID.String(), alice.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), got.CommentCount)
+
+	second.Body = "second"
+	second.Mentions = nil
+	require.NoError(t, comments.Update(ctx, second))
+	c, err := comments.GetByID(ctx, second.ID.String(), task.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "second", c.Body)
+	assert.Empty(t, c.Mentions)
+
+	// Удаление мягкое: комментарий пропадает из списка и из счётчика
+	require.NoError(t, comments.Delete(ctx, first.ID.String(), task.ID.String()))
+	assert.ErrorIs(t, comments.Delete(ctx, first.ID.String(), task.ID.String()), drepo.ErrCommentNotFound)
+	list, err := comments.ListByTask(ctx, task.ID.String())
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, second.ID, list[0].ID)
+	var stored int64
+	db.Unscoped().Model(&model.Comment{}).Where("task_id = ?", task.ID).Count(&stored)
+	assert.Equal(t, int64(2), stored)
+
+	page, err := tasks.List(ctx, alice.ID.String(), drepo.TaskFilter{}, drepo.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Tasks, 1)
+	assert.Equal(t, int64(1), page.Tasks[0].CommentCount)
+}
+
+func TestRepository_Attachments(t *testing.T) {
+	db := setupRealDB(t)
+	tasks := NewTaskRepository(db)
+	attachments := NewAttachmentRepository(db)
+	ctx := context.Background()
+
+	alice := model.User{ID: uuid.New(), Email: "alice@example.com", PasswordHash: "x"}
+	require.NoError(t, db.Create(&alice).Error)
+	kept := &model.Task{ID: uuid.New(), UserID: alice.ID, Title: "Kept", Status: "todo"}
+	purged := &model.Task{ID: uuid.New(), UserID: alice.ID, Title: "Purged", Status: "todo"}
+	require.NoError(t, tasks.Create(ctx, kept))
+	require.NoError(t, tasks.Create(ctx, purged))
+
+	var seen []int64
+	for _, task := range []*model.Task{kept, purged} {
+		a := &model.Attachment{ID: uuid.New(), TaskID: task.ID, UploadedBy: alice.ID, Name: "f.png", ContentType: "image/png", Size: 100}
+		a.StorageKey = model.AttachmentKey(task.ID, a.ID)
+		require.NoError(t, attachments.Create(ctx, a, func(used int64) error {
+			seen = append(seen, used)
+			return nil
+		}))
+	}
+	assert.Equal(t, []int64{0, 100}, seen)
+
+	// Отказ проверки квоты не сохраняет вложение
+	over := errors.New("over quota")
+	extra := &model.Attachment{ID: uuid.New(), TaskID: kept.ID, UploadedBy: alice.ID, Name: "g.png", ContentType: "image/png", Size: 100}
+	extra.StorageKey = model.AttachmentKey(kept.ID, extra.ID)
+	assert.ErrorIs(t, attachments.Create(ctx, extra, func(int64) error { return over }), over)
+
+	used, err := attachments.UsageByUser(ctx, alice.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, int64(200), used)
+
+	// Мягко удалённая задача сохраняет файлы, стёртая из базы — нет
+	require.NoError(t, tasks.Delete(ctx, purged.ID.String(), alice.ID.String()))
+	orphans, err := attachments.Orphans(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, orphans)
+
+	require.NoError(t, db.Unscoped().Delete(&model.Task{}, "id = ?", purged.ID).Error)
+	orphans, err = attachments.Orphans(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, orphans, 1)
+	assert.Equal(t, purged.ID, orphans[0].TaskID)
+
+	require.NoError(t, attachments.Delete(ctx, orphans[0].ID.String(), purged.ID.String()))
+	assert.ErrorIs(t, attachments.Delete(ctx, orphans[0].ID.String(), purged.ID.String()), drepo.ErrAttachmentNotFound)
+	list, err := attachments.ListByTask(ctx, kept.ID.String())
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+}
+
+func TestRepository_History(t *testing.T) {
+	db := setupRealDB(t)
+	tasks := NewTaskRepository(db)
+	ctx := context.Background()
+
+	alice := model.User{ID: uuid.New(), Email: "alice@example.com", PasswordHash: "x"}
+	require.NoError(t, db.Create(&alice).Error)
+	userID := alice.ID.String()
+	task := &model.Task{ID: uuid.New(), UserID: alice.ID, Title: "Draft", Status: "todo", Priority: "medium"}
+	require.NoError(t, tasks.Create(ctx, task))
+
+	task.Title = "Final"
+	task.Content = "Text"
+	require.NoError(t, tasks.Update(ctx, task, userID))
+	// Сохранение без изменений не создаёт ревизию
+	require.NoError(t, tasks.Update(ctx, task, userID))
+	_, err := tasks.AddTag(ctx, task.ID.String(), "work", userID)
+	require.NoError(t, err)
+	_, err = tasks.Archive(ctx, task.ID.String(), userID)
+	require.NoError(t, err)
+
+	history, err := tasks.ListRevisions(ctx, task.ID.String(), userID)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, []int{3, 2, 1}, []int{history[0].Revision, history[1].Revision, history[2].Revision})
+	assert.Equal(t, "archived", history[0].Changes[0].Field)
+	assert.Equal(t, "tags", history[1].Changes[0].Field)
+	assert.JSONEq(t, `["work"]`, string(history[1].Changes[0].New))
+	require.Len(t, history[2].Changes, 2)
+	assert.JSONEq(t, `"Draft"`, string(history[2].Changes[0].Old))
+	assert.Equal(t, alice.ID, history[2].ChangedBy)
+
+	_, err = tasks.ListRevisions(ctx, task.ID.String(), uuid.New().String())
+	assert.Error(t, err)
+}
+
+func TestRepository_Trash(t *testing.T) {
+	db := setupRealDB(t)
+	tasks := NewTaskRepository(db)
+	ctx := context.Background()
+
+	alice := model.User{ID: uuid.New(), Email: "alice@example.com", PasswordHash: "x"}
+	require.NoError(t, db.Create(&alice).Error)
+	userID := alice.ID.String()
+	parent := &model.Task{ID: uuid.New(), UserID: alice.ID, Title: "Parent", Status: "todo", Priority: "medium"}
+	require.NoError(t, tasks.Create(ctx, parent))
+	child := &model.Task{ID: uuid.New(), UserID: alice.ID, ParentID: &parent.ID, Title: "Child", Status: "todo", Priority: "medium"}
+	require.NoError(t, tasks.Create(ctx, child))
+
+	// В корзине видна только удалённая задача, подзадачи уходят вместе с ней
+	require.NoError(t, tasks.Delete(ctx, parent.ID.String(), userID))
+	page, err := tasks.ListTrash(ctx, userID, drepo.PageRequest{Desc: true})
+	require.NoError(t, err)
+	require.Len(t, page.Tasks, 1)
+	assert.Equal(t, parent.ID, page.Tasks[0].ID)
+	assert.True(t, page.Tasks[0].DeletedAt.Valid)
+
+	require.NoError(t, tasks.Restore(ctx, []string{parent.ID.String()}, userID))
+	_, err = tasks.GetByID(ctx, child.ID.String(), userID)
+	require.NoError(t, err)
+
+	// Подзадача, удалённая раньше родителя, остаётся в корзине после его восстановления
+	require.NoError(t, tasks.Delete(ctx, child.ID.String(), userID))
+	require.NoError(t, tasks.Delete(ctx, parent.ID.String(), userID))
+	require.NoError(t, tasks.Restore(ctx, []string{parent.ID.String()}, userID))
+	page, err = tasks.ListTrash(ctx, userID, drepo.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, page.Tasks, 1)
+	assert.Equal(t, child.ID, page.Tasks[0].ID)
+
+	_, err = tasks.GetDeleted(ctx, child.ID.String(), uuid.New().String())
+	assert.Error(t, err)
+	require.NoError(t, tasks.Purge(ctx, []string{child.ID.String()}, userID))
+	var count int64
+	db.Unscoped().Model(&model.Task{}).Where("id = ?", child.ID).Count(&count)
+	assert.Zero(t, count)
+
+	// Задачи с истёкшим сроком хранения стираются вместе с комментариями
+	require.NoError(t, db.Create(&model.Comment{ID: uuid.New(), TaskID: parent.ID, AuthorID: alice.ID, Body: "hi"}).Error)
+	require.NoError(t, tasks.Delete(ctx, parent.ID.String(), userID))
+	n, err := tasks.PurgeDeletedBefore(ctx, time.Now().Add(-time.Hour), 10)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	n, err = tasks.PurgeDeletedBefore(ctx, time.Now().Add(time.Minute), 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	db.Unscoped().Model(&model.Comment{}).Where("task_id = ?", parent.ID).Count(&count)
+	assert.Zero(t, count)
+}
+
+func TestRepository_Versions(t *testing.T) {
+	db := setupRealDB(t)
+	tasks := NewTaskRepository(db)
+	ctx := context.Background()
+
+	alice := model.User{ID: uuid.New(), Email: "alice@example.com", PasswordHash: "x"}
+	require.NoError(t, db.Create(&alice).Error)
+	userID := alice.ID.String()
+	task := &model.Task{ID: uuid.New(), UserID: alice.ID, Title: "Draft", Status: "todo", Priority: "medium"}
+	require.NoError(t, tasks.Create(ctx, task))
+	assert.Equal(t, int64(1), task.Version)
+
+	stale := *task
+	task.Title = "Final"
+	require.NoError(t, tasks.Update(ctx, task, userID))
+	assert.Equal(t, int64(2), task.Version)
+	// Сохранение без изменений не меняет версию
+	require.NoError(t, tasks.Update(ctx, task, userID))
+	assert.Equal(t, int64(2), task.Version)
+
+	// Клиент со старой версией не затирает чужие изменения
+	stale.Title = "Mine"
+	assert.ErrorIs(t, tasks.Update(ctx, &stale, userID), drepo.ErrVersionConflict)
+
+	watched, err := tasks.AddWatcher(ctx, task.ID.String(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), watched.Version)
+	tagged, err := tasks.AddTag(ctx, task.ID.String(), "work", userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), tagged.Version)
+
+	// Комментарии, переименование тега и удаление проекта тоже меняют ответ, а значит и ETag
+	version := func() int64 {
+		got, err := tasks.GetByID(ctx, task.ID.String(), userID)
+		require.NoError(t, err)
+		return got.Version
+	}
+	comments := NewCommentRepository(db)
+	comment := &model.Comment{ID: uuid.New(), TaskID: task.ID, AuthorID: alice.ID, Body: "Looks good"}
+	require.NoError(t, comments.Create(ctx, comment))
+	assert.Equal(t, int64(5), version())
+	require.NoError(t, comments.Delete(ctx, comment.ID.String(), task.ID.String()))
+	assert.Equal(t, int64(6), version())
+
+	tag := tagged.Tags[0]
+	tag.Color = "#ff0000"
+	_, err = NewTagRepository(db).Update(ctx, &tag)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), version())
+	tag.Name = "job"
+	renamed, err := NewTagRepository(db).Update(ctx, &tag)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{task.ID}, renamed)
+	assert.Equal(t, int64(7), version())
+
+	project := &model.Project{ID: uuid.New(), UserID: alice.ID, Name: "Launch"}
+	projects := NewProjectRepository(db)
+	require.NoError(t, projects.Create(ctx, project))
+	require.NoError(t, db.Model(&model.Task{}).Where("id = ?", task.ID).UpdateColumn("project_id", project.ID).Error)
+	require.NoError(t, projects.Delete(ctx, project.ID.String(), userID))
+	assert.Equal(t, int64(8), version())
+}
+
+func TestRepository_UpdateFields(t *testing.T) {
+	db := setupRealDB(t)
+	tasks := NewTaskRepository(db)
+	ctx := context.Background()
+
+	alice := model.User{ID: uuid.New(), Email: "alice@example.com", PasswordHash: "x"}
+	require.NoError(t, db.Create(&alice).Error)
+	userID := alice.ID.String()
+	due := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	task := &model.Task{ID: uuid.New(), UserID: alice.ID, Title: "Draft", Content: "body", Status: "todo", Priority: "high", DueDate: &due}
+	require.NoError(t, tasks.Create(ctx, task))
+
+	// Пишутся только перечисленные колонки, остальное в структуре игнорируется
+	patched := *task
+	patched.DueDate = nil
+	patched.Title = "ignored"
+	require.NoError(t, tasks.UpdateFields(ctx, &patched, []string{"due_date"}, userID))
+	assert.Equal(t, int64(2), patched.Version)
+
+	saved, err := tasks.GetByID(ctx, task.ID.String(), userID)
+	require.NoError(t, err)
+	assert.Nil(t, saved.DueDate)
+	assert.Equal(t, "Draft", saved.Title)
+	assert.Equal(t, "body", saved.Content)
+
+	assert.ErrorIs(t, tasks.UpdateFields(ctx, task, []string{"title"}, userID), drepo.ErrVersionConflict)
+}
+
+func TestRepository_Workflow(t *testing.T) {
+	db := setupRealDB(t)
+	tasks := NewTaskRepository(db)
+	projects := NewProjectRepository(db)
+	ctx := context.Background()
+
+	alice := model.User{ID: uuid.New(), Email: "alice@example.com", PasswordHash: "x"}
+	require.NoError(t, db.Create(&alice).Error)
+	userID := alice.ID.String()
+	project := &model.Project{ID: uuid.New(), UserID: alice.ID, Name: "Work"}
+	require.NoError(t, projects.Create(ctx, project))
+
+	wf, err := tasks.Workflow(ctx, &project.ID, nil)
+	require.NoError(t, err)
+	assert.Nil(t, wf)
+
+	project.Workflow = &model.Workflow{Statuses: []string{"todo", "review", "blocked", "done"}}
+	require.NoError(t, projects.Update(ctx, project))
+	wf, err = tasks.Workflow(ctx, &project.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, project.Workflow.Statuses, wf.Statuses)
+
+	// Массовое завершение проставляет completed_at и сохраняет started_at
+	task := &model.Task{ID: uuid.New(), UserID: alice.ID, Title: "Draft", Status: "todo", Priority: "medium"}
+	require.NoError(t, tasks.Create(ctx, task))
+	_, err = tasks.UpdateStatus(ctx, []string{task.ID.String()}, "review", userID, allowAll)
+	require.NoError(t, err)
+	// Проверка видит текущий статус под блокировкой и может отменить всё изменение
+	veto := errors.New("not allowed")
+	_, err = tasks.UpdateStatus(ctx, []string{task.ID.String()}, "done", userID, func(t model.Task) error {
+		if t.Status == "review" {
+			return veto
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, veto)
+	_, err = tasks.UpdateStatus(ctx, []string{task.ID.String()}, "done", userID, allowAll)
+	require.NoError(t, err)
+	done, err := tasks.GetByID(ctx, task.ID.String(), userID)
+	require.NoError(t, err)
+	assert.NotNil(t, done.StartedAt)
+	assert.NotNil(t, done.CompletedAt)
+
+	stats, err := tasks.Stats(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats["done"])
+	assert.Equal(t, int64(0), stats["review"])
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+	"todo-list/internal/domain/model"
+	drepo "todo-list/internal/domain/repository"
+)
+
+// trashRootCond matches the deleted tasks a user deleted directly rather than
+// along with a deleted parent.
+const trashRootCond = `tasks.deleted_at IS NOT NULL AND NOT EXISTS (SELECT 1 FROM tasks p
+	WHERE p.id = tasks.parent_id AND p.deleted_at IS NOT NULL)`
+
+// deletedSubtreeIDs returns the given deleted tasks the user may see together
+// with their deleted descendants. Unless all is set, only descendants deleted
+// together with or after their parent are followed, so a subtask the user had
+// deleted on its own stays in the trash when its parent is restored.
+func deletedSubtreeIDs(tx *gorm.DB, ids []string, userID string, all bool) ([]uuid.UUID, error) {
+	follow := "t.deleted_at >= s.deleted_at"
+	if all {
+		follow = "t.deleted_at IS NOT NULL"
+	}
+	var out []uuid.UUID
+	err := tx.Raw(`WITH RECURSIVE subtree AS (
+			SELECT id, deleted_at FROM tasks WHERE id IN ? AND `+taskVisibleSQL+` AND deleted_at IS NOT NULL
+			UNION
+			SELECT t.id, t.deleted_at FROM tasks t JOIN subtree s ON t.parent_id = s.id WHERE `+follow+`
+		)
+		SELECT id FROM subtree`, ids, userID, userID).Scan(&out).Error
+	return out, err
+}
+
+func (r *taskRepositoryImpl) ListTrash(ctx context.Context, userID string, page drepo.PageRequest) (drepo.TaskPage, error) {
+	if page.Sort == "" {
+		page.Sort = drepo.SortDeletedAt
+	}
+	q := r.db.WithContext(ctx).Unscoped().Scopes(withAssociations, visibleTo(userID)).Where(trashRootCond)
+	return paginate(q, page)
+}
+
+func (r *taskRepositoryImpl) GetDeleted(ctx context.Context, id string, userID string) (model.Task, error) {
+	var task model.Task
+	err := r.db.WithContext(ctx).Unscoped().Scopes(withAssociations, visibleTo(userID)).
+		Where("tasks.id = ? AND tasks.deleted_at IS NOT NULL", id).First(&task).Error
+	return task, err
+}
+
+func (r *taskRepositoryImpl) Restore(ctx context.Context, ids []string, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockHierarchy(tx, ids, userID); err != nil {
+			return err
+		}
+		sub, err := deletedSubtreeIDs(tx, ids, userID, false)
+		if err != nil || len(sub) == 0 {
+			return err
+		}
+		err = tx.Unscoped().Model(&model.Task{}).
+			Where("id IN ? AND parent_id IS NOT NULL AND parent_id NOT IN ?", sub, sub).
+			Where("NOT EXISTS (SELECT 1 FROM tasks p WHERE p.id = tasks.parent_id AND p.deleted_at IS NULL)").
+			Update("parent_id", nil).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Unscoped().Model(&model.Task{}).Where("id IN ?", sub).
+			Updates(map[string]interface{}{"deleted_at": nil, "updated_at": time.Now(), "version": gorm.Expr("version + 1")}).Error
+		if err != nil {
+			return err
+		}
+		if err := syncBlocked(tx, sub); err != nil {
+			return err
+		}
+		return refreshDependents(tx, sub)
+	})
+}
+
+func (r *taskRepositoryImpl) Purge(ctx context.Context, ids []string, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockHierarchy(tx, ids, userID); err != nil {
+			return err
+		}
+		sub, err := deletedSubtreeIDs(tx, ids, userID, true)
+		if err != nil {
+			return err
+		}
+		return eraseTasks(tx, sub)
+	})
+}
+
+// PurgeDeletedBefore skips rows another instance is already erasing.
+func (r *taskRepositoryImpl) PurgeDeletedBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []uuid.UUID
+		err := tx.Raw(`SELECT id FROM tasks WHERE deleted_at IS NOT NULL AND deleted_at < ?
+			ORDER BY deleted_at LIMIT ? FOR UPDATE SKIP LOCKED`, before, limit).Scan(&ids).Error
+		if err != nil {
+			return err
+		}
+		n = int64(len(ids))
+		return eraseTasks(tx, ids)
+	})
+	return n, err
+}
+
+// eraseTasks hard-deletes the tasks and every row that hangs off them.
+// Subtasks outside ids move to the top level. Attachment files are left to
+// the attachment janitor, which removes them once their task is gone.
+func eraseTasks(tx *gorm.DB, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := tx.Exec("DELETE FROM task_tags WHERE task_id IN ?", ids).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("task_id IN ?", ids).Delete(&model.TaskAssignee{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("task_id IN ?", ids).Delete(&model.TaskWatcher{}).Error; err != nil {
+		return err
+	}
+	err := tx.Exec("DELETE FROM comment_mentions WHERE comment_id IN (SELECT id FROM comments WHERE task_id IN ?)", ids).Error
+	if err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("task_id IN ?", ids).Delete(&model.Comment{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("task_id IN ?", ids).Delete(&model.TaskRevision{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("task_id IN ?", ids).Delete(&model.Reminder{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("blocker_id IN ? OR blocked_id IN ?", ids, ids).Delete(&model.TaskDependency{}).Error; err != nil {
+		return err
+	}
+	err = tx.Unscoped().Model(&model.Task{}).Where("parent_id IN ? AND id NOT IN ?", ids, ids).Update("parent_id", nil).Error
+	if err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("id IN ?", ids).Delete(&model.Task{}).Error
+}
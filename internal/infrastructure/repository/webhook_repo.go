@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+	"todo-list/internal/domain/model"
+	drepo "todo-list/internal/domain/repository"
+)
+
+type webhookRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) drepo.WebhookRepository {
+	return &webhookRepositoryImpl{db: db}
+}
+
+func (r *webhookRepositoryImpl) Create(ctx context.Context, sub *model.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Create(sub).Error
+}
+
+func (r *webhookRepositoryImpl) GetByID(ctx context.Context, id string, userID string) (model.WebhookSubscription, error) {
+	var sub model.WebhookSubscription
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&sub).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.WebhookSubscription{}, drepo.ErrWebhookNotFound
+	}
+	return sub, err
+}
+
+func (r *webhookRepositoryImpl) List(ctx context.Context, userID string) ([]model.WebhookSubscription, error) {
+	var out []model.WebhookSubscription
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&out).Error
+	return out, err
+}
+
+func (r *webhookRepositoryImpl) ListActive(ctx context.Context, userID string) ([]model.WebhookSubscription, error) {
+	var out []model.WebhookSubscription
+	err := r.db.WithContext(ctx).Where("user_id = ? AND active", userID).Find(&out).Error
+	return out, err
+}
+
+func (r *webhookRepositoryImpl) Update(ctx context.Context, sub *model.WebhookSubscription) error {
+	res := r.db.WithContext(ctx).Model(sub).Where("user_id = ?", sub.UserID).Updates(map[string]interface{}{
+		"url": sub.URL, "secret": sub.Secret, "events": sub.Events, "active": sub.Active,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return drepo.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepositoryImpl) Delete(ctx context.Context, id string, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&model.WebhookSubscription{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return drepo.ErrWebhookNotFound
+		}
+		return tx.Where("subscription_id = ?", id).Delete(&model.WebhookDelivery{}).Error
+	})
+}
+
+func (r *webhookRepositoryImpl) CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&deliveries).Error
+}
+
+func (r *webhookRepositoryImpl) ListDeliveries(ctx context.Context, subscriptionID string, userID string, limit int) ([]model.WebhookDelivery, error) {
+	var out []model.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Where("subscription_id = ? AND user_id = ?", subscriptionID, userID).
+		Order("created_at DESC").Limit(limit).Find(&out).Error
+	return out, err
+}
+
+func (r *webhookRepositoryImpl) GetDelivery(ctx context.Context, id string, subscriptionID string, userID string) (model.WebhookDelivery, error) {
+	var d model.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND subscription_id = ? AND user_id = ?", id, subscriptionID, userID).
+		First(&d).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.WebhookDelivery{}, drepo.ErrDeliveryNotFound
+	}
+	return d, err
+}
+
+// ClaimDeliveries follows ReminderRepository.ClaimDue: SKIP LOCKED keeps
+// workers apart and an expired lease makes the delivery claimable again.
+// Deliveries of paused subscriptions stay queued until they are resumed.
+func (r *webhookRepositoryImpl) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]drepo.PendingDelivery, error) {
+	db := r.db.WithContext(ctx)
+	var ids []uuid.UUID
+	err := db.Raw(`WITH due AS (
+			SELECT d.id FROM webhook_deliveries d
+			JOIN webhook_subscriptions s ON s.id = d.subscription_id AND s.active
+			WHERE d.status = ? AND d.next_attempt_at <= ?
+			ORDER BY d.next_attempt_at
+			LIMIT ?
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		UPDATE webhook_deliveries SET next_attempt_at = ?, attempts = webhook_deliveries.attempts + 1
+		FROM due WHERE webhook_deliveries.id = due.id
+		RETURNING webhook_deliveries.id`, model.WebhookDeliveryPending, now, limit, now.Add(lease)).Scan(&ids).Error
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	var deliveries []model.WebhookDelivery
+	if err := db.Where("id IN ?", ids).Order("created_at").Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	subIDs := make([]uuid.UUID, 0, len(deliveries))
+	for _, d := range deliveries {
+		subIDs = append(subIDs, d.SubscriptionID)
+	}
+	var subs []model.WebhookSubscription
+	if err := db.Where("id IN ?", subIDs).Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	subByID := make(map[uuid.UUID]model.WebhookSubscription, len(subs))
+	for _, s := range subs {
+		subByID[s.ID] = s
+	}
+
+	out := make([]drepo.PendingDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		sub := subByID[d.SubscriptionID]
+		out = append(out, drepo.PendingDelivery{Delivery: d, URL: sub.URL, Secret: sub.Secret})
+	}
+	return out, nil
+}
+
+func (r *webhookRepositoryImpl) MarkDelivered(ctx context.Context, id uuid.UUID, statusCode int, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.WebhookDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status": model.WebhookDeliverySucceeded, "response_status": statusCode, "delivered_at": at, "last_error": "",
+	}).Error
+}
+
+func (r *webhookRepositoryImpl) MarkDeliveryFailed(ctx context.Context, id uuid.UUID, statusCode int, reason string, retryAt *time.Time) error {
+	updates := map[string]interface{}{"response_status": statusCode, "last_error": reason}
+	if retryAt != nil {
+		updates["next_attempt_at"] = *retryAt
+	} else {
+		updates["status"] = model.WebhookDeliveryFailed
+	}
+	return r.db.WithContext(ctx).Model(&model.WebhookDelivery{}).Where("id = ?", id).Updates(updates).Error
+}
@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+	"time"
+	"todo-list/internal/domain/model"
+	drepo "todo-list/internal/domain/repository"
+)
+
+type workspaceRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceRepository(db *gorm.DB) drepo.WorkspaceRepository {
+	return &workspaceRepositoryImpl{db: db}
+}
+
+func (r *workspaceRepositoryImpl) Create(ctx context.Context, ws *model.Workspace) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(ws).Error; err != nil {
+			return err
+		}
+		owner := model.WorkspaceMember{WorkspaceID: ws.ID, UserID: ws.CreatedBy, Role: model.RoleOwner}
+		return tx.Create(&owner).Error
+	})
+}
+
+func (r *workspaceRepositoryImpl) summaries(ctx context.Context, userID string) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&model.Workspace{}).
+		Select(`workspaces.*, m.role,
+			(SELECT COUNT(*) FROM workspace_members c WHERE c.workspace_id = workspaces.id) AS member_count`).
+		Joins("JOIN workspace_members m ON m.workspace_id = workspaces.id AND m.user_id = ?", userID)
+}
+
+func (r *workspaceRepositoryImpl) GetByID(ctx context.Context, id string, userID string) (drepo.WorkspaceSummary, error) {
+	var out []drepo.WorkspaceSummary
+	if err := r.summaries(ctx, userID).Where("workspaces.id = ?", id).Scan(&out).Error; err != nil {
+		return drepo.WorkspaceSummary{}, err
+	}
+	if len(out) == 0 {
+		return drepo.WorkspaceSummary{}, drepo.ErrWorkspaceNotFound
+	}
+	return out[0], nil
+}
+
+func (r *workspaceRepositoryImpl) List(ctx context.Context, userID string) ([]drepo.WorkspaceSummary, error) {
+	var out []drepo.WorkspaceSummary
+	err := r.summaries(ctx, userID).Order("workspaces.name, workspaces.created_at").Scan(&out).Error
+	return out, err
+}
+
+func (r *workspaceRepositoryImpl) Update(ctx context.Context, ws *model.Workspace) error {
+	return r.db.WithContext(ctx).Model(ws).Select("name", "workflow", "updated_at").Updates(ws).Error
+}
+
+func (r *workspaceRepositoryImpl) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ?", id).Delete(&model.Workspace{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return drepo.ErrWorkspaceNotFound
+		}
+		if err := tx.Where("workspace_id = ?", id).Delete(&model.WorkspaceMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("workspace_id = ?", id).Delete(&model.WorkspaceInvitation{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("workspace_id = ?", id).Delete(&model.Task{}).Error
+	})
+}
+
+func (r *workspaceRepositoryImpl) Role(ctx context.Context, id string, userID string) (string, error) {
+	var m model.WorkspaceMember
+	err := r.db.WithContext(ctx).Where("workspace_id = ? AND user_id = ?", id, userID).First(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", drepo.ErrWorkspaceNotFound
+	}
+	return m.Role, err
+}
+
+func (r *workspaceRepositoryImpl) members(tx *gorm.DB) *gorm.DB {
+	return tx.Model(&model.WorkspaceMember{}).
+		Select("workspace_members.*, users.email").
+		Joins("JOIN users ON users.id = workspace_members.user_id")
+}
+
+func (r *workspaceRepositoryImpl) ListMembers(ctx context.Context, id string) ([]drepo.Member, error) {
+	var out []drepo.Member
+	err := r.members(r.db.WithContext(ctx)).
+		Where("workspace_members.workspace_id = ?", id).
+		Order("workspace_members.created_at, users.email").
+		Scan(&out).Error
+	return out, err
+}
+
+func (r *workspaceRepositoryImpl) getMember(tx *gorm.DB, id, userID string) (drepo.Member, error) {
+	var out []drepo.Member
+	err := r.members(tx).
+		Where("workspace_members.workspace_id = ? AND workspace_members.user_id = ?", id, userID).
+		Scan(&out).Error
+	if err != nil {
+		return drepo.Member{}, err
+	}
+	if len(out) == 0 {
+		return drepo.Member{}, drepo.ErrMemberNotFound
+	}
+	return out[0], nil
+}
+
+func (r *workspaceRepositoryImpl) AddMember(ctx context.Context, id, email, role string) (drepo.Member, error) {
+	wsID, err := uuid.Parse(id)
+	if err != nil {
+		return drepo.Member{}, drepo.ErrWorkspaceNotFound
+	}
+	var out drepo.Member
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		err := tx.Where("LOWER(email) = ?", strings.ToLower(strings.TrimSpace(email))).First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return drepo.ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+		m := model.WorkspaceMember{WorkspaceID: wsID, UserID: user.ID, Role: role}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&m)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return drepo.ErrMemberExists
+		}
+		out = drepo.Member{WorkspaceMember: m, Email: user.Email}
+		return nil
+	})
+	return out, err
+}
+
+func (r *workspaceRepositoryImpl) UpdateMemberRole(ctx context.Context, id, userID, role string) (drepo.Member, error) {
+	var out drepo.Member
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		m, err := r.lockMember(tx, id, userID)
+		if err != nil {
+			return err
+		}
+		if m.Role == model.RoleOwner && role != model.RoleOwner {
+			if err := ensureAnotherOwner(tx, id); err != nil {
+				return err
+			}
+		}
+		err = tx.Model(&model.WorkspaceMember{}).
+			Where("workspace_id = ? AND user_id = ?", id, userID).
+			Update("role", role).Error
+		if err != nil {
+			return err
+		}
+		out, err = r.getMember(tx, id, userID)
+		return err
+	})
+	return out, err
+}
+
+func (r *workspaceRepositoryImpl) RemoveMember(ctx context.Context, id, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		m, err := r.lockMember(tx, id, userID)
+		if err != nil {
+			return err
+		}
+		if m.Role == model.RoleOwner {
+			if err := ensureAnotherOwner(tx, id); err != nil {
+				return err
+			}
+		}
+		return tx.Where("workspace_id = ? AND user_id = ?", id, userID).Delete(&model.WorkspaceMember{}).Error
+	})
+}
+
+// lockMember locks the workspace row so concurrent role changes cannot both
+// demote the last two owners, then loads the member.
+func (r *workspaceRepositoryImpl) lockMember(tx *gorm.DB, id, userID string) (model.WorkspaceMember, error) {
+	var ws model.Workspace
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&ws).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.WorkspaceMember{}, drepo.ErrWorkspaceNotFound
+	}
+	if err != nil {
+		return model.WorkspaceMember{}, err
+	}
+	var m model.WorkspaceMember
+	err = tx.Where("workspace_id = ? AND user_id = ?", id, userID).First(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.WorkspaceMember{}, drepo.ErrMemberNotFound
+	}
+	return m, err
+}
+
+func ensureAnotherOwner(tx *gorm.DB, id string) error {
+	var owners int64
+	err := tx.Model(&model.WorkspaceMember{}).
+		Where("workspace_id = ? AND role = ?", id, model.RoleOwner).
+		Count(&owners).Error
+	if err != nil {
+		return err
+	}
+	if owners < 2 {
+		return drepo.ErrLastOwner
+	}
+	return nil
+}
+
+func (r *workspaceRepositoryImpl) CreateInvitation(ctx context.Context, inv *model.WorkspaceInvitation) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.WorkspaceInvitation{}).
+			Where("workspace_id = ? AND LOWER(email) = ? AND status = ?", inv.WorkspaceID, strings.ToLower(inv.Email), model.InvitationPending).
+			Updates(map[string]interface{}{"status": model.InvitationRevoked, "responded_at": time.Now()}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(inv).Error
+	})
+}
+
+func (r *workspaceRepositoryImpl) GetInvitation(ctx context.Context, invitationID string) (model.WorkspaceInvitation, error) {
+	var inv model.WorkspaceInvitation
+	err := r.db.WithContext(ctx).Where("id = ?", invitationID).First(&inv).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return inv, drepo.ErrInvitationNotFound
+	}
+	return inv, err
+}
+
+func (r *workspaceRepositoryImpl) ListInvitations(ctx context.Context, id string) ([]model.WorkspaceInvitation, error) {
+	var out []model.WorkspaceInvitation
+	err := r.db.WithContext(ctx).
+		Where("workspace_id = ? AND status = ? AND expires_at > ?", id, model.InvitationPending, time.Now()).
+		Order("created_at DESC").
+		Find(&out).Error
+	return out, err
+}
+
+func (r *workspaceRepositoryImpl) RevokeInvitation(ctx context.Context, id, invitationID string) error {
+	res := r.db.WithContext(ctx).Model(&model.WorkspaceInvitation{}).
+		Where("id = ? AND workspace_id = ? AND status = ?", invitationID, id, model.InvitationPending).
+		Updates(map[string]interface{}{"status": model.InvitationRevoked, "responded_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return drepo.ErrInvitationNotFound
+	}
+	return nil
+}
+
+func (r *workspaceRepositoryImpl) AcceptInvitation(ctx context.Context, invitationID, userID string) (drepo.Member, error) {
+	var out drepo.Member
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		inv, err := lockOpenInvitation(tx, invitationID)
+		if err != nil {
+			return err
+		}
+		var user model.User
+		if err := tx.Where("id = ?", userID).First(&user).Error; err != nil {
+			return err
+		}
+		if !strings.EqualFold(strings.TrimSpace(user.Email), inv.Email) {
+			return drepo.ErrInvitationEmail
+		}
+		m := model.WorkspaceMember{WorkspaceID: inv.WorkspaceID, UserID: user.ID, Role: inv.Role}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&m)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return drepo.ErrMemberExists
+		}
+		out = drepo.Member{WorkspaceMember: m, Email: user.Email}
+		return closeInvitation(tx, inv.ID, model.InvitationAccepted)
+	})
+	return out, err
+}
+
+func (r *workspaceRepositoryImpl) DeclineInvitation(ctx context.Context, invitationID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		inv, err := lockOpenInvitation(tx, invitationID)
+		if err != nil {
+			return err
+		}
+		return closeInvitation(tx, inv.ID, model.InvitationDeclined)
+	})
+}
+
+// lockOpenInvitation serialises concurrent answers to the same invitation.
+func lockOpenInvitation(tx *gorm.DB, invitationID string) (model.WorkspaceInvitation, error) {
+	var inv model.WorkspaceInvitation
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", invitationID).First(&inv).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return inv, drepo.ErrInvitationNotFound
+	}
+	if err != nil {
+		return inv, err
+	}
+	if !inv.Open(time.Now()) {
+		return inv, drepo.ErrInvitationClosed
+	}
+	return inv, nil
+}
+
+func closeInvitation(tx *gorm.DB, id uuid.UUID, status string) error {
+	return tx.Model(&model.WorkspaceInvitation{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": status, "responded_at": time.Now()}).Error
+}
@@ -2,9 +2,12 @@ package testutils
 
 import (
 	"context"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
+	"io"
 	"time"
 	"todo-list/internal/domain/model"
+	"todo-list/internal/domain/repository"
 )
 
 type AllMocks struct {
@@ -13,41 +16,42 @@ type AllMocks struct {
 
 // Репозиторий
 func (m *AllMocks) Create(ctx context.Context, t *model.Task) error { return m.Called(ctx, t).Error(0) }
-func (m *AllMocks) GetAll(ctx context.Context, uID string) ([]model.Task, error) {
-	args := m.Called(ctx, uID)
-	return args.Get(0).([]model.Task), args.Error(1)
+func (m *AllMocks) GetAll(ctx context.Context, uID string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, uID, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
+}
+func (m *AllMocks) List(ctx context.Context, uID string, f repository.TaskFilter, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, uID, f, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
 }
 func (m *AllMocks) GetByID(ctx context.Context, id, uID string) (model.Task, error) {
 	args := m.Called(ctx, id, uID)
 	return args.Get(0).(model.Task), args.Error(1)
 }
-func (m *AllMocks) Update(ctx context.Context, t *model.Task) error { return m.Called(ctx, t).Error(0) }
+func (m *AllMocks) Update(ctx context.Context, t *model.Task, uID string) error {
+	return m.Called(ctx, t, uID).Error(0)
+}
+func (m *AllMocks) UpdateFields(ctx context.Context, t *model.Task, fields []string, uID string) error {
+	return m.Called(ctx, t, fields, uID).Error(0)
+}
 func (m *AllMocks) Delete(ctx context.Context, id, uID string) error {
 	return m.Called(ctx, id, uID).Error(0)
 }
-func (m *AllMocks) FindByStatus(ctx context.Context, s, uID string) ([]model.Task, error) {
-	args := m.Called(ctx, s, uID)
-	return args.Get(0).([]model.Task), args.Error(1)
+func (m *AllMocks) FindByStatus(ctx context.Context, s, uID string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, s, uID, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
 }
-func (m *AllMocks) FindByPriority(ctx context.Context, p, uID string) ([]model.Task, error) {
-	args := m.Called(ctx, p, uID)
-	return args.Get(0).([]model.Task), args.Error(1)
+func (m *AllMocks) FindByPriority(ctx context.Context, p, uID string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, p, uID, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
 }
-func (m *AllMocks) FindByTag(ctx context.Context, t, uID string) ([]model.Task, error) {
-	args := m.Called(ctx, t, uID)
-	return args.Get(0).([]model.Task), args.Error(1)
+func (m *AllMocks) FindByTag(ctx context.Context, t, uID string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, t, uID, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
 }
-func (m *AllMocks) Search(ctx context.Context, q, uID string) ([]model.Task, error) {
-	args := m.Called(ctx, q, uID)
-	return args.Get(0).([]model.Task), args.Error(1)
-}
-func (m *AllMocks) GetToday(ctx context.Context, uID string) ([]model.Task, error) {
-	args := m.Called(ctx, uID)
-	return args.Get(0).([]model.Task), args.Error(1)
-}
-func (m *AllMocks) GetOverdue(ctx context.Context, uID string) ([]model.Task, error) {
-	args := m.Called(ctx, uID)
-	return args.Get(0).([]model.Task), args.Error(1)
+func (m *AllMocks) Search(ctx context.Context, q, uID string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, q, uID, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
 }
 func (m *AllMocks) AddTag(ctx context.Context, id, tag, uID string) (model.Task, error) {
 	args := m.Called(ctx, id, tag, uID)
@@ -57,12 +61,52 @@ func (m *AllMocks) RemoveTag(ctx context.Context, id, tag, uID string) (model.Ta
 	args := m.Called(ctx, id, tag, uID)
 	return args.Get(0).(model.Task), args.Error(1)
 }
+func (m *AllMocks) AddAssignee(ctx context.Context, id, assigneeID, uID string) (model.Task, error) {
+	args := m.Called(ctx, id, assigneeID, uID)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+func (m *AllMocks) RemoveAssignee(ctx context.Context, id, assigneeID, uID string) (model.Task, error) {
+	args := m.Called(ctx, id, assigneeID, uID)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+func (m *AllMocks) AddWatcher(ctx context.Context, id, uID string) (model.Task, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+func (m *AllMocks) RemoveWatcher(ctx context.Context, id, uID string) (model.Task, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+func (m *AllMocks) Complete(ctx context.Context, t *model.Task, fields []string, next *model.Task, uID string) error {
+	return m.Called(ctx, t, fields, next, uID).Error(0)
+}
 func (m *AllMocks) BulkDelete(ctx context.Context, ids []string, uID string) error {
 	return m.Called(ctx, ids, uID).Error(0)
 }
 func (m *AllMocks) BulkUpdateStatus(ctx context.Context, ids []string, s, uID string) error {
 	return m.Called(ctx, ids, s, uID).Error(0)
 }
+
+// UpdateStatus runs check over the tasks given to Return, as the repository
+// does under its row locks, and reports those not yet in status s as changed.
+func (m *AllMocks) UpdateStatus(ctx context.Context, ids []string, s, uID string, check func(model.Task) error) ([]uuid.UUID, error) {
+	args := m.Called(ctx, ids, s, uID)
+	var changed []uuid.UUID
+	for _, t := range args.Get(0).([]model.Task) {
+		if err := check(t); err != nil {
+			return nil, err
+		}
+		if t.Status != s {
+			changed = append(changed, t.ID)
+		}
+	}
+	return changed, args.Error(1)
+}
+func (m *AllMocks) DeleteMany(ctx context.Context, ids []string, uID string) ([]uuid.UUID, error) {
+	args := m.Called(ctx, ids, uID)
+	deleted, _ := args.Get(0).([]uuid.UUID)
+	return deleted, args.Error(1)
+}
 func (m *AllMocks) Archive(ctx context.Context, id, uID string) (model.Task, error) {
 	args := m.Called(ctx, id, uID)
 	return args.Get(0).(model.Task), args.Error(1)
@@ -75,62 +119,110 @@ func (m *AllMocks) Stats(ctx context.Context, uID string) (map[string]int64, err
 	args := m.Called(ctx, uID)
 	return args.Get(0).(map[string]int64), args.Error(1)
 }
+func (m *AllMocks) Workflow(ctx context.Context, projectID, workspaceID *uuid.UUID) (*model.Workflow, error) {
+	args := m.Called(ctx, projectID, workspaceID)
+	return args.Get(0).(*model.Workflow), args.Error(1)
+}
+func (m *AllMocks) GetSubtree(ctx context.Context, id, uID string) ([]model.Task, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).([]model.Task), args.Error(1)
+}
+func (m *AllMocks) Move(ctx context.Context, id string, parentID *string, uID string) (model.Task, error) {
+	args := m.Called(ctx, id, parentID, uID)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+func (m *AllMocks) AddDependency(ctx context.Context, blockerID, blockedID, uID string) error {
+	return m.Called(ctx, blockerID, blockedID, uID).Error(0)
+}
+func (m *AllMocks) RemoveDependency(ctx context.Context, blockerID, blockedID, uID string) error {
+	return m.Called(ctx, blockerID, blockedID, uID).Error(0)
+}
+func (m *AllMocks) GetDependencyGraph(ctx context.Context, id, uID string) (model.DependencyGraph, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).(model.DependencyGraph), args.Error(1)
+}
+func (m *AllMocks) ListRevisions(ctx context.Context, id, uID string) ([]model.TaskRevision, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).([]model.TaskRevision), args.Error(1)
+}
+func (m *AllMocks) ListTrash(ctx context.Context, uID string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, uID, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
+}
+func (m *AllMocks) GetDeleted(ctx context.Context, id, uID string) (model.Task, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+func (m *AllMocks) Restore(ctx context.Context, ids []string, uID string) error {
+	return m.Called(ctx, ids, uID).Error(0)
+}
+func (m *AllMocks) Purge(ctx context.Context, ids []string, uID string) error {
+	return m.Called(ctx, ids, uID).Error(0)
+}
+func (m *AllMocks) PurgeDeletedBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
 
 // Сервис (методы CreateTask и т.д.)
-func (m *AllMocks) CreateTask(ctx context.Context, u, t, c, s, p string, d *time.Time) (model.Task, error) {
-	args := m.Called(ctx, u, t, c, s, p, d)
+func (m *AllMocks) CreateTask(ctx context.Context, u, t, c, s, p string, d *time.Time, project, workspace *string) (model.Task, error) {
+	args := m.Called(ctx, u, t, c, s, p, d, project, workspace)
 	return args.Get(0).(model.Task), args.Error(1)
 }
-func (m *AllMocks) GetAllTasks(ctx context.Context, u string) ([]model.Task, error) {
-	args := m.Called(ctx, u)
-	return args.Get(0).([]model.Task), args.Error(1)
+func (m *AllMocks) GetAllTasks(ctx context.Context, u string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, u, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
+}
+func (m *AllMocks) ListTasks(ctx context.Context, u, f string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, u, f, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
 }
 func (m *AllMocks) GetTaskByID(ctx context.Context, id, u string) (model.Task, error) {
 	args := m.Called(ctx, id, u)
 	return args.Get(0).(model.Task), args.Error(1)
 }
-func (m *AllMocks) UpdateTask(ctx context.Context, id, u, t, c, s, p string, d *time.Time) (model.Task, error) {
-	args := m.Called(ctx, id, u, t, c, s, p, d)
+func (m *AllMocks) UpdateTask(ctx context.Context, id, u, t, c, s, p string, d *time.Time, v int64) (model.Task, error) {
+	args := m.Called(ctx, id, u, t, c, s, p, d, v)
 	return args.Get(0).(model.Task), args.Error(1)
 }
-func (m *AllMocks) DeleteTask(ctx context.Context, id, u string) error {
-	return m.Called(ctx, id, u).Error(0)
+func (m *AllMocks) DeleteTask(ctx context.Context, id, u string, v int64) error {
+	return m.Called(ctx, id, u, v).Error(0)
 }
-func (m *AllMocks) ChangeStatus(ctx context.Context, id, u, s string) (model.Task, error) {
-	args := m.Called(ctx, id, u, s)
+func (m *AllMocks) ChangeStatus(ctx context.Context, id, u, s string, v int64) (model.Task, error) {
+	args := m.Called(ctx, id, u, s, v)
 	return args.Get(0).(model.Task), args.Error(1)
 }
-func (m *AllMocks) GetTasksByStatus(ctx context.Context, s, u string) ([]model.Task, error) {
-	args := m.Called(ctx, s, u)
-	return args.Get(0).([]model.Task), args.Error(1)
+func (m *AllMocks) GetTasksByStatus(ctx context.Context, s, u string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, s, u, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
 }
-func (m *AllMocks) SearchTasks(ctx context.Context, q, u string) ([]model.Task, error) {
-	args := m.Called(ctx, q, u)
-	return args.Get(0).([]model.Task), args.Error(1)
+func (m *AllMocks) SearchTasks(ctx context.Context, q, u string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, q, u, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
 }
-func (m *AllMocks) GetTodayTasks(ctx context.Context, u string) ([]model.Task, error) {
-	args := m.Called(ctx, u)
-	return args.Get(0).([]model.Task), args.Error(1)
+func (m *AllMocks) GetTodayTasks(ctx context.Context, u string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, u, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
 }
-func (m *AllMocks) GetOverdueTasks(ctx context.Context, u string) ([]model.Task, error) {
-	args := m.Called(ctx, u)
-	return args.Get(0).([]model.Task), args.Error(1)
+func (m *AllMocks) GetOverdueTasks(ctx context.Context, u string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, u, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
 }
-func (m *AllMocks) ArchiveTask(ctx context.Context, id, u string) (model.Task, error) {
-	args := m.Called(ctx, id, u)
+func (m *AllMocks) ArchiveTask(ctx context.Context, id, u string, v int64) (model.Task, error) {
+	args := m.Called(ctx, id, u, v)
 	return args.Get(0).(model.Task), args.Error(1)
 }
-func (m *AllMocks) UnarchiveTask(ctx context.Context, id, u string) (model.Task, error) {
-	args := m.Called(ctx, id, u)
+func (m *AllMocks) UnarchiveTask(ctx context.Context, id, u string, v int64) (model.Task, error) {
+	args := m.Called(ctx, id, u, v)
 	return args.Get(0).(model.Task), args.Error(1)
 }
-func (m *AllMocks) ChangePriority(ctx context.Context, id, u, p string) (model.Task, error) {
-	args := m.Called(ctx, id, u, p)
+func (m *AllMocks) ChangePriority(ctx context.Context, id, u, p string, v int64) (model.Task, error) {
+	args := m.Called(ctx, id, u, p, v)
 	return args.Get(0).(model.Task), args.Error(1)
 }
-func (m *AllMocks) GetTasksByPriority(ctx context.Context, p, u string) ([]model.Task, error) {
-	args := m.Called(ctx, p, u)
-	return args.Get(0).([]model.Task), args.Error(1)
+func (m *AllMocks) GetTasksByPriority(ctx context.Context, p, u string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, p, u, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
 }
 func (m *AllMocks) AddTagToTask(ctx context.Context, id, u, t string) (model.Task, error) {
 	args := m.Called(ctx, id, u, t)
@@ -140,7 +232,631 @@ func (m *AllMocks) RemoveTagFromTask(ctx context.Context, id, u, t string) (mode
 	args := m.Called(ctx, id, u, t)
 	return args.Get(0).(model.Task), args.Error(1)
 }
-func (m *AllMocks) GetTasksByTag(ctx context.Context, t, u string) ([]model.Task, error) {
-	args := m.Called(ctx, t, u)
-	return args.Get(0).([]model.Task), args.Error(1)
+func (m *AllMocks) GetTasksByTag(ctx context.Context, t, u string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, t, u, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
+}
+func (m *AllMocks) AssignTask(ctx context.Context, id, u, assigneeID string, v int64) (model.Task, error) {
+	args := m.Called(ctx, id, u, assigneeID, v)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+func (m *AllMocks) UnassignTask(ctx context.Context, id, u, assigneeID string, v int64) (model.Task, error) {
+	args := m.Called(ctx, id, u, assigneeID, v)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+func (m *AllMocks) WatchTask(ctx context.Context, id, u string) (model.Task, error) {
+	args := m.Called(ctx, id, u)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+func (m *AllMocks) UnwatchTask(ctx context.Context, id, u string) (model.Task, error) {
+	args := m.Called(ctx, id, u)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+func (m *AllMocks) ListAssignedTasks(ctx context.Context, u, filter string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, u, filter, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
+}
+func (m *AllMocks) ListWatchedTasks(ctx context.Context, u, filter string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, u, filter, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
+}
+func (m *AllMocks) CreateSubtask(ctx context.Context, parent, u, t, c, s, p string, d *time.Time, v int64) (model.Task, error) {
+	args := m.Called(ctx, parent, u, t, c, s, p, d, v)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+func (m *AllMocks) MoveTask(ctx context.Context, id, u string, parentID *string, v int64) (model.Task, error) {
+	args := m.Called(ctx, id, u, parentID, v)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+func (m *AllMocks) GetTaskTree(ctx context.Context, id, u string) (*model.TaskNode, error) {
+	args := m.Called(ctx, id, u)
+	node, _ := args.Get(0).(*model.TaskNode)
+	return node, args.Error(1)
+}
+func (m *AllMocks) AddTaskDependency(ctx context.Context, id, u, blockerID string, v int64) (model.Task, error) {
+	args := m.Called(ctx, id, u, blockerID, v)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+func (m *AllMocks) RemoveTaskDependency(ctx context.Context, id, u, blockerID string, v int64) (model.Task, error) {
+	args := m.Called(ctx, id, u, blockerID, v)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+func (m *AllMocks) GetTaskDependencies(ctx context.Context, id, u string) (model.DependencyGraph, error) {
+	args := m.Called(ctx, id, u)
+	return args.Get(0).(model.DependencyGraph), args.Error(1)
+}
+func (m *AllMocks) SetRecurrence(ctx context.Context, id, u, rrule string, v int64) (model.Task, error) {
+	args := m.Called(ctx, id, u, rrule, v)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+func (m *AllMocks) GetOccurrences(ctx context.Context, id, u string, from, to time.Time, limit int) ([]time.Time, error) {
+	args := m.Called(ctx, id, u, from, to, limit)
+	occurrences, _ := args.Get(0).([]time.Time)
+	return occurrences, args.Error(1)
+}
+func (m *AllMocks) GetTaskHistory(ctx context.Context, id, u string) ([]model.TaskRevision, error) {
+	args := m.Called(ctx, id, u)
+	return args.Get(0).([]model.TaskRevision), args.Error(1)
+}
+func (m *AllMocks) RevertTask(ctx context.Context, id, u string, revision int, v int64) (model.Task, error) {
+	args := m.Called(ctx, id, u, revision, v)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+func (m *AllMocks) PatchTask(ctx context.Context, id, u, format string, patch []byte, v int64) (model.Task, error) {
+	args := m.Called(ctx, id, u, format, patch, v)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+func (m *AllMocks) RestoreTask(ctx context.Context, id, u string, v int64) (model.Task, error) {
+	args := m.Called(ctx, id, u, v)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+func (m *AllMocks) BulkRestore(ctx context.Context, ids []string, u string) error {
+	return m.Called(ctx, ids, u).Error(0)
+}
+func (m *AllMocks) PurgeTask(ctx context.Context, id, u string, v int64) error {
+	return m.Called(ctx, id, u, v).Error(0)
+}
+
+// TaskServiceMocks — AllMocks в роли сервиса задач: AddTag и RemoveTag
+// сервиса принимают версию, а одноимённые методы репозитория нет
+type TaskServiceMocks struct {
+	AllMocks
+}
+
+func (m *TaskServiceMocks) AddTag(ctx context.Context, id, u, tag string, v int64) (model.Task, error) {
+	args := m.Called(ctx, id, u, tag, v)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+func (m *TaskServiceMocks) RemoveTag(ctx context.Context, id, u, tag string, v int64) (model.Task, error) {
+	args := m.Called(ctx, id, u, tag, v)
+	return args.Get(0).(model.Task), args.Error(1)
+}
+
+type TagMocks struct {
+	mock.Mock
+}
+
+// Репозиторий тегов
+func (m *TagMocks) List(ctx context.Context, uID string) ([]repository.TagUsage, error) {
+	args := m.Called(ctx, uID)
+	return args.Get(0).([]repository.TagUsage), args.Error(1)
+}
+func (m *TagMocks) GetByID(ctx context.Context, id uint, uID string) (model.Tag, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).(model.Tag), args.Error(1)
+}
+func (m *TagMocks) Create(ctx context.Context, t *model.Tag) error { return m.Called(ctx, t).Error(0) }
+func (m *TagMocks) Update(ctx context.Context, t *model.Tag) ([]uuid.UUID, error) {
+	args := m.Called(ctx, t)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+func (m *TagMocks) Merge(ctx context.Context, src, dst uint, uID string) ([]uuid.UUID, error) {
+	args := m.Called(ctx, src, dst, uID)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+func (m *TagMocks) Delete(ctx context.Context, id uint, uID string) ([]uuid.UUID, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+// Сервис тегов
+func (m *TagMocks) ListTags(ctx context.Context, uID string) ([]repository.TagUsage, error) {
+	args := m.Called(ctx, uID)
+	return args.Get(0).([]repository.TagUsage), args.Error(1)
+}
+func (m *TagMocks) CreateTag(ctx context.Context, uID, name, color string) (model.Tag, error) {
+	args := m.Called(ctx, uID, name, color)
+	return args.Get(0).(model.Tag), args.Error(1)
+}
+func (m *TagMocks) UpdateTag(ctx context.Context, id uint, uID string, name, color *string) (model.Tag, error) {
+	args := m.Called(ctx, id, uID, name, color)
+	return args.Get(0).(model.Tag), args.Error(1)
+}
+func (m *TagMocks) MergeTags(ctx context.Context, src, dst uint, uID string) (model.Tag, error) {
+	args := m.Called(ctx, src, dst, uID)
+	return args.Get(0).(model.Tag), args.Error(1)
+}
+func (m *TagMocks) DeleteTag(ctx context.Context, id uint, uID string) error {
+	return m.Called(ctx, id, uID).Error(0)
+}
+
+type ReminderMocks struct {
+	mock.Mock
+}
+
+// Репозиторий напоминаний
+func (m *ReminderMocks) Create(ctx context.Context, r *model.Reminder) error {
+	return m.Called(ctx, r).Error(0)
+}
+func (m *ReminderMocks) ListByTask(ctx context.Context, taskID, uID string) ([]model.Reminder, error) {
+	args := m.Called(ctx, taskID, uID)
+	return args.Get(0).([]model.Reminder), args.Error(1)
+}
+func (m *ReminderMocks) Delete(ctx context.Context, id, taskID, uID string) error {
+	return m.Called(ctx, id, taskID, uID).Error(0)
+}
+func (m *ReminderMocks) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]repository.DueReminder, error) {
+	args := m.Called(ctx, now, lease, limit)
+	return args.Get(0).([]repository.DueReminder), args.Error(1)
+}
+func (m *ReminderMocks) MarkSent(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return m.Called(ctx, id, at).Error(0)
+}
+func (m *ReminderMocks) MarkFailed(ctx context.Context, id uuid.UUID, reason string, retryAt *time.Time) error {
+	return m.Called(ctx, id, reason, retryAt).Error(0)
+}
+
+// Сервис напоминаний
+func (m *ReminderMocks) AddReminder(ctx context.Context, taskID, uID string, at *time.Time, offset *int, channel, target string) (model.Reminder, error) {
+	args := m.Called(ctx, taskID, uID, at, offset, channel, target)
+	return args.Get(0).(model.Reminder), args.Error(1)
+}
+func (m *ReminderMocks) ListReminders(ctx context.Context, taskID, uID string) ([]model.Reminder, error) {
+	args := m.Called(ctx, taskID, uID)
+	return args.Get(0).([]model.Reminder), args.Error(1)
+}
+func (m *ReminderMocks) DeleteReminder(ctx context.Context, id, taskID, uID string) error {
+	return m.Called(ctx, id, taskID, uID).Error(0)
+}
+
+type WebhookMocks struct {
+	mock.Mock
+}
+
+// Репозиторий вебхуков
+func (m *WebhookMocks) Create(ctx context.Context, s *model.WebhookSubscription) error {
+	return m.Called(ctx, s).Error(0)
+}
+func (m *WebhookMocks) GetByID(ctx context.Context, id, uID string) (model.WebhookSubscription, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).(model.WebhookSubscription), args.Error(1)
+}
+func (m *WebhookMocks) List(ctx context.Context, uID string) ([]model.WebhookSubscription, error) {
+	args := m.Called(ctx, uID)
+	return args.Get(0).([]model.WebhookSubscription), args.Error(1)
+}
+func (m *WebhookMocks) ListActive(ctx context.Context, uID string) ([]model.WebhookSubscription, error) {
+	args := m.Called(ctx, uID)
+	return args.Get(0).([]model.WebhookSubscription), args.Error(1)
+}
+func (m *WebhookMocks) Update(ctx context.Context, s *model.WebhookSubscription) error {
+	return m.Called(ctx, s).Error(0)
+}
+func (m *WebhookMocks) Delete(ctx context.Context, id, uID string) error {
+	return m.Called(ctx, id, uID).Error(0)
+}
+func (m *WebhookMocks) CreateDeliveries(ctx context.Context, d []model.WebhookDelivery) error {
+	return m.Called(ctx, d).Error(0)
+}
+func (m *WebhookMocks) ListDeliveries(ctx context.Context, subID, uID string, limit int) ([]model.WebhookDelivery, error) {
+	args := m.Called(ctx, subID, uID, limit)
+	return args.Get(0).([]model.WebhookDelivery), args.Error(1)
+}
+func (m *WebhookMocks) GetDelivery(ctx context.Context, id, subID, uID string) (model.WebhookDelivery, error) {
+	args := m.Called(ctx, id, subID, uID)
+	return args.Get(0).(model.WebhookDelivery), args.Error(1)
+}
+func (m *WebhookMocks) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]repository.PendingDelivery, error) {
+	args := m.Called(ctx, now, lease, limit)
+	return args.Get(0).([]repository.PendingDelivery), args.Error(1)
+}
+func (m *WebhookMocks) MarkDelivered(ctx context.Context, id uuid.UUID, status int, at time.Time) error {
+	return m.Called(ctx, id, status, at).Error(0)
+}
+func (m *WebhookMocks) MarkDeliveryFailed(ctx context.Context, id uuid.UUID, status int, reason string, retryAt *time.Time) error {
+	return m.Called(ctx, id, status, reason, retryAt).Error(0)
+}
+
+// Сервис вебхуков
+func (m *WebhookMocks) CreateWebhook(ctx context.Context, uID, url, secret string, events []string) (model.WebhookSubscription, error) {
+	args := m.Called(ctx, uID, url, secret, events)
+	return args.Get(0).(model.WebhookSubscription), args.Error(1)
+}
+func (m *WebhookMocks) ListWebhooks(ctx context.Context, uID string) ([]model.WebhookSubscription, error) {
+	args := m.Called(ctx, uID)
+	return args.Get(0).([]model.WebhookSubscription), args.Error(1)
+}
+func (m *WebhookMocks) GetWebhook(ctx context.Context, id, uID string) (model.WebhookSubscription, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).(model.WebhookSubscription), args.Error(1)
+}
+func (m *WebhookMocks) UpdateWebhook(ctx context.Context, id, uID string, url, secret *string, events []string, active *bool) (model.WebhookSubscription, error) {
+	args := m.Called(ctx, id, uID, url, secret, events, active)
+	return args.Get(0).(model.WebhookSubscription), args.Error(1)
+}
+func (m *WebhookMocks) DeleteWebhook(ctx context.Context, id, uID string) error {
+	return m.Called(ctx, id, uID).Error(0)
+}
+func (m *WebhookMocks) ListWebhookDeliveries(ctx context.Context, id, uID string, limit int) ([]model.WebhookDelivery, error) {
+	args := m.Called(ctx, id, uID, limit)
+	return args.Get(0).([]model.WebhookDelivery), args.Error(1)
+}
+func (m *WebhookMocks) Redeliver(ctx context.Context, id, deliveryID, uID string) (model.WebhookDelivery, error) {
+	args := m.Called(ctx, id, deliveryID, uID)
+	return args.Get(0).(model.WebhookDelivery), args.Error(1)
+}
+
+type SavedSearchMocks struct {
+	mock.Mock
+}
+
+// Репозиторий сохранённых поисков
+func (m *SavedSearchMocks) List(ctx context.Context, uID string) ([]model.SavedSearch, error) {
+	args := m.Called(ctx, uID)
+	return args.Get(0).([]model.SavedSearch), args.Error(1)
+}
+func (m *SavedSearchMocks) GetByID(ctx context.Context, id, uID string) (model.SavedSearch, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).(model.SavedSearch), args.Error(1)
+}
+func (m *SavedSearchMocks) Create(ctx context.Context, s *model.SavedSearch) error {
+	return m.Called(ctx, s).Error(0)
+}
+func (m *SavedSearchMocks) Update(ctx context.Context, s *model.SavedSearch) error {
+	return m.Called(ctx, s).Error(0)
+}
+func (m *SavedSearchMocks) Delete(ctx context.Context, id, uID string) error {
+	return m.Called(ctx, id, uID).Error(0)
+}
+
+// Сервис сохранённых поисков
+func (m *SavedSearchMocks) ListSavedSearches(ctx context.Context, uID string) ([]model.SavedSearch, error) {
+	args := m.Called(ctx, uID)
+	return args.Get(0).([]model.SavedSearch), args.Error(1)
+}
+func (m *SavedSearchMocks) GetSavedSearch(ctx context.Context, id, uID string) (model.SavedSearch, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).(model.SavedSearch), args.Error(1)
+}
+func (m *SavedSearchMocks) CreateSavedSearch(ctx context.Context, uID, name, filter, sort string, desc bool) (model.SavedSearch, error) {
+	args := m.Called(ctx, uID, name, filter, sort, desc)
+	return args.Get(0).(model.SavedSearch), args.Error(1)
+}
+func (m *SavedSearchMocks) UpdateSavedSearch(ctx context.Context, id, uID, name, filter, sort string, desc bool) (model.SavedSearch, error) {
+	args := m.Called(ctx, id, uID, name, filter, sort, desc)
+	return args.Get(0).(model.SavedSearch), args.Error(1)
+}
+func (m *SavedSearchMocks) DeleteSavedSearch(ctx context.Context, id, uID string) error {
+	return m.Called(ctx, id, uID).Error(0)
+}
+func (m *SavedSearchMocks) RunSavedSearch(ctx context.Context, id, uID string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, id, uID, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
+}
+
+type ProjectMocks struct {
+	mock.Mock
+}
+
+// Репозиторий проектов
+func (m *ProjectMocks) Create(ctx context.Context, p *model.Project) error {
+	return m.Called(ctx, p).Error(0)
+}
+func (m *ProjectMocks) GetByID(ctx context.Context, id, uID string) (model.Project, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).(model.Project), args.Error(1)
+}
+func (m *ProjectMocks) List(ctx context.Context, uID string, includeArchived bool) ([]model.Project, error) {
+	args := m.Called(ctx, uID, includeArchived)
+	return args.Get(0).([]model.Project), args.Error(1)
+}
+func (m *ProjectMocks) Update(ctx context.Context, p *model.Project) error {
+	return m.Called(ctx, p).Error(0)
+}
+func (m *ProjectMocks) Delete(ctx context.Context, id, uID string) error {
+	return m.Called(ctx, id, uID).Error(0)
+}
+func (m *ProjectMocks) Reorder(ctx context.Context, uID string, ids []string) error {
+	return m.Called(ctx, uID, ids).Error(0)
+}
+func (m *ProjectMocks) Stats(ctx context.Context, id, uID string) (map[string]int64, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+func (m *ProjectMocks) MoveTasks(ctx context.Context, ids []string, projectID *string, uID string) ([]uuid.UUID, error) {
+	args := m.Called(ctx, ids, projectID, uID)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+// Сервис проектов
+func (m *ProjectMocks) CreateProject(ctx context.Context, uID, name, color, description string) (model.Project, error) {
+	args := m.Called(ctx, uID, name, color, description)
+	return args.Get(0).(model.Project), args.Error(1)
+}
+func (m *ProjectMocks) ListProjects(ctx context.Context, uID string, includeArchived bool) ([]model.Project, error) {
+	args := m.Called(ctx, uID, includeArchived)
+	return args.Get(0).([]model.Project), args.Error(1)
+}
+func (m *ProjectMocks) GetProject(ctx context.Context, id, uID string) (model.Project, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).(model.Project), args.Error(1)
+}
+func (m *ProjectMocks) UpdateProject(ctx context.Context, id, uID string, name, color, description *string) (model.Project, error) {
+	args := m.Called(ctx, id, uID, name, color, description)
+	return args.Get(0).(model.Project), args.Error(1)
+}
+func (m *ProjectMocks) ArchiveProject(ctx context.Context, id, uID string) (model.Project, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).(model.Project), args.Error(1)
+}
+func (m *ProjectMocks) UnarchiveProject(ctx context.Context, id, uID string) (model.Project, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).(model.Project), args.Error(1)
+}
+func (m *ProjectMocks) DeleteProject(ctx context.Context, id, uID string) error {
+	return m.Called(ctx, id, uID).Error(0)
+}
+func (m *ProjectMocks) ReorderProjects(ctx context.Context, uID string, ids []string) ([]model.Project, error) {
+	args := m.Called(ctx, uID, ids)
+	return args.Get(0).([]model.Project), args.Error(1)
+}
+func (m *ProjectMocks) SetWorkflow(ctx context.Context, id, uID string, wf *model.Workflow) (model.Project, error) {
+	args := m.Called(ctx, id, uID, wf)
+	return args.Get(0).(model.Project), args.Error(1)
+}
+func (m *ProjectMocks) ListProjectTasks(ctx context.Context, id, uID, filter string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, id, uID, filter, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
+}
+func (m *ProjectMocks) SearchProjectTasks(ctx context.Context, id, uID, q string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, id, uID, q, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
+}
+func (m *ProjectMocks) ProjectStats(ctx context.Context, id, uID string) (map[string]int64, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+func (m *ProjectMocks) MoveTasksToProject(ctx context.Context, uID string, projectID *string, ids []string) ([]uuid.UUID, error) {
+	args := m.Called(ctx, uID, projectID, ids)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+type WorkspaceMocks struct {
+	mock.Mock
+}
+
+// Репозиторий рабочих пространств
+func (m *WorkspaceMocks) Create(ctx context.Context, ws *model.Workspace) error {
+	return m.Called(ctx, ws).Error(0)
+}
+func (m *WorkspaceMocks) GetByID(ctx context.Context, id, uID string) (repository.WorkspaceSummary, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).(repository.WorkspaceSummary), args.Error(1)
+}
+func (m *WorkspaceMocks) List(ctx context.Context, uID string) ([]repository.WorkspaceSummary, error) {
+	args := m.Called(ctx, uID)
+	return args.Get(0).([]repository.WorkspaceSummary), args.Error(1)
+}
+func (m *WorkspaceMocks) Update(ctx context.Context, ws *model.Workspace) error {
+	return m.Called(ctx, ws).Error(0)
+}
+func (m *WorkspaceMocks) Delete(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
+}
+func (m *WorkspaceMocks) Role(ctx context.Context, id, uID string) (string, error) {
+	args := m.Called(ctx, id, uID)
+	return args.String(0), args.Error(1)
+}
+func (m *WorkspaceMocks) ListMembers(ctx context.Context, id string) ([]repository.Member, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]repository.Member), args.Error(1)
+}
+func (m *WorkspaceMocks) AddMember(ctx context.Context, id, email, role string) (repository.Member, error) {
+	args := m.Called(ctx, id, email, role)
+	return args.Get(0).(repository.Member), args.Error(1)
+}
+func (m *WorkspaceMocks) UpdateMemberRole(ctx context.Context, id, uID, role string) (repository.Member, error) {
+	args := m.Called(ctx, id, uID, role)
+	return args.Get(0).(repository.Member), args.Error(1)
+}
+func (m *WorkspaceMocks) RemoveMember(ctx context.Context, id, uID string) error {
+	return m.Called(ctx, id, uID).Error(0)
+}
+func (m *WorkspaceMocks) CreateInvitation(ctx context.Context, inv *model.WorkspaceInvitation) error {
+	return m.Called(ctx, inv).Error(0)
+}
+func (m *WorkspaceMocks) GetInvitation(ctx context.Context, invID string) (model.WorkspaceInvitation, error) {
+	args := m.Called(ctx, invID)
+	return args.Get(0).(model.WorkspaceInvitation), args.Error(1)
+}
+func (m *WorkspaceMocks) ListInvitations(ctx context.Context, id string) ([]model.WorkspaceInvitation, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]model.WorkspaceInvitation), args.Error(1)
+}
+func (m *WorkspaceMocks) RevokeInvitation(ctx context.Context, id, invID string) error {
+	return m.Called(ctx, id, invID).Error(0)
+}
+func (m *WorkspaceMocks) AcceptInvitation(ctx context.Context, invID, uID string) (repository.Member, error) {
+	args := m.Called(ctx, invID, uID)
+	return args.Get(0).(repository.Member), args.Error(1)
+}
+func (m *WorkspaceMocks) DeclineInvitation(ctx context.Context, invID string) error {
+	return m.Called(ctx, invID).Error(0)
+}
+
+// Сервис рабочих пространств
+func (m *WorkspaceMocks) CreateWorkspace(ctx context.Context, uID, name string) (repository.WorkspaceSummary, error) {
+	args := m.Called(ctx, uID, name)
+	return args.Get(0).(repository.WorkspaceSummary), args.Error(1)
+}
+func (m *WorkspaceMocks) ListWorkspaces(ctx context.Context, uID string) ([]repository.WorkspaceSummary, error) {
+	args := m.Called(ctx, uID)
+	return args.Get(0).([]repository.WorkspaceSummary), args.Error(1)
+}
+func (m *WorkspaceMocks) GetWorkspace(ctx context.Context, id, uID string) (repository.WorkspaceSummary, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).(repository.WorkspaceSummary), args.Error(1)
+}
+func (m *WorkspaceMocks) RenameWorkspace(ctx context.Context, id, uID, name string) (repository.WorkspaceSummary, error) {
+	args := m.Called(ctx, id, uID, name)
+	return args.Get(0).(repository.WorkspaceSummary), args.Error(1)
+}
+func (m *WorkspaceMocks) DeleteWorkspace(ctx context.Context, id, uID string) error {
+	return m.Called(ctx, id, uID).Error(0)
+}
+func (m *WorkspaceMocks) SetWorkflow(ctx context.Context, id, uID string, wf *model.Workflow) (repository.WorkspaceSummary, error) {
+	args := m.Called(ctx, id, uID, wf)
+	return args.Get(0).(repository.WorkspaceSummary), args.Error(1)
+}
+func (m *WorkspaceMocks) ListWorkspaceTasks(ctx context.Context, id, uID, filter string, page repository.PageRequest) (repository.TaskPage, error) {
+	args := m.Called(ctx, id, uID, filter, page)
+	return args.Get(0).(repository.TaskPage), args.Error(1)
+}
+func (m *WorkspaceMocks) ListWorkspaceMembers(ctx context.Context, id, uID string) ([]repository.Member, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).([]repository.Member), args.Error(1)
+}
+func (m *WorkspaceMocks) AddWorkspaceMember(ctx context.Context, id, uID, email, role string) (repository.Member, error) {
+	args := m.Called(ctx, id, uID, email, role)
+	return args.Get(0).(repository.Member), args.Error(1)
+}
+func (m *WorkspaceMocks) ChangeMemberRole(ctx context.Context, id, uID, memberID, role string) (repository.Member, error) {
+	args := m.Called(ctx, id, uID, memberID, role)
+	return args.Get(0).(repository.Member), args.Error(1)
+}
+func (m *WorkspaceMocks) RemoveWorkspaceMember(ctx context.Context, id, uID, memberID string) error {
+	return m.Called(ctx, id, uID, memberID).Error(0)
+}
+func (m *WorkspaceMocks) InviteMember(ctx context.Context, id, uID, email, role string) (model.WorkspaceInvitation, string, error) {
+	args := m.Called(ctx, id, uID, email, role)
+	return args.Get(0).(model.WorkspaceInvitation), args.String(1), args.Error(2)
+}
+func (m *WorkspaceMocks) ListPendingInvitations(ctx context.Context, id, uID string) ([]model.WorkspaceInvitation, error) {
+	args := m.Called(ctx, id, uID)
+	return args.Get(0).([]model.WorkspaceInvitation), args.Error(1)
+}
+func (m *WorkspaceMocks) RevokeWorkspaceInvitation(ctx context.Context, id, uID, invID string) error {
+	return m.Called(ctx, id, uID, invID).Error(0)
+}
+func (m *WorkspaceMocks) InvitationByToken(ctx context.Context, token string) (model.WorkspaceInvitation, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(model.WorkspaceInvitation), args.Error(1)
+}
+func (m *WorkspaceMocks) AcceptInvitationToken(ctx context.Context, token, uID string) (repository.Member, error) {
+	args := m.Called(ctx, token, uID)
+	return args.Get(0).(repository.Member), args.Error(1)
+}
+func (m *WorkspaceMocks) DeclineInvitationToken(ctx context.Context, token string) error {
+	return m.Called(ctx, token).Error(0)
+}
+
+type CommentMocks struct {
+	mock.Mock
+}
+
+// Репозиторий комментариев
+func (m *CommentMocks) Create(ctx context.Context, c *model.Comment) error {
+	return m.Called(ctx, c).Error(0)
+}
+func (m *CommentMocks) GetByID(ctx context.Context, id, taskID string) (model.Comment, error) {
+	args := m.Called(ctx, id, taskID)
+	return args.Get(0).(model.Comment), args.Error(1)
+}
+func (m *CommentMocks) ListByTask(ctx context.Context, taskID string) ([]model.Comment, error) {
+	args := m.Called(ctx, taskID)
+	return args.Get(0).([]model.Comment), args.Error(1)
+}
+func (m *CommentMocks) Update(ctx context.Context, c *model.Comment) error {
+	return m.Called(ctx, c).Error(0)
+}
+func (m *CommentMocks) Delete(ctx context.Context, id, taskID string) error {
+	return m.Called(ctx, id, taskID).Error(0)
+}
+func (m *CommentMocks) MentionCandidates(ctx context.Context, task model.Task) ([]repository.MentionCandidate, error) {
+	args := m.Called(ctx, task)
+	return args.Get(0).([]repository.MentionCandidate), args.Error(1)
+}
+
+// Сервис комментариев
+func (m *CommentMocks) ListComments(ctx context.Context, taskID, uID string) ([]model.Comment, error) {
+	args := m.Called(ctx, taskID, uID)
+	return args.Get(0).([]model.Comment), args.Error(1)
+}
+func (m *CommentMocks) AddComment(ctx context.Context, taskID, uID, body string) (model.Comment, error) {
+	args := m.Called(ctx, taskID, uID, body)
+	return args.Get(0).(model.Comment), args.Error(1)
+}
+func (m *CommentMocks) EditComment(ctx context.Context, id, taskID, uID, body string) (model.Comment, error) {
+	args := m.Called(ctx, id, taskID, uID, body)
+	return args.Get(0).(model.Comment), args.Error(1)
+}
+func (m *CommentMocks) DeleteComment(ctx context.Context, id, taskID, uID string) error {
+	return m.Called(ctx, id, taskID, uID).Error(0)
+}
+
+type AttachmentMocks struct {
+	mock.Mock
+}
+
+// Репозиторий вложений
+// Create hands fits the usage given as the second Return value, if any.
+func (m *AttachmentMocks) Create(ctx context.Context, a *model.Attachment, fits func(used int64) error) error {
+	args := m.Called(ctx, a)
+	if len(args) > 1 {
+		if err := fits(args.Get(1).(int64)); err != nil {
+			return err
+		}
+	}
+	return args.Error(0)
+}
+func (m *AttachmentMocks) GetByID(ctx context.Context, id, taskID string) (model.Attachment, error) {
+	args := m.Called(ctx, id, taskID)
+	return args.Get(0).(model.Attachment), args.Error(1)
+}
+func (m *AttachmentMocks) ListByTask(ctx context.Context, taskID string) ([]model.Attachment, error) {
+	args := m.Called(ctx, taskID)
+	return args.Get(0).([]model.Attachment), args.Error(1)
+}
+func (m *AttachmentMocks) Delete(ctx context.Context, id, taskID string) error {
+	return m.Called(ctx, id, taskID).Error(0)
+}
+func (m *AttachmentMocks) UsageByUser(ctx context.Context, uID string) (int64, error) {
+	args := m.Called(ctx, uID)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *AttachmentMocks) Orphans(ctx context.Context, limit int) ([]model.Attachment, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]model.Attachment), args.Error(1)
+}
+
+// Сервис вложений
+func (m *AttachmentMocks) ListAttachments(ctx context.Context, taskID, uID string) ([]model.Attachment, error) {
+	args := m.Called(ctx, taskID, uID)
+	return args.Get(0).([]model.Attachment), args.Error(1)
+}
+func (m *AttachmentMocks) UploadAttachment(ctx context.Context, taskID, uID string, upload model.AttachmentUpload) (model.Attachment, error) {
+	args := m.Called(ctx, taskID, uID, upload)
+	return args.Get(0).(model.Attachment), args.Error(1)
+}
+func (m *AttachmentMocks) OpenAttachment(ctx context.Context, id, taskID, uID string) (model.Attachment, io.ReadCloser, error) {
+	args := m.Called(ctx, id, taskID, uID)
+	body, _ := args.Get(1).(io.ReadCloser)
+	return args.Get(0).(model.Attachment), body, args.Error(2)
+}
+func (m *AttachmentMocks) DeleteAttachment(ctx context.Context, id, taskID, uID string) error {
+	return m.Called(ctx, id, taskID, uID).Error(0)
+}
+func (m *AttachmentMocks) PurgeOrphans(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
 }